@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/process/checks"
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// processesHandler lists the processes collected by the last run of the process check, filtered and sorted
+// according to the query parameters
+func processesHandler(w http.ResponseWriter, req *http.Request) {
+	query, err := parseProcessQuery(req.URL.Query())
+	if err != nil {
+		setJSONError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	msgs, ok := checks.GetCheckOutput(config.ProcessCheckName)
+	if !ok {
+		setJSONError(w, errors.New("process check is not running or has not been scheduled yet"), http.StatusNotFound)
+		return
+	}
+
+	entries, err := checks.QueryProcesses(msgs, query)
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to query processes: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []*checks.ProcessListEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		_ = log.Error(err)
+	}
+}
+
+func parseProcessQuery(values url.Values) (*checks.ProcessQuery, error) {
+	query := &checks.ProcessQuery{
+		ContainerID: values.Get("container"),
+		User:        values.Get("user"),
+		SortBy:      values.Get("sort"),
+	}
+
+	if cmdline := values.Get("cmdline"); cmdline != "" {
+		r, err := regexp.Compile(cmdline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cmdline pattern: %s", err)
+		}
+		query.Cmdline = r
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit: %s", err)
+		}
+		query.Limit = l
+	}
+
+	return query, query.Validate()
+}
@@ -25,6 +25,7 @@ func setupHandlers(r *mux.Router) {
 	r.HandleFunc("/agent/status", statusHandler).Methods("GET")
 	r.HandleFunc("/agent/tagger-list", getTaggerList).Methods("GET")
 	r.HandleFunc("/check/{check}", checkHandler).Methods("GET")
+	r.HandleFunc("/processes", processesHandler).Methods("GET")
 }
 
 // StartServer starts the config server
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	apiutil "github.com/DataDog/datadog-agent/pkg/api/util"
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/checks"
+)
+
+const processesURLTpl = "http://%s/processes"
+
+type psFlags struct {
+	container string
+	user      string
+	cmdline   string
+	sort      string
+	limit     int
+	json      bool
+}
+
+var psArgs psFlags
+
+// PsCmd is a command that lists the processes collected by a running process-agent
+var PsCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "List the processes collected by the process check of a running agent, as sent to Datadog",
+	Long: `List the processes collected by the last run of the process check of a running agent, along with
+their container and its tags, exactly as sent to Datadog. Processes can be filtered by container, user and
+command line, and sorted by CPU, RSS or IO usage.`,
+	Args:         cobra.NoArgs,
+	RunE:         runPsCmd,
+	SilenceUsage: true,
+}
+
+func init() {
+	PsCmd.Flags().StringVar(&psArgs.container, "container", "", "Only list the processes of the container with this ID or ID prefix")
+	PsCmd.Flags().StringVarP(&psArgs.user, "user", "u", "", "Only list the processes of the user with this name or uid")
+	PsCmd.Flags().StringVar(&psArgs.cmdline, "cmdline", "", "Only list the processes whose command line matches this regular expression")
+	PsCmd.Flags().StringVarP(&psArgs.sort, "sort", "s", checks.SortByPID, "Sort processes by pid, cpu, rss or io")
+	PsCmd.Flags().IntVarP(&psArgs.limit, "limit", "n", 0, "Maximum number of processes to list, 0 for no limit")
+	PsCmd.Flags().BoolVar(&psArgs.json, "json", false, "Output processes as JSON")
+}
+
+func runPsCmd(cmd *cobra.Command, _ []string) error {
+	if err := initConfig(os.Stdout, cmd); err != nil {
+		return err
+	}
+
+	processesURL, err := getProcessesURL()
+	if err != nil {
+		return err
+	}
+
+	return listProcesses(os.Stdout, processesURL)
+}
+
+func listProcesses(w io.Writer, processesURL string) error {
+	body, err := apiutil.DoGet(httpClient, processesURL, apiutil.LeaveConnectionOpen)
+	if err != nil {
+		return fmt.Errorf("could not list processes: %s", err)
+	}
+
+	if psArgs.json {
+		var out bytes.Buffer
+		if err := json.Indent(&out, body, "", "  "); err != nil {
+			return err
+		}
+		_, err := out.WriteTo(w)
+		return err
+	}
+
+	var entries []*checks.ProcessListEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return fmt.Errorf("could not parse processes: %s", err)
+	}
+	return checks.HumanFormatProcessList(entries, w)
+}
+
+func getProcessesURL() (string, error) {
+	addressPort, err := ddconfig.GetProcessAPIAddressPort()
+	if err != nil {
+		return "", fmt.Errorf("config error: %s", err.Error())
+	}
+
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"container": psArgs.container,
+		"user":      psArgs.user,
+		"cmdline":   psArgs.cmdline,
+		"sort":      psArgs.sort,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if psArgs.limit > 0 {
+		values.Set("limit", strconv.Itoa(psArgs.limit))
+	}
+
+	u := fmt.Sprintf(processesURLTpl, addressPort)
+	if len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+	return u, nil
+}
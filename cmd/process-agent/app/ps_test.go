@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/checks"
+)
+
+func fakeProcessesServer(t *testing.T, entries []*checks.ProcessListEntry) *httptest.Server {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		b, err := json.Marshal(entries)
+		require.NoError(t, err)
+
+		_, err = w.Write(b)
+		require.NoError(t, err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func TestListProcesses(t *testing.T) {
+	entries := []*checks.ProcessListEntry{
+		{
+			Process: &model.Process{
+				Pid:     42,
+				Command: &model.Command{Args: []string{"redis-server", "*:6379"}, Ppid: 1},
+				User:    &model.ProcessUser{Name: "redis"},
+				Memory:  &model.MemoryStat{Rss: 2048},
+				Cpu:     &model.CPUStat{TotalPct: 2.5},
+			},
+			ContainerTags: []string{"image_name:redis"},
+		},
+	}
+	server := fakeProcessesServer(t, entries)
+	defer server.Close()
+	defer func(args psFlags) { psArgs = args }(psArgs)
+
+	t.Run("table", func(t *testing.T) {
+		psArgs.json = false
+		var b strings.Builder
+		require.NoError(t, listProcesses(&b, server.URL))
+
+		var expected strings.Builder
+		require.NoError(t, checks.HumanFormatProcessList(entries, &expected))
+		assert.Equal(t, expected.String(), b.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		psArgs.json = true
+		var b strings.Builder
+		require.NoError(t, listProcesses(&b, server.URL))
+
+		var decoded []*checks.ProcessListEntry
+		require.NoError(t, json.Unmarshal([]byte(b.String()), &decoded))
+		assert.Equal(t, entries, decoded)
+	})
+}
+
+func TestGetProcessesURL(t *testing.T) {
+	config.Datadog.Set("process_config.cmd_port", 6162)
+	defer func(args psFlags) { psArgs = args }(psArgs)
+
+	psArgs.container = "abc"
+	psArgs.cmdline = "^java .*"
+	psArgs.sort = checks.SortByRSS
+	psArgs.limit = 10
+
+	u, err := getProcessesURL()
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:6162/processes?cmdline=%5Ejava+.%2A&container=abc&limit=10&sort=rss", u)
+}
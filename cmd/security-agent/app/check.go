@@ -12,7 +12,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/cihub/seelog"
@@ -22,6 +24,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/compliance/agent"
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/export"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
@@ -41,6 +44,11 @@ var (
 		dumpRegoInput     string
 		dumpReports       string
 		skipRegoEval      bool
+		dir               string
+		output            string
+		outputFile        string
+		failOnFindings    bool
+		waivers           string
 	}{}
 )
 
@@ -53,6 +61,11 @@ func setupCheckCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&checkArgs.dumpRegoInput, "dump-rego-input", "", "", "Path to file where to dump the Rego input JSON")
 	cmd.Flags().StringVarP(&checkArgs.dumpReports, "dump-reports", "", "", "Path to file where to dump reports")
 	cmd.Flags().BoolVarP(&checkArgs.skipRegoEval, "skip-rego-eval", "", false, "Skip rego evaluation")
+	cmd.Flags().StringVarP(&checkArgs.dir, "dir", "", "", "Compliance suites directory to read rules from, instead of the configured one")
+	cmd.Flags().StringVarP(&checkArgs.output, "output", "o", "", fmt.Sprintf("Write a report of the results in the given format: %s, %s or %s", export.JSON, export.SARIF, export.JUnit))
+	cmd.Flags().StringVarP(&checkArgs.outputFile, "output-file", "", "", "Path to file where to write the report, instead of the standard output")
+	cmd.Flags().BoolVarP(&checkArgs.failOnFindings, "fail-on-findings", "", false, "Exit with an error if a rule failed or couldn't be evaluated")
+	cmd.Flags().StringVarP(&checkArgs.waivers, "waivers", "", "", "Waiver file accepting the failures of some rules, instead of the configured one")
 }
 
 // CheckCmd returns a cobra command to run security agent checks
@@ -70,12 +83,29 @@ func CheckCmd(confPathArrayGetter func() []string) *cobra.Command {
 }
 
 func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
-	err := configureLogger()
-	if err != nil {
+	var (
+		outputFormat export.Format
+		err          error
+	)
+	if checkArgs.output != "" {
+		if outputFormat, err = export.ParseFormat(checkArgs.output); err != nil {
+			return err
+		}
+	} else if checkArgs.outputFile != "" || checkArgs.failOnFindings {
+		return errors.New("--output-file and --fail-on-findings require an --output format")
+	}
+
+	// keep the standard output for the report
+	logWriter := io.Writer(os.Stdout)
+	if outputFormat != "" && checkArgs.outputFile == "" {
+		logWriter = os.Stderr
+	}
+
+	if err := configureLogger(logWriter); err != nil {
 		return err
 	}
 
-	if checkArgs.skipRegoEval && checkArgs.dumpReports != "" {
+	if checkArgs.skipRegoEval && (checkArgs.dumpReports != "" || outputFormat != "") {
 		return errors.New("skipping the rego evaluation does not allow the generation of reports")
 	}
 
@@ -139,6 +169,27 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		return err
 	}
 
+	configDir := checkArgs.dir
+	if configDir == "" {
+		configDir = config.Datadog.GetString("compliance_config.dir")
+	}
+
+	if outputFormat != "" {
+		reporter.exportReport = export.NewReport()
+
+		suiteFiles := []string{checkArgs.file}
+		if checkArgs.file == "" {
+			if suiteFiles, err = filepath.Glob(filepath.Join(configDir, "*.yaml")); err != nil {
+				return err
+			}
+		}
+		for _, suiteFile := range suiteFiles {
+			if err := reporter.exportReport.AddSuiteFile(suiteFile); err != nil {
+				log.Warnf("Failed to read rule descriptions from %s: %v", suiteFile, err)
+			}
+		}
+	}
+
 	if ruleID != "" {
 		log.Infof("Looking for rule with ID=%s", ruleID)
 		options = append(options, checks.WithMatchRule(checks.IsRuleID(ruleID)))
@@ -160,13 +211,28 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 
 	options = append(options, checks.WithRegoEvalSkip(checkArgs.skipRegoEval))
 
+	waiversFile := checkArgs.waivers
+	if waiversFile == "" {
+		waiversFile = config.Datadog.GetString("compliance_config.waivers_file")
+	}
+	if waiversFile != "" {
+		options = append(options, checks.WithWaivers(waiversFile), checks.WithHostTags(coreconfig.GetConfiguredTags(false)))
+	}
+
 	if checkArgs.file != "" {
 		err = agent.RunChecksFromFile(reporter, checkArgs.file, options...)
 	} else {
-		configDir := config.Datadog.GetString("compliance_config.dir")
 		err = agent.RunChecks(reporter, configDir, options...)
 	}
 
+	// write the report of the checks that ran, even if some of them failed to run
+	if outputFormat != "" {
+		if err := reporter.writeReport(outputFormat, checkArgs.outputFile); err != nil {
+			log.Errorf("Failed to write report: %v", err)
+			return err
+		}
+	}
+
 	if err != nil {
 		log.Errorf("Failed to run checks: %v", err)
 		return err
@@ -177,10 +243,16 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		return err
 	}
 
+	if outputFormat != "" && checkArgs.failOnFindings {
+		if summary := reporter.exportReport.Summary(); summary.HasFindings() {
+			return fmt.Errorf("%d rule results failed and %d couldn't be evaluated", summary.Failed, summary.Errors)
+		}
+	}
+
 	return nil
 }
 
-func configureLogger() error {
+func configureLogger(w io.Writer) error {
 	var (
 		logFormat = "%LEVEL | %Msg%n"
 		logLevel  = "info"
@@ -190,7 +262,7 @@ func configureLogger() error {
 		logFormat = fmt.Sprintf("%%Date(%s) | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n", logDateFormat)
 		logLevel = "trace"
 	}
-	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(os.Stdout, seelog.DebugLvl, logFormat)
+	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(w, seelog.DebugLvl, logFormat)
 	if err != nil {
 		return err
 	}
@@ -204,6 +276,7 @@ type RunCheckReporter struct {
 	reporter        event.Reporter
 	events          map[string][]*event.Event
 	dumpReportsPath string
+	exportReport    *export.Report
 }
 
 // NewCheckReporter creates a new RunCheckReporter
@@ -235,6 +308,14 @@ func NewCheckReporter(stopper startstop.Stopper, report bool, dumpReportsPath st
 func (r *RunCheckReporter) Report(event *event.Event) {
 	r.events[event.AgentRuleID] = append(r.events[event.AgentRuleID], event)
 
+	if r.exportReport != nil {
+		r.exportReport.Report(event)
+		if r.reporter != nil {
+			r.reporter.Report(event)
+		}
+		return
+	}
+
 	eventJSON, err := checks.PrettyPrintJSON(event, "  ")
 	if err != nil {
 		log.Errorf("Failed to marshal rule event: %v", err)
@@ -265,6 +346,24 @@ func (r *RunCheckReporter) dumpReports() error {
 	return nil
 }
 
+// writeReport writes the report of the checks in the given format, to a file or to the standard output
+func (r *RunCheckReporter) writeReport(format export.Format, path string) error {
+	if path == "" {
+		return r.exportReport.Write(os.Stdout, format)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := r.exportReport.Write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func init() {
 	complianceCmd.AddCommand(CheckCmd(func() []string {
 		return confPathArray
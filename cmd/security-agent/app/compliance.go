@@ -146,6 +146,10 @@ func startCompliance(hostname string, stopper startstop.Stopper, statsdClient *d
 		}
 	}
 
+	if waiversFile := coreconfig.Datadog.GetString("compliance_config.waivers_file"); waiversFile != "" {
+		options = append(options, checks.WithWaivers(waiversFile), checks.WithHostTags(coreconfig.GetConfiguredTags(false)))
+	}
+
 	agent, err := agent.New(
 		reporter,
 		scheduler,
@@ -16,6 +16,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -122,6 +123,7 @@ var (
 		remoteStorageFormats     []string
 		remoteStorageCompression bool
 		remoteRequest            bool
+		policyPathDepth          int
 	}{}
 
 	activityDumpGenerateCmd = &cobra.Command{
@@ -153,6 +155,18 @@ var (
 		RunE:  listActivityDumps,
 	}
 
+	activityDumpDiffCmd = &cobra.Command{
+		Use:   "diff",
+		Short: "compare the process lineages, files, network flows and DNS names of two activity dumps",
+		RunE:  diffActivityDumps,
+	}
+
+	activityDumpDiffArgs = struct {
+		base   string
+		target string
+		json   bool
+	}{}
+
 	selfTestCmd = &cobra.Command{
 		Use:   "self-test",
 		Short: "Run runtime self test",
@@ -184,6 +198,24 @@ var (
 		RunE:  evalRule,
 	}
 
+	testPolicyCmd = &cobra.Command{
+		Use:   "test",
+		Short: "Replay events against policies and report the rules they trigger",
+		Long: `Replay events, described as the values of their fields in JSON files, against the policies of a directory
+and report the rules they trigger, with the values of the fields of the rules. Events are evaluated in order, so that
+the variables set by the actions of a rule are seen by the next events. Expectations list, for each rule, the names of
+the events expected to trigger it, so that policies can be tested without running system-probe.`,
+		RunE: testPolicy,
+	}
+
+	testPolicyArgs = struct {
+		dir                string
+		events             string
+		expectations       string
+		updateExpectations bool
+		json               bool
+	}{}
+
 	downloadPolicyCmd = &cobra.Command{
 		Use:   "download",
 		Short: "Download policies",
@@ -315,6 +347,33 @@ func init() {
 		false,
 		"when set, the transcoding will be done by system-probe instead of the current security-agent instance",
 	)
+	activityDumpGenerateEncodingCmd.Flags().IntVar(
+		&activityDumpArgs.policyPathDepth,
+		"policy-path-depth",
+		sprobe.DefaultPolicyPathDepth,
+		fmt.Sprintf("number of directories kept in the file paths of the %s format, deeper files are matched with a glob. Set to 0 to keep full paths. Ignored with --remote, system-probe uses its own configuration.", dump.Policy),
+	)
+
+	activityDumpDiffCmd.Flags().StringVar(
+		&activityDumpDiffArgs.base,
+		"base",
+		"",
+		fmt.Sprintf("path to the base activity dump file, in one of the %v formats", []dump.StorageFormat{dump.PROTOBUF, dump.JSON}),
+	)
+	_ = activityDumpDiffCmd.MarkFlagRequired("base")
+	activityDumpDiffCmd.Flags().StringVar(
+		&activityDumpDiffArgs.target,
+		"target",
+		"",
+		"path to the activity dump file compared to the base one",
+	)
+	_ = activityDumpDiffCmd.MarkFlagRequired("target")
+	activityDumpDiffCmd.Flags().BoolVar(
+		&activityDumpDiffArgs.json,
+		"json",
+		false,
+		"output the diff as JSON",
+	)
 
 	processCacheCmd.AddCommand(processCacheDumpCmd)
 	runtimeCmd.AddCommand(processCacheCmd)
@@ -325,6 +384,7 @@ func init() {
 	activityDumpCmd.AddCommand(activityDumpGenerateCmd)
 	activityDumpCmd.AddCommand(activityDumpListCmd)
 	activityDumpCmd.AddCommand(activityDumpStopCmd)
+	activityDumpCmd.AddCommand(activityDumpDiffCmd)
 	runtimeCmd.AddCommand(activityDumpCmd)
 
 	runtimeCmd.AddCommand(checkPoliciesCmd)
@@ -338,6 +398,14 @@ func init() {
 	_ = evalCmd.MarkFlagRequired("event-file")
 	evalCmd.Flags().BoolVar(&evalArgs.debug, "debug", false, "Display an event dump if the evaluation fail")
 
+	commonPolicyCmd.AddCommand(testPolicyCmd)
+	testPolicyCmd.Flags().StringVar(&testPolicyArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+	testPolicyCmd.Flags().StringVar(&testPolicyArgs.events, "events", "", "Path to a JSON file of events, or to a directory of JSON files")
+	_ = testPolicyCmd.MarkFlagRequired("events")
+	testPolicyCmd.Flags().StringVar(&testPolicyArgs.expectations, "expectations", "", "Path to a YAML file listing, for each rule, the events expected to trigger it")
+	testPolicyCmd.Flags().BoolVar(&testPolicyArgs.updateExpectations, "update-expectations", false, "Write the rules triggered by the events to the expectations file instead of checking them")
+	testPolicyCmd.Flags().BoolVar(&testPolicyArgs.json, "json", false, "Output the report as JSON")
+
 	runtimeCmd.AddCommand(selfTestCmd)
 	runtimeCmd.AddCommand(reloadPoliciesCmd)
 
@@ -548,6 +616,40 @@ func stopActivityDump(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func diffActivityDumps(cmd *cobra.Command, args []string) error {
+	base := sprobe.NewEmptyActivityDump()
+	if err := base.Decode(activityDumpDiffArgs.base); err != nil {
+		return fmt.Errorf("couldn't decode %s: %w", activityDumpDiffArgs.base, err)
+	}
+	target := sprobe.NewEmptyActivityDump()
+	if err := target.Decode(activityDumpDiffArgs.target); err != nil {
+		return fmt.Errorf("couldn't decode %s: %w", activityDumpDiffArgs.target, err)
+	}
+
+	diff := sprobe.DiffActivityDumps(base, target)
+
+	if activityDumpDiffArgs.json {
+		output, err := json.MarshalIndent(diff, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", string(output))
+		return nil
+	}
+
+	if diff.IsEmpty() {
+		fmt.Println("no difference found")
+		return nil
+	}
+	if diff.Added.Len() > 0 {
+		fmt.Printf("added:\n%s", diff.Added.String())
+	}
+	if diff.Removed.Len() > 0 {
+		fmt.Printf("removed:\n%s", diff.Removed.String())
+	}
+	return nil
+}
+
 func generateEncodingFromActivityDump(cmd *cobra.Command, args []string) error {
 	// Read configuration files received from the command line arguments '-c'
 	if err := common.MergeConfigurationFiles("datadog", confPathArray, cmd.Flags().Lookup("cfgpath").Changed); err != nil {
@@ -579,7 +681,7 @@ func generateEncodingFromActivityDump(cmd *cobra.Command, args []string) error {
 
 	} else {
 		// encoding request will be handled locally
-		ad := sprobe.NewEmptyActivityDump()
+		ad := sprobe.NewEmptyActivityDump(sprobe.WithPolicyPathDepth(activityDumpArgs.policyPathDepth))
 
 		// open and parse input file
 		if err := ad.Decode(activityDumpArgs.file); err != nil {
@@ -837,6 +939,141 @@ func evalRule(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func newTestEvent(eventType eval.EventType) (eval.Event, error) {
+	kind := model.ParseEvalEventType(eventType)
+	if kind == model.UnknownEventType {
+		return nil, fmt.Errorf("unknown event type `%s`", eventType)
+	}
+
+	m := &model.Model{}
+	event := m.NewEventWithType(kind)
+	event.Init()
+
+	return event, nil
+}
+
+func testPolicy(cmd *cobra.Command, args []string) error {
+	if testPolicyArgs.updateExpectations && testPolicyArgs.expectations == "" {
+		return errors.New("--update-expectations requires --expectations")
+	}
+
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	var evalOpts eval.Opts
+	evalOpts.
+		WithConstants(model.SECLConstants).
+		WithVariables(model.SECLVariables).
+		WithLegacyFields(model.SECLLegacyFields)
+
+	var opts rules.Opts
+	opts.
+		WithSupportedDiscarders(sprobe.SupportedDiscarders).
+		WithEventTypeEnabled(enabled).
+		WithReservedRuleIDs(sprobe.AllCustomRuleIDs()).
+		WithLogger(seclog.DefaultLogger)
+
+	model := &model.Model{}
+	ruleSet := rules.NewRuleSet(model, model.NewEvent, &opts, &evalOpts, &eval.MacroStore{})
+
+	agentVersion, err := utils.GetAgentSemverVersion()
+	if err != nil {
+		return err
+	}
+
+	agentVersionFilter, err := rules.NewAgentVersionFilter(agentVersion)
+	if err != nil {
+		return fmt.Errorf("failed to create agent version filter: %w", err)
+	}
+
+	loaderOpts := rules.PolicyLoaderOpts{
+		RuleFilters: []rules.RuleFilter{
+			agentVersionFilter,
+		},
+	}
+
+	provider, err := rules.NewPoliciesDirProvider(testPolicyArgs.dir, false)
+	if err != nil {
+		return err
+	}
+
+	loader := rules.NewPolicyLoader(provider)
+
+	if err := ruleSet.LoadPolicies(loader, loaderOpts); err.ErrorOrNil() != nil {
+		return err
+	}
+
+	events, err := rules.LoadTestEvents(testPolicyArgs.events)
+	if err != nil {
+		return fmt.Errorf("unable to load events: %w", err)
+	}
+
+	report := rules.NewPolicyTester(ruleSet, newTestEvent).Run(events)
+
+	if testPolicyArgs.json {
+		output, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", string(output))
+	} else {
+		printPolicyTestReport(os.Stdout, report)
+	}
+
+	if testPolicyArgs.expectations == "" {
+		return nil
+	}
+
+	if testPolicyArgs.updateExpectations {
+		f, err := os.Create(testPolicyArgs.expectations)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return rules.NewRuleExpectations(ruleSet, report).Write(f)
+	}
+
+	f, err := os.Open(testPolicyArgs.expectations)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	expectations, err := rules.LoadRuleExpectations(f)
+	if err != nil {
+		return fmt.Errorf("unable to load expectations: %w", err)
+	}
+
+	if err := expectations.Check(ruleSet, report); err.ErrorOrNil() != nil {
+		return err
+	}
+	fmt.Printf("All the expectations of %s are met\n", testPolicyArgs.expectations)
+
+	return nil
+}
+
+func printPolicyTestReport(w io.Writer, report *rules.PolicyTestReport) {
+	for _, result := range report.Events {
+		switch {
+		case result.Error != "":
+			fmt.Fprintf(w, "%s (%s): error: %s\n", result.Name, result.Type, result.Error)
+		case len(result.Matches) == 0:
+			fmt.Fprintf(w, "%s (%s): no rule triggered\n", result.Name, result.Type)
+		default:
+			fmt.Fprintf(w, "%s (%s):\n", result.Name, result.Type)
+			for _, match := range result.Matches {
+				fields := make([]string, 0, len(match.Fields))
+				for field, value := range match.Fields {
+					fields = append(fields, fmt.Sprintf("%s=%v", field, value))
+				}
+				sort.Strings(fields)
+				fmt.Fprintf(w, "\t%s: %s\n", match.RuleID, strings.Join(fields, " "))
+			}
+		}
+	}
+}
+
 func runRuntimeSelfTest(cmd *cobra.Command, args []string) error {
 	client, err := secagent.NewRuntimeSecurityClient()
 	if err != nil {
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf || windows
+// +build linux_bpf windows
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	networkconfig "github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http/debugging"
+	"github.com/DataDog/datadog-agent/pkg/network/replay"
+)
+
+func init() {
+	debugCommand.AddCommand(replayCommand)
+}
+
+var (
+	replayCommand = &cobra.Command{
+		Use:   "replay <capture file>",
+		Short: "Replay a pcap or pcapng capture through the DNS and userspace HTTP parsers",
+		Long: `Replay a pcap or pcapng capture through the DNS parser as well as the userspace
+HTTP/2 and Kafka decoders, and print the resulting stats as JSON. The system-probe
+configuration is honored, except that DNS stats are always collected. No running
+system-probe or privilege is required.`,
+		Args: cobra.ExactArgs(1),
+		RunE: replayCapture,
+	}
+)
+
+type replayOutput struct {
+	DNS       []debugging.DNSSummary     `json:"dns"`
+	HTTP      []debugging.RequestSummary `json:"http"`
+	Kafka     []debugging.KafkaSummary   `json:"kafka"`
+	Telemetry map[string]interface{}     `json:"telemetry"`
+}
+
+func replayCapture(_ *cobra.Command, args []string) error {
+	if _, err := setupConfig(); err != nil {
+		return err
+	}
+
+	cfg := networkconfig.New()
+	cfg.CollectDNSStats = true
+
+	res, err := replay.File(cfg, args[0])
+	if err != nil {
+		return fmt.Errorf("could not replay %s: %w", args[0], err)
+	}
+
+	out := replayOutput{
+		DNS:       debugging.DNS(res.DNS),
+		HTTP:      debugging.HTTP(res.HTTP, nil),
+		Kafka:     debugging.Kafka(res.Kafka),
+		Telemetry: res.Telemetry,
+	}
+	// sort the entries so that replaying the same capture always prints the same output
+	sort.Slice(out.DNS, func(i, j int) bool { return lessJSON(out.DNS[i], out.DNS[j]) })
+	sort.Slice(out.HTTP, func(i, j int) bool { return lessJSON(out.HTTP[i], out.HTTP[j]) })
+	sort.Slice(out.Kafka, func(i, j int) bool { return lessJSON(out.Kafka[i], out.Kafka[j]) })
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func lessJSON(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) < string(jb)
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux || windows
+// +build linux windows
+
+package modules
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// kafkaStatsBuffer holds the Kafka stats returned along with the connections of
+// each client, until the client fetches them. The connections payload doesn't
+// have a dedicated Kafka message, so these stats are served by a separate endpoint.
+// The clients which haven't fetched their stats for clientExpiry, such as the
+// ones not aware of this endpoint, are expired like in the network state.
+type kafkaStatsBuffer struct {
+	mux          sync.Mutex
+	byClient     map[string]*kafkaStatsClient
+	maxEntries   int
+	clientExpiry time.Duration
+}
+
+type kafkaStatsClient struct {
+	stats     map[kafka.Key]*kafka.RequestStat
+	lastFetch time.Time
+}
+
+func newKafkaStatsBuffer(maxEntries int, clientExpiry time.Duration) *kafkaStatsBuffer {
+	return &kafkaStatsBuffer{
+		byClient:     make(map[string]*kafkaStatsClient),
+		maxEntries:   maxEntries,
+		clientExpiry: clientExpiry,
+	}
+}
+
+// add buffers the given stats for the given client, merging them with the ones
+// it has not fetched yet. New entries beyond maxEntries are dropped.
+func (b *kafkaStatsBuffer) add(clientID string, stats map[kafka.Key]*kafka.RequestStat, now time.Time) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.removeExpiredClients(now)
+
+	if len(stats) == 0 {
+		return
+	}
+
+	c, ok := b.byClient[clientID]
+	if !ok {
+		b.byClient[clientID] = &kafkaStatsClient{stats: stats, lastFetch: now}
+		return
+	}
+	if c.stats == nil {
+		c.stats = stats
+		return
+	}
+	for key, stat := range stats {
+		if prev, ok := c.stats[key]; ok {
+			prev.CombineWith(stat)
+		} else if len(c.stats) < b.maxEntries {
+			c.stats[key] = stat
+		}
+	}
+}
+
+// getAndReset returns the stats buffered for the given client, and forgets them.
+func (b *kafkaStatsBuffer) getAndReset(clientID string, now time.Time) map[kafka.Key]*kafka.RequestStat {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	c, ok := b.byClient[clientID]
+	if !ok {
+		return nil
+	}
+	stats := c.stats
+	c.stats = nil
+	c.lastFetch = now
+	return stats
+}
+
+func (b *kafkaStatsBuffer) removeExpiredClients(now time.Time) {
+	for id, c := range b.byClient {
+		if c.lastFetch.Add(b.clientExpiry).Before(now) {
+			log.Debugf("expiring kafka stats client: %s, had %d stats", id, len(c.stats))
+			delete(b.byClient, id)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux || windows
+// +build linux windows
+
+package modules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestKafkaStatsBuffer(t *testing.T) {
+	client, broker := util.AddressFromString("10.0.0.1"), util.AddressFromString("10.0.0.2")
+	orders := kafka.NewKey(client, broker, 52800, 9092, "orders", kafka.ProduceAPIKey, 8)
+	payments := kafka.NewKey(client, broker, 52800, 9092, "payments", kafka.ProduceAPIKey, 8)
+
+	now := time.Now()
+	buffer := newKafkaStatsBuffer(1, time.Minute)
+	buffer.add("a", map[kafka.Key]*kafka.RequestStat{orders: {Count: 2}}, now)
+	buffer.add("a", map[kafka.Key]*kafka.RequestStat{orders: {Count: 3}, payments: {Count: 1}}, now)
+	buffer.add("b", map[kafka.Key]*kafka.RequestStat{payments: {Count: 4}}, now)
+
+	// the entry beyond the limit is dropped, while the known one is merged
+	assert.Equal(t, map[kafka.Key]*kafka.RequestStat{orders: {Count: 5}}, buffer.getAndReset("a", now))
+	assert.Empty(t, buffer.getAndReset("a", now))
+	assert.Equal(t, map[kafka.Key]*kafka.RequestStat{payments: {Count: 4}}, buffer.getAndReset("b", now))
+}
+
+func TestKafkaStatsBufferClientExpiry(t *testing.T) {
+	client, broker := util.AddressFromString("10.0.0.1"), util.AddressFromString("10.0.0.2")
+	orders := kafka.NewKey(client, broker, 52800, 9092, "orders", kafka.ProduceAPIKey, 8)
+
+	now := time.Now()
+	buffer := newKafkaStatsBuffer(10, time.Minute)
+	buffer.add("fetching", map[kafka.Key]*kafka.RequestStat{orders: {Count: 1}}, now)
+	buffer.add("idle", map[kafka.Key]*kafka.RequestStat{orders: {Count: 1}}, now)
+
+	// the clients never fetching their stats are expired, the others keep theirs
+	now = now.Add(50 * time.Second)
+	buffer.getAndReset("fetching", now)
+	now = now.Add(20 * time.Second)
+	buffer.add("fetching", map[kafka.Key]*kafka.RequestStat{orders: {Count: 2}}, now)
+	buffer.add("idle", map[kafka.Key]*kafka.RequestStat{orders: {Count: 2}}, now)
+	assert.Len(t, buffer.byClient, 2)
+	assert.Equal(t, map[kafka.Key]*kafka.RequestStat{orders: {Count: 2}}, buffer.getAndReset("idle", now))
+	assert.Equal(t, map[kafka.Key]*kafka.RequestStat{orders: {Count: 2}}, buffer.getAndReset("fetching", now))
+}
@@ -53,7 +53,7 @@ var NetworkTracer = module.Factory{
 		log.Infof("Creating tracer for: %s", filepath.Base(os.Args[0]))
 
 		t, err := tracer.NewTracer(ncfg)
-		return &networkTracer{tracer: t}, err
+		return &networkTracer{tracer: t, kafkaStats: newKafkaStatsBuffer(ncfg.MaxKafkaStatsBuffered, ncfg.ClientStateExpiry)}, err
 	},
 }
 
@@ -61,6 +61,7 @@ var _ module.Module = &networkTracer{}
 
 type networkTracer struct {
 	tracer       *tracer.Tracer
+	kafkaStats   *kafkaStatsBuffer
 	restartTimer *time.Timer
 }
 
@@ -82,6 +83,7 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 			w.WriteHeader(500)
 			return
 		}
+		nt.kafkaStats.add(id, cs.Kafka, time.Now())
 		contentType := req.Header.Get("Accept")
 		marshaler := encoding.GetMarshaler(contentType)
 		writeConnections(w, marshaler, cs)
@@ -105,6 +107,22 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 		}
 	}))
 
+	httpMux.HandleFunc("/dns_query_log", utils.WithConcurrencyLimit(1, func(w http.ResponseWriter, req *http.Request) {
+		records, err := nt.tracer.GetDNSQueryLog()
+		if err != nil {
+			log.Errorf("unable to retrieve dns query log: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, records)
+	}))
+
+	httpMux.HandleFunc("/kafka_stats", utils.WithConcurrencyLimit(utils.DefaultMaxConcurrentRequests, func(w http.ResponseWriter, req *http.Request) {
+		id := getClientID(req)
+		utils.WriteAsJSON(w, encoding.FormatKafkaAggregations(nt.kafkaStats.getAndReset(id, time.Now())))
+	}))
+
 	httpMux.HandleFunc("/debug/net_maps", func(w http.ResponseWriter, req *http.Request) {
 		cs, err := nt.tracer.DebugNetworkMaps()
 		if err != nil {
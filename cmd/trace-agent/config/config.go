@@ -256,6 +256,21 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 		}
 	}
 
+	if k := "apm_config.resource_normalization.enabled"; coreconfig.Datadog.IsSet(k) {
+		c.ResourceNormalization.Enabled = coreconfig.Datadog.GetBool(k)
+	}
+	if k := "apm_config.resource_normalization.services"; coreconfig.Datadog.IsSet(k) {
+		c.ResourceNormalization.Services = coreconfig.Datadog.GetStringSlice(k)
+	}
+	if k := "apm_config.resource_normalization.routes"; coreconfig.Datadog.IsSet(k) {
+		var routes []*config.RoutePattern
+		if err := coreconfig.Datadog.UnmarshalKey(k, &routes); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"service\": \"service_name\",\"pattern\":\"/users/{id}\"}]', error: %v", k, err)
+		} else {
+			c.ResourceNormalization.Routes = routes
+		}
+	}
+
 	if coreconfig.Datadog.IsSet("bind_host") || coreconfig.Datadog.IsSet("apm_config.apm_non_local_traffic") {
 		if coreconfig.Datadog.IsSet("bind_host") {
 			host := coreconfig.Datadog.GetString("bind_host")
@@ -288,6 +303,12 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 		MaxRequestBytes:        c.MaxRequestBytes,
 		SpanNameRemappings:     coreconfig.Datadog.GetStringMapString("otlp_config.traces.span_name_remappings"),
 		SpanNameAsResourceName: coreconfig.Datadog.GetBool("otlp_config.traces.span_name_as_resource_name"),
+		AttributesMapping: config.OTLPAttributesMapping{
+			Service:       coreconfig.Datadog.GetStringSlice("otlp_config.traces.attributes_mapping.service"),
+			Resource:      coreconfig.Datadog.GetStringSlice("otlp_config.traces.attributes_mapping.resource"),
+			OperationName: coreconfig.Datadog.GetStringSlice("otlp_config.traces.attributes_mapping.operation_name"),
+			PeerTags:      coreconfig.Datadog.GetStringMapStringSlice("otlp_config.traces.attributes_mapping.peer_tags"),
+		},
 	}
 
 	if coreconfig.Datadog.GetBool("apm_config.telemetry.enabled") {
@@ -418,6 +439,9 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 	if v := coreconfig.Datadog.GetInt("apm_config.max_catalog_entries"); v > 0 {
 		c.MaxCatalogEntries = v
 	}
+	if v := coreconfig.Datadog.GetInt("apm_config.debug_traces.buffer_size"); v > 0 {
+		c.DebugTracesBufferSize = v
+	}
 	if k := "apm_config.profiling_dd_url"; coreconfig.Datadog.IsSet(k) {
 		c.ProfilingProxy.DDURL = coreconfig.Datadog.GetString(k)
 	}
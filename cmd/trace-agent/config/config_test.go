@@ -432,6 +432,12 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(c.LogThrottling)
 	assert.True(c.OTLPReceiver.SpanNameAsResourceName)
 	assert.Equal(map[string]string{"a": "b", "and:colons": "in:values", "c": "d", "with.dots": "in.side"}, c.OTLPReceiver.SpanNameRemappings)
+	assert.Equal(config.OTLPAttributesMapping{
+		Service:       []string{"app.name", "service.name"},
+		Resource:      []string{"http.route"},
+		OperationName: []string{"app.operation"},
+		PeerTags:      map[string][]string{"peer.hostname": {"net.peer.name", "server.address"}},
+	}, c.OTLPReceiver.AttributesMapping)
 
 	noProxy := true
 	if _, ok := os.LookupEnv("NO_PROXY"); ok {
@@ -473,6 +479,15 @@ func TestFullYamlConfig(t *testing.T) {
 		},
 	}, c.ReplaceTags)
 
+	assert.Equal(config.ResourceNormalization{
+		Enabled:  true,
+		Services: []string{"web-store", "web-api"},
+		Routes: []*config.RoutePattern{
+			{Service: "web-store", Pattern: "/users/{name}/orders"},
+			{Pattern: "/files/*"},
+		},
+	}, c.ResourceNormalization)
+
 	assert.EqualValues([]string{"/health", "/500"}, c.Ignore["resource"])
 
 	o := c.Obfuscation
@@ -486,6 +501,11 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(o.RemoveStackTraces)
 	assert.True(o.Redis.Enabled)
 	assert.True(o.Memcached.Enabled)
+	assert.True(o.GraphQL.Enabled)
+	assert.True(o.AWS.Enabled)
+	assert.EqualValues([]string{"MessageGroupId"}, o.AWS.KeepParams)
+	assert.True(o.Message.Enabled)
+	assert.EqualValues([]string{"amqp.body"}, o.Message.RedactTags)
 	assert.True(o.CreditCards.Enabled)
 	assert.True(o.CreditCards.Luhn)
 }
@@ -514,6 +534,7 @@ func TestUndocumentedYamlConfig(t *testing.T) {
 	assert.Equal(1000.0, c.MaxEPS)
 	assert.Equal(25, c.ReceiverPort)
 	assert.Equal(120*time.Second, c.ConnectionResetInterval)
+	assert.Equal(500, c.DebugTracesBufferSize)
 	// watchdog
 	assert.Equal(0.07, c.MaxCPU)
 	assert.Equal(30e6, c.MaxMemory)
@@ -826,6 +847,36 @@ func TestLoadEnv(t *testing.T) {
 		assert.Contains(cfg.ReplaceTags, rule2)
 	})
 
+	env = "DD_APM_RESOURCE_NORMALIZATION_ROUTES"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `[{"service":"web","pattern":"/users/:id"}, {"pattern":"/files/*"}]`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := LoadConfigFile("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal([]*config.RoutePattern{
+			{Service: "web", Pattern: "/users/:id"},
+			{Pattern: "/files/*"},
+		}, cfg.ResourceNormalization.Routes)
+	})
+
+	env = "DD_OTLP_CONFIG_TRACES_ATTRIBUTES_MAPPING_PEER_TAGS"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `{"peer.service": ["peer.service"], "peer.db.name": ["db.name", "db.instance"]}`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := LoadConfigFile("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(map[string][]string{
+			"peer.service": {"peer.service"},
+			"peer.db.name": {"db.name", "db.instance"},
+		}, cfg.OTLPReceiver.AttributesMapping.PeerTags)
+	})
+
 	env = "DD_APM_FILTER_TAGS_REQUIRE"
 	t.Run(env, func(t *testing.T) {
 		defer cleanConfig()()
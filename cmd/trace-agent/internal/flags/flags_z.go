@@ -20,6 +20,22 @@ var (
 	// Info will display information about a running agent.
 	Info bool
 
+	// Traces will display the traces recently received by a running agent,
+	// optionally filtered using TracesFilter.
+	Traces bool
+
+	// TracesFilter holds the filters applied to the traces displayed using Traces.
+	TracesFilter struct {
+		Service  string
+		Resource string
+		Error    bool
+		Limit    int
+	}
+
+	// TraceID will display the span tree of the trace with this ID, as recently
+	// received by a running agent.
+	TraceID uint64
+
 	// CPUProfile specifies the path to output CPU profiling information to.
 	// When empty, CPU profiling is disabled.
 	CPUProfile string
@@ -44,6 +60,14 @@ func init() {
 	flag.BoolVar(&Version, "version", false, "Show version information and exit")
 	flag.BoolVar(&Info, "info", false, "Show info about running trace agent process and exit")
 
+	// local trace inspection
+	flag.BoolVar(&Traces, "traces", false, "Show the traces recently received by the running trace agent and exit")
+	flag.StringVar(&TracesFilter.Service, "traces-service", "", "Only show traces with this root service when using -traces")
+	flag.StringVar(&TracesFilter.Resource, "traces-resource", "", "Only show traces with a root resource containing this string when using -traces")
+	flag.BoolVar(&TracesFilter.Error, "traces-error", false, "Only show traces containing errors when using -traces")
+	flag.IntVar(&TracesFilter.Limit, "traces-limit", 50, "Maximum number of traces to show when using -traces")
+	flag.Uint64Var(&TraceID, "trace", 0, "Show the span tree of the trace with this ID, as received by the running trace agent, and exit")
+
 	// profiling
 	flag.StringVar(&CPUProfile, "cpuprofile", "", "Write cpu profile to file")
 	flag.StringVar(&MemProfile, "memprofile", "", "Write memory profile to `file`")
@@ -29,6 +29,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/api"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	tracelog "github.com/DataDog/datadog-agent/pkg/trace/log"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
@@ -85,6 +86,20 @@ func Run(ctx context.Context) {
 		return
 	}
 
+	if flags.Traces {
+		if err := inspect.PrintTraces(os.Stdout, cfg, inspect.Filter(flags.TracesFilter)); err != nil {
+			osutil.Exitf("Failed to print traces: %s", err)
+		}
+		return
+	}
+
+	if flags.TraceID != 0 {
+		if err := inspect.PrintTrace(os.Stdout, cfg, flags.TraceID); err != nil {
+			osutil.Exitf("Failed to print trace: %s", err)
+		}
+		return
+	}
+
 	if err := coreconfig.SetupLogger(
 		coreconfig.LoggerName("TRACE"),
 		coreconfig.Datadog.GetString("log_level"),
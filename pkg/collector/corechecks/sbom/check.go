@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	ddConfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+	"github.com/DataDog/datadog-agent/pkg/util/hostname"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	checkName                      = "sbom"
+	defaultHostScanIntervalSeconds = 3600
+	defaultLockfileMaxDepth        = 4
+	defaultMaxLockfileSize         = 10 * 1024 * 1024
+	defaultCacheSize               = 1000
+)
+
+// defaultLockfileDirs are the directories where applications are usually installed in container images
+var defaultLockfileDirs = []string{"/app", "/usr/src/app", "/srv", "/opt", "/home", "/var/www"}
+
+func init() {
+	core.RegisterCheck(checkName, CheckFactory)
+}
+
+// Config holds the sbom check configuration
+type Config struct {
+	HostScan                bool     `yaml:"host_scan"`
+	HostRoot                string   `yaml:"host_root"`
+	HostScanIntervalSeconds int      `yaml:"host_scan_interval_seconds"`
+	LockfileDirs            []string `yaml:"lockfile_dirs"`
+	LockfileMaxDepth        int      `yaml:"lockfile_max_depth"`
+	MaxLockfileSize         int64    `yaml:"max_lockfile_size"`
+	CacheSize               int      `yaml:"cache_size"`
+}
+
+// Parse parses the sbom check config and set default values
+func (c *Config) Parse(data []byte) error {
+	c.HostScan = true
+	c.HostRoot = "/"
+	if ddConfig.IsContainerized() {
+		c.HostRoot = "/host"
+	}
+	c.HostScanIntervalSeconds = defaultHostScanIntervalSeconds
+	c.LockfileDirs = defaultLockfileDirs
+	c.LockfileMaxDepth = defaultLockfileMaxDepth
+	c.MaxLockfileSize = defaultMaxLockfileSize
+	c.CacheSize = defaultCacheSize
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+
+	if c.HostScanIntervalSeconds <= 0 {
+		c.HostScanIntervalSeconds = defaultHostScanIntervalSeconds
+	}
+	if c.CacheSize <= 0 {
+		c.CacheSize = defaultCacheSize
+	}
+	return nil
+}
+
+// Check sends the software bills of materials of the host and of the container images
+type Check struct {
+	core.CheckBase
+	workloadmetaStore workloadmeta.Store
+	instance          *Config
+	processor         *processor
+	stopCh            chan struct{}
+}
+
+// Configure parses the check configuration and initializes the sbom check
+func (c *Check) Configure(config, initConfig integration.Data, source string) error {
+	if !ddConfig.Datadog.GetBool("sbom.enabled") {
+		return errors.New("collection of software bills of materials is disabled")
+	}
+
+	var err error
+
+	err = c.CommonConfigure(initConfig, config, source)
+	if err != nil {
+		return err
+	}
+
+	err = c.instance.Parse(config)
+	if err != nil {
+		return err
+	}
+
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	c.processor, err = newProcessor(sender, ddConfig.Datadog.GetString("container_proc_root"), c.instance.CacheSize, sbom.ScanOptions{
+		LockfileDirs:     c.instance.LockfileDirs,
+		LockfileMaxDepth: c.instance.LockfileMaxDepth,
+		MaxFileSize:      c.instance.MaxLockfileSize,
+	})
+	return err
+}
+
+// Run starts the sbom check
+func (c *Check) Run() error {
+	log.Infof("Starting long-running check %q", c.ID())
+	defer log.Infof("Shutting down long-running check %q", c.ID())
+
+	contEventsCh := c.workloadmetaStore.Subscribe(
+		checkName,
+		workloadmeta.NormalPriority,
+		workloadmeta.NewFilter(
+			[]workloadmeta.Kind{workloadmeta.KindContainer},
+			workloadmeta.SourceRuntime,
+			workloadmeta.EventTypeSet,
+		),
+	)
+	defer c.workloadmetaStore.Unsubscribe(contEventsCh)
+
+	processorCtx, stopProcessor := context.WithCancel(context.Background())
+	defer stopProcessor()
+
+	host, err := hostname.Get(processorCtx)
+	if err != nil {
+		log.Warnf("Couldn't get the hostname, the software bills of materials won't have one: %v", err)
+	}
+	c.processor.hostname = host
+
+	hostRoot := ""
+	if c.instance.HostScan {
+		hostRoot = c.instance.HostRoot
+	}
+	c.processor.start(processorCtx, hostRoot, time.Duration(c.instance.HostScanIntervalSeconds)*time.Second)
+
+	for {
+		select {
+		case eventBundle := <-contEventsCh:
+			c.processor.processEvents(eventBundle)
+		case <-c.stopCh:
+			return nil
+		}
+	}
+}
+
+// Stop stops the sbom check
+func (c *Check) Stop() { close(c.stopCh) }
+
+// Interval returns 0, it makes sbom a long-running check
+func (c *Check) Interval() time.Duration { return 0 }
+
+// CheckFactory registers the sbom check
+func CheckFactory() check.Check {
+	return &Check{
+		CheckBase:         core.NewCheckBase(checkName),
+		workloadmetaStore: workloadmeta.GetGlobalStore(),
+		instance:          &Config{},
+		stopCh:            make(chan struct{}),
+	}
+}
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	sourceHost           = "host"
+	sourceContainerImage = "container_image"
+
+	scanQueueSize = 100
+)
+
+// payload is the event sent to the event platform for each software bill of materials
+type payload struct {
+	Host        string    `json:"host"`
+	Source      string    `json:"source"`
+	ID          string    `json:"id"`
+	Tags        []string  `json:"tags,omitempty"`
+	GeneratedAt string    `json:"generated_at"`
+	CycloneDX   *sbom.BOM `json:"cyclonedx"`
+}
+
+// scanRequest is the request to scan the image of a container through its root filesystem
+type scanRequest struct {
+	imageID   string
+	image     workloadmeta.ContainerImage
+	rootfs    string
+	container string
+}
+
+type processor struct {
+	sender   aggregator.Sender
+	hostname string
+	procRoot string
+	opts     sbom.ScanOptions
+	queue    chan scanRequest
+
+	// scannedImages holds the images scanned or being scanned, so that each image is scanned once
+	scannedImages     *simplelru.LRU
+	scannedImagesLock sync.Mutex
+}
+
+func newProcessor(sender aggregator.Sender, procRoot string, cacheSize int, opts sbom.ScanOptions) (*processor, error) {
+	scannedImages, err := simplelru.NewLRU(cacheSize, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &processor{
+		sender:        sender,
+		procRoot:      procRoot,
+		opts:          opts,
+		queue:         make(chan scanRequest, scanQueueSize),
+		scannedImages: scannedImages,
+	}, nil
+}
+
+// start spawns a go routine scanning the host and the queued container images. The host isn't scanned when its root
+// is empty.
+func (p *processor) start(ctx context.Context, hostRoot string, hostScanInterval time.Duration) {
+	go p.processQueue(ctx, hostRoot, hostScanInterval)
+}
+
+// processEvents queues the scan of the images of the running containers which weren't scanned yet
+func (p *processor) processEvents(evBundle workloadmeta.EventBundle) {
+	close(evBundle.Ch)
+
+	log.Tracef("Processing %d events", len(evBundle.Events))
+
+	for _, event := range evBundle.Events {
+		container, ok := event.Entity.(*workloadmeta.Container)
+		if !ok {
+			log.Debugf("Expected workloadmeta.Container got %T, skipping", event.Entity)
+			continue
+		}
+
+		p.processContainer(container)
+	}
+}
+
+// processContainer queues the scan of the image of a container, the root filesystem of the container being read
+// through its main process. This root filesystem includes the writable layer of the container, so the packages
+// installed or removed by the first container scanned are attributed to its image, whose software bill of materials
+// is then cached and not updated by the scans of the other containers of the image. Reading the image layers
+// instead requires access to the storage of each container runtime.
+func (p *processor) processContainer(container *workloadmeta.Container) {
+	if !container.State.Running || container.PID <= 0 {
+		return
+	}
+
+	// the image ID is reported by the runtime. The image name isn't used instead, as a tag can be moved to another
+	// image and the software bill of materials of the new image would never be sent.
+	imageID := container.Image.ID
+	if imageID == "" {
+		log.Debugf("Container %q has no image ID, skipping", container.ID)
+		return
+	}
+
+	p.scannedImagesLock.Lock()
+	defer p.scannedImagesLock.Unlock()
+
+	if p.scannedImages.Contains(imageID) {
+		return
+	}
+
+	request := scanRequest{
+		imageID:   imageID,
+		image:     container.Image,
+		rootfs:    filepath.Join(p.procRoot, strconv.Itoa(container.PID), "root"),
+		container: container.ID,
+	}
+
+	select {
+	case p.queue <- request:
+		p.scannedImages.Add(imageID, struct{}{})
+	default:
+		// the image will be queued again with the next event of one of its containers
+		log.Debugf("Scan queue full, dropping the scan of image %q", imageID)
+	}
+}
+
+// processQueue scans the queued container images, and the host periodically
+func (p *processor) processQueue(ctx context.Context, hostRoot string, hostScanInterval time.Duration) {
+	var hostScanCh <-chan time.Time
+	if hostRoot != "" {
+		p.scanHost(ctx, hostRoot)
+
+		ticker := time.NewTicker(hostScanInterval)
+		defer ticker.Stop()
+		hostScanCh = ticker.C
+	}
+
+	for {
+		select {
+		case request := <-p.queue:
+			p.scanContainerImage(ctx, request)
+		case <-hostScanCh:
+			p.scanHost(ctx, hostRoot)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scanHost sends the software bill of materials of the host
+func (p *processor) scanHost(ctx context.Context, hostRoot string) {
+	inventory, err := sbom.Scan(ctx, hostRoot, p.opts)
+	if err != nil {
+		log.Warnf("Couldn't scan the host root filesystem %s: %v", hostRoot, err)
+		return
+	}
+
+	subject := sbom.Component{
+		BOMRef: "host:" + p.hostname,
+		Type:   sbom.ComponentTypeOperatingSystem,
+		Name:   p.hostname,
+	}
+	p.send(sourceHost, p.hostname, nil, sbom.NewBOM(subject, inventory, time.Now()))
+}
+
+// scanContainerImage sends the software bill of materials of a container image. The image is scanned again with the
+// next container using it when the scan fails, as the container may have exited in the meantime.
+func (p *processor) scanContainerImage(ctx context.Context, request scanRequest) {
+	inventory, err := sbom.Scan(ctx, request.rootfs, p.opts)
+	if err != nil {
+		log.Debugf("Couldn't scan image %q through container %q: %v", request.imageID, request.container, err)
+
+		p.scannedImagesLock.Lock()
+		p.scannedImages.Remove(request.imageID)
+		p.scannedImagesLock.Unlock()
+		return
+	}
+
+	subject := sbom.Component{
+		BOMRef:  request.imageID,
+		Type:    sbom.ComponentTypeContainer,
+		Name:    request.image.Name,
+		Version: request.image.Tag,
+	}
+
+	tags := []string{"image_id:" + request.imageID}
+	if request.image.Name != "" {
+		tags = append(tags, "image_name:"+request.image.Name)
+	}
+	if request.image.ShortName != "" {
+		tags = append(tags, "short_image:"+request.image.ShortName)
+	}
+	if request.image.Tag != "" {
+		tags = append(tags, "image_tag:"+request.image.Tag)
+	}
+
+	p.send(sourceContainerImage, request.imageID, tags, sbom.NewBOM(subject, inventory, time.Now()))
+}
+
+// send forwards a software bill of materials to the event platform
+func (p *processor) send(source string, id string, tags []string, bom *sbom.BOM) {
+	raw, err := json.Marshal(payload{
+		Host:        p.hostname,
+		Source:      source,
+		ID:          id,
+		Tags:        tags,
+		GeneratedAt: bom.Metadata.Timestamp,
+		CycloneDX:   bom,
+	})
+	if err != nil {
+		log.Errorf("Couldn't marshal the software bill of materials of %s %q: %v", source, id, err)
+		return
+	}
+
+	log.Debugf("Sending the software bill of materials of %s %q with %d components", source, id, len(bom.Components))
+	p.sender.EventPlatformEvent(string(raw), epforwarder.EventTypeSBOM)
+}
@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// newTestRootfs creates an Alpine Linux root filesystem, as seen through the main process of a container
+func newTestRootfs(t *testing.T, procRoot string, pid int) {
+	root := filepath.Join(procRoot, strconv.Itoa(pid), "root")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "lib", "apk", "db"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "os-release"), []byte("ID=alpine\nVERSION_ID=3.16.2\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "lib", "apk", "db", "installed"), []byte("P:musl\nV:1.2.3-r0\nA:x86_64\n"), 0644))
+}
+
+func newTestContainer(id string, pid int, imageID string) *workloadmeta.Container {
+	return &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: id},
+		Image: workloadmeta.ContainerImage{
+			ID:        imageID,
+			RawName:   "docker.io/library/nginx:1.23-alpine",
+			Name:      "docker.io/library/nginx",
+			ShortName: "nginx",
+			Tag:       "1.23-alpine",
+		},
+		PID:   pid,
+		State: workloadmeta.ContainerState{Running: true},
+	}
+}
+
+func newTestProcessor(t *testing.T, name string, procRoot string) (*processor, *mocksender.MockSender) {
+	sender := mocksender.NewMockSender(check.ID(name))
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+
+	p, err := newProcessor(sender, procRoot, 10, sbom.ScanOptions{})
+	require.NoError(t, err)
+	p.hostname = "my-host"
+
+	return p, sender
+}
+
+// drainQueue scans the queued container images
+func drainQueue(p *processor) {
+	for {
+		select {
+		case request := <-p.queue:
+			p.scanContainerImage(context.Background(), request)
+		default:
+			return
+		}
+	}
+}
+
+func processContainers(p *processor, containers ...*workloadmeta.Container) {
+	var events []workloadmeta.Event
+	for _, container := range containers {
+		events = append(events, workloadmeta.Event{Type: workloadmeta.EventTypeSet, Entity: container})
+	}
+	p.processEvents(workloadmeta.EventBundle{Events: events, Ch: make(chan struct{})})
+	drainQueue(p)
+}
+
+func TestProcessContainers(t *testing.T) {
+	procRoot := t.TempDir()
+	newTestRootfs(t, procRoot, 100)
+	newTestRootfs(t, procRoot, 200)
+
+	p, sender := newTestProcessor(t, "process containers", procRoot)
+
+	// the image of both containers is scanned once
+	processContainers(p, newTestContainer("cont1", 100, "sha256:1234"), newTestContainer("cont2", 200, "sha256:1234"))
+	processContainers(p, newTestContainer("cont1", 100, "sha256:1234"))
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+
+	require.Len(t, sender.Calls, 1)
+	assert.Equal(t, epforwarder.EventTypeSBOM, sender.Calls[0].Arguments.String(1))
+
+	var sent payload
+	require.NoError(t, json.Unmarshal([]byte(sender.Calls[0].Arguments.String(0)), &sent))
+	assert.Equal(t, "my-host", sent.Host)
+	assert.Equal(t, sourceContainerImage, sent.Source)
+	assert.Equal(t, "sha256:1234", sent.ID)
+	assert.Equal(t, []string{
+		"image_id:sha256:1234",
+		"image_name:docker.io/library/nginx",
+		"short_image:nginx",
+		"image_tag:1.23-alpine",
+	}, sent.Tags)
+	assert.Equal(t, &sbom.Component{
+		BOMRef:  "sha256:1234",
+		Type:    sbom.ComponentTypeContainer,
+		Name:    "docker.io/library/nginx",
+		Version: "1.23-alpine",
+	}, sent.CycloneDX.Metadata.Component)
+	require.Len(t, sent.CycloneDX.Components, 2)
+	assert.Equal(t, "pkg:apk/alpine/musl@1.2.3-r0?arch=x86_64&distro=alpine-3.16.2", sent.CycloneDX.Components[1].PURL)
+
+	// another image is scanned, the stopped containers are ignored
+	stopped := newTestContainer("cont3", 100, "sha256:5678")
+	stopped.State.Running = false
+	processContainers(p, stopped, newTestContainer("cont4", 200, "sha256:abcd"))
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 2)
+}
+
+func TestProcessContainersImageID(t *testing.T) {
+	procRoot := t.TempDir()
+	newTestRootfs(t, procRoot, 100)
+	newTestRootfs(t, procRoot, 200)
+
+	p, sender := newTestProcessor(t, "image id", procRoot)
+
+	// the image name isn't a substitute for the image ID
+	processContainers(p, newTestContainer("cont1", 100, ""))
+	sender.AssertNotCalled(t, "EventPlatformEvent", mock.Anything, mock.Anything)
+	assert.False(t, p.scannedImages.Contains("docker.io/library/nginx:1.23-alpine"))
+
+	// the tag moved to another image, which is scanned as well
+	processContainers(p, newTestContainer("cont1", 100, "sha256:1234"))
+	processContainers(p, newTestContainer("cont2", 200, "sha256:5678"))
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 2)
+}
+
+func TestProcessContainersScanFailure(t *testing.T) {
+	procRoot := t.TempDir()
+	p, sender := newTestProcessor(t, "scan failure", procRoot)
+
+	// the container exited before its image was scanned
+	processContainers(p, newTestContainer("cont1", 100, "sha256:1234"))
+	sender.AssertNotCalled(t, "EventPlatformEvent", mock.Anything, mock.Anything)
+	assert.False(t, p.scannedImages.Contains("sha256:1234"))
+
+	// the image is scanned with the next container using it
+	newTestRootfs(t, procRoot, 200)
+	processContainers(p, newTestContainer("cont2", 200, "sha256:1234"))
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+	assert.True(t, p.scannedImages.Contains("sha256:1234"))
+}
+
+func TestScanHost(t *testing.T) {
+	hostRoot := t.TempDir()
+	newTestRootfs(t, hostRoot, 1)
+
+	p, sender := newTestProcessor(t, "scan host", t.TempDir())
+	p.scanHost(context.Background(), filepath.Join(hostRoot, "1", "root"))
+	sender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+
+	var sent payload
+	require.NoError(t, json.Unmarshal([]byte(sender.Calls[0].Arguments.String(0)), &sent))
+	assert.Equal(t, sourceHost, sent.Source)
+	assert.Equal(t, "my-host", sent.ID)
+	assert.Empty(t, sent.Tags)
+	assert.Equal(t, &sbom.Component{
+		BOMRef: "host:my-host",
+		Type:   sbom.ComponentTypeOperatingSystem,
+		Name:   "my-host",
+	}, sent.CycloneDX.Metadata.Component)
+}
+
+func TestConfigParse(t *testing.T) {
+	config := &Config{}
+	require.NoError(t, config.Parse([]byte("host_scan: false\nlockfile_dirs: [/workspace]\ncache_size: -1\n")))
+
+	assert.False(t, config.HostScan)
+	assert.Equal(t, []string{"/workspace"}, config.LockfileDirs)
+	assert.Equal(t, defaultCacheSize, config.CacheSize)
+	assert.Equal(t, defaultHostScanIntervalSeconds, config.HostScanIntervalSeconds)
+	assert.Equal(t, defaultLockfileMaxDepth, config.LockfileMaxDepth)
+}
@@ -226,12 +226,17 @@ func lazyInitObfuscator() *obfuscate.Obfuscator {
 type sqlConfig struct {
 	// DBMS identifies the type of database management system (e.g. MySQL, Postgres, and SQL Server).
 	DBMS string `json:"dbms"`
+	// DBMSDialect specifies whether the dialect-specific constructs of DBMS (e.g. SQL Server bracket delimited
+	// identifiers) should be tokenized as such.
+	DBMSDialect bool `json:"dbms_dialect"`
 	// TableNames specifies whether the obfuscator should extract and return table names as SQL metadata when obfuscating.
 	TableNames bool `json:"table_names"`
 	// CollectCommands specifies whether the obfuscator should extract and return commands as SQL metadata when obfuscating.
 	CollectCommands bool `json:"collect_commands"`
 	// CollectComments specifies whether the obfuscator should extract and return comments as SQL metadata when obfuscating.
 	CollectComments bool `json:"collect_comments"`
+	// CollectProcedures specifies whether the obfuscator should extract and return called procedures as SQL metadata when obfuscating.
+	CollectProcedures bool `json:"collect_procedures"`
 	// ReplaceDigits specifies whether digits in table names and identifiers should be obfuscated.
 	ReplaceDigits bool `json:"replace_digits"`
 	// KeepSQLAlias specifies whether or not to strip sql aliases while obfuscating.
@@ -258,13 +263,15 @@ func ObfuscateSQL(rawQuery, opts *C.char, errResult **C.char) *C.char {
 	}
 	s := C.GoString(rawQuery)
 	obfuscatedQuery, err := lazyInitObfuscator().ObfuscateSQLStringWithOptions(s, &obfuscate.SQLConfig{
-		DBMS:             sqlOpts.DBMS,
-		TableNames:       sqlOpts.TableNames,
-		CollectCommands:  sqlOpts.CollectCommands,
-		CollectComments:  sqlOpts.CollectComments,
-		ReplaceDigits:    sqlOpts.ReplaceDigits,
-		KeepSQLAlias:     sqlOpts.KeepSQLAlias,
-		DollarQuotedFunc: sqlOpts.DollarQuotedFunc,
+		DBMS:              sqlOpts.DBMS,
+		DBMSDialect:       sqlOpts.DBMSDialect,
+		TableNames:        sqlOpts.TableNames,
+		CollectCommands:   sqlOpts.CollectCommands,
+		CollectComments:   sqlOpts.CollectComments,
+		CollectProcedures: sqlOpts.CollectProcedures,
+		ReplaceDigits:     sqlOpts.ReplaceDigits,
+		KeepSQLAlias:      sqlOpts.KeepSQLAlias,
+		DollarQuotedFunc:  sqlOpts.DollarQuotedFunc,
 	})
 	if err != nil {
 		// memory will be freed by caller
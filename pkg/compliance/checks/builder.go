@@ -231,6 +231,27 @@ func WithRegoInput(regoInputPath string) BuilderOption {
 	}
 }
 
+// WithWaivers configures the waivers of the failing rules from a waiver file
+func WithWaivers(waiversPath string) BuilderOption {
+	return func(b *builder) error {
+		waivers, err := compliance.ParseWaivers(waiversPath)
+		if err != nil {
+			return fmt.Errorf("failed to load waivers: %w", err)
+		}
+		log.Infof("Loaded %d compliance waivers from %s", len(waivers), waiversPath)
+		b.waivers = waivers
+		return nil
+	}
+}
+
+// WithHostTags configures the host tags matched by the waivers
+func WithHostTags(hostTags []string) BuilderOption {
+	return func(b *builder) error {
+		b.hostTags = hostTags
+		return nil
+	}
+}
+
 // WithRegoInputDumpPath configures a builder to dump the rego input to the provided file path
 func WithRegoInputDumpPath(regoInputDumpPath string) BuilderOption {
 	return func(b *builder) error {
@@ -309,6 +330,10 @@ type builder struct {
 	regoInputDumpPath string
 	regoEvalSkip      bool
 
+	waivers   []*compliance.Waiver
+	hostTags  []string
+	waiverSet *waiverSet
+
 	status *status
 }
 
@@ -698,6 +723,8 @@ func (b *builder) newCheck(meta *compliance.SuiteMeta, ruleScope compliance.Rule
 		scope:           ruleScope,
 		checkable:       checkable,
 
+		waivers: b.getWaiverSet(),
+
 		eventNotify: notify,
 	}, nil
 }
@@ -731,10 +758,20 @@ func (b *builder) newRegoCheck(meta *compliance.SuiteMeta, ruleScope compliance.
 		scope:           ruleScope,
 		checkable:       regoCheck,
 
+		waivers: b.getWaiverSet(),
+
 		eventNotify: notify,
 	}, nil
 }
 
+// getWaiverSet returns the waivers shared by all the checks, if any
+func (b *builder) getWaiverSet() *waiverSet {
+	if b.waiverSet == nil && len(b.waivers) > 0 {
+		b.waiverSet = newWaiverSet(b.waivers, b.hostTags)
+	}
+	return b.waiverSet
+}
+
 func (b *builder) Reporter() event.Reporter {
 	return b.reporter
 }
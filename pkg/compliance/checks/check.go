@@ -37,6 +37,9 @@ type complianceCheck struct {
 
 	checkable checkable
 
+	// waivers of the failing rules, if any
+	waivers *waiverSet
+
 	eventNotify eventNotify
 }
 
@@ -128,6 +131,18 @@ func (c *complianceCheck) Run() error {
 
 		data, result := reportToEventData(report)
 
+		var waiver *event.Waiver
+		if result == event.Failed {
+			if w := c.waivers.match(c.ruleID, data); w != nil {
+				log.Debugf("%s: failure waived until %s: %s", c.ruleID, w.ExpiresAt(), w.Justification)
+				result = event.Waived
+				waiver = &event.Waiver{
+					Justification: w.Justification,
+					ExpireAt:      w.ExpiresAt(),
+				}
+			}
+		}
+
 		resource := c.reportToResource(report)
 
 		quadID := resourceQuadID{
@@ -158,6 +173,7 @@ func (c *complianceCheck) Run() error {
 			Data:             data,
 			Evaluator:        evaluator,
 			ExpireAt:         c.computeExpireAt(),
+			Waiver:           waiver,
 		}
 
 		log.Debugf("%s: reporting [%s] [%s] [%s]", c.ruleID, e.Result, e.ResourceID, e.ResourceType)
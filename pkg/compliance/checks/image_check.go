@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	imageDpkgStatusPath = "/var/lib/dpkg/status"
+	// imageDpkgStatusDir holds a status file per package in distroless images
+	imageDpkgStatusDir = "/var/lib/dpkg/status.d"
+)
+
+var imageReportedFields = []string{
+	compliance.ImageFieldPath,
+	compliance.DockerImageFieldID,
+	compliance.DockerImageFieldTags,
+}
+
+func resolveImage(_ context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Image == nil {
+		return nil, fmt.Errorf("expecting image resource in image check")
+	}
+
+	image := res.Image
+
+	contentParser, err := validateParserKind(image.Parser)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range image.Files {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid image file pattern `%s`: %w", pattern, err)
+		}
+	}
+
+	imagePath, err := resolvePath(e, image.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := newImageSource(e.NormalizeToHostRoot(imagePath))
+	if err != nil {
+		return nil, err
+	}
+
+	localImages, err := listLocalImages(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read images from %s: %w", imagePath, err)
+	}
+
+	log.Debugf("%s: found %d images in %s", ruleID, len(localImages), imagePath)
+
+	filter := func(name string) (bool, bool) {
+		if name == imageDpkgStatusPath || path.Dir(name) == imageDpkgStatusDir || name == sbom.ApkInstalledPath {
+			return true, true
+		}
+		if isImageRpmDBFile(name) {
+			return true, false
+		}
+		if matchesAnyPattern(image.Files, name) {
+			return true, contentParser != ""
+		}
+		return false, false
+	}
+
+	instances := make([]resolvedInstance, 0, len(localImages))
+	for _, localImage := range localImages {
+		inspect, err := localImage.inspect(src)
+		if err != nil {
+			return nil, err
+		}
+
+		files, err := localImage.readFiles(src, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the files of image %s: %w", inspect.ID, err)
+		}
+
+		imageFiles, packages, err := splitImageFiles(files, image.Files, contentParser)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the packages of image %s: %w", inspect.ID, err)
+		}
+
+		regoPackages := make([]interface{}, len(packages))
+		for i, pkg := range packages {
+			regoPackages[i] = pkg.regoInput()
+		}
+
+		id := inspect.ID
+		if len(inspect.RepoTags) > 0 {
+			id = inspect.RepoTags[0]
+		}
+
+		instances = append(instances, newResolvedInstance(
+			eval.NewInstance(
+				eval.VarMap{
+					compliance.ImageFieldPath:       imagePath,
+					compliance.ImageFieldFiles:      imageFiles,
+					compliance.DockerImageFieldID:   inspect.ID,
+					compliance.DockerImageFieldTags: inspect.RepoTags,
+					compliance.DockerImageInspect:   inspect,
+				},
+				eval.FunctionMap{
+					compliance.DockerFuncTemplate: dockerTemplateQuery(compliance.DockerFuncTemplate, inspect),
+				},
+				eval.RegoInputMap{
+					"path":     imagePath,
+					"id":       inspect.ID,
+					"tags":     inspect.RepoTags,
+					"inspect":  inspect,
+					"files":    imageFiles,
+					"packages": regoPackages,
+				},
+			),
+			id, "container_image",
+		))
+	}
+
+	if len(instances) == 0 && !rego {
+		return nil, fmt.Errorf("no images found in %q", image.Path)
+	}
+
+	return newResolvedInstances(instances), nil
+}
+
+// isImageRpmDBFile returns whether a file of an image is part of a rpm database
+func isImageRpmDBFile(name string) bool {
+	for _, dbPath := range sbom.RpmDBPaths {
+		if path.Dir(name) == dbPath {
+			return true
+		}
+	}
+	return false
+}
+
+// splitImageFiles returns the files matching the patterns of the resource, and the packages of the dpkg status files
+// and of the apk database. The rpm databases of images can't be read, as the rpm command only reads them from disk.
+func splitImageFiles(files map[string]*imageFile, patterns []string, contentParser string) ([]interface{}, []installedPackage, error) {
+	imageFiles := []interface{}{}
+	var packages []installedPackage
+
+	for _, file := range sortedImageFiles(files) {
+		switch {
+		case file.path == imageDpkgStatusPath || path.Dir(file.path) == imageDpkgStatusDir:
+			if pkgs, err := parseDpkgStatus(bytes.NewReader(file.content), file.path); err == nil {
+				packages = append(packages, pkgs...)
+			} else {
+				log.Warnf("failed to parse dpkg status file %s: %v", file.path, err)
+			}
+		case file.path == sbom.ApkInstalledPath:
+			if pkgs, err := parseApkInstalled(bytes.NewReader(file.content), file.path); err == nil {
+				packages = append(packages, pkgs...)
+			} else {
+				log.Warnf("failed to parse apk database %s: %v", file.path, err)
+			}
+		case isImageRpmDBFile(file.path):
+			return nil, nil, fmt.Errorf("%w: the rpm database %s of images can't be read", ErrPackageManagerNotSupported, path.Dir(file.path))
+		}
+
+		if !matchesAnyPattern(patterns, file.path) {
+			continue
+		}
+
+		hdr := file.header
+		regoFile := map[string]interface{}{
+			"path":        file.path,
+			"type":        imageFileType(hdr),
+			"permissions": uint64(os.FileMode(hdr.Mode) & os.ModePerm),
+			"uid":         hdr.Uid,
+			"gid":         hdr.Gid,
+			"size":        hdr.Size,
+		}
+		if hdr.Linkname != "" {
+			regoFile["target"] = hdr.Linkname
+		}
+		if contentParser != "" && file.content != nil {
+			if content, err := contentParsers[contentParser](file.content); err == nil {
+				regoFile["content"] = content
+			} else {
+				log.Warnf("failed to parse image file %s: %v", file.path, err)
+			}
+		}
+		imageFiles = append(imageFiles, regoFile)
+	}
+
+	return imageFiles, packages, nil
+}
+
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
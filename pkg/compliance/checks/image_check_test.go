@@ -0,0 +1,325 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+)
+
+type testImageFile struct {
+	name    string
+	mode    int64
+	uid     int
+	content string
+}
+
+const testImageConfig = `{
+	"architecture": "amd64",
+	"os": "linux",
+	"config": {
+		"User": "nobody",
+		"Env": ["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],
+		"Cmd": ["nginx", "-g", "daemon off;"]
+	},
+	"rootfs": {"type": "layers", "diff_ids": []}
+}`
+
+var testImageLayers = [][]testImageFile{
+	{
+		{name: "etc/", mode: 0755},
+		{name: "etc/passwd", mode: 0644, content: "root:x:0:0:root:/root:/bin/sh\n"},
+		{name: "etc/shadow", mode: 0640, content: "root:*:19000:0:99999:7:::\n"},
+		{name: "etc/ssl/private/server.key", mode: 0600, content: "secret"},
+		{name: "var/lib/dpkg/status", mode: 0644, content: "Package: nginx\nStatus: install ok installed\nArchitecture: amd64\nVersion: 1.18.0-6.1\n"},
+	},
+	{
+		{name: "etc/.wh.shadow"},
+		{name: "etc/ssl/.wh..wh..opq"},
+		{name: "etc/ssl/certs.pem", mode: 0644, uid: 101, content: "cert"},
+		{name: "etc/nginx.conf", mode: 0666, uid: 101, content: "user nginx;\n"},
+	},
+}
+
+func gzipTestLayer(t *testing.T, files []testImageFile) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, file := range files {
+		hdr := &tar.Header{Name: file.name, Mode: file.mode, Uid: file.uid, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		if file.name[len(file.name)-1] == '/' {
+			hdr.Typeflag = tar.TypeDir
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(file.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+// writeTestOCILayout writes an image as an OCI image layout in a directory
+func writeTestOCILayout(t *testing.T, dir string) {
+	writeBlob := func(content []byte, mediaType string) v1.Descriptor {
+		sum := fmt.Sprintf("%x", sha256.Sum256(content))
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "blobs", "sha256", sum), content, 0644))
+		var desc v1.Descriptor
+		assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"mediaType": %q, "digest": "sha256:%s", "size": %d}`, mediaType, sum, len(content))), &desc))
+		return desc
+	}
+
+	manifest := v1.Manifest{
+		MediaType: v1.MediaTypeImageManifest,
+		Config:    writeBlob([]byte(testImageConfig), v1.MediaTypeImageConfig),
+	}
+	manifest.SchemaVersion = 2
+	for _, layer := range testImageLayers {
+		manifest.Layers = append(manifest.Layers, writeBlob(gzipTestLayer(t, layer), v1.MediaTypeImageLayerGzip))
+	}
+
+	content, err := json.Marshal(manifest)
+	assert.NoError(t, err)
+	manifestDesc := writeBlob(content, v1.MediaTypeImageManifest)
+	manifestDesc.Annotations = map[string]string{v1.AnnotationRefName: "nginx:1.18"}
+
+	// the manifest of an attestation, which isn't an image
+	attestationDesc := writeBlob([]byte(`{"schemaVersion": 2, "layers": []}`), v1.MediaTypeImageManifest)
+	attestationDesc.Platform = &v1.Platform{OS: "unknown", Architecture: "unknown"}
+
+	index := v1.Index{Manifests: []v1.Descriptor{manifestDesc, attestationDesc}}
+	index.SchemaVersion = 2
+	content, err = json.Marshal(index)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "index.json"), content, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion": "1.0.0"}`), 0644))
+}
+
+// writeTestDockerArchive writes an image as a `docker save` tarball
+func writeTestDockerArchive(t *testing.T, path string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	writeFile := func(name string, content []byte) {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(content)
+		assert.NoError(t, err)
+	}
+
+	archiveImage := dockerArchiveImage{
+		Config:   "config.json",
+		RepoTags: []string{"nginx:1.18"},
+	}
+	for i, layer := range testImageLayers {
+		name := fmt.Sprintf("layer%d/layer.tar", i)
+		writeFile(name, gzipTestLayer(t, layer))
+		archiveImage.Layers = append(archiveImage.Layers, name)
+	}
+	writeFile("config.json", []byte(testImageConfig))
+
+	content, err := json.Marshal([]dockerArchiveImage{archiveImage})
+	assert.NoError(t, err)
+	writeFile("manifest.json", content)
+
+	assert.NoError(t, tw.Close())
+}
+
+func TestResolveImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T) string
+	}{
+		{
+			name: "oci layout",
+			setup: func(t *testing.T) string {
+				dir := t.TempDir()
+				writeTestOCILayout(t, dir)
+				return dir
+			},
+		},
+		{
+			name: "docker archive",
+			setup: func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "image.tar")
+				writeTestDockerArchive(t, path)
+				return path
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := test.setup(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string { return path })
+
+			resource := compliance.ResourceCommon{
+				Image: &compliance.Image{
+					Path:   path,
+					Files:  []string{"/etc/*", "/etc/ssl/*", "/etc/ssl/private/*"},
+					Parser: "raw",
+				},
+			}
+
+			resolved, err := resolveImage(context.Background(), env, "rule-id", resource, true)
+			assert.NoError(t, err)
+
+			instances := resolveInstances(t, resolved)
+			assert.Len(t, instances, 1)
+
+			input := instances[0].RegoInput()
+			assert.Equal(t, []string{"nginx:1.18"}, input["tags"])
+			assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(testImageConfig))), input["id"])
+
+			inspect := input["inspect"].(*types.ImageInspect)
+			assert.Equal(t, "nobody", inspect.Config.User)
+			assert.Equal(t, "linux", inspect.Os)
+
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"path": "/etc/nginx.conf", "type": "file", "permissions": uint64(0666), "uid": 101, "gid": 0, "size": int64(12), "content": "user nginx;\n"},
+				map[string]interface{}{"path": "/etc/passwd", "type": "file", "permissions": uint64(0644), "uid": 0, "gid": 0, "size": int64(30), "content": "root:x:0:0:root:/root:/bin/sh\n"},
+				map[string]interface{}{"path": "/etc/ssl/certs.pem", "type": "file", "permissions": uint64(0644), "uid": 101, "gid": 0, "size": int64(4), "content": "cert"},
+			}, input["files"])
+
+			assert.Equal(t, []interface{}{
+				eval.RegoInputMap{"name": "nginx", "version": "1.18.0-6.1", "architecture": "amd64", "manager": "dpkg"},
+			}, input["packages"])
+		})
+	}
+}
+
+func TestSplitImageFilesPackages(t *testing.T) {
+	files := map[string]*imageFile{
+		"/lib/apk/db/installed": {
+			path:    "/lib/apk/db/installed",
+			header:  &tar.Header{Mode: 0644},
+			content: []byte("P:musl\nV:1.2.3-r0\nA:x86_64\n"),
+		},
+		"/var/lib/dpkg/status.d/base": {
+			path:    "/var/lib/dpkg/status.d/base",
+			header:  &tar.Header{Mode: 0644},
+			content: []byte("Package: base-files\nStatus: install ok installed\nArchitecture: amd64\nVersion: 11.1\n"),
+		},
+	}
+
+	_, packages, err := splitImageFiles(files, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []installedPackage{
+		{Name: "musl", Version: "1.2.3-r0", Architecture: "x86_64", Manager: "apk"},
+		{Name: "base-files", Version: "11.1", Architecture: "amd64", Manager: "dpkg"},
+	}, packages)
+
+	// the rpm packages of images can't be listed, the check must not see an empty list
+	files["/var/lib/rpm/rpmdb.sqlite"] = &imageFile{path: "/var/lib/rpm/rpmdb.sqlite", header: &tar.Header{Mode: 0644}}
+	_, _, err = splitImageFiles(files, nil, "")
+	assert.True(t, errors.Is(err, ErrPackageManagerNotSupported))
+}
+
+func TestImageCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeTestOCILayout(t, dir)
+
+	env := &mocks.Env{}
+	env.On("MaxEventsPerRun").Return(30).Maybe()
+	env.On("NormalizeToHostRoot", dir).Return(dir)
+
+	resource := compliance.Resource{
+		ResourceCommon: compliance.ResourceCommon{
+			Image: &compliance.Image{Path: dir},
+		},
+		Condition: `docker.template("{{- .Config.User -}}") != ""`,
+	}
+
+	check, err := newResourceCheck(env, "rule-id", resource)
+	assert.NoError(t, err)
+
+	reports := check.check(env)
+	assert.Len(t, reports, 1)
+	assert.NoError(t, reports[0].Error)
+	assert.True(t, reports[0].Passed)
+	assert.Equal(t, compliance.ReportResource{ID: "nginx:1.18", Type: "container_image"}, reports[0].Resource)
+}
+
+func TestRegoImageCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeTestOCILayout(t, dir)
+
+	rule := &compliance.RegoRule{
+		RuleCommon: compliance.RuleCommon{ID: "rule-id"},
+		Module: `
+			package test
+
+			import data.datadog as dd
+
+			writable_files(image) = [f.path | f := image.files[_]; bits.and(f.permissions, 2) != 0]
+
+			findings[f] {
+				image := input.images[_]
+				image.inspect.Config.User != "root"
+				count(writable_files(image)) == 0
+				f := dd.passed_finding("container_image", image.id, {})
+			}
+
+			findings[f] {
+				image := input.images[_]
+				files := writable_files(image)
+				count(files) > 0
+				f := dd.failing_finding("container_image", image.id, {"files": files})
+			}
+		`,
+		Findings: "data.test.findings",
+	}
+
+	check := &regoCheck{
+		ruleID: rule.ID,
+		inputs: []compliance.RegoInput{
+			{
+				ResourceCommon: compliance.ResourceCommon{
+					Image: &compliance.Image{Path: dir, Files: []string{"/etc/*"}},
+				},
+				TagName: "images",
+				Type:    "array",
+			},
+		},
+	}
+	assert.NoError(t, check.compileRule(rule, "", &compliance.SuiteMeta{}))
+
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", dir).Return(dir)
+	env.On("ProvidedInput", mock.Anything).Return(nil).Once()
+	env.On("Hostname").Return("hostname_test").Once()
+	env.On("DumpInputPath").Return("").Once()
+	env.On("ShouldSkipRegoEval").Return(false).Once()
+	defer env.AssertExpectations(t)
+
+	reports := check.check(env)
+	assert.Len(t, reports, 1)
+	assert.False(t, reports[0].Passed)
+	assert.NoError(t, reports[0].Error)
+	assert.Equal(t, []interface{}{"/etc/nginx.conf"}, reports[0].Data["files"])
+}
@@ -0,0 +1,410 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	ociIndexFile            = "index.json"
+	dockerArchiveManifest   = "manifest.json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerIndexMediaType    = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// maxImageFileSize is the maximum size of the image files whose content is read
+	maxImageFileSize = 1024 * 1024
+
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+var errImageFileNotFound = errors.New("file not found in image archive")
+
+// imageSource gives access to the files of an OCI image layout or a `docker save` archive
+type imageSource interface {
+	open(name string) (io.ReadCloser, error)
+}
+
+// dirImageSource is an image layout or archive extracted in a directory
+type dirImageSource string
+
+func (s dirImageSource) open(name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(string(s), filepath.FromSlash(name)))
+	if os.IsNotExist(err) {
+		return nil, errImageFileNotFound
+	}
+	return f, err
+}
+
+// tarImageSource is an image layout or archive as a tarball, optionally compressed with gzip
+type tarImageSource string
+
+type tarEntryReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *tarEntryReader) Close() error {
+	return r.closer.Close()
+}
+
+// open scans the tarball for the file, as the tar format has no index
+func (s tarImageSource) open(name string) (io.ReadCloser, error) {
+	f, err := os.Open(string(s))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := decompressReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			f.Close()
+			if err == io.EOF {
+				return nil, errImageFileNotFound
+			}
+			return nil, err
+		}
+		if path.Clean(strings.TrimPrefix(hdr.Name, "./")) == name && hdr.Typeflag == tar.TypeReg {
+			return &tarEntryReader{Reader: tr, closer: f}, nil
+		}
+	}
+}
+
+func newImageSource(path string) (imageSource, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return dirImageSource(path), nil
+	}
+	return tarImageSource(path), nil
+}
+
+// decompressReader returns a reader of the decompressed content of gzip streams, other streams being read as is
+func decompressReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return nil, errors.New("zstd compressed layers are not supported")
+	default:
+		return br, nil
+	}
+}
+
+func readImageJSON(src imageSource, name string, v interface{}) ([]byte, error) {
+	r, err := src.open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return content, nil
+}
+
+// localImage is an image of an OCI image layout or a `docker save` archive
+type localImage struct {
+	tags   []string
+	config string
+	layers []string
+}
+
+// imageConfig is the subset of the OCI image configuration exposed as docker image inspect data. The `config` object
+// is the same as the one of docker containers.
+type imageConfig struct {
+	Created      string            `json:"created,omitempty"`
+	Author       string            `json:"author,omitempty"`
+	Architecture string            `json:"architecture"`
+	Variant      string            `json:"variant,omitempty"`
+	OS           string            `json:"os"`
+	OSVersion    string            `json:"os.version,omitempty"`
+	Config       *container.Config `json:"config,omitempty"`
+	RootFS       v1.RootFS         `json:"rootfs"`
+}
+
+// dockerArchiveImage is an image of the manifest of a `docker save` archive
+type dockerArchiveImage struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// ociManifest holds either an OCI image index or an image manifest, as descriptors of image layouts don't always
+// have a media type
+type ociManifest struct {
+	MediaType string          `json:"mediaType,omitempty"`
+	Manifests []v1.Descriptor `json:"manifests,omitempty"`
+	Config    *v1.Descriptor  `json:"config,omitempty"`
+	Layers    []v1.Descriptor `json:"layers,omitempty"`
+}
+
+func blobPath(desc v1.Descriptor) string {
+	return path.Join("blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+}
+
+// listLocalImages returns the images of an image source. The manifest of `docker save` archives is preferred, as it
+// holds the tags of the images, over the index of OCI image layouts.
+func listLocalImages(src imageSource) ([]*localImage, error) {
+	var archiveImages []dockerArchiveImage
+	if _, err := readImageJSON(src, dockerArchiveManifest, &archiveImages); err == nil {
+		images := make([]*localImage, len(archiveImages))
+		for i, image := range archiveImages {
+			images[i] = &localImage{
+				tags:   image.RepoTags,
+				config: image.Config,
+				layers: image.Layers,
+			}
+		}
+		return images, nil
+	} else if !errors.Is(err, errImageFileNotFound) {
+		return nil, err
+	}
+
+	var index ociManifest
+	if _, err := readImageJSON(src, ociIndexFile, &index); err != nil {
+		if errors.Is(err, errImageFileNotFound) {
+			return nil, fmt.Errorf("neither an OCI image layout nor a docker image archive")
+		}
+		return nil, err
+	}
+
+	var images []*localImage
+	if err := appendOCIImages(src, &images, index.Manifests, nil, 0); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// appendOCIImages appends the images of index descriptors, nested indexes being used for multi-platform images
+func appendOCIImages(src imageSource, images *[]*localImage, descriptors []v1.Descriptor, tags []string, depth int) error {
+	if depth > 4 {
+		return errors.New("too many nested image indexes")
+	}
+
+	for _, desc := range descriptors {
+		// skip the attestations of multi-platform images
+		if desc.Platform != nil && desc.Platform.OS == "unknown" {
+			continue
+		}
+
+		descTags := tags
+		if ref := desc.Annotations[v1.AnnotationRefName]; ref != "" {
+			descTags = append(descTags[:len(descTags):len(descTags)], ref)
+		}
+
+		var manifest ociManifest
+		if _, err := readImageJSON(src, blobPath(desc), &manifest); err != nil {
+			return err
+		}
+
+		switch {
+		case desc.MediaType == v1.MediaTypeImageIndex || desc.MediaType == dockerIndexMediaType || len(manifest.Manifests) > 0:
+			if err := appendOCIImages(src, images, manifest.Manifests, descTags, depth+1); err != nil {
+				return err
+			}
+		case manifest.Config != nil:
+			image := &localImage{
+				tags:   descTags,
+				config: blobPath(*manifest.Config),
+			}
+			for _, layer := range manifest.Layers {
+				image.layers = append(image.layers, blobPath(layer))
+			}
+			*images = append(*images, image)
+		}
+	}
+	return nil
+}
+
+// inspect returns the configuration of an image as docker image inspect data
+func (img *localImage) inspect(src imageSource) (*types.ImageInspect, error) {
+	var config imageConfig
+	content, err := readImageJSON(src, img.config, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	inspect := &types.ImageInspect{
+		ID:           fmt.Sprintf("sha256:%x", sha256.Sum256(content)),
+		RepoTags:     img.tags,
+		Created:      config.Created,
+		Author:       config.Author,
+		Config:       config.Config,
+		Architecture: config.Architecture,
+		Variant:      config.Variant,
+		Os:           config.OS,
+		OsVersion:    config.OSVersion,
+		RootFS: types.RootFS{
+			Type: config.RootFS.Type,
+		},
+	}
+	if inspect.RepoTags == nil {
+		inspect.RepoTags = []string{}
+	}
+	for _, diffID := range config.RootFS.DiffIDs {
+		inspect.RootFS.Layers = append(inspect.RootFS.Layers, diffID.String())
+	}
+
+	return inspect, nil
+}
+
+// imageFile is a file of the filesystem of an image
+type imageFile struct {
+	path    string
+	header  *tar.Header
+	content []byte
+}
+
+// imageFileFilter selects the files of the filesystem of an image, and whether their content is read
+type imageFileFilter func(path string) (selected bool, withContent bool)
+
+// readFiles returns the selected files of the filesystem of an image, applying the layers in order
+func (img *localImage) readFiles(src imageSource, filter imageFileFilter) (map[string]*imageFile, error) {
+	files := make(map[string]*imageFile)
+
+	for _, layer := range img.layers {
+		layerFiles, whiteouts, err := readLayerFiles(src, layer, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layer %s: %w", layer, err)
+		}
+
+		// whiteouts only hide the files of the lower layers
+		for _, whiteout := range whiteouts {
+			for path := range files {
+				if path == whiteout || strings.HasPrefix(path, strings.TrimSuffix(whiteout, "/")+"/") {
+					delete(files, path)
+				}
+			}
+		}
+		for path, file := range layerFiles {
+			files[path] = file
+		}
+	}
+
+	return files, nil
+}
+
+// readLayerFiles returns the selected files of a layer and the paths it removes from the lower layers. The paths
+// of opaque directories end with a slash, as only their content is removed.
+func readLayerFiles(src imageSource, layer string, filter imageFileFilter) (map[string]*imageFile, []string, error) {
+	r, err := src.open(layer)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	dr, err := decompressReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make(map[string]*imageFile)
+	var whiteouts []string
+
+	tr := tar.NewReader(dr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name := path.Join("/", hdr.Name)
+		dir, base := path.Split(name)
+
+		switch {
+		case base == whiteoutOpaque:
+			whiteouts = append(whiteouts, dir)
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			whiteouts = append(whiteouts, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+			continue
+		}
+
+		selected, withContent := filter(name)
+		if !selected {
+			continue
+		}
+
+		file := &imageFile{path: name, header: hdr}
+		if withContent && hdr.Typeflag == tar.TypeReg && hdr.Size <= maxImageFileSize {
+			if file.content, err = io.ReadAll(tr); err != nil {
+				return nil, nil, err
+			}
+		}
+		files[name] = file
+	}
+
+	return files, whiteouts, nil
+}
+
+// sortedImageFiles returns the files sorted by path
+func sortedImageFiles(files map[string]*imageFile) []*imageFile {
+	sorted := make([]*imageFile, 0, len(files))
+	for _, file := range files {
+		sorted = append(sorted, file)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].path < sorted[j].path
+	})
+	return sorted
+}
+
+// imageFileType returns the type of a file of an image
+func imageFileType(hdr *tar.Header) string {
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		return "file"
+	case tar.TypeDir:
+		return "directory"
+	case tar.TypeSymlink:
+		return "symlink"
+	case tar.TypeLink:
+		return "hardlink"
+	default:
+		return "other"
+	}
+}
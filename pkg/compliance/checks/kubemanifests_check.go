@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var kubeManifestReportedFields = []string{
+	compliance.KubeManifestFieldPath,
+	compliance.KubeResourceFieldName,
+	compliance.KubeResourceFieldGroup,
+	compliance.KubeResourceFieldVersion,
+	compliance.KubeResourceFieldNamespace,
+	compliance.KubeResourceFieldKind,
+}
+
+// kubeManifestExtensions are the extensions of the manifest files read from a directory
+var kubeManifestExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+func resolveKubeManifests(_ context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.KubeManifests == nil {
+		return nil, fmt.Errorf("expecting kubeManifests resource in kubeManifests check")
+	}
+
+	manifests := res.KubeManifests
+
+	path, err := resolvePath(e, manifests.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := listKubeManifestFiles(e.NormalizeToHostRoot(path))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("%s: reading Kubernetes objects from %d manifest files", ruleID, len(files))
+
+	var instances []resolvedInstance
+	for _, file := range files {
+		relPath := e.RelativeToHostRoot(file)
+
+		objects, err := readKubeManifest(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Kubernetes manifest %s: %w", relPath, err)
+		}
+
+		for _, object := range objects {
+			if manifests.Kind != "" && !strings.EqualFold(object.GetKind(), manifests.Kind) {
+				continue
+			}
+			if manifests.Namespace != "" && object.GetNamespace() != manifests.Namespace {
+				continue
+			}
+			instances = append(instances, newKubeManifestInstance(relPath, object))
+		}
+	}
+
+	if len(instances) == 0 && !rego {
+		return nil, fmt.Errorf("no Kubernetes objects found in manifests %q", manifests.Path)
+	}
+
+	return newResolvedInstances(instances), nil
+}
+
+func newKubeManifestInstance(path string, object unstructured.Unstructured) resolvedInstance {
+	gvk := object.GroupVersionKind()
+	namespace := object.GetNamespace()
+	name := object.GetName()
+
+	id := name
+	if namespace != "" {
+		id = namespace + "/" + name
+	}
+
+	return newResolvedInstance(
+		eval.NewInstance(
+			eval.VarMap{
+				compliance.KubeManifestFieldPath:      path,
+				compliance.KubeResourceFieldKind:      gvk.Kind,
+				compliance.KubeResourceFieldGroup:     gvk.Group,
+				compliance.KubeResourceFieldVersion:   gvk.Version,
+				compliance.KubeResourceFieldNamespace: namespace,
+				compliance.KubeResourceFieldName:      name,
+				compliance.KubeResourceFieldResource:  object,
+			},
+			eval.FunctionMap{
+				compliance.KubeResourceFuncJQ: kubeResourceJQ(object),
+			},
+			eval.RegoInputMap{
+				"path":      path,
+				"kind":      gvk.Kind,
+				"group":     gvk.Group,
+				"version":   gvk.Version,
+				"namespace": namespace,
+				"name":      name,
+				"resource":  object,
+			},
+		),
+		id, "kube_"+strings.ToLower(gvk.Kind),
+	)
+}
+
+// listKubeManifestFiles returns the manifest files of a path: the file itself, the manifest files of a directory and
+// its subdirectories, or the files matching a glob pattern
+func listKubeManifestFiles(path string) ([]string, error) {
+	paths, err := filepath.Glob(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if !fi.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && kubeManifestExtensions[strings.ToLower(filepath.Ext(path))] {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// readKubeManifest returns the objects of a YAML or JSON manifest file, which can hold several documents. The items of
+// lists are returned as separate objects.
+func readKubeManifest(path string) ([]unstructured.Unstructured, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objects []unstructured.Unstructured
+
+	decoder := k8syaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		// skip empty documents, such as the templates of a Helm chart that render to nothing
+		if len(content) == 0 {
+			continue
+		}
+
+		object := unstructured.Unstructured{Object: content}
+		if object.GetKind() == "" {
+			return nil, fmt.Errorf("object without kind")
+		}
+
+		if !object.IsList() {
+			objects = append(objects, object)
+			continue
+		}
+
+		err := object.EachListItem(func(item runtime.Object) error {
+			if item, ok := item.(*unstructured.Unstructured); ok {
+				objects = append(objects, *item)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
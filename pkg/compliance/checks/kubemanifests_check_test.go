@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+)
+
+func resolveInstances(t *testing.T, resolved resolved) []eval.Instance {
+	t.Helper()
+
+	it, ok := resolved.(eval.Iterator)
+	assert.True(t, ok)
+
+	var instances []eval.Instance
+	for !it.Done() {
+		instance, err := it.Next()
+		assert.NoError(t, err)
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+func TestResolveKubeManifests(t *testing.T) {
+	dir, err := filepath.Abs("./testdata/kubemanifests")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		resource  compliance.KubernetesManifests
+		expectIDs []string
+	}{
+		{
+			name:      "directory",
+			resource:  compliance.KubernetesManifests{Path: dir},
+			expectIDs: []string{"default/api", "default/api", "monitoring/node-exporter", "monitoring/node-exporter"},
+		},
+		{
+			name:      "kind",
+			resource:  compliance.KubernetesManifests{Path: dir, Kind: "daemonset"},
+			expectIDs: []string{"monitoring/node-exporter"},
+		},
+		{
+			name:      "namespace",
+			resource:  compliance.KubernetesManifests{Path: filepath.Join(dir, "chart", "*.json"), Namespace: "default"},
+			expectIDs: []string{"default/api", "default/api"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string { return path })
+			env.On("RelativeToHostRoot", mock.Anything).Return(func(path string) string { return path })
+
+			resolved, err := resolveKubeManifests(context.Background(), env, "rule-id", compliance.ResourceCommon{KubeManifests: &test.resource}, true)
+			assert.NoError(t, err)
+
+			var ids []string
+			for _, instance := range resolveInstances(t, resolved) {
+				ids = append(ids, instance.(resolvedInstance).ID())
+			}
+			assert.Equal(t, test.expectIDs, ids)
+		})
+	}
+}
+
+func TestKubeManifestsCheck(t *testing.T) {
+	path, err := filepath.Abs("./testdata/kubemanifests/chart/rendered.yaml")
+	assert.NoError(t, err)
+
+	env := &mocks.Env{}
+	env.On("MaxEventsPerRun").Return(30).Maybe()
+	env.On("NormalizeToHostRoot", path).Return(path)
+	env.On("RelativeToHostRoot", path).Return("/charts/rendered.yaml")
+
+	resource := compliance.Resource{
+		ResourceCommon: compliance.ResourceCommon{
+			KubeManifests: &compliance.KubernetesManifests{
+				Path: path,
+				Kind: "DaemonSet",
+			},
+		},
+		Condition: `kube.resource.jq(".spec.template.spec.hostNetwork") != "true"`,
+	}
+
+	check, err := newResourceCheck(env, "rule-id", resource)
+	assert.NoError(t, err)
+
+	reports := check.check(env)
+	assert.Len(t, reports, 1)
+	assert.NoError(t, reports[0].Error)
+	assert.False(t, reports[0].Passed)
+	assert.Equal(t, "/charts/rendered.yaml", reports[0].Data[compliance.KubeManifestFieldPath])
+	assert.Equal(t, "DaemonSet", reports[0].Data[compliance.KubeResourceFieldKind])
+	assert.Equal(t, "apps", reports[0].Data[compliance.KubeResourceFieldGroup])
+	assert.Equal(t, "monitoring", reports[0].Data[compliance.KubeResourceFieldNamespace])
+	assert.Equal(t, compliance.ReportResource{ID: "monitoring/node-exporter", Type: "kube_daemonset"}, reports[0].Resource)
+}
@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	dpkgManager = "dpkg"
+	rpmManager  = "rpm"
+	apkManager  = "apk"
+
+	defaultDpkgStatusPath = "/var/lib/dpkg/status"
+)
+
+// ErrPackageManagerNotSupported is returned when the packages of a package manager can't be read on this host, such
+// as the rpm packages when the rpm command isn't installed
+var ErrPackageManagerNotSupported = errors.New("package manager not supported")
+
+// lookPath finds the commands querying the package databases, it is replaced in tests
+var lookPath = exec.LookPath
+
+var packageReportedFields = []string{
+	compliance.PackageFieldName,
+	compliance.PackageFieldVersion,
+	compliance.PackageFieldArchitecture,
+	compliance.PackageFieldManager,
+}
+
+// installedPackage is a package installed according to a package manager database
+type installedPackage struct {
+	Name         string
+	Version      string
+	Architecture string
+	Manager      string
+	Source       string
+}
+
+func resolvePackages(ctx context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Packages == nil {
+		return nil, fmt.Errorf("expecting packages resource in packages check")
+	}
+
+	manager := res.Packages.Manager
+
+	path := res.Packages.Path
+	if path == "" {
+		switch manager {
+		case dpkgManager:
+			path = defaultDpkgStatusPath
+		case rpmManager:
+			path = defaultRpmDBPath(e)
+		case apkManager:
+			path = sbom.ApkInstalledPath
+		}
+	}
+
+	path, err := resolvePath(e, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []installedPackage
+	switch manager {
+	case dpkgManager:
+		packages, err = readDpkgStatusFile(e.NormalizeToHostRoot(path))
+	case rpmManager:
+		packages, err = queryRpmDB(ctx, e.NormalizeToHostRoot(path))
+	case apkManager:
+		packages, err = readApkInstalledFile(e.NormalizeToHostRoot(path))
+	default:
+		return nil, fmt.Errorf("unsupported package manager `%s`, expected %s, %s or %s", manager, dpkgManager, rpmManager, apkManager)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s packages from %s: %w", manager, path, err)
+	}
+
+	log.Debugf("%s: found %d %s packages", ruleID, len(packages), manager)
+
+	instances := make([]resolvedInstance, len(packages))
+	for i, pkg := range packages {
+		instances[i] = newResolvedInstance(
+			eval.NewInstance(
+				eval.VarMap{
+					compliance.PackageFieldName:         pkg.Name,
+					compliance.PackageFieldVersion:      pkg.Version,
+					compliance.PackageFieldArchitecture: pkg.Architecture,
+					compliance.PackageFieldManager:      pkg.Manager,
+				},
+				nil,
+				pkg.regoInput(),
+			),
+			pkg.Name, "package",
+		)
+	}
+
+	return newResolvedInstances(instances), nil
+}
+
+func (p *installedPackage) regoInput() eval.RegoInputMap {
+	input := eval.RegoInputMap{
+		"name":         p.Name,
+		"version":      p.Version,
+		"architecture": p.Architecture,
+		"manager":      p.Manager,
+	}
+	if p.Source != "" {
+		input["source"] = p.Source
+	}
+	return input
+}
+
+// newInstalledPackages returns the installed packages of the packages read as in the software bills of materials
+func newInstalledPackages(manager string, pkgs []sbom.Package) []installedPackage {
+	packages := make([]installedPackage, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		packages = append(packages, installedPackage{
+			Name:         pkg.Name,
+			Version:      pkg.Version,
+			Architecture: pkg.Architecture,
+			Manager:      manager,
+			Source:       pkg.Source,
+		})
+	}
+	return packages
+}
+
+func readDpkgStatusFile(path string) ([]installedPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseDpkgStatus(f, path)
+}
+
+// parseDpkgStatus returns the installed packages of a dpkg status file
+func parseDpkgStatus(r io.Reader, path string) ([]installedPackage, error) {
+	pkgs, err := sbom.ParseDpkgStatus(r, path)
+	if err != nil {
+		return nil, err
+	}
+	return newInstalledPackages(dpkgManager, pkgs), nil
+}
+
+func readApkInstalledFile(path string) ([]installedPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseApkInstalled(f, path)
+}
+
+// parseApkInstalled returns the installed packages of an apk database
+func parseApkInstalled(r io.Reader, path string) ([]installedPackage, error) {
+	pkgs, err := sbom.ParseApkInstalled(r, path)
+	if err != nil {
+		return nil, err
+	}
+	return newInstalledPackages(apkManager, pkgs), nil
+}
+
+// defaultRpmDBPath returns the first location of the rpm database found on the host
+func defaultRpmDBPath(e env.Env) string {
+	for _, dbPath := range sbom.RpmDBPaths {
+		if _, err := os.Stat(e.NormalizeToHostRoot(dbPath)); err == nil {
+			return dbPath
+		}
+	}
+	return sbom.RpmDBPaths[0]
+}
+
+// queryRpmDB lists the packages of a rpm database with the rpm command, as the database format depends on the
+// version of rpm
+func queryRpmDB(ctx context.Context, dbPath string) ([]installedPackage, error) {
+	if _, err := lookPath("rpm"); err != nil {
+		return nil, fmt.Errorf("%w: the rpm command isn't available: %v", ErrPackageManagerNotSupported, err)
+	}
+
+	exitCode, stdout, err := commandRunner(ctx, "rpm", []string{"--dbpath", dbPath, "-qa", "--queryformat", sbom.RpmQueryFormat}, true)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("rpm exited with code %d", exitCode)
+	}
+
+	packages := newInstalledPackages(rpmManager, sbom.ParseRpmQuery(string(stdout), dbPath))
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
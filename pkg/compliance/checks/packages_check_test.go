@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/sbom"
+)
+
+func TestReadDpkgStatusFile(t *testing.T) {
+	packages, err := readDpkgStatusFile("./testdata/packages/dpkg-status")
+	assert.NoError(t, err)
+	assert.Equal(t, []installedPackage{
+		{Name: "base-files", Version: "11.1+deb11u3", Architecture: "amd64", Manager: "dpkg"},
+		{Name: "libssl1.1", Version: "1.1.1n-0+deb11u3", Architecture: "amd64", Manager: "dpkg", Source: "openssl"},
+	}, packages)
+}
+
+func TestResolvePackages(t *testing.T) {
+	statusPath, err := filepath.Abs("./testdata/packages/dpkg-status")
+	assert.NoError(t, err)
+	apkPath, err := filepath.Abs("./testdata/packages/apk-installed")
+	assert.NoError(t, err)
+
+	defer func() { commandRunner, lookPath = runCommand, exec.LookPath }()
+	lookPath = func(name string) (string, error) {
+		return "/usr/bin/" + name, nil
+	}
+	commandRunner = func(ctx context.Context, name string, args []string, captureStdout bool) (int, []byte, error) {
+		assert.Equal(t, "rpm", name)
+		assert.Equal(t, []string{"--dbpath", "/host/var/lib/rpm", "-qa", "--queryformat", sbom.RpmQueryFormat}, args)
+		return 0, []byte("openssl-libs\t1:1.1.1k-6.el8\tx86_64\nbash\t4.4.20-4.el8\tx86_64\ngpg-pubkey\tfd431d51-4ae0493b\t(none)\n"), nil
+	}
+
+	tests := []struct {
+		name         string
+		resource     compliance.Packages
+		setup        func(env *mocks.Env)
+		expectInputs []eval.RegoInputMap
+	}{
+		{
+			name:     "dpkg",
+			resource: compliance.Packages{Manager: "dpkg", Path: statusPath},
+			setup: func(env *mocks.Env) {
+				env.On("NormalizeToHostRoot", statusPath).Return(statusPath)
+			},
+			expectInputs: []eval.RegoInputMap{
+				{"name": "base-files", "version": "11.1+deb11u3", "architecture": "amd64", "manager": "dpkg"},
+				{"name": "libssl1.1", "version": "1.1.1n-0+deb11u3", "architecture": "amd64", "manager": "dpkg", "source": "openssl"},
+			},
+		},
+		{
+			name:     "rpm",
+			resource: compliance.Packages{Manager: "rpm"},
+			setup: func(env *mocks.Env) {
+				env.On("NormalizeToHostRoot", "/var/lib/rpm").Return("/host/var/lib/rpm")
+				env.On("NormalizeToHostRoot", "/usr/lib/sysimage/rpm").Return("/host/usr/lib/sysimage/rpm")
+			},
+			expectInputs: []eval.RegoInputMap{
+				{"name": "bash", "version": "4.4.20-4.el8", "architecture": "x86_64", "manager": "rpm"},
+				{"name": "openssl-libs", "version": "1:1.1.1k-6.el8", "architecture": "x86_64", "manager": "rpm"},
+			},
+		},
+		{
+			name:     "apk",
+			resource: compliance.Packages{Manager: "apk", Path: apkPath},
+			setup: func(env *mocks.Env) {
+				env.On("NormalizeToHostRoot", apkPath).Return(apkPath)
+			},
+			expectInputs: []eval.RegoInputMap{
+				{"name": "musl", "version": "1.2.3-r0", "architecture": "x86_64", "manager": "apk"},
+				{"name": "busybox", "version": "1.35.0-r17", "architecture": "x86_64", "manager": "apk"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := &mocks.Env{}
+			defer env.AssertExpectations(t)
+			test.setup(env)
+
+			resolved, err := resolvePackages(context.Background(), env, "rule-id", compliance.ResourceCommon{Packages: &test.resource}, true)
+			assert.NoError(t, err)
+
+			var inputs []eval.RegoInputMap
+			for _, instance := range resolveInstances(t, resolved) {
+				inputs = append(inputs, instance.RegoInput())
+			}
+			assert.Equal(t, test.expectInputs, inputs)
+		})
+	}
+}
+
+func TestResolvePackagesUnknownManager(t *testing.T) {
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.Anything).Return("/var/lib/pacman/local").Maybe()
+
+	_, err := resolvePackages(context.Background(), env, "rule-id", compliance.ResourceCommon{Packages: &compliance.Packages{Manager: "pacman", Path: "/var/lib/pacman/local"}}, true)
+	assert.Error(t, err)
+}
+
+func TestResolvePackagesMissingRpm(t *testing.T) {
+	defer func() { lookPath = exec.LookPath }()
+	lookPath = func(name string) (string, error) {
+		return "", exec.ErrNotFound
+	}
+
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", "/var/lib/rpm").Return("/host/var/lib/rpm")
+
+	_, err := resolvePackages(context.Background(), env, "rule-id", compliance.ResourceCommon{Packages: &compliance.Packages{Manager: "rpm", Path: "/var/lib/rpm"}}, true)
+	assert.True(t, errors.Is(err, ErrPackageManagerNotSupported))
+}
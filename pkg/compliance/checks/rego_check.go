@@ -174,7 +174,10 @@ func (r *regoCheck) buildNormalInput(env env.Env) (eval.RegoInputMap, error) {
 		defer cancel()
 
 		resolved, err := resolve(ctx, env, r.ruleID, input.ResourceCommon, true)
-		if err != nil {
+		if errors.Is(err, ErrPackageManagerNotSupported) {
+			// an empty list of packages would be evaluated as if none was installed
+			return nil, err
+		} else if err != nil {
 			log.Warnf("failed to resolve input: %v", err)
 			continue
 		}
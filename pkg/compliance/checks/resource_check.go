@@ -208,6 +208,12 @@ func resourceKindToResolverAndFields(env env.Env, ruleID string, kind compliance
 			return nil, nil, log.Errorf("%s: kube client not initialized", ruleID)
 		}
 		return resolveKubeapiserver, kubeResourceReportedFields, nil
+	case compliance.KindKubernetesManifests:
+		return resolveKubeManifests, kubeManifestReportedFields, nil
+	case compliance.KindImage:
+		return resolveImage, imageReportedFields, nil
+	case compliance.KindPackages:
+		return resolvePackages, packageReportedFields, nil
 	case compliance.KindConstants:
 		return resolveConstants, nil, nil
 	default:
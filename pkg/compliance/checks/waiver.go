@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"path"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// waiverPathFields are the report fields holding the file paths of a resource
+var waiverPathFields = []string{compliance.FileFieldPath, compliance.AuditFieldPath}
+
+// waiverImageFields are the report fields holding the container images of a resource
+var waiverImageFields = []string{compliance.DockerContainerFieldImage, compliance.DockerImageFieldTags}
+
+// waiverSet holds the waivers of the checks, by rule
+type waiverSet struct {
+	waivers  map[string][]*compliance.Waiver
+	hostTags map[string]bool
+	now      func() time.Time
+}
+
+func newWaiverSet(waivers []*compliance.Waiver, hostTags []string) *waiverSet {
+	ws := &waiverSet{
+		waivers:  make(map[string][]*compliance.Waiver),
+		hostTags: make(map[string]bool),
+		now:      time.Now,
+	}
+	for _, waiver := range waivers {
+		ws.waivers[waiver.RuleID] = append(ws.waivers[waiver.RuleID], waiver)
+	}
+	for _, tag := range hostTags {
+		ws.hostTags[tag] = true
+	}
+	return ws
+}
+
+// dataStrings returns the string values of a report field, which can hold a string or a list of strings
+func dataStrings(data event.Data, field string) []string {
+	switch value := data[field].(type) {
+	case string:
+		return []string{value}
+	case []string:
+		return value
+	case []interface{}:
+		var values []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// matchesField returns whether one of the values of the fields matches a glob pattern
+func matchesField(pattern string, data event.Data, fields []string) bool {
+	for _, field := range fields {
+		for _, value := range dataStrings(data, field) {
+			if matched, _ := path.Match(pattern, value); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (ws *waiverSet) matches(waiver *compliance.Waiver, data event.Data) bool {
+	selector := &waiver.Resource
+
+	if selector.Path != "" && !matchesField(selector.Path, data, waiverPathFields) {
+		return false
+	}
+	if selector.Image != "" && !matchesField(selector.Image, data, waiverImageFields) {
+		return false
+	}
+	if selector.Namespace != "" && data[compliance.KubeResourceFieldNamespace] != selector.Namespace {
+		return false
+	}
+	if selector.Name != "" && data[compliance.KubeResourceFieldName] != selector.Name {
+		return false
+	}
+	for _, tag := range selector.HostTags {
+		if !ws.hostTags[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// match returns the waiver of a failed rule, if any. Expired waivers are ignored, so that the rule fails again.
+func (ws *waiverSet) match(ruleID string, data event.Data) *compliance.Waiver {
+	if ws == nil {
+		return nil
+	}
+
+	now := ws.now()
+	for _, waiver := range ws.waivers[ruleID] {
+		if !waiver.IsExpired(now) && ws.matches(waiver, data) {
+			return waiver
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+)
+
+const testWaivers = `
+waivers:
+  - ruleId: file-rule
+    resource:
+      path: /etc/kubernetes/*.conf
+    justification: managed by the cloud provider
+    expires: 2030-01-01
+  - ruleId: docker-rule
+    resource:
+      image: registry.example.com/monitoring/*
+      hostTags:
+        - env:prod
+    justification: monitoring agents run privileged
+    expires: 2030-01-01
+  - ruleId: kube-rule
+    resource:
+      namespace: kube-system
+      name: node-exporter
+    justification: needs host access
+    expires: 2030-01-01
+`
+
+func loadTestWaivers(t *testing.T) []*compliance.Waiver {
+	filename := filepath.Join(t.TempDir(), "waivers.yaml")
+	assert.NoError(t, os.WriteFile(filename, []byte(testWaivers), 0644))
+
+	waivers, err := compliance.ParseWaivers(filename)
+	assert.NoError(t, err)
+	return waivers
+}
+
+func TestWaiverSetMatch(t *testing.T) {
+	waivers := newWaiverSet(loadTestWaivers(t), []string{"env:prod", "team:sre"})
+	waivers.now = func() time.Time { return time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		ruleID  string
+		data    event.Data
+		matches bool
+	}{
+		{
+			name:    "file path",
+			ruleID:  "file-rule",
+			data:    event.Data{compliance.FileFieldPath: "/etc/kubernetes/admin.conf"},
+			matches: true,
+		},
+		{
+			name:   "other file path",
+			ruleID: "file-rule",
+			data:   event.Data{compliance.FileFieldPath: "/etc/kubernetes/manifests/etcd.yaml"},
+		},
+		{
+			name:   "other rule",
+			ruleID: "other-rule",
+			data:   event.Data{compliance.FileFieldPath: "/etc/kubernetes/admin.conf"},
+		},
+		{
+			name:    "container image",
+			ruleID:  "docker-rule",
+			data:    event.Data{compliance.DockerContainerFieldImage: "registry.example.com/monitoring/agent:7"},
+			matches: true,
+		},
+		{
+			name:    "image tags",
+			ruleID:  "docker-rule",
+			data:    event.Data{compliance.DockerImageFieldTags: []string{"nginx:1.21", "registry.example.com/monitoring/agent:7"}},
+			matches: true,
+		},
+		{
+			name:   "other image",
+			ruleID: "docker-rule",
+			data:   event.Data{compliance.DockerContainerFieldImage: "nginx:1.21"},
+		},
+		{
+			name:   "kubernetes resource",
+			ruleID: "kube-rule",
+			data: event.Data{
+				compliance.KubeResourceFieldNamespace: "kube-system",
+				compliance.KubeResourceFieldName:      "node-exporter",
+			},
+			matches: true,
+		},
+		{
+			name:   "other namespace",
+			ruleID: "kube-rule",
+			data: event.Data{
+				compliance.KubeResourceFieldNamespace: "default",
+				compliance.KubeResourceFieldName:      "node-exporter",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			waiver := waivers.match(test.ruleID, test.data)
+			if test.matches {
+				assert.NotNil(t, waiver)
+				assert.Equal(t, test.ruleID, waiver.RuleID)
+			} else {
+				assert.Nil(t, waiver)
+			}
+		})
+	}
+}
+
+func TestWaiverSetHostTags(t *testing.T) {
+	waivers := newWaiverSet(loadTestWaivers(t), []string{"env:staging"})
+	waivers.now = func() time.Time { return time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	assert.Nil(t, waivers.match("docker-rule", event.Data{compliance.DockerContainerFieldImage: "registry.example.com/monitoring/agent:7"}))
+}
+
+func TestCheckRunWaived(t *testing.T) {
+	const (
+		ruleID       = "file-rule"
+		resourceType = "file"
+		resourceID   = "resource-id"
+	)
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		expectResult string
+	}{
+		{
+			name:         "waived failure",
+			now:          time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC),
+			expectResult: event.Waived,
+		},
+		{
+			name:         "expired waiver",
+			now:          time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC),
+			expectResult: event.Failed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := &mocks.Env{}
+			defer env.AssertExpectations(t)
+
+			reporter := &mocks.Reporter{}
+			defer reporter.AssertExpectations(t)
+
+			checkable := &mockCheckable{}
+			defer checkable.AssertExpectations(t)
+
+			waivers := newWaiverSet(loadTestWaivers(t), nil)
+			waivers.now = func() time.Time { return test.now }
+
+			check := &complianceCheck{
+				Env: env,
+
+				ruleID:    ruleID,
+				checkable: checkable,
+				scope:     resourceType,
+				waivers:   waivers,
+
+				suiteMeta: &compliance.SuiteMeta{Framework: "cis"},
+			}
+
+			env.On("Hostname").Return(resourceID)
+			env.On("IsLeader").Return(true)
+			env.On("Reporter").Return(reporter)
+			reporter.On("Report", mock.MatchedBy(func(e *event.Event) bool {
+				if e.Result != test.expectResult {
+					return false
+				}
+				if test.expectResult == event.Waived {
+					return e.Waiver != nil && e.Waiver.Justification == "managed by the cloud provider" &&
+						e.Waiver.ExpireAt.Equal(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC))
+				}
+				return e.Waiver == nil
+			})).Once()
+			checkable.On("check", check).Return([]*compliance.Report{
+				{
+					Passed: false,
+					Data: event.Data{
+						compliance.FileFieldPath: "/etc/kubernetes/admin.conf",
+					},
+				},
+			})
+
+			assert.NoError(t, check.Run())
+		})
+	}
+}
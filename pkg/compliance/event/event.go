@@ -14,6 +14,8 @@ const (
 	Failed = "failed"
 	// Error is used to report result of a rule check that resulted in an error (unable to evaluate condition)
 	Error = "error"
+	// Waived is used to report unsuccessful result of a rule check for which the risk was accepted with a waiver
+	Waived = "waived"
 )
 
 // Data defines a key value map for storing attributes of a reported rule event
@@ -32,4 +34,11 @@ type Event struct {
 	Data             interface{} `json:"data,omitempty"`
 	ExpireAt         time.Time   `json:"expire_at,omitempty"`
 	Evaluator        string      `json:"evaluator,omitempty"`
+	Waiver           *Waiver     `json:"waiver,omitempty"`
+}
+
+// Waiver describes the waiver of a waived rule event
+type Waiver struct {
+	Justification string    `json:"justification"`
+	ExpireAt      time.Time `json:"expire_at"`
 }
@@ -0,0 +1,255 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package export holds the compliance reports written by a local run of the compliance checks, in formats understood
+// by CI pipelines.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// Format is the format of a compliance report
+type Format string
+
+const (
+	// JSON is the format of the compliance reports as JSON documents
+	JSON Format = "json"
+	// SARIF is the format of the compliance reports as SARIF 2.1.0 logs
+	SARIF Format = "sarif"
+	// JUnit is the format of the compliance reports as JUnit XML reports
+	JUnit Format = "junit"
+)
+
+// ParseFormat returns the format matching a name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case JSON, SARIF, JUnit:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported report format `%s`, expected one of %s, %s or %s", name, JSON, SARIF, JUnit)
+	}
+}
+
+// Rule describes a rule of a compliance report
+type Rule struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description,omitempty"`
+	Framework   string   `json:"framework,omitempty"`
+	Benchmark   string   `json:"benchmark,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Results     []Result `json:"results"`
+}
+
+// Result is the outcome of a rule for a resource
+type Result struct {
+	Result       string        `json:"result"`
+	ResourceType string        `json:"resource_type,omitempty"`
+	ResourceID   string        `json:"resource_id,omitempty"`
+	Evidence     interface{}   `json:"evidence,omitempty"`
+	Waiver       *event.Waiver `json:"waiver,omitempty"`
+}
+
+// Summary counts the results of a compliance report
+type Summary struct {
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	Errors int `json:"errors"`
+	Waived int `json:"waived"`
+}
+
+// HasFindings returns whether a rule failed or couldn't be evaluated. Waived failures aren't findings.
+func (s Summary) HasFindings() bool {
+	return s.Failed > 0 || s.Errors > 0
+}
+
+type ruleKey struct {
+	framework string
+	id        string
+}
+
+// Report collects the results of the compliance checks. It implements the event reporter interface of the checks.
+type Report struct {
+	sync.Mutex
+
+	rules map[ruleKey]*Rule
+	order []ruleKey
+}
+
+// NewReport returns a new empty report
+func NewReport() *Report {
+	return &Report{
+		rules: make(map[ruleKey]*Rule),
+	}
+}
+
+func (r *Report) getRule(framework, id string) *Rule {
+	key := ruleKey{framework: framework, id: id}
+	rule, ok := r.rules[key]
+	if !ok {
+		rule = &Rule{ID: id, Framework: framework, Results: []Result{}}
+		r.rules[key] = rule
+		r.order = append(r.order, key)
+	}
+	return rule
+}
+
+// AddSuite registers the rules of a suite, so that they are described in the report
+func (r *Report) AddSuite(suite *compliance.Suite) {
+	r.Lock()
+	defer r.Unlock()
+
+	var commons []*compliance.RuleCommon
+	for i := range suite.Rules {
+		commons = append(commons, suite.Rules[i].Common())
+	}
+	for i := range suite.RegoRules {
+		commons = append(commons, suite.RegoRules[i].Common())
+	}
+
+	for _, common := range commons {
+		rule := r.getRule(suite.Meta.Framework, common.ID)
+		rule.Description = common.Description
+		rule.Benchmark = suite.Meta.Name
+		rule.Version = suite.Meta.Version
+	}
+}
+
+// AddSuiteFile registers the rules of a suite file
+func (r *Report) AddSuiteFile(path string) error {
+	suite, err := compliance.ParseSuite(path)
+	if err != nil {
+		return err
+	}
+	r.AddSuite(suite)
+	return nil
+}
+
+// Report adds the result of an event to the report
+func (r *Report) Report(e *event.Event) {
+	r.Lock()
+	defer r.Unlock()
+
+	evidence := e.Data
+	if data, ok := evidence.(event.Data); ok && len(data) == 0 {
+		evidence = nil
+	}
+
+	rule := r.getRule(e.AgentFrameworkID, e.AgentRuleID)
+	rule.Results = append(rule.Results, Result{
+		Result:       e.Result,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		Evidence:     evidence,
+		Waiver:       e.Waiver,
+	})
+}
+
+// ReportRaw is a no-op, only events are part of the report
+func (r *Report) ReportRaw(content []byte, service string, tags ...string) {}
+
+// Rules returns the rules of the report that have results, sorted by framework and ID
+func (r *Report) Rules() []*Rule {
+	r.Lock()
+	defer r.Unlock()
+
+	var rules []*Rule
+	for _, key := range r.order {
+		if rule := r.rules[key]; len(rule.Results) > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Framework != rules[j].Framework {
+			return rules[i].Framework < rules[j].Framework
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules
+}
+
+func summarize(rules []*Rule) Summary {
+	var summary Summary
+	for _, rule := range rules {
+		for _, result := range rule.Results {
+			switch result.Result {
+			case event.Passed:
+				summary.Passed++
+			case event.Failed:
+				summary.Failed++
+			case event.Waived:
+				summary.Waived++
+			default:
+				summary.Errors++
+			}
+		}
+	}
+	return summary
+}
+
+// Summary returns the number of passed, failed, errored and waived results
+func (r *Report) Summary() Summary {
+	return summarize(r.Rules())
+}
+
+// Write writes the report in the given format
+func (r *Report) Write(w io.Writer, format Format) error {
+	rules := r.Rules()
+
+	switch format {
+	case JSON:
+		return writeJSON(w, jsonReport{Rules: rules, Summary: summarize(rules)})
+	case SARIF:
+		return writeJSON(w, newSARIFLog(rules))
+	case JUnit:
+		return writeJUnit(w, rules)
+	default:
+		return fmt.Errorf("unsupported report format `%s`", format)
+	}
+}
+
+// jsonReport is the JSON representation of a report
+type jsonReport struct {
+	Rules   []*Rule `json:"rules"`
+	Summary Summary `json:"summary"`
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// evidenceString returns the evidence of a result as JSON
+func evidenceString(evidence interface{}) string {
+	if evidence == nil {
+		return ""
+	}
+	raw, err := json.Marshal(evidence)
+	if err != nil {
+		return fmt.Sprintf("%v", evidence)
+	}
+	return string(raw)
+}
+
+// resourceName returns the name of the resource of a result
+func (r *Result) resourceName() string {
+	switch {
+	case r.ResourceType == "":
+		return r.ResourceID
+	case r.ResourceID == "":
+		return r.ResourceType
+	default:
+		return r.ResourceType + ":" + r.ResourceID
+	}
+}
@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+func newTestReport(t *testing.T) *Report {
+	report := NewReport()
+	require.NoError(t, report.AddSuiteFile("../testdata/cis-docker.yaml"))
+
+	report.Report(&event.Event{
+		AgentRuleID:      "cis-docker-1",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Passed,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "host",
+		Data:             event.Data{"file.permissions": 0644},
+	})
+	report.Report(&event.Event{
+		AgentRuleID:      "cis-docker-2",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Failed,
+		ResourceType:     "docker_container",
+		ResourceID:       "nginx",
+		Data:             event.Data{"container.privileged": true},
+	})
+	report.Report(&event.Event{
+		AgentRuleID:      "cis-docker-2",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Error,
+		ResourceType:     "docker_container",
+		ResourceID:       "redis",
+		Data:             event.Data{"error": "inspect failed"},
+	})
+	report.Report(&event.Event{
+		AgentRuleID:      "custom-1",
+		AgentFrameworkID: "custom",
+		Result:           event.Passed,
+	})
+	return report
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, expected := range map[string]Format{"json": JSON, "SARIF": SARIF, "junit": JUnit} {
+		format, err := ParseFormat(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := ParseFormat("csv")
+	assert.Error(t, err)
+}
+
+func TestReportJSON(t *testing.T) {
+	report := newTestReport(t)
+
+	summary := report.Summary()
+	assert.Equal(t, Summary{Passed: 2, Failed: 1, Errors: 1}, summary)
+	assert.True(t, summary.HasFindings())
+
+	var buffer bytes.Buffer
+	require.NoError(t, report.Write(&buffer, JSON))
+
+	var decoded jsonReport
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &decoded))
+	require.Len(t, decoded.Rules, 3)
+	assert.Equal(t, "cis-docker-1", decoded.Rules[0].ID)
+	assert.Equal(t, "CIS Docker Generic", decoded.Rules[0].Benchmark)
+	assert.Equal(t, "cis-docker-2", decoded.Rules[1].ID)
+	assert.Len(t, decoded.Rules[1].Results, 2)
+	assert.Equal(t, "custom-1", decoded.Rules[2].ID)
+	assert.Nil(t, decoded.Rules[2].Results[0].Evidence)
+	assert.Equal(t, summary, decoded.Summary)
+}
+
+func TestReportSARIF(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, newTestReport(t).Write(&buffer, SARIF))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &log))
+	assert.Equal(t, sarifVersion, log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	require.Len(t, run.Tool.Driver.Rules, 3)
+	assert.Equal(t, "cis-docker-2", run.Tool.Driver.Rules[1].ID)
+
+	require.Len(t, run.Results, 4)
+	assert.Equal(t, "pass", run.Results[0].Kind)
+	assert.Equal(t, "fail", run.Results[1].Kind)
+	assert.Equal(t, "error", run.Results[1].Level)
+	assert.Equal(t, 1, run.Results[1].RuleIndex)
+	assert.Equal(t, "nginx", run.Results[1].Locations[0].LogicalLocations[0].Name)
+	assert.Equal(t, "review", run.Results[2].Kind)
+	assert.Empty(t, run.Results[3].Locations)
+}
+
+func TestReportJUnit(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, newTestReport(t).Write(&buffer, JUnit))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buffer.Bytes(), &suites))
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 1, suites.Errors)
+
+	require.Len(t, suites.Suites, 2)
+	assert.Equal(t, "cis-docker", suites.Suites[0].Name)
+	require.Len(t, suites.Suites[0].TestCases, 3)
+	assert.Equal(t, "cis-docker-2 [docker_container:nginx]", suites.Suites[0].TestCases[1].Name)
+	require.NotNil(t, suites.Suites[0].TestCases[1].Failure)
+	assert.Equal(t, `{"container.privileged":true}`, suites.Suites[0].TestCases[1].Failure.Contents)
+	require.NotNil(t, suites.Suites[0].TestCases[2].Error)
+}
+
+func TestReportAddSuite(t *testing.T) {
+	report := NewReport()
+	report.AddSuite(&compliance.Suite{
+		Meta: compliance.SuiteMeta{Name: "Custom", Framework: "custom", Version: "1.0.0"},
+		RegoRules: []compliance.RegoRule{{
+			RuleCommon: compliance.RuleCommon{ID: "custom-1", Description: "Custom rule"},
+		}},
+	})
+
+	assert.Empty(t, report.Rules(), "rules without results shouldn't be reported")
+
+	report.Report(&event.Event{AgentRuleID: "custom-1", AgentFrameworkID: "custom", Result: event.Passed})
+	rules := report.Rules()
+	require.Len(t, rules, 1)
+	assert.Equal(t, "Custom rule", rules[0].Description)
+	assert.Equal(t, "1.0.0", rules[0].Version)
+}
+
+func TestReportWaived(t *testing.T) {
+	report := NewReport()
+	report.Report(&event.Event{
+		AgentRuleID:      "cis-kubernetes-5.2.1",
+		AgentFrameworkID: "cis-kubernetes",
+		Result:           event.Waived,
+		ResourceType:     "kube_daemonset",
+		ResourceID:       "kube-system/node-exporter",
+		Waiver:           &event.Waiver{Justification: "needs host access"},
+	})
+
+	summary := report.Summary()
+	assert.Equal(t, Summary{Waived: 1}, summary)
+	assert.False(t, summary.HasFindings())
+
+	var buffer bytes.Buffer
+	require.NoError(t, report.Write(&buffer, SARIF))
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &log))
+	require.Len(t, log.Runs[0].Results, 1)
+	require.Len(t, log.Runs[0].Results[0].Suppressions, 1)
+	assert.Equal(t, "needs host access", log.Runs[0].Results[0].Suppressions[0].Justification)
+
+	buffer.Reset()
+	require.NoError(t, report.Write(&buffer, JUnit))
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buffer.Bytes(), &suites))
+	assert.Equal(t, 1, suites.Skipped)
+	assert.Equal(t, 0, suites.Failures)
+	require.NotNil(t, suites.Suites[0].TestCases[0].Skipped)
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as a JUnit XML report: one test suite per framework, and one test case per rule and
+// resource
+func writeJUnit(w io.Writer, rules []*Rule) error {
+	suites := junitTestSuites{Name: sarifToolName}
+
+	suiteIndex := make(map[string]int)
+	for _, rule := range rules {
+		index, ok := suiteIndex[rule.Framework]
+		if !ok {
+			index = len(suites.Suites)
+			suiteIndex[rule.Framework] = index
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: rule.Framework})
+		}
+		suite := &suites.Suites[index]
+
+		for _, result := range rule.Results {
+			name := rule.ID
+			if resource := result.resourceName(); resource != "" {
+				name = fmt.Sprintf("%s [%s]", rule.ID, resource)
+			}
+
+			testCase := junitTestCase{
+				Name:      name,
+				ClassName: rule.Framework,
+				SystemOut: rule.Description,
+			}
+
+			evidence := evidenceString(result.Evidence)
+			switch result.Result {
+			case event.Passed:
+			case event.Waived:
+				var justification string
+				if result.Waiver != nil {
+					justification = result.Waiver.Justification
+				}
+				testCase.Skipped = &junitMessage{Message: fmt.Sprintf("%s failure waived: %s", rule.ID, justification), Type: event.Waived, Contents: evidence}
+				suite.Skipped++
+			case event.Failed:
+				testCase.Failure = &junitMessage{Message: fmt.Sprintf("%s failed", rule.ID), Type: event.Failed, Contents: evidence}
+				suite.Failures++
+			default:
+				testCase.Error = &junitMessage{Message: fmt.Sprintf("%s couldn't be evaluated", rule.ID), Type: result.Result, Contents: evidence}
+				suite.Errors++
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+			suite.Tests++
+		}
+
+		suites.Tests += len(rule.Results)
+	}
+
+	for _, suite := range suites.Suites {
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package export
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion  = "2.1.0"
+	sarifToolName = "datadog-security-agent"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	ShortDescription *sarifMessage          `json:"shortDescription,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID       string                 `json:"ruleId"`
+	RuleIndex    int                    `json:"ruleIndex"`
+	Kind         string                 `json:"kind"`
+	Level        string                 `json:"level"`
+	Message      sarifMessage           `json:"message"`
+	Locations    []sarifLocation        `json:"locations,omitempty"`
+	Suppressions []sarifSuppression     `json:"suppressions,omitempty"`
+	Properties   map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// sarifKindAndLevel maps the result of a rule to the kind and level of a SARIF result. Rules that couldn't be
+// evaluated need a review, as nothing tells whether they passed.
+func sarifKindAndLevel(result string) (string, string) {
+	switch result {
+	case event.Passed:
+		return "pass", "none"
+	case event.Failed:
+		return "fail", "error"
+	case event.Waived:
+		return "fail", "note"
+	default:
+		return "review", "warning"
+	}
+}
+
+func newSARIFLog(rules []*Rule) *sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:    sarifToolName,
+				Version: version.AgentVersion,
+				Rules:   []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	for index, rule := range rules {
+		sr := sarifRule{
+			ID: rule.ID,
+			Properties: map[string]interface{}{
+				"framework": rule.Framework,
+			},
+		}
+		if rule.Description != "" {
+			sr.ShortDescription = &sarifMessage{Text: rule.Description}
+		}
+		if rule.Benchmark != "" {
+			sr.Properties["benchmark"] = rule.Benchmark
+		}
+		if rule.Version != "" {
+			sr.Properties["benchmark_version"] = rule.Version
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sr)
+
+		for _, result := range rule.Results {
+			kind, level := sarifKindAndLevel(result.Result)
+
+			resource := result.resourceName()
+			text := fmt.Sprintf("%s: %s", rule.ID, result.Result)
+			if resource != "" {
+				text = fmt.Sprintf("%s %s for %s", rule.ID, result.Result, resource)
+			}
+
+			res := sarifResult{
+				RuleID:    rule.ID,
+				RuleIndex: index,
+				Kind:      kind,
+				Level:     level,
+				Message:   sarifMessage{Text: text},
+			}
+			if result.ResourceID != "" {
+				res.Locations = []sarifLocation{{
+					LogicalLocations: []sarifLogicalLocation{{
+						Name: result.ResourceID,
+						Kind: result.ResourceType,
+					}},
+				}}
+			}
+			if result.Waiver != nil {
+				res.Suppressions = []sarifSuppression{{
+					Kind:          "external",
+					Status:        "accepted",
+					Justification: result.Waiver.Justification,
+				}}
+			}
+			if result.Evidence != nil {
+				res.Properties = map[string]interface{}{
+					"evidence": result.Evidence,
+				}
+			}
+			run.Results = append(run.Results, res)
+		}
+	}
+
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+}
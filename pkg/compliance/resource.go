@@ -34,19 +34,28 @@ const (
 	KindConstants = ResourceKind("constants")
 	// KindCustom is used for a Custom check
 	KindCustom = ResourceKind("custom")
+	// KindKubernetesManifests is used for a KubernetesManifests resource
+	KindKubernetesManifests = ResourceKind("kubeManifests")
+	// KindImage is used for an Image resource
+	KindImage = ResourceKind("image")
+	// KindPackages is used for a Packages resource
+	KindPackages = ResourceKind("packages")
 )
 
 // ResourceCommon describes the base fields of resource types
 type ResourceCommon struct {
-	File          *File               `yaml:"file,omitempty"`
-	Process       *Process            `yaml:"process,omitempty"`
-	Group         *Group              `yaml:"group,omitempty"`
-	Command       *Command            `yaml:"command,omitempty"`
-	Audit         *Audit              `yaml:"audit,omitempty"`
-	Docker        *DockerResource     `yaml:"docker,omitempty"`
-	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
-	Constants     *ConstantsResource  `yaml:"constants,omitempty"`
-	Custom        *Custom             `yaml:"custom,omitempty"`
+	File          *File                `yaml:"file,omitempty"`
+	Process       *Process             `yaml:"process,omitempty"`
+	Group         *Group               `yaml:"group,omitempty"`
+	Command       *Command             `yaml:"command,omitempty"`
+	Audit         *Audit               `yaml:"audit,omitempty"`
+	Docker        *DockerResource      `yaml:"docker,omitempty"`
+	KubeApiserver *KubernetesResource  `yaml:"kubeApiserver,omitempty"`
+	Constants     *ConstantsResource   `yaml:"constants,omitempty"`
+	Custom        *Custom              `yaml:"custom,omitempty"`
+	KubeManifests *KubernetesManifests `yaml:"kubeManifests,omitempty"`
+	Image         *Image               `yaml:"image,omitempty"`
+	Packages      *Packages            `yaml:"packages,omitempty"`
 }
 
 // Resource describes supported resource types observed by a Rule
@@ -96,6 +105,12 @@ func (r *ResourceCommon) Kind() ResourceKind {
 		return KindConstants
 	case r.Custom != nil:
 		return KindCustom
+	case r.KubeManifests != nil:
+		return KindKubernetesManifests
+	case r.Image != nil:
+		return KindImage
+	case r.Packages != nil:
+		return KindPackages
 	default:
 		return KindInvalid
 	}
@@ -183,6 +198,20 @@ type KubernetesAPIRequest struct {
 	ResourceName string `yaml:"resourceName,omitempty"`
 }
 
+// Fields available for KubernetesManifests, in addition to the ones of KubernetesResource
+const (
+	KubeManifestFieldPath = "kube.manifest.path"
+)
+
+// KubernetesManifests describes Kubernetes objects read from manifest files, such as the output of `helm template`.
+// Objects are exposed like the ones of a KubernetesResource, so that the same rules apply before deploying them.
+type KubernetesManifests struct {
+	// Path of a manifest file, a directory of manifest files, or a glob pattern
+	Path      string `yaml:"path"`
+	Kind      string `yaml:"kind,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
 // Fields & functions available for Group
 const (
 	GroupFieldName  = "group.name"
@@ -291,6 +320,36 @@ type DockerResource struct {
 	Kind string `yaml:"kind"`
 }
 
+// Fields available for Image, in addition to the ones of docker images
+const (
+	ImageFieldPath  = "image.path"
+	ImageFieldFiles = "image.files"
+)
+
+// Image describes container images read from an OCI image layout or a `docker save` archive, either extracted in a
+// directory or as a tarball. Images are exposed like docker images, with the files of their filesystem matching the
+// `files` glob patterns and the packages installed by dpkg.
+type Image struct {
+	Path   string   `yaml:"path"`
+	Files  []string `yaml:"files,omitempty"`
+	Parser string   `yaml:"parser,omitempty"`
+}
+
+// Fields available for Packages
+const (
+	PackageFieldName         = "package.name"
+	PackageFieldVersion      = "package.version"
+	PackageFieldArchitecture = "package.architecture"
+	PackageFieldManager      = "package.manager"
+)
+
+// Packages describes the packages installed according to a package manager database: the dpkg status file, the rpm
+// database directory, or the apk database
+type Packages struct {
+	Manager string `yaml:"manager"`
+	Path    string `yaml:"path,omitempty"`
+}
+
 // ConstantsResource describes a resources filled with constants
 type ConstantsResource struct {
 	Values map[string]interface{} `yaml:",inline"`
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compliance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// waiverDateLayout is the layout of the expiry dates of waivers given as days
+const waiverDateLayout = "2006-01-02"
+
+// WaiverResource selects the resources a waiver applies to. Each set field must match the resource: `path` and
+// `image` are glob patterns, matched against the file paths and container images of the resource, `namespace` and
+// `name` are matched against the Kubernetes resource, and all the `hostTags` must be set on the host.
+type WaiverResource struct {
+	Path      string   `yaml:"path,omitempty"`
+	Image     string   `yaml:"image,omitempty"`
+	Namespace string   `yaml:"namespace,omitempty"`
+	Name      string   `yaml:"name,omitempty"`
+	HostTags  []string `yaml:"hostTags,omitempty"`
+}
+
+// Waiver accepts the risk of a rule failing for some resources, until it expires
+type Waiver struct {
+	RuleID        string         `yaml:"ruleId"`
+	Resource      WaiverResource `yaml:"resource,omitempty"`
+	Justification string         `yaml:"justification"`
+	Expires       string         `yaml:"expires"`
+
+	expiresAt time.Time
+}
+
+// ExpiresAt returns the time at which the waiver expires
+func (w *Waiver) ExpiresAt() time.Time {
+	return w.expiresAt
+}
+
+// IsExpired returns whether the waiver expired
+func (w *Waiver) IsExpired(now time.Time) bool {
+	return !now.Before(w.expiresAt)
+}
+
+// check validates the waiver and parses its expiry date, given either as a day, the waiver expiring at the end of
+// the day in UTC, or as a RFC 3339 time
+func (w *Waiver) check() error {
+	if w.RuleID == "" {
+		return errors.New("missing rule ID")
+	}
+	if w.Justification == "" {
+		return errors.New("missing justification")
+	}
+	if w.Expires == "" {
+		return errors.New("missing expiry date")
+	}
+
+	for _, pattern := range []string{w.Resource.Path, w.Resource.Image} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern `%s`: %w", pattern, err)
+		}
+	}
+
+	if day, err := time.Parse(waiverDateLayout, w.Expires); err == nil {
+		w.expiresAt = day.AddDate(0, 0, 1)
+	} else if w.expiresAt, err = time.Parse(time.RFC3339, w.Expires); err != nil {
+		return fmt.Errorf("invalid expiry date `%s`, expected YYYY-MM-DD or RFC 3339", w.Expires)
+	}
+
+	return nil
+}
+
+// WaiverFile is the content of a waiver file
+type WaiverFile struct {
+	Waivers []*Waiver `yaml:"waivers"`
+}
+
+// ParseWaivers loads and validates the waivers of a waiver file
+func ParseWaivers(filename string) ([]*Waiver, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file WaiverFile
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, err
+	}
+
+	for i, waiver := range file.Waivers {
+		if err := waiver.check(); err != nil {
+			return nil, fmt.Errorf("invalid waiver #%d of %s: %w", i+1, filename, err)
+		}
+	}
+
+	return file.Waivers, nil
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compliance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWaivers(t *testing.T) {
+	waivers, err := ParseWaivers("./testdata/waivers.yaml")
+	require.NoError(t, err)
+	require.Len(t, waivers, 2)
+
+	assert.Equal(t, "cis-kubernetes-5.2.1", waivers[0].RuleID)
+	assert.Equal(t, "kube-system", waivers[0].Resource.Namespace)
+	assert.Equal(t, time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC), waivers[0].ExpiresAt(), "a day should expire at its end")
+	assert.False(t, waivers[0].IsExpired(time.Date(2030, 12, 31, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, waivers[0].IsExpired(time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, []string{"env:prod"}, waivers[1].Resource.HostTags)
+	assert.Equal(t, time.Date(2030, 6, 30, 12, 0, 0, 0, time.UTC), waivers[1].ExpiresAt())
+}
+
+func TestParseInvalidWaivers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "missing justification",
+			content: "waivers:\n  - ruleId: rule\n    expires: 2030-01-01\n",
+		},
+		{
+			name:    "missing expiry date",
+			content: "waivers:\n  - ruleId: rule\n    justification: accepted\n",
+		},
+		{
+			name:    "invalid expiry date",
+			content: "waivers:\n  - ruleId: rule\n    justification: accepted\n    expires: next year\n",
+		},
+		{
+			name:    "invalid pattern",
+			content: "waivers:\n  - ruleId: rule\n    justification: accepted\n    expires: 2030-01-01\n    resource:\n      path: /etc/[\n",
+		},
+		{
+			name:    "unknown field",
+			content: "waivers:\n  - rule: rule\n    justification: accepted\n    expires: 2030-01-01\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "waivers.yaml")
+			require.NoError(t, os.WriteFile(filename, []byte(test.content), 0644))
+
+			_, err := ParseWaivers(filename)
+			assert.Error(t, err)
+		})
+	}
+}
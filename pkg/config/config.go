@@ -673,6 +673,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 
+	// SBOM
+	config.BindEnvAndSetDefault("sbom.enabled", false)
+	bindEnvAndSetLogsConfigKeys(config, "sbom.")
+
 	// Kube ApiServer
 	config.BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
 	config.BindEnvAndSetDefault("kubernetes_apiserver_ca_path", "")
@@ -1061,6 +1065,7 @@ func InitConfig(config Config) {
 	config.BindEnv("compliance_config.run_commands_as")
 	bindEnvAndSetLogsConfigKeys(config, "compliance_config.endpoints.")
 	config.BindEnvAndSetDefault("compliance_config.ignore_host_selectors", false)
+	config.BindEnvAndSetDefault("compliance_config.waivers_file", "")
 
 	// Datadog security agent (runtime)
 	config.BindEnvAndSetDefault("runtime_security_config.enabled", false)
@@ -1119,10 +1124,20 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.remote_storage.compression", true)
 	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.syscall_monitor.enabled", true)
 	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.syscall_monitor.period", 60)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.policy_export.path_depth", 3)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.drift.baseline_directory", "")
+	config.BindEnvAndSetDefault("runtime_security_config.fim_baseline.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.fim_baseline.paths", []string{})
+	config.BindEnvAndSetDefault("runtime_security_config.fim_baseline.hash_algorithm", "sha256")
+	config.BindEnvAndSetDefault("runtime_security_config.fim_baseline.max_baselines", 10000)
+	config.BindEnvAndSetDefault("runtime_security_config.fim_baseline.max_file_size", 10*1024*1024)
+	config.BindEnvAndSetDefault("runtime_security_config.fim_baseline.baseline_file", "")
+	config.BindEnvAndSetDefault("runtime_security_config.fim_baseline.check_period", 1)
 	bindEnvAndSetLogsConfigKeys(config, "runtime_security_config.activity_dump.remote_storage.endpoints.")
 	config.BindEnvAndSetDefault("runtime_security_config.event_stream.use_ring_buffer", false)
 	config.BindEnv("runtime_security_config.event_stream.buffer_size")
 	config.BindEnvAndSetDefault("runtime_security_config.envs_with_value", []string{"LD_PRELOAD", "LD_LIBRARY_PATH", "PATH", "HISTSIZE", "HISTFILESIZE"})
+	config.BindEnvAndSetDefault("runtime_security_config.response_actions.enabled", false)
 
 	// Serverless Agent
 	config.BindEnvAndSetDefault("serverless.logs_enabled", true)
@@ -83,6 +83,10 @@ func setupOTLPEnvironmentVariables(config Config) {
 	// Traces settingds
 	config.BindEnvAndSetDefault("otlp_config.traces.span_name_remappings", map[string]string{})
 	config.BindEnv("otlp_config.traces.span_name_as_resource_name")
+	config.BindEnv("otlp_config.traces.attributes_mapping.service")
+	config.BindEnv("otlp_config.traces.attributes_mapping.resource")
+	config.BindEnv("otlp_config.traces.attributes_mapping.operation_name")
+	config.BindEnv("otlp_config.traces.attributes_mapping.peer_tags")
 
 	// HTTP settings
 	config.BindEnv(OTLPSection + ".receiver.protocols.http.endpoint")
@@ -67,6 +67,33 @@ const (
 
 	// DefaultProcessEventsCheckInterval is the default interval used by the process_events check
 	DefaultProcessEventsCheckInterval = 10 * time.Second
+
+	// DefaultConnectionsExportMaxFileSize is the default size, in bytes, at which connections export files get rotated
+	DefaultConnectionsExportMaxFileSize = 100 * 1024 * 1024
+
+	// DefaultConnectionsExportMaxFiles is the default number of rotated connections export files to keep
+	DefaultConnectionsExportMaxFiles = 5
+
+	// DefaultConnectionsExportQueueSize is the default number of connections check payloads that can wait to be exported
+	DefaultConnectionsExportQueueSize = 4
+
+	// DefaultAnomalyDetectionMaxTrackedProcesses is the default number of processes the anomaly detectors keep state for
+	DefaultAnomalyDetectionMaxTrackedProcesses = 10000
+
+	// DefaultAnomalyDetectionRSSGrowthWindow is the default period the RSS growth slope is computed over
+	DefaultAnomalyDetectionRSSGrowthWindow = 10 * time.Minute
+
+	// DefaultAnomalyDetectionRSSGrowthMinRate is the default RSS growth slope, in bytes per second, past which a process is anomalous
+	DefaultAnomalyDetectionRSSGrowthMinRate = 100 * 1024
+
+	// DefaultAnomalyDetectionCPUThreshold is the default CPU usage percentage, 100 being a full core, past which a process may be anomalous
+	DefaultAnomalyDetectionCPUThreshold = 90
+
+	// DefaultAnomalyDetectionCPUDuration is the default period a process must stay above the CPU threshold for
+	DefaultAnomalyDetectionCPUDuration = 5 * time.Minute
+
+	// DefaultAnomalyDetectionOpenFilesLimitRatio is the default share of its open files limit past which a process is anomalous
+	DefaultAnomalyDetectionOpenFilesLimitRatio = 0.9
 )
 
 // setupProcesses is meant to be called multiple times for different configs, but overrides apply to all configs, so
@@ -109,6 +136,16 @@ func setupProcesses(config Config) {
 	})
 	procBindEnvAndSetDefault(config, "process_config.container_collection.enabled", true)
 	procBindEnvAndSetDefault(config, "process_config.process_collection.enabled", false)
+	procBindEnvAndSetDefault(config, "process_config.process_collection.network_metrics.enabled", false)
+
+	// Anomaly detection on the realtime samples of processes
+	procBindEnvAndSetDefault(config, "process_config.anomaly_detection.enabled", false)
+	procBindEnvAndSetDefault(config, "process_config.anomaly_detection.max_tracked_processes", DefaultAnomalyDetectionMaxTrackedProcesses)
+	procBindEnvAndSetDefault(config, "process_config.anomaly_detection.rss_growth.window", DefaultAnomalyDetectionRSSGrowthWindow)
+	procBindEnvAndSetDefault(config, "process_config.anomaly_detection.rss_growth.min_bytes_per_second", DefaultAnomalyDetectionRSSGrowthMinRate)
+	procBindEnvAndSetDefault(config, "process_config.anomaly_detection.cpu.threshold", DefaultAnomalyDetectionCPUThreshold)
+	procBindEnvAndSetDefault(config, "process_config.anomaly_detection.cpu.duration", DefaultAnomalyDetectionCPUDuration)
+	procBindEnvAndSetDefault(config, "process_config.anomaly_detection.open_files.limit_ratio", DefaultAnomalyDetectionOpenFilesLimitRatio)
 
 	config.BindEnv("process_config.process_dd_url",
 		"DD_PROCESS_CONFIG_PROCESS_DD_URL",
@@ -184,6 +221,16 @@ func setupProcesses(config Config) {
 	procBindEnvAndSetDefault(config, "process_config.event_collection.store.stats_interval", DefaultProcessEventStoreStatsInterval)
 	procBindEnvAndSetDefault(config, "process_config.event_collection.enabled", false)
 	procBindEnvAndSetDefault(config, "process_config.event_collection.interval", DefaultProcessEventsCheckInterval)
+	procBindEnvAndSetDefault(config, "process_config.event_collection.logs.enabled", false)
+	procBindEnvAndSetDefault(config, "process_config.event_collection.dogstatsd_events.command_patterns", []string{})
+
+	// Connections Export
+	procBindEnvAndSetDefault(config, "process_config.connections_export.enabled", false)
+	procBindEnvAndSetDefault(config, "process_config.connections_export.format", "json")
+	procBindEnvAndSetDefault(config, "process_config.connections_export.destination", "")
+	procBindEnvAndSetDefault(config, "process_config.connections_export.max_file_size", DefaultConnectionsExportMaxFileSize)
+	procBindEnvAndSetDefault(config, "process_config.connections_export.max_files", DefaultConnectionsExportMaxFiles)
+	procBindEnvAndSetDefault(config, "process_config.connections_export.queue_size", DefaultConnectionsExportQueueSize)
 
 	processesAddOverrideOnce.Do(func() {
 		AddOverrideFunc(loadProcessTransforms)
@@ -50,6 +50,38 @@ func TestProcessDefaultConfig(t *testing.T) {
 			key:          "process_config.process_collection.enabled",
 			defaultValue: false,
 		},
+		{
+			key:          "process_config.process_collection.network_metrics.enabled",
+			defaultValue: false,
+		},
+		{
+			key:          "process_config.anomaly_detection.enabled",
+			defaultValue: false,
+		},
+		{
+			key:          "process_config.anomaly_detection.max_tracked_processes",
+			defaultValue: DefaultAnomalyDetectionMaxTrackedProcesses,
+		},
+		{
+			key:          "process_config.anomaly_detection.rss_growth.window",
+			defaultValue: DefaultAnomalyDetectionRSSGrowthWindow,
+		},
+		{
+			key:          "process_config.anomaly_detection.rss_growth.min_bytes_per_second",
+			defaultValue: DefaultAnomalyDetectionRSSGrowthMinRate,
+		},
+		{
+			key:          "process_config.anomaly_detection.cpu.threshold",
+			defaultValue: DefaultAnomalyDetectionCPUThreshold,
+		},
+		{
+			key:          "process_config.anomaly_detection.cpu.duration",
+			defaultValue: DefaultAnomalyDetectionCPUDuration,
+		},
+		{
+			key:          "process_config.anomaly_detection.open_files.limit_ratio",
+			defaultValue: DefaultAnomalyDetectionOpenFilesLimitRatio,
+		},
 		{
 			key:          "process_config.container_collection.enabled",
 			defaultValue: true,
@@ -122,6 +154,34 @@ func TestProcessDefaultConfig(t *testing.T) {
 			key:          "process_config.event_collection.interval",
 			defaultValue: DefaultProcessEventsCheckInterval,
 		},
+		{
+			key:          "process_config.event_collection.logs.enabled",
+			defaultValue: false,
+		},
+		{
+			key:          "process_config.event_collection.dogstatsd_events.command_patterns",
+			defaultValue: []string{},
+		},
+		{
+			key:          "process_config.connections_export.enabled",
+			defaultValue: false,
+		},
+		{
+			key:          "process_config.connections_export.format",
+			defaultValue: "json",
+		},
+		{
+			key:          "process_config.connections_export.max_file_size",
+			defaultValue: DefaultConnectionsExportMaxFileSize,
+		},
+		{
+			key:          "process_config.connections_export.max_files",
+			defaultValue: DefaultConnectionsExportMaxFiles,
+		},
+		{
+			key:          "process_config.connections_export.queue_size",
+			defaultValue: DefaultConnectionsExportQueueSize,
+		},
 	} {
 		t.Run(tc.key+" default", func(t *testing.T) {
 			assert.Equal(t, tc.defaultValue, cfg.Get(tc.key))
@@ -247,6 +307,25 @@ func TestEnvVarOverride(t *testing.T) {
 			value:    "true",
 			expected: true,
 		},
+		{
+			key:      "process_config.process_collection.network_metrics.enabled",
+			env:      "DD_PROCESS_CONFIG_PROCESS_COLLECTION_NETWORK_METRICS_ENABLED",
+			value:    "true",
+			expected: true,
+		},
+		{
+			key:      "process_config.anomaly_detection.enabled",
+			env:      "DD_PROCESS_CONFIG_ANOMALY_DETECTION_ENABLED",
+			value:    "true",
+			expType:  "boolean",
+			expected: true,
+		},
+		{
+			key:      "process_config.anomaly_detection.cpu.duration",
+			env:      "DD_PROCESS_CONFIG_ANOMALY_DETECTION_CPU_DURATION",
+			value:    "1m",
+			expected: time.Minute,
+		},
 		{
 			key:      "process_config.container_collection.enabled",
 			env:      "DD_PROCESS_CONFIG_CONTAINER_COLLECTION_ENABLED",
@@ -417,6 +496,20 @@ func TestEnvVarOverride(t *testing.T) {
 			value:    "20s",
 			expected: 20 * time.Second,
 		},
+		{
+			key:      "process_config.event_collection.logs.enabled",
+			env:      "DD_PROCESS_CONFIG_EVENT_COLLECTION_LOGS_ENABLED",
+			value:    "true",
+			expType:  "boolean",
+			expected: true,
+		},
+		{
+			key:      "process_config.event_collection.dogstatsd_events.command_patterns",
+			env:      "DD_PROCESS_CONFIG_EVENT_COLLECTION_DOGSTATSD_EVENTS_COMMAND_PATTERNS",
+			value:    "^/usr/sbin/cron backup.sh",
+			expType:  "stringSlice",
+			expected: []string{"^/usr/sbin/cron", "backup.sh"},
+		},
 	} {
 		t.Run(tc.env, func(t *testing.T) {
 			reset := setEnvForTest(tc.env, tc.value)
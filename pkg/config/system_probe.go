@@ -139,6 +139,16 @@ func InitSystemProbeConfig(cfg Config) {
 	cfg.BindEnv(join(netNS, "enable_https_monitoring"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTPS_MONITORING")
 	cfg.BindEnvAndSetDefault(join(netNS, "enable_gateway_lookup"), true, "DD_SYSTEM_PROBE_NETWORK_ENABLE_GATEWAY_LOOKUP")
 	cfg.BindEnvAndSetDefault(join(netNS, "max_http_stats_buffered"), 100000, "DD_SYSTEM_PROBE_NETWORK_MAX_HTTP_STATS_BUFFERED")
+	cfg.BindEnv(join(netNS, "enable_http2_monitoring"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "http2_ports"), []string{"50051"}, "DD_SYSTEM_PROBE_NETWORK_HTTP2_PORTS")
+	cfg.BindEnv(join(netNS, "enable_kafka_monitoring"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_KAFKA_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "kafka_ports"), []string{"9092"}, "DD_SYSTEM_PROBE_NETWORK_KAFKA_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "max_kafka_stats_buffered"), 100000, "DD_SYSTEM_PROBE_NETWORK_MAX_KAFKA_STATS_BUFFERED")
+	cfg.BindEnv(join(netNS, "enable_tls_metadata"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_TLS_METADATA")
+	cfg.BindEnvAndSetDefault(join(netNS, "tls_ports"), []string{"443"}, "DD_SYSTEM_PROBE_NETWORK_TLS_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "max_tracked_tls_connections"), 100000, "DD_SYSTEM_PROBE_NETWORK_MAX_TRACKED_TLS_CONNECTIONS")
+	cfg.BindEnv(join(netNS, "enable_dns_query_log"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_DNS_QUERY_LOG")
+	cfg.BindEnvAndSetDefault(join(netNS, "max_dns_query_log_records"), 10000, "DD_SYSTEM_PROBE_NETWORK_MAX_DNS_QUERY_LOG_RECORDS")
 	httpRules := join(netNS, "http_replace_rules")
 	cfg.BindEnv(httpRules, "DD_SYSTEM_PROBE_NETWORK_HTTP_REPLACE_RULES")
 	cfg.SetEnvKeyTransformer(httpRules, func(in string) interface{} {
@@ -36,6 +36,9 @@ const (
 
 	// EventTypeNetworkDevicesNetFlow is the event type for network devices NetFlow data
 	EventTypeNetworkDevicesNetFlow = "network-devices-netflow"
+
+	// EventTypeSBOM is the event type for the software bills of materials of hosts and container images
+	EventTypeSBOM = "sbom"
 )
 
 var passthroughPipelineDescs = []passthroughPipelineDesc{
@@ -113,6 +116,16 @@ var passthroughPipelineDescs = []passthroughPipelineDesc{
 		//   by aggregator.
 		defaultInputChanSize: 10000,
 	},
+	{
+		eventType:                     EventTypeSBOM,
+		endpointsConfigPrefix:         "sbom.",
+		hostnameEndpointPrefix:        "sbom-intake.",
+		intakeTrackType:               "sbom",
+		defaultBatchMaxConcurrentSend: 10,
+		defaultBatchMaxContentSize:    20e6,
+		defaultBatchMaxSize:           pkgconfig.DefaultBatchMaxSize,
+		defaultInputChanSize:          pkgconfig.DefaultInputChanSize,
+	},
 }
 
 // An EventPlatformForwarder forwards Messages to a destination based on their event type
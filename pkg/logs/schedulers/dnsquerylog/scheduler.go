@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package dnsquerylog ships the DNS queries logged by system-probe as logs.
+package dnsquerylog
+
+import (
+	"encoding/json"
+	"time"
+
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/schedulers"
+	"github.com/DataDog/datadog-agent/pkg/logs/schedulers/channel"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/net"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/v2/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	sourceName = "DNS Query Log"
+	source     = "dns"
+
+	// pollInterval is how often the query log is fetched from system-probe
+	pollInterval = 10 * time.Second
+	// pidToContainerCacheDuration is how long the container of a process is cached
+	pidToContainerCacheDuration = time.Minute
+)
+
+// Scheduler is a logs-agent Scheduler that polls system-probe for the DNS
+// queries it logged, and sends each of them as a JSON log through a channel
+// source. Queries are attributed to the container of the process that sent
+// them, when known.
+type Scheduler struct {
+	channel  *channel.Scheduler
+	logsChan chan *config.ChannelMessage
+
+	fetch       func() ([]dns.QueryRecord, error)
+	containerID func(pid uint32) string
+	interval    time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ schedulers.Scheduler = &Scheduler{}
+
+// IsEnabled returns true if the DNS query log is enabled in the system-probe
+// configuration.
+func IsEnabled() bool {
+	return ddconfig.Datadog.GetBool("network_config.enable_dns_query_log")
+}
+
+// New creates a new Scheduler fetching the query log from the system-probe
+// listening on the configured socket.
+func New() *Scheduler {
+	net.SetSystemProbePath(ddconfig.Datadog.GetString("system_probe_config.sysprobe_socket"))
+	return newScheduler(fetchQueryLog, containerIDForPID, pollInterval)
+}
+
+func newScheduler(fetch func() ([]dns.QueryRecord, error), containerID func(pid uint32) string, interval time.Duration) *Scheduler {
+	logsChan := make(chan *config.ChannelMessage, 100)
+	return &Scheduler{
+		channel:     channel.NewScheduler(sourceName, source, logsChan, nil),
+		logsChan:    logsChan,
+		fetch:       fetch,
+		containerID: containerID,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start implements schedulers.Scheduler#Start.
+func (s *Scheduler) Start(sourceMgr schedulers.SourceManager) {
+	s.channel.Start(sourceMgr)
+	go s.run()
+}
+
+// Stop implements schedulers.Scheduler#Stop.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	<-s.done
+	s.channel.Stop()
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.collect()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// collect sends the queries logged since the last call to the logs pipeline
+func (s *Scheduler) collect() {
+	records, err := s.fetch()
+	if err != nil {
+		log.Debugf("unable to fetch the dns query log from system-probe: %s", err)
+		return
+	}
+
+	for i := range records {
+		record := &records[i]
+		if record.Pid != 0 && record.ContainerID == "" {
+			record.ContainerID = s.containerID(record.Pid)
+		}
+
+		content, err := json.Marshal(record)
+		if err != nil {
+			log.Warnf("unable to marshal dns query log record: %s", err)
+			continue
+		}
+
+		select {
+		case s.logsChan <- &config.ChannelMessage{Content: content, Timestamp: record.Timestamp.UTC()}:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func fetchQueryLog() ([]dns.QueryRecord, error) {
+	sysProbeUtil, err := net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return nil, err
+	}
+	return sysProbeUtil.GetDNSQueryLog()
+}
+
+func containerIDForPID(pid uint32) string {
+	cID, err := metrics.GetProvider().GetMetaCollector().GetContainerIDForPID(int(pid), pidToContainerCacheDuration)
+	if err != nil {
+		log.Debugf("unable to get the container of pid %d: %s", pid, err)
+		return ""
+	}
+	return cID
+}
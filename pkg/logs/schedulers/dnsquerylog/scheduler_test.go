@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package dnsquerylog
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/schedulers"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestScheduler(t *testing.T) {
+	ts := time.Date(2022, 5, 4, 14, 2, 0, 0, time.UTC)
+	batches := [][]dns.QueryRecord{
+		{
+			{Timestamp: ts, ClientIP: util.AddressFromString("10.0.0.5"), Pid: 42, Question: "abc.com", QueryType: "A", Answers: []string{"1.2.3.4"}},
+			{Timestamp: ts, ClientIP: util.AddressFromString("10.0.0.6"), Question: "def.com", QueryType: "A", Timeout: true},
+		},
+	}
+	fetch := func() ([]dns.QueryRecord, error) {
+		if len(batches) == 0 {
+			return nil, errors.New("system-probe unavailable")
+		}
+		batch := batches[0]
+		batches = batches[1:]
+		return batch, nil
+	}
+	containerID := func(pid uint32) string {
+		if pid == 42 {
+			return "cid-42"
+		}
+		return ""
+	}
+
+	s := newScheduler(fetch, containerID, time.Millisecond)
+	spy := &schedulers.MockSourceManager{}
+	s.Start(spy)
+
+	require.Len(t, spy.Events, 1)
+	require.True(t, spy.Events[0].Add)
+	logSource := spy.Events[0].Source
+	assert.Equal(t, sourceName, logSource.Name)
+	assert.Equal(t, config.StringChannelType, logSource.Config.Type)
+	assert.Equal(t, source, logSource.Config.Source)
+
+	var records []dns.QueryRecord
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-logSource.Config.Channel:
+			var record dns.QueryRecord
+			require.NoError(t, json.Unmarshal(msg.Content, &record))
+			assert.Equal(t, ts, msg.Timestamp)
+			records = append(records, record)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for dns query log records")
+		}
+	}
+	s.Stop()
+
+	assert.Equal(t, "abc.com", records[0].Question)
+	assert.Equal(t, uint32(42), records[0].Pid)
+	assert.Equal(t, "cid-42", records[0].ContainerID)
+	assert.Equal(t, []string{"1.2.3.4"}, records[0].Answers)
+	assert.Equal(t, "def.com", records[1].Question)
+	assert.Empty(t, records[1].ContainerID)
+	assert.True(t, records[1].Timeout)
+}
@@ -6,6 +6,7 @@
 package config
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
@@ -68,6 +69,14 @@ type Config struct {
 	// These stats objects get flushed on every client request (default 30s check interval)
 	MaxDNSStats int
 
+	// EnableDNSQueryLog specifies whether the DNS snooper should log every query along with its response, in addition
+	// to aggregating them into stats
+	EnableDNSQueryLog bool
+
+	// MaxDNSQueryLogRecords is the maximum number of DNS query log records buffered in memory. Records are flushed
+	// on every query log request, and the ones that don't fit in between are dropped.
+	MaxDNSQueryLogRecords int
+
 	// EnableHTTPMonitoring specifies whether the tracer should monitor HTTP traffic
 	EnableHTTPMonitoring bool
 
@@ -75,6 +84,26 @@ type Config struct {
 	// Supported libraries: OpenSSL
 	EnableHTTPSMonitoring bool
 
+	// EnableHTTP2Monitoring specifies whether the tracer should decode the HTTP/2 traffic, including gRPC calls,
+	// sent to HTTP2Ports, and report it as HTTP stats
+	EnableHTTP2Monitoring bool
+
+	// HTTP2Ports lists the ports HTTP/2 servers listen on. Only the connections to these ports are decoded.
+	HTTP2Ports []uint16
+
+	// EnableKafkaMonitoring specifies whether the tracer should monitor Kafka produce and fetch requests
+	EnableKafkaMonitoring bool
+
+	// KafkaPorts lists the ports Kafka brokers listen on. Only requests sent to these ports are monitored.
+	KafkaPorts []uint16
+
+	// EnableTLSMetadata specifies whether the tracer should record the version, cipher suite, server name and
+	// certificate expiry of the TLS sessions, by decoding their handshakes
+	EnableTLSMetadata bool
+
+	// TLSPorts lists the ports TLS servers listen on. Only the handshakes of connections to these ports are decoded.
+	TLSPorts []uint16
+
 	// UDPConnTimeout determines the length of traffic inactivity between two
 	// (IP, port)-pairs before declaring a UDP connection as inactive. This is
 	// set to /proc/sys/net/netfilter/nf_conntrack_udp_timeout on Linux by
@@ -110,6 +139,14 @@ type Config struct {
 	// get flushed on every client request (default 30s check interval)
 	MaxHTTPStatsBuffered int
 
+	// MaxKafkaStatsBuffered represents the maximum number of Kafka stats we'll buffer in memory. These stats
+	// get flushed on every client request (default 30s check interval)
+	MaxKafkaStatsBuffered int
+
+	// MaxTrackedTLSConnections represents the maximum number of connections whose TLS metadata we'll keep in memory.
+	// The metadata of a connection is kept until it gets closed.
+	MaxTrackedTLSConnections int
+
 	// MaxConnectionsStateBuffered represents the maximum number of state objects that we'll store in memory. These state objects store
 	// the stats for a connection so we can accurately determine traffic change between client requests.
 	MaxConnectionsStateBuffered int
@@ -215,10 +252,21 @@ func New() *Config {
 		MaxDNSStatsBuffered: 75000,
 		DNSTimeout:          time.Duration(cfg.GetInt(join(spNS, "dns_timeout_in_s"))) * time.Second,
 
+		EnableDNSQueryLog:     cfg.GetBool(join(netNS, "enable_dns_query_log")),
+		MaxDNSQueryLogRecords: cfg.GetInt(join(netNS, "max_dns_query_log_records")),
+
 		EnableHTTPMonitoring:  cfg.GetBool(join(netNS, "enable_http_monitoring")),
 		EnableHTTPSMonitoring: cfg.GetBool(join(netNS, "enable_https_monitoring")),
 		MaxHTTPStatsBuffered:  cfg.GetInt(join(netNS, "max_http_stats_buffered")),
 
+		EnableHTTP2Monitoring: cfg.GetBool(join(netNS, "enable_http2_monitoring")),
+
+		EnableKafkaMonitoring: cfg.GetBool(join(netNS, "enable_kafka_monitoring")),
+		MaxKafkaStatsBuffered: cfg.GetInt(join(netNS, "max_kafka_stats_buffered")),
+
+		EnableTLSMetadata:        cfg.GetBool(join(netNS, "enable_tls_metadata")),
+		MaxTrackedTLSConnections: cfg.GetInt(join(netNS, "max_tracked_tls_connections")),
+
 		EnableConntrack:              cfg.GetBool(join(spNS, "enable_conntrack")),
 		ConntrackMaxStateSize:        cfg.GetInt(join(spNS, "conntrack_max_state_size")),
 		ConntrackRateLimit:           cfg.GetInt(join(spNS, "conntrack_rate_limit")),
@@ -255,6 +303,10 @@ func New() *Config {
 		c.HTTPReplaceRules = rr
 	}
 
+	c.HTTP2Ports = getPorts(cfg, join(netNS, "http2_ports"))
+	c.KafkaPorts = getPorts(cfg, join(netNS, "kafka_ports"))
+	c.TLSPorts = getPorts(cfg, join(netNS, "tls_ports"))
+
 	if c.OffsetGuessThreshold > maxOffsetThreshold {
 		log.Warn("offset_guess_threshold exceeds maximum of 3000. Setting it to the default of 400")
 		c.OffsetGuessThreshold = defaultOffsetThreshold
@@ -302,3 +354,18 @@ func New() *Config {
 
 	return c
 }
+
+// getPorts returns the valid ports listed under the given key, logging the
+// invalid ones
+func getPorts(cfg ddconfig.Config, key string) []uint16 {
+	var ports []uint16
+	for _, p := range cfg.GetStringSlice(key) {
+		port, err := strconv.ParseUint(p, 10, 16)
+		if err != nil || port == 0 {
+			log.Errorf("invalid port %q in %q", p, key)
+			continue
+		}
+		ports = append(ports, uint16(port))
+	}
+	return ports
+}
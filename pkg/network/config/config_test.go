@@ -82,6 +82,177 @@ func TestEnableHTTPMonitoring(t *testing.T) {
 	})
 }
 
+func TestEnableHTTP2Monitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableHTTP2.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTP2Monitoring)
+		assert.Equal(t, []uint16{50051, 8080}, cfg.HTTP2Ports)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_HTTP2_PORTS", "9000")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_HTTP2_PORTS")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTP2Monitoring)
+		assert.Equal(t, []uint16{9000}, cfg.HTTP2Ports)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableHTTP2Monitoring)
+		assert.Equal(t, []uint16{50051}, cfg.HTTP2Ports)
+	})
+}
+
+func TestEnableKafkaMonitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableKafka.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableKafkaMonitoring)
+		assert.Equal(t, []uint16{9092, 29092}, cfg.KafkaPorts)
+		assert.Equal(t, 100000, cfg.MaxKafkaStatsBuffered)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_KAFKA_MONITORING", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_KAFKA_MONITORING")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_KAFKA_PORTS", "9093 invalid")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_KAFKA_PORTS")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableKafkaMonitoring)
+		assert.Equal(t, []uint16{9093}, cfg.KafkaPorts)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableKafkaMonitoring)
+		assert.Equal(t, []uint16{9092}, cfg.KafkaPorts)
+	})
+}
+
+func TestEnableTLSMetadata(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableTLSMetadata.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableTLSMetadata)
+		assert.Equal(t, []uint16{443, 8443}, cfg.TLSPorts)
+		assert.Equal(t, 5000, cfg.MaxTrackedTLSConnections)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_TLS_METADATA", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_TLS_METADATA")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_TLS_PORTS", "8443 0")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_TLS_PORTS")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableTLSMetadata)
+		assert.Equal(t, []uint16{8443}, cfg.TLSPorts)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableTLSMetadata)
+		assert.Equal(t, []uint16{443}, cfg.TLSPorts)
+		assert.Equal(t, 100000, cfg.MaxTrackedTLSConnections)
+	})
+}
+
+func TestEnableDNSQueryLog(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableDNSQueryLog.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableDNSQueryLog)
+		assert.Equal(t, 500, cfg.MaxDNSQueryLogRecords)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_DNS_QUERY_LOG", "true")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_DNS_QUERY_LOG")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_MAX_DNS_QUERY_LOG_RECORDS", "2000")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_MAX_DNS_QUERY_LOG_RECORDS")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableDNSQueryLog)
+		assert.Equal(t, 2000, cfg.MaxDNSQueryLogRecords)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig()
+		defer restoreGlobalConfig()
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableDNSQueryLog)
+		assert.Equal(t, 10000, cfg.MaxDNSQueryLogRecords)
+	})
+}
+
 func TestDisableGatewayLookup(t *testing.T) {
 	t.Run("via YAML", func(t *testing.T) {
 		newConfig()
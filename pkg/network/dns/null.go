@@ -27,6 +27,10 @@ func (nullReverseDNS) GetDNSStats() StatsByKeyByNameByType {
 	return nil
 }
 
+func (nullReverseDNS) GetQueryLog() []QueryRecord {
+	return nil
+}
+
 func (nullReverseDNS) GetStats() map[string]int64 {
 	return map[string]int64{
 		"lookups":           0,
@@ -10,6 +10,7 @@ package dns
 
 import (
 	"bytes"
+	"strings"
 	"syscall"
 	"time"
 
@@ -69,6 +70,7 @@ type dnsParser struct {
 	dnsPayload         *layers.DNS
 	collectDNSStats    bool
 	collectDNSDomains  bool
+	collectQueryLog    bool
 	recordedQueryTypes map[layers.DNSType]struct{}
 }
 
@@ -105,6 +107,7 @@ func newDNSParser(layerType gopacket.LayerType, cfg *config.Config) *dnsParser {
 		dnsPayload:         dnsPayload,
 		collectDNSStats:    cfg.CollectDNSStats,
 		collectDNSDomains:  cfg.CollectDNSDomains,
+		collectQueryLog:    cfg.EnableDNSQueryLog,
 		recordedQueryTypes: queryTypes,
 	}
 }
@@ -129,7 +132,7 @@ func (p *dnsParser) ParseInto(data []byte, t *translation, pktInfo *dnsPacketInf
 		return err
 	}
 
-	if !p.collectDNSStats {
+	if !p.collectDNSStats && !p.collectQueryLog {
 		return nil
 	}
 
@@ -199,6 +202,9 @@ func (p *dnsParser) parseAnswerInto(
 		} else {
 			pktInfo.question = ToHostname("")
 		}
+		if p.collectQueryLog {
+			pktInfo.name = strings.ToLower(string(question.Name))
+		}
 		return nil
 	}
 
@@ -211,6 +217,9 @@ func (p *dnsParser) parseAnswerInto(
 	pktInfo.queryType = QueryType(question.Type)
 	alias := p.extractCNAME(question.Name, dns.Answers)
 	p.extractIPsInto(alias, dns.Answers, t)
+	if p.collectQueryLog {
+		pktInfo.answers = p.extractAnswers(dns.Answers)
+	}
 	inplaceASCIILower(question.Name)
 	t.dns = HostnameFromBytes(question.Name)
 
@@ -245,6 +254,36 @@ func (*dnsParser) extractIPsInto(alias []byte, records []layers.DNSResourceRecor
 	}
 }
 
+// extractAnswers returns the data of the given resource records, such as IP
+// addresses and domain names, for the query log
+func (*dnsParser) extractAnswers(records []layers.DNSResourceRecord) []string {
+	answers := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Class != layers.DNSClassIN {
+			continue
+		}
+		switch record.Type {
+		case layers.DNSTypeA, layers.DNSTypeAAAA:
+			answers = append(answers, record.IP.String())
+		case layers.DNSTypeCNAME:
+			answers = append(answers, string(record.CNAME))
+		case layers.DNSTypePTR:
+			answers = append(answers, string(record.PTR))
+		case layers.DNSTypeNS:
+			answers = append(answers, string(record.NS))
+		case layers.DNSTypeMX:
+			answers = append(answers, string(record.MX.Name))
+		case layers.DNSTypeSRV:
+			answers = append(answers, string(record.SRV.Name))
+		case layers.DNSTypeTXT:
+			for _, txt := range record.TXTs {
+				answers = append(answers, string(txt))
+			}
+		}
+	}
+	return answers
+}
+
 func (p *dnsParser) isWantedQueryType(checktype layers.DNSType) bool {
 	_, ok := p.recordedQueryTypes[checktype]
 	return ok
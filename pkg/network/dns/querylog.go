@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"go.uber.org/atomic"
+)
+
+type pendingQuery struct {
+	ts    time.Time
+	name  string
+	qtype QueryType
+}
+
+// queryLogKeeper matches DNS queries with their responses, and buffers a
+// record for each of them until they get flushed. Unlike dnsStatKeeper, it
+// keeps the details of every single query rather than aggregating them.
+type queryLogKeeper struct {
+	mux        sync.Mutex
+	pending    map[stateKey]pendingQuery
+	records    []QueryRecord
+	maxRecords int
+	timeout    time.Duration
+	exit       chan struct{}
+
+	droppedQueries *atomic.Int64
+	droppedRecords *atomic.Int64
+}
+
+func newQueryLogKeeper(timeout time.Duration, maxRecords int) *queryLogKeeper {
+	k := &queryLogKeeper{
+		pending:        make(map[stateKey]pendingQuery),
+		maxRecords:     maxRecords,
+		timeout:        timeout,
+		exit:           make(chan struct{}),
+		droppedQueries: atomic.NewInt64(0),
+		droppedRecords: atomic.NewInt64(0),
+	}
+
+	ticker := time.NewTicker(timeout)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				k.removeExpiredQueries(now)
+			case <-k.exit:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return k
+}
+
+// ProcessPacketInfo records the query held by info, or builds the record of
+// the query it is the response of
+func (k *queryLogKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	sk := stateKey{key: info.key, id: info.transactionID}
+
+	if info.pktType == query {
+		if _, ok := k.pending[sk]; ok {
+			return
+		}
+		if len(k.pending) >= maxStateMapSize {
+			k.droppedQueries.Inc()
+			return
+		}
+		k.pending[sk] = pendingQuery{ts: ts, name: info.name, qtype: info.queryType}
+		return
+	}
+
+	// If a response does not have a corresponding query entry, we discard it
+	q, ok := k.pending[sk]
+	if !ok {
+		return
+	}
+	delete(k.pending, sk)
+
+	latency := ts.Sub(q.ts)
+	if latency > k.timeout {
+		k.add(newQueryRecord(sk.key, q, true))
+		return
+	}
+
+	record := newQueryRecord(sk.key, q, false)
+	record.ResponseCode = info.rCode
+	record.Answers = info.answers
+	record.LatencyMicros = uint64(latency.Microseconds())
+	k.add(record)
+}
+
+// Flush returns the records buffered since the last call
+func (k *queryLogKeeper) Flush() []QueryRecord {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	ret := k.records
+	k.records = nil
+	return ret
+}
+
+func (k *queryLogKeeper) add(record QueryRecord) {
+	if len(k.records) >= k.maxRecords {
+		k.droppedRecords.Inc()
+		return
+	}
+	k.records = append(k.records, record)
+}
+
+// removeExpiredQueries logs the queries that got no response within the
+// timeout as timed out
+func (k *queryLogKeeper) removeExpiredQueries(now time.Time) {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	threshold := now.Add(-k.timeout)
+	for sk, q := range k.pending {
+		if q.ts.Before(threshold) {
+			delete(k.pending, sk)
+			k.add(newQueryRecord(sk.key, q, true))
+		}
+	}
+}
+
+func (k *queryLogKeeper) Close() {
+	k.exit <- struct{}{}
+}
+
+func newQueryRecord(key Key, q pendingQuery, timeout bool) QueryRecord {
+	record := QueryRecord{
+		Timestamp:  q.ts,
+		ClientIP:   key.ClientIP,
+		ClientPort: key.ClientPort,
+		ServerIP:   key.ServerIP,
+		Question:   q.name,
+		QueryType:  layers.DNSType(q.qtype).String(),
+		Timeout:    timeout,
+	}
+	switch key.Protocol {
+	case syscall.IPPROTO_TCP:
+		record.Protocol = "tcp"
+	case syscall.IPPROTO_UDP:
+		record.Protocol = "udp"
+	}
+	return record
+}
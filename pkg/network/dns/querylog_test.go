@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"encoding/json"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestQueryLogResponse(t *testing.T) {
+	k := newQueryLogKeeper(DNSTimeoutSecs*time.Second, 100)
+	defer k.Close()
+	key := getSampleDNSKey()
+
+	then := time.Now()
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: query, key: key, name: "abc.com", queryType: TypeA}, then)
+	assert.Empty(t, k.Flush())
+
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: successfulResponse, key: key, queryType: TypeA, answers: []string{"10.0.0.1"}}, then.Add(20*time.Millisecond))
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: query, key: key, name: "missing.com", queryType: TypeAAAA}, then)
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: failedResponse, key: key, rCode: 3}, then.Add(5*time.Millisecond))
+
+	records := k.Flush()
+	require.Len(t, records, 2)
+	assert.Equal(t, QueryRecord{
+		Timestamp:     then,
+		ClientIP:      key.ClientIP,
+		ClientPort:    key.ClientPort,
+		ServerIP:      key.ServerIP,
+		Protocol:      "udp",
+		Question:      "abc.com",
+		QueryType:     "A",
+		Answers:       []string{"10.0.0.1"},
+		LatencyMicros: 20000,
+	}, records[0])
+	assert.Equal(t, "missing.com", records[1].Question)
+	assert.Equal(t, "AAAA", records[1].QueryType)
+	assert.Equal(t, uint8(3), records[1].ResponseCode)
+	assert.Empty(t, records[1].Answers)
+	assert.Equal(t, uint64(5000), records[1].LatencyMicros)
+
+	assert.Empty(t, k.Flush())
+}
+
+func TestQueryLogTimeout(t *testing.T) {
+	k := newQueryLogKeeper(DNSTimeoutSecs*time.Second, 100)
+	defer k.Close()
+	key := getSampleDNSKey()
+
+	then := time.Now()
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: query, key: key, name: "late.com", queryType: TypeA}, then)
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: successfulResponse, key: key}, then.Add(DNSTimeoutSecs*time.Second+time.Millisecond))
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: query, key: key, name: "lost.com", queryType: TypeA}, then)
+	k.removeExpiredQueries(then.Add(DNSTimeoutSecs*time.Second + time.Millisecond))
+
+	// a response without a query is discarded
+	k.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: successfulResponse, key: key}, then)
+
+	records := k.Flush()
+	require.Len(t, records, 2)
+	for i, name := range []string{"late.com", "lost.com"} {
+		assert.Equal(t, name, records[i].Question)
+		assert.True(t, records[i].Timeout)
+		assert.Zero(t, records[i].LatencyMicros)
+	}
+}
+
+func TestQueryLogMaxRecords(t *testing.T) {
+	k := newQueryLogKeeper(DNSTimeoutSecs*time.Second, 2)
+	defer k.Close()
+	key := getSampleDNSKey()
+
+	now := time.Now()
+	for id := uint16(0); id < 3; id++ {
+		k.ProcessPacketInfo(dnsPacketInfo{transactionID: id, pktType: query, key: key, name: "abc.com", queryType: TypeA}, now)
+		k.ProcessPacketInfo(dnsPacketInfo{transactionID: id, pktType: successfulResponse, key: key}, now)
+	}
+
+	assert.Len(t, k.Flush(), 2)
+	assert.Equal(t, int64(1), k.droppedRecords.Load())
+}
+
+func TestQueryRecordJSON(t *testing.T) {
+	record := QueryRecord{
+		Timestamp:    time.Date(2022, 5, 4, 14, 2, 0, 0, time.UTC),
+		ClientIP:     util.AddressFromString("10.0.0.5"),
+		ClientPort:   41000,
+		ServerIP:     util.AddressFromString("10.0.0.10"),
+		Protocol:     "tcp",
+		Pid:          42,
+		Question:     "abc.com",
+		QueryType:    "A",
+		ResponseCode: 0,
+		Answers:      []string{"1.2.3.4"},
+	}
+
+	b, err := json.Marshal(&record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"timestamp": "2022-05-04T14:02:00Z",
+		"client_ip": "10.0.0.5",
+		"client_port": 41000,
+		"server_ip": "10.0.0.10",
+		"protocol": "tcp",
+		"pid": 42,
+		"question": "abc.com",
+		"query_type": "A",
+		"rcode": 0,
+		"answers": ["1.2.3.4"]
+	}`, string(b))
+
+	var decoded QueryRecord
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, record, decoded)
+	assert.Equal(t, Key{
+		ServerIP:   record.ServerIP,
+		ClientIP:   record.ClientIP,
+		ClientPort: 41000,
+		Protocol:   syscall.IPPROTO_TCP,
+	}, decoded.Key())
+}
+
+func TestParseQueryLogFields(t *testing.T) {
+	cfg := &config.Config{CollectDNSStats: false, EnableDNSQueryLog: true}
+	parser := newDNSParser(layers.LayerTypeEthernet, cfg)
+
+	client, server := net.ParseIP("10.0.0.5").To4(), net.ParseIP("10.0.0.10").To4()
+	question := layers.DNSQuestion{Name: []byte("WWW.Example.com"), Type: layers.DNSTypeA, Class: layers.DNSClassIN}
+
+	t.Run("query", func(t *testing.T) {
+		data := serializeDNSPacket(t, client, server, 41000, 53, &layers.DNS{
+			ID:        7,
+			Questions: []layers.DNSQuestion{question},
+		})
+
+		var info dnsPacketInfo
+		require.NoError(t, parser.ParseInto(data, new(translation), &info))
+		assert.Equal(t, query, info.pktType)
+		assert.Equal(t, "www.example.com", info.name)
+		assert.Equal(t, uint16(7), info.transactionID)
+		assert.Equal(t, Key{
+			ServerIP:   util.AddressFromNetIP(server),
+			ClientIP:   util.AddressFromNetIP(client),
+			ClientPort: 41000,
+			Protocol:   syscall.IPPROTO_UDP,
+		}, info.key)
+	})
+
+	t.Run("response", func(t *testing.T) {
+		data := serializeDNSPacket(t, server, client, 53, 41000, &layers.DNS{
+			ID:        7,
+			QR:        true,
+			Questions: []layers.DNSQuestion{question},
+			Answers: []layers.DNSResourceRecord{
+				{Name: []byte("WWW.Example.com"), Type: layers.DNSTypeCNAME, Class: layers.DNSClassIN, TTL: 60, CNAME: []byte("cdn.example.net")},
+				{Name: []byte("cdn.example.net"), Type: layers.DNSTypeA, Class: layers.DNSClassIN, TTL: 60, IP: net.ParseIP("1.2.3.4").To4()},
+			},
+		})
+
+		var info dnsPacketInfo
+		require.NoError(t, parser.ParseInto(data, &translation{ips: make(map[util.Address]time.Time)}, &info))
+		assert.Equal(t, successfulResponse, info.pktType)
+		assert.Equal(t, []string{"cdn.example.net", "1.2.3.4"}, info.answers)
+		assert.Equal(t, uint16(41000), info.key.ClientPort)
+	})
+}
+
+func serializeDNSPacket(t *testing.T, src, dst net.IP, sport, dport uint16, dns *layers.DNS) []byte {
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 1},
+		DstMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 2},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP, SrcIP: src, DstIP: dst}
+	udp := &layers.UDP{SrcPort: layers.UDPPort(sport), DstPort: layers.UDPPort(dport)}
+	require.NoError(t, udp.SetNetworkLayerForChecksum(ip))
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	require.NoError(t, gopacket.SerializeLayers(buf, opts, eth, ip, udp, dns))
+	return buf.Bytes()
+}
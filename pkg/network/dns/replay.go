@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/filter"
+)
+
+// Replay runs all the packets of the given capture through a DNS snooper and
+// returns the resulting DNS stats along with the snooper telemetry. The source is
+// closed upon return.
+//
+// Latencies are computed from the timestamps recorded in the capture, which makes
+// the result deterministic.
+func Replay(cfg *config.Config, source *filter.PcapSource) (StatsByKeyByNameByType, map[string]int64, error) {
+	snooper, err := newSocketFilterSnooper(cfg, source)
+	if err != nil {
+		source.Close()
+		return nil, nil, err
+	}
+	defer snooper.Close()
+
+	<-source.Done()
+	if err := source.Err(); err != nil {
+		return nil, nil, err
+	}
+	return snooper.GetDNSStats(), snooper.GetStats(), nil
+}
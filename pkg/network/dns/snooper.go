@@ -42,6 +42,7 @@ type socketFilterSnooper struct {
 	parser          *dnsParser
 	cache           *reverseDNSCache
 	statKeeper      *dnsStatKeeper
+	queryLog        *queryLogKeeper
 	exit            chan struct{}
 	wg              sync.WaitGroup
 	collectLocalDNS bool
@@ -82,6 +83,11 @@ func newSocketFilterSnooper(cfg *config.Config, source packetSource) (*socketFil
 	} else {
 		log.Infof("DNS Stats Collection has been disabled.")
 	}
+	var queryLog *queryLogKeeper
+	if cfg.EnableDNSQueryLog {
+		queryLog = newQueryLogKeeper(cfg.DNSTimeout, cfg.MaxDNSQueryLogRecords)
+		log.Infof("DNS query log has been enabled. Maximum number of buffered records: %d", cfg.MaxDNSQueryLogRecords)
+	}
 	snooper := &socketFilterSnooper{
 		decodingErrors: atomic.NewInt64(0),
 		truncatedPkts:  atomic.NewInt64(0),
@@ -93,6 +99,7 @@ func newSocketFilterSnooper(cfg *config.Config, source packetSource) (*socketFil
 		parser:          newDNSParser(source.PacketType(), cfg),
 		cache:           cache,
 		statKeeper:      statKeeper,
+		queryLog:        queryLog,
 		translation:     new(translation),
 		exit:            make(chan struct{}),
 		collectLocalDNS: cfg.CollectLocalDNS,
@@ -127,6 +134,14 @@ func (s *socketFilterSnooper) GetDNSStats() StatsByKeyByNameByType {
 	return s.statKeeper.GetAndResetAllStats()
 }
 
+// GetQueryLog returns the records of the queries logged since the last call
+func (s *socketFilterSnooper) GetQueryLog() []QueryRecord {
+	if s.queryLog == nil {
+		return nil
+	}
+	return s.queryLog.Flush()
+}
+
 // GetStats returns stats for use with telemetry
 func (s *socketFilterSnooper) GetStats() map[string]int64 {
 	stats := s.cache.Stats()
@@ -146,6 +161,10 @@ func (s *socketFilterSnooper) GetStats() map[string]int64 {
 		stats["num_stats"] = int64(numStats)
 		stats["dropped_stats"] = int64(droppedStats)
 	}
+	if s.queryLog != nil {
+		stats["query_log_dropped_queries"] = s.queryLog.droppedQueries.Load()
+		stats["query_log_dropped_records"] = s.queryLog.droppedRecords.Load()
+	}
 	return stats
 }
 
@@ -163,6 +182,9 @@ func (s *socketFilterSnooper) Close() {
 	if s.statKeeper != nil {
 		s.statKeeper.Close()
 	}
+	if s.queryLog != nil {
+		s.queryLog.Close()
+	}
 }
 
 // processPacket retrieves DNS information from the received packet data and adds it to
@@ -186,8 +208,13 @@ func (s *socketFilterSnooper) processPacket(data []byte, ts time.Time) error {
 		return nil
 	}
 
-	if s.statKeeper != nil && (s.collectLocalDNS || !pktInfo.key.ServerIP.IsLoopback()) {
-		s.statKeeper.ProcessPacketInfo(pktInfo, ts)
+	if s.collectLocalDNS || !pktInfo.key.ServerIP.IsLoopback() {
+		if s.statKeeper != nil {
+			s.statKeeper.ProcessPacketInfo(pktInfo, ts)
+		}
+		if s.queryLog != nil {
+			s.queryLog.ProcessPacketInfo(pktInfo, ts)
+		}
 	}
 
 	if pktInfo.pktType == successfulResponse {
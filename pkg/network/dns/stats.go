@@ -42,6 +42,10 @@ type dnsPacketInfo struct {
 	rCode         uint8    // responseCode
 	question      Hostname // only relevant for query packets
 	queryType     QueryType
+
+	// The fields below are only set when the query log is enabled
+	name    string   // only relevant for query packets
+	answers []string // only relevant for successful responses
 }
 
 type stateKey struct {
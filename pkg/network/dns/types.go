@@ -6,6 +6,9 @@
 package dns
 
 import (
+	"syscall"
+	"time"
+
 	"github.com/google/gopacket/layers"
 
 	"github.com/DataDog/datadog-agent/pkg/process/util"
@@ -67,6 +70,7 @@ type StatsByKeyByNameByType map[Key]map[Hostname]map[QueryType]Stats
 type ReverseDNS interface {
 	Resolve([]util.Address) map[util.Address][]Hostname
 	GetDNSStats() StatsByKeyByNameByType
+	GetQueryLog() []QueryRecord
 	GetStats() map[string]int64
 	Start() error
 	Close()
@@ -88,3 +92,44 @@ type Stats struct {
 	FailureLatencySum uint64
 	CountByRcode      map[uint32]uint32
 }
+
+// QueryRecord is a single DNS query along with the response it got, as logged
+// when the DNS query log is enabled
+type QueryRecord struct {
+	// Timestamp is the time at which the query was sent
+	Timestamp  time.Time    `json:"timestamp"`
+	ClientIP   util.Address `json:"client_ip"`
+	ClientPort uint16       `json:"client_port"`
+	ServerIP   util.Address `json:"server_ip"`
+	// Protocol is either "udp" or "tcp"
+	Protocol string `json:"protocol"`
+	// Pid is the process that sent the query, when known
+	Pid uint32 `json:"pid,omitempty"`
+	// ContainerID is the container the process that sent the query runs in,
+	// when known
+	ContainerID string `json:"container_id,omitempty"`
+
+	Question  string `json:"question"`
+	QueryType string `json:"query_type"`
+	// ResponseCode is only relevant if the query didn't time out
+	ResponseCode uint8 `json:"rcode"`
+	// Answers holds the data of the resource records of the answer section,
+	// such as IP addresses and CNAME targets
+	Answers []string `json:"answers,omitempty"`
+	// LatencyMicros is the time the response took to come back
+	LatencyMicros uint64 `json:"latency_us,omitempty"`
+	// Timeout is true if no response came back within the DNS timeout
+	Timeout bool `json:"timeout,omitempty"`
+}
+
+// Key returns the Key of the client and server that exchanged the query
+func (r *QueryRecord) Key() Key {
+	k := Key{ServerIP: r.ServerIP, ClientIP: r.ClientIP, ClientPort: r.ClientPort}
+	switch r.Protocol {
+	case "tcp":
+		k.Protocol = syscall.IPPROTO_TCP
+	case "udp":
+		k.Protocol = syscall.IPPROTO_UDP
+	}
+	return k
+}
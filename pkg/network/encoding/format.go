@@ -47,6 +47,7 @@ func FormatConnection(
 	conn network.ConnectionStats,
 	routes map[string]RouteIdx,
 	httpEncoder *httpEncoder,
+	kafkaEncoder *kafkaEncoder,
 	dnsFormatter *dnsFormatter,
 	ipc ipCache,
 	tagsSet *network.TagsSet,
@@ -83,7 +84,7 @@ func FormatConnection(
 	}
 
 	conn.Tags |= tags
-	c.Tags = formatTags(tagsSet, conn)
+	c.Tags = formatTags(tagsSet, conn, kafkaEncoder.GetKafkaTags(conn), formatTLSTags(conn.TLS))
 
 	return c
 }
@@ -233,9 +234,14 @@ func routeKey(v *network.Via) string {
 	return v.Subnet.Alias
 }
 
-func formatTags(tagsSet *network.TagsSet, c network.ConnectionStats) (tagsIdx []uint32) {
+func formatTags(tagsSet *network.TagsSet, c network.ConnectionStats, dynamicTags ...[]string) (tagsIdx []uint32) {
 	for _, tag := range network.GetStaticTags(c.Tags) {
 		tagsIdx = append(tagsIdx, tagsSet.Add(tag))
 	}
+	for _, tags := range dynamicTags {
+		for _, tag := range tags {
+			tagsIdx = append(tagsIdx, tagsSet.Add(tag))
+		}
+	}
 	return tagsIdx
 }
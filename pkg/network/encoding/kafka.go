@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package encoding
+
+import (
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+const (
+	kafkaTopicTagPrefix      = "kafka_topic:"
+	kafkaAPITagPrefix        = "kafka_api:"
+	kafkaAPIVersionTagPrefix = "kafka_api_version:"
+)
+
+// kafkaEncoder attaches the Kafka requests seen on a connection to that
+// connection as tags, listing the topics, the request types (produce or fetch)
+// and their versions. The connections payload doesn't have a dedicated Kafka
+// message, so the request counts are exposed separately, see
+// FormatKafkaAggregations.
+type kafkaEncoder struct {
+	tags map[http.KeyTuple][]string
+
+	orphanEntries int
+}
+
+func newKafkaEncoder(payload *network.Connections) *kafkaEncoder {
+	if len(payload.Kafka) == 0 {
+		return nil
+	}
+
+	encoder := &kafkaEncoder{
+		tags: make(map[http.KeyTuple][]string, len(payload.Conns)),
+	}
+
+	// pre-populate tags map with keys for all existent connections
+	// this allows us to skip orphan Kafka objects that can't be matched to a connection
+	for _, conn := range payload.Conns {
+		encoder.tags[network.HTTPKeyTupleFromConn(conn)] = nil
+	}
+
+	encoder.buildTags(payload)
+	return encoder
+}
+
+// GetKafkaTags returns the tags of the Kafka requests seen on the given connection
+func (e *kafkaEncoder) GetKafkaTags(c network.ConnectionStats) []string {
+	if e == nil {
+		return nil
+	}
+
+	return e.tags[network.HTTPKeyTupleFromConn(c)]
+}
+
+func (e *kafkaEncoder) buildTags(payload *network.Connections) {
+	tagsByTuple := make(map[http.KeyTuple]map[string]struct{})
+	for key := range payload.Kafka {
+		if _, ok := e.tags[key.KeyTuple]; !ok {
+			// if there is no matching connection don't even bother to tag it
+			e.orphanEntries++
+			continue
+		}
+
+		tags, ok := tagsByTuple[key.KeyTuple]
+		if !ok {
+			tags = make(map[string]struct{})
+			tagsByTuple[key.KeyTuple] = tags
+		}
+		tags[kafkaTopicTagPrefix+key.TopicName] = struct{}{}
+		tags[kafkaAPITagPrefix+key.RequestAPIKey.String()] = struct{}{}
+		tags[kafkaAPIVersionTagPrefix+kafkaAPIVersion(key)] = struct{}{}
+	}
+
+	for tuple, tags := range tagsByTuple {
+		sorted := make([]string, 0, len(tags))
+		for tag := range tags {
+			sorted = append(sorted, tag)
+		}
+		sort.Strings(sorted)
+		e.tags[tuple] = sorted
+	}
+}
+
+// kafkaAPIVersion returns the request type and version of the given key, e.g. "produce_v8"
+func kafkaAPIVersion(key kafka.Key) string {
+	return key.RequestAPIKey.String() + "_v" + strconv.Itoa(int(key.RequestVersion))
+}
+
+// FormatKafkaAggregations returns the given Kafka stats, aggregated by connection,
+// topic, request type and version, in the form under which they are exposed by
+// system-probe. They are sorted to get a stable output.
+func FormatKafkaAggregations(stats map[kafka.Key]*kafka.RequestStat) []kafka.Aggregation {
+	aggregations := make([]kafka.Aggregation, 0, len(stats))
+	for key, stat := range stats {
+		aggregations = append(aggregations, kafka.Aggregation{
+			Client:     formatEndpoint(key.SrcIPLow, key.SrcIPHigh, key.SrcPort),
+			Server:     formatEndpoint(key.DstIPLow, key.DstIPHigh, key.DstPort),
+			Topic:      key.TopicName,
+			API:        key.RequestAPIKey.String(),
+			APIVersion: key.RequestVersion,
+			Count:      stat.Count,
+		})
+	}
+
+	sort.Slice(aggregations, func(i, j int) bool {
+		a, b := aggregations[i], aggregations[j]
+		if a.Client != b.Client {
+			return a.Client < b.Client
+		}
+		if a.Server != b.Server {
+			return a.Server < b.Server
+		}
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		if a.API != b.API {
+			return a.API < b.API
+		}
+		return a.APIVersion < b.APIVersion
+	})
+	return aggregations
+}
+
+func formatEndpoint(low, high uint64, port uint16) string {
+	return net.JoinHostPort(util.FromLowHigh(low, high).String(), strconv.Itoa(int(port)))
+}
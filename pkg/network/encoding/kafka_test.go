@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestFormatKafkaTags(t *testing.T) {
+	var (
+		clientPort = uint16(52800)
+		serverPort = uint16(9092)
+		client     = util.AddressFromString("10.0.0.1")
+		broker     = util.AddressFromString("10.0.0.2")
+	)
+
+	newKey := func(sport uint16, topic string, apiKey kafka.APIKey) kafka.Key {
+		return kafka.NewKey(client, broker, sport, serverPort, topic, apiKey, 8)
+	}
+
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source: client,
+					Dest:   broker,
+					SPort:  clientPort,
+					DPort:  serverPort,
+				},
+				{
+					Source: client,
+					Dest:   broker,
+					SPort:  clientPort + 1,
+					DPort:  serverPort,
+				},
+			},
+		},
+		Kafka: map[kafka.Key]*kafka.RequestStat{
+			newKey(clientPort, "orders", kafka.ProduceAPIKey):   {Count: 2},
+			newKey(clientPort, "payments", kafka.ProduceAPIKey): {Count: 1},
+			newKey(clientPort, "orders", kafka.FetchAPIKey):     {Count: 1},
+			// orphan entry
+			newKey(clientPort+2, "orders", kafka.FetchAPIKey): {Count: 1},
+		},
+	}
+
+	encoder := newKafkaEncoder(in)
+	assert.Equal(t, 1, encoder.orphanEntries)
+
+	marshaler := GetMarshaler("application/protobuf")
+	blob, err := marshaler.Marshal(in)
+	require.NoError(t, err)
+	result, err := GetUnmarshaler("application/protobuf").Unmarshal(blob)
+	require.NoError(t, err)
+
+	require.Len(t, result.Conns, 2)
+	var tags []string
+	for _, idx := range result.Conns[0].Tags {
+		tags = append(tags, result.Tags[idx])
+	}
+	assert.Equal(t, []string{
+		"kafka_api:fetch",
+		"kafka_api:produce",
+		"kafka_api_version:fetch_v8",
+		"kafka_api_version:produce_v8",
+		"kafka_topic:orders",
+		"kafka_topic:payments",
+	}, tags)
+	assert.Empty(t, result.Conns[1].Tags)
+
+	assert.Nil(t, newKafkaEncoder(&network.Connections{}))
+}
+
+func TestFormatKafkaAggregations(t *testing.T) {
+	client := util.AddressFromString("10.0.0.1")
+	broker := util.AddressFromString("10.0.0.2")
+	stats := map[kafka.Key]*kafka.RequestStat{
+		kafka.NewKey(client, broker, 52800, 9092, "payments", kafka.ProduceAPIKey, 8): {Count: 1},
+		kafka.NewKey(client, broker, 52800, 9092, "orders", kafka.ProduceAPIKey, 8):   {Count: 2},
+		kafka.NewKey(client, broker, 52800, 9092, "orders", kafka.FetchAPIKey, 12):    {Count: 3},
+	}
+
+	assert.Equal(t, []kafka.Aggregation{
+		{Client: "10.0.0.1:52800", Server: "10.0.0.2:9092", Topic: "orders", API: "fetch", APIVersion: 12, Count: 3},
+		{Client: "10.0.0.1:52800", Server: "10.0.0.2:9092", Topic: "orders", API: "produce", APIVersion: 8, Count: 2},
+		{Client: "10.0.0.1:52800", Server: "10.0.0.2:9092", Topic: "payments", API: "produce", APIVersion: 8, Count: 1},
+	}, FormatKafkaAggregations(stats))
+	assert.Empty(t, FormatKafkaAggregations(nil))
+}
@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package encoding
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/tls"
+)
+
+const (
+	tlsVersionTagPrefix      = "tls_version:"
+	tlsCipherSuiteTagPrefix  = "tls_cipher_suite:"
+	tlsServerNameTagPrefix   = "tls_server_name:"
+	tlsCertNotAfterTagPrefix = "tls_cert_not_after:"
+)
+
+// formatTLSTags returns the tags describing the TLS session of a connection.
+// The connections payload doesn't have a dedicated TLS message, so the metadata
+// is forwarded as tags.
+func formatTLSTags(info *tls.Info) []string {
+	if info == nil {
+		return nil
+	}
+
+	tags := make([]string, 0, 4)
+	if v := info.VersionName(); v != "" {
+		tags = append(tags, tlsVersionTagPrefix+v)
+	}
+	if cs := info.CipherSuiteName(); cs != "" {
+		tags = append(tags, tlsCipherSuiteTagPrefix+cs)
+	}
+	if info.ServerName != "" {
+		tags = append(tags, tlsServerNameTagPrefix+info.ServerName)
+	}
+	if !info.CertNotAfter.IsZero() {
+		tags = append(tags, tlsCertNotAfterTagPrefix+info.CertNotAfter.UTC().Format(time.RFC3339))
+	}
+	return tags
+}
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package encoding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/tls"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestFormatTLSTags(t *testing.T) {
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source: util.AddressFromString("10.0.0.1"),
+					Dest:   util.AddressFromString("10.0.0.2"),
+					SPort:  52800,
+					DPort:  443,
+					TLS: &tls.Info{
+						Version:      tls.VersionTLS12,
+						CipherSuite:  0xc02b,
+						ServerName:   "api.example.com",
+						CertNotAfter: time.Date(2031, 6, 30, 12, 0, 0, 0, time.UTC),
+					},
+				},
+				{
+					Source: util.AddressFromString("10.0.0.1"),
+					Dest:   util.AddressFromString("10.0.0.3"),
+					SPort:  52801,
+					DPort:  443,
+					// only the ClientHello was seen so far
+					TLS: &tls.Info{ServerName: "www.example.com"},
+				},
+				{
+					Source: util.AddressFromString("10.0.0.1"),
+					Dest:   util.AddressFromString("10.0.0.4"),
+					SPort:  52802,
+					DPort:  80,
+				},
+			},
+		},
+	}
+
+	marshaler := GetMarshaler("application/protobuf")
+	blob, err := marshaler.Marshal(in)
+	require.NoError(t, err)
+	result, err := GetUnmarshaler("application/protobuf").Unmarshal(blob)
+	require.NoError(t, err)
+	require.Len(t, result.Conns, 3)
+
+	tags := func(i int) []string {
+		var tags []string
+		for _, idx := range result.Conns[i].Tags {
+			tags = append(tags, result.Tags[idx])
+		}
+		return tags
+	}
+	assert.Equal(t, []string{
+		"tls_version:tls_1.2",
+		"tls_cipher_suite:TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		"tls_server_name:api.example.com",
+		"tls_cert_not_after:2031-06-30T12:00:00Z",
+	}, tags(0))
+	assert.Equal(t, []string{"tls_server_name:www.example.com"}, tags(1))
+	assert.Empty(t, tags(2))
+}
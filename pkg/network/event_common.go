@@ -15,6 +15,8 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+	"github.com/DataDog/datadog-agent/pkg/network/tls"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
@@ -122,6 +124,7 @@ type Connections struct {
 	ConnTelemetry               map[ConnTelemetryType]int64
 	CompilationTelemetryByAsset map[string]RuntimeCompilationTelemetry
 	HTTP                        map[http.Key]*http.RequestStats
+	Kafka                       map[kafka.Key]*kafka.RequestStat
 	DNSStats                    dns.StatsByKeyByNameByType
 }
 
@@ -248,6 +251,8 @@ type ConnectionStats struct {
 
 	IPTranslation *IPTranslation
 	Via           *Via
+	// TLS holds the metadata of the TLS session of the connection, if any
+	TLS *tls.Info
 
 	// Monotonic stores a list of StatCounters
 	// each identified by a unique "cookie"
@@ -441,6 +446,10 @@ func ConnectionSummary(c *ConnectionStats, names map[util.Address][]dns.Hostname
 		)
 	}
 
+	if c.TLS != nil {
+		str += ", " + c.TLS.String()
+	}
+
 	str += fmt.Sprintf(", last update epoch: %d, cookies: %+v", c.LastUpdateEpoch, cookies)
 
 	return str
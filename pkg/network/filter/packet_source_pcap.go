@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package filter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"go.uber.org/atomic"
+)
+
+// pcapngMagic is the block type of the section header block starting every pcapng file
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+type pcapReader interface {
+	ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+// PcapSource reads the packets of a pcap or pcapng capture file. It can be used in
+// place of an AFPacketSource to replay recorded traffic deterministically, without
+// requiring any privilege.
+type PcapSource struct {
+	file       *os.File
+	reader     pcapReader
+	packetType gopacket.LayerType
+
+	mux  sync.Mutex
+	done chan struct{}
+	err  error
+
+	// telemetry
+	packets *atomic.Int64
+	bytes   *atomic.Int64
+}
+
+// NewPcapSource opens the given pcap or pcapng capture file. Only the Ethernet and
+// raw IP link types are supported.
+func NewPcapSource(path string) (*PcapSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newPcapReader(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error reading capture file %s: %w", path, err)
+	}
+
+	var packetType gopacket.LayerType
+	switch r.LinkType() {
+	case layers.LinkTypeEthernet:
+		packetType = layers.LayerTypeEthernet
+	case layers.LinkTypeRaw, layers.LinkTypeIPv4:
+		// raw captures are assumed to only hold IPv4 packets
+		packetType = layers.LayerTypeIPv4
+	case layers.LinkTypeIPv6:
+		packetType = layers.LayerTypeIPv6
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported link type %s in capture file %s", r.LinkType(), path)
+	}
+
+	return &PcapSource{
+		file:       f,
+		reader:     r,
+		packetType: packetType,
+		done:       make(chan struct{}),
+		packets:    atomic.NewInt64(0),
+		bytes:      atomic.NewInt64(0),
+	}, nil
+}
+
+func newPcapReader(r *bufio.Reader) (pcapReader, error) {
+	magic, err := r.Peek(len(pcapngMagic))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(magic, pcapngMagic) {
+		return pcapgo.NewNgReader(r, pcapgo.DefaultNgReaderOptions)
+	}
+	return pcapgo.NewReader(r)
+}
+
+// VisitPackets invokes the given callback for each packet of the capture file, with
+// the timestamp recorded in the capture. Once the whole file has been read, the
+// channel returned by Done is closed and VisitPackets returns immediately.
+func (p *PcapSource) VisitPackets(exit <-chan struct{}, visit func([]byte, time.Time) error) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	for {
+		select {
+		case <-exit:
+			return nil
+		case <-p.done:
+			return nil
+		default:
+		}
+
+		data, ci, err := p.reader.ZeroCopyReadPacketData()
+		if err != nil {
+			if err != io.EOF {
+				p.err = err
+			}
+			close(p.done)
+			return p.err
+		}
+
+		p.packets.Inc()
+		p.bytes.Add(int64(len(data)))
+		if err := visit(data, ci.Timestamp); err != nil {
+			return err
+		}
+	}
+}
+
+// Done returns a channel that's closed once all the packets of the capture file have been visited.
+func (p *PcapSource) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the error which interrupted the reading of the capture file, if any.
+// It must only be called once the channel returned by Done is closed.
+func (p *PcapSource) Err() error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.err
+}
+
+// Stats returns a map of counters, meant to be reported as telemetry
+func (p *PcapSource) Stats() map[string]int64 {
+	return map[string]int64{
+		"packets_read": p.packets.Load(),
+		"bytes_read":   p.bytes.Load(),
+	}
+}
+
+// PacketType returns the type of the first layer of the packets in the capture file
+func (p *PcapSource) PacketType() gopacket.LayerType {
+	return p.packetType
+}
+
+// Close closes the capture file
+func (p *PcapSource) Close() {
+	p.file.Close()
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPackets = [][]byte{
+	{0x01, 0x02, 0x03},
+	{0x04, 0x05, 0x06, 0x07},
+	{0x08},
+}
+
+func writeCapture(t *testing.T, ng bool, linkType layers.LinkType, packets [][]byte, start time.Time) string {
+	path := filepath.Join(t.TempDir(), "capture")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var write func(gopacket.CaptureInfo, []byte) error
+	if ng {
+		w, err := pcapgo.NewNgWriter(f, linkType)
+		require.NoError(t, err)
+		defer w.Flush()
+		write = w.WritePacket
+	} else {
+		w := pcapgo.NewWriter(f)
+		require.NoError(t, w.WriteFileHeader(65536, linkType))
+		write = w.WritePacket
+	}
+
+	for i, p := range packets {
+		ci := gopacket.CaptureInfo{
+			Timestamp:     start.Add(time.Duration(i) * time.Millisecond),
+			CaptureLength: len(p),
+			Length:        len(p),
+		}
+		require.NoError(t, write(ci, p))
+	}
+	return path
+}
+
+func TestPcapSource(t *testing.T) {
+	start := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, ng := range map[string]bool{"pcap": false, "pcapng": true} {
+		t.Run(name, func(t *testing.T) {
+			src, err := NewPcapSource(writeCapture(t, ng, layers.LinkTypeEthernet, testPackets, start))
+			require.NoError(t, err)
+			defer src.Close()
+			assert.Equal(t, layers.LayerTypeEthernet, src.PacketType())
+
+			var (
+				packets    [][]byte
+				timestamps []time.Time
+			)
+			err = src.VisitPackets(nil, func(data []byte, ts time.Time) error {
+				packets = append(packets, append([]byte(nil), data...))
+				timestamps = append(timestamps, ts)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testPackets, packets)
+			for i, ts := range timestamps {
+				assert.True(t, start.Add(time.Duration(i)*time.Millisecond).Equal(ts))
+			}
+
+			select {
+			case <-src.Done():
+			default:
+				t.Fatal("source should be done")
+			}
+			assert.NoError(t, src.Err())
+			assert.Equal(t, map[string]int64{"packets_read": 3, "bytes_read": 8}, src.Stats())
+
+			// the capture is only read once
+			err = src.VisitPackets(nil, func([]byte, time.Time) error {
+				t.Fatal("no packet should be visited")
+				return nil
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPcapSourceCancel(t *testing.T) {
+	src, err := NewPcapSource(writeCapture(t, false, layers.LinkTypeRaw, testPackets, time.Now()))
+	require.NoError(t, err)
+	defer src.Close()
+	assert.Equal(t, layers.LayerTypeIPv4, src.PacketType())
+
+	exit := make(chan struct{})
+	visited := 0
+	err = src.VisitPackets(exit, func([]byte, time.Time) error {
+		visited++
+		close(exit)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, visited)
+
+	// reading resumes where it stopped
+	err = src.VisitPackets(nil, func([]byte, time.Time) error {
+		visited++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, visited)
+}
+
+func TestPcapSourceErrors(t *testing.T) {
+	t.Run("unsupported link type", func(t *testing.T) {
+		_, err := NewPcapSource(writeCapture(t, false, layers.LinkTypeLinuxSLL, testPackets, time.Now()))
+		assert.Error(t, err)
+	})
+
+	t.Run("not a capture", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "capture")
+		require.NoError(t, os.WriteFile(path, []byte("not a capture file"), 0644))
+		_, err := NewPcapSource(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		path := writeCapture(t, false, layers.LinkTypeEthernet, testPackets, time.Now())
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(path, info.Size()-2))
+
+		src, err := NewPcapSource(path)
+		require.NoError(t, err)
+		defer src.Close()
+
+		visited := 0
+		err = src.VisitPackets(nil, func([]byte, time.Time) error {
+			visited++
+			return nil
+		})
+		assert.Error(t, err)
+		assert.Equal(t, err, src.Err())
+		assert.Equal(t, 2, visited)
+	})
+}
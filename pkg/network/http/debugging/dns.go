@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package debugging
+
+import (
+	"github.com/google/gopacket/layers"
+
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+)
+
+// DNSSummary represents a (debug-friendly) aggregated view of the DNS queries
+// matching a (client, server, protocol, domain, query type) tuple
+type DNSSummary struct {
+	Client            Address
+	Server            string
+	Protocol          string
+	Domain            string
+	QueryType         string
+	Timeouts          uint32
+	SuccessLatencySum uint64
+	FailureLatencySum uint64
+	CountByRcode      map[uint32]uint32
+}
+
+// DNS returns a debug-friendly representation of dns.StatsByKeyByNameByType
+func DNS(stats dns.StatsByKeyByNameByType) []DNSSummary {
+	all := make([]DNSSummary, 0, len(stats))
+	for k, byDomain := range stats {
+		for domain, byType := range byDomain {
+			for qtype, s := range byType {
+				all = append(all, DNSSummary{
+					Client: Address{
+						IP:   k.ClientIP.String(),
+						Port: k.ClientPort,
+					},
+					Server:            k.ServerIP.String(),
+					Protocol:          layers.IPProtocol(k.Protocol).String(),
+					Domain:            dns.ToString(domain),
+					QueryType:         layers.DNSType(qtype).String(),
+					Timeouts:          s.Timeouts,
+					SuccessLatencySum: s.SuccessLatencySum,
+					FailureLatencySum: s.FailureLatencySum,
+					CountByRcode:      s.CountByRcode,
+				})
+			}
+		}
+	}
+
+	return all
+}
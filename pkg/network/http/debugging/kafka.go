@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package debugging
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+)
+
+// KafkaSummary represents a (debug-friendly) aggregated view of the Kafka requests
+// matching a (client, broker, topic, API key, API version) tuple
+type KafkaSummary struct {
+	Client     Address
+	Server     Address
+	Topic      string
+	API        string
+	APIVersion uint16
+	Count      int
+}
+
+// Kafka returns a debug-friendly representation of map[kafka.Key]*kafka.RequestStat
+func Kafka(stats map[kafka.Key]*kafka.RequestStat) []KafkaSummary {
+	all := make([]KafkaSummary, 0, len(stats))
+	for k, v := range stats {
+		all = append(all, KafkaSummary{
+			Client: Address{
+				IP:   formatIP(k.SrcIPLow, k.SrcIPHigh).String(),
+				Port: k.SrcPort,
+			},
+			Server: Address{
+				IP:   formatIP(k.DstIPLow, k.DstIPHigh).String(),
+				Port: k.DstPort,
+			},
+			Topic:      k.TopicName,
+			API:        k.RequestAPIKey.String(),
+			APIVersion: k.RequestVersion,
+			Count:      v.Count,
+		})
+	}
+
+	return all
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package http2
+
+import (
+	"fmt"
+
+	"golang.org/x/net/bpf"
+)
+
+// maxPorts bounds the number of ports of the filter, so that its jumps fit in a byte
+const maxPorts = 32
+
+// generateBPFFilter returns a classic BPF filter capturing the TCP segments sent
+// from or to one of the given ports. Both directions are needed, as requests are
+// matched with the responses, and HPACK decoding is stateful in each direction.
+func generateBPFFilter(ports []uint16) ([]bpf.RawInstruction, error) {
+	if len(ports) == 0 || len(ports) > maxPorts {
+		return nil, fmt.Errorf("between 1 and %d http2 ports must be configured, got %d", maxPorts, len(ports))
+	}
+
+	n := len(ports)
+	ipv4 := 5 + n + 1 + n + 1
+	capture := ipv4 + 7 + n + 1 + n + 1
+	drop := capture + 1
+
+	var insts []bpf.Instruction
+	// skip returns the offset of a jump from the next instruction to the one at index to
+	skip := func(to int) uint8 {
+		return uint8(to - len(insts) - 1)
+	}
+	// checkPorts captures the packet if the loaded port is one of ports
+	checkPorts := func() {
+		for _, port := range ports {
+			insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port), SkipTrue: skip(capture)})
+		}
+	}
+
+	// (000) ldh [12] -- load Ethertype
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 12})
+	// (001) jeq #0x86dd -- if IPv6, go next, else check IPv4
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x86dd, SkipFalse: skip(ipv4)})
+	// (002) ldb [20] -- load IPv6 Next Header
+	insts = append(insts, bpf.LoadAbsolute{Size: 1, Off: 20})
+	// (003) jeq #0x6 -- if TCP, go next, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x6, SkipFalse: skip(drop)})
+	// (004) ldh [54] -- load source port
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 54})
+	// (005) jeq #port -- for each port, capture if equal
+	checkPorts()
+	// ldh [56] -- load dest port
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 56})
+	// jeq #port -- for each port, capture if equal; then drop
+	checkPorts()
+	insts = append(insts, bpf.Jump{Skip: uint32(skip(drop))})
+
+	// jeq #0x800 -- if IPv4, go next, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x800, SkipFalse: skip(drop)})
+	// ldb [23] -- load IPv4 Protocol
+	insts = append(insts, bpf.LoadAbsolute{Size: 1, Off: 23})
+	// jeq #0x6 -- if TCP, go next, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x6, SkipFalse: skip(drop)})
+	// ldh [20] -- load Fragment Offset
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 20})
+	// jset #0x1fff -- use 0x1fff as mask for fragment offset, if != 0, drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: skip(drop)})
+	// ldxb 4*([14]&0xf) -- x = IP header length
+	insts = append(insts, bpf.LoadMemShift{Off: 14})
+	// ldh [x + 14] -- load source port
+	insts = append(insts, bpf.LoadIndirect{Size: 2, Off: 14})
+	// jeq #port -- for each port, capture if equal
+	checkPorts()
+	// ldh [x + 16] -- load dest port
+	insts = append(insts, bpf.LoadIndirect{Size: 2, Off: 16})
+	// jeq #port -- for each port, capture if equal; then drop
+	checkPorts()
+	insts = append(insts, bpf.Jump{Skip: uint32(skip(drop))})
+
+	// ret #262144 -- capture
+	insts = append(insts, bpf.RetConstant{Val: 262144})
+	// ret #0 -- drop
+	insts = append(insts, bpf.RetConstant{Val: 0})
+
+	return bpf.Assemble(insts)
+}
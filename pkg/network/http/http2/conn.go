@@ -0,0 +1,373 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package http2
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// defaultMaxStreams is the default maximum number of streams tracked per connection.
+const defaultMaxStreams = 1024
+
+// Transaction is an HTTP/2 request/response exchange decoded from a single stream.
+type Transaction struct {
+	StreamID uint32
+	Method   http.Method
+	// Path is the request path with its query string removed
+	Path string
+	// Status is the status code of the response
+	Status int
+	// GRPCStatus is the gRPC status code found in the response trailers, or -1
+	// if the response has none
+	GRPCStatus int
+	// RequestStarted is the timestamp (in nanoseconds) of the first frame of the request
+	RequestStarted uint64
+	// ResponseLastSeen is the timestamp (in nanoseconds) of the last frame of the response
+	ResponseLastSeen uint64
+}
+
+// IsGRPC returns true if the transaction is a gRPC call.
+func (tx *Transaction) IsGRPC() bool {
+	return tx.GRPCStatus >= 0
+}
+
+// StatusClass returns an integer representing the status code class.
+// For gRPC calls failing with a non-OK status, the class is derived from the
+// HTTP status code equivalent to the gRPC status.
+// Example: a 404 would return 400
+func (tx *Transaction) StatusClass() int {
+	status := tx.Status
+	if tx.GRPCStatus > 0 {
+		status = grpcToHTTPStatus(tx.GRPCStatus)
+	}
+	return (status / 100) * 100
+}
+
+// RequestLatency returns the latency of the request in nanoseconds
+func (tx *Transaction) RequestLatency() float64 {
+	if tx.RequestStarted == 0 || tx.ResponseLastSeen <= tx.RequestStarted {
+		return 0
+	}
+	return float64(tx.ResponseLastSeen - tx.RequestStarted)
+}
+
+// stream holds the state of an HTTP/2 stream whose response has not completed yet.
+type stream struct {
+	method           http.Method
+	path             string
+	status           int
+	grpcStatus       int
+	requestStarted   uint64
+	responseLastSeen uint64
+}
+
+// halfConn holds the decoding state of one direction of a connection.
+type halfConn struct {
+	fromClient bool
+
+	// buf holds the bytes of the incomplete frame received last
+	buf []byte
+	// skip is the number of payload bytes of the current frame left to discard
+	skip uint32
+	// prefaceChecked is true once the client preface was consumed, or found to
+	// be missing because decoding started in the middle of the connection
+	prefaceChecked bool
+
+	decoder *hpack.Decoder
+
+	// block holds the header block being reassembled from a HEADERS or PUSH_PROMISE
+	// frame and the CONTINUATION frames following it, which is described by blockHeader
+	block       []byte
+	blockHeader frameHeader
+	inBlock     bool
+}
+
+func newHalfConn(fromClient bool) halfConn {
+	d := hpack.NewDecoder(defaultHeaderTableSize, nil)
+	d.SetMaxStringLength(maxHeaderBlockSize)
+	return halfConn{fromClient: fromClient, decoder: d}
+}
+
+// Conn decodes the HTTP/2 frames exchanged over a single connection into
+// transactions. Each direction of the connection must be fed in order and
+// from its start, since HPACK decoding is stateful.
+//
+// Conn is not safe for concurrent use.
+type Conn struct {
+	client     halfConn
+	server     halfConn
+	streams    map[uint32]*stream
+	maxStreams int
+	err        error
+}
+
+// NewConn returns a new Conn.
+func NewConn() *Conn {
+	return &Conn{
+		client:     newHalfConn(true),
+		server:     newHalfConn(false),
+		streams:    make(map[uint32]*stream),
+		maxStreams: defaultMaxStreams,
+	}
+}
+
+// Feed decodes the given bytes, sent by the client if fromClient is true and by
+// the server otherwise, and captured at the given timestamp (in nanoseconds).
+// Frames may be split across calls. It returns the transactions completed by
+// these bytes. Once an error is returned, the connection can not be decoded
+// anymore and all subsequent calls return the same error.
+func (c *Conn) Feed(fromClient bool, ts uint64, data []byte) ([]Transaction, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	h := &c.server
+	if fromClient {
+		h = &c.client
+	}
+	buf := data
+	if len(h.buf) > 0 {
+		h.buf = append(h.buf, data...)
+		buf = h.buf
+	}
+
+	var txs []Transaction
+	for len(buf) > 0 {
+		if h.skip > 0 {
+			n := h.skip
+			if uint32(len(buf)) < n {
+				n = uint32(len(buf))
+			}
+			buf = buf[n:]
+			h.skip -= n
+			continue
+		}
+		if fromClient && !h.prefaceChecked {
+			n := len(buf)
+			if n > len(clientPreface) {
+				n = len(clientPreface)
+			}
+			if string(buf[:n]) != clientPreface[:n] {
+				// we started decoding in the middle of the connection
+				h.prefaceChecked = true
+			} else if n < len(clientPreface) {
+				break
+			} else {
+				buf = buf[n:]
+				h.prefaceChecked = true
+				continue
+			}
+		}
+		if len(buf) < frameHeaderLen {
+			break
+		}
+		fh := readFrameHeader(buf)
+		if h.inBlock && fh.typ != frameContinuation {
+			c.err = errMissingContinuation
+			return txs, c.err
+		}
+		if !hasUsefulPayload(fh.typ) {
+			buf = buf[frameHeaderLen:]
+			h.skip = fh.length
+			if tx := c.handleFrame(h, ts, fh); tx != nil {
+				txs = append(txs, *tx)
+			}
+			continue
+		}
+		if fh.length > maxHeaderBlockSize {
+			c.err = errFrameTooLarge
+			return txs, c.err
+		}
+		end := frameHeaderLen + int(fh.length)
+		if len(buf) < end {
+			break
+		}
+		tx, err := c.handlePayload(h, ts, fh, buf[frameHeaderLen:end])
+		if err != nil {
+			c.err = err
+			return txs, err
+		}
+		if tx != nil {
+			txs = append(txs, *tx)
+		}
+		buf = buf[end:]
+	}
+	// keep the bytes of the incomplete frame for the next call
+	h.buf = append(h.buf[:0], buf...)
+	return txs, nil
+}
+
+// hasUsefulPayload returns true if the payload of frames of the given type is
+// needed to decode transactions. Other payloads are discarded without being buffered.
+func hasUsefulPayload(typ frameType) bool {
+	switch typ {
+	case frameHeaders, frameContinuation, framePushPromise, frameSettings:
+		return true
+	}
+	return false
+}
+
+// handleFrame handles a frame whose payload is discarded.
+func (c *Conn) handleFrame(h *halfConn, ts uint64, fh frameHeader) *Transaction {
+	switch fh.typ {
+	case frameData:
+		s, ok := c.streams[fh.streamID]
+		if !ok || h.fromClient {
+			return nil
+		}
+		s.responseLastSeen = ts
+		if fh.has(flagEndStream) {
+			return c.complete(fh.streamID, s)
+		}
+	case frameRSTStream:
+		delete(c.streams, fh.streamID)
+	}
+	return nil
+}
+
+// handlePayload handles a frame along with its payload.
+func (c *Conn) handlePayload(h *halfConn, ts uint64, fh frameHeader, payload []byte) (*Transaction, error) {
+	switch fh.typ {
+	case frameHeaders, framePushPromise:
+		frag, err := headerBlockFragment(fh, payload)
+		if err != nil {
+			return nil, err
+		}
+		if fh.has(flagEndHeaders) {
+			return c.decodeBlock(h, ts, fh, frag)
+		}
+		h.block = append(h.block[:0], frag...)
+		h.blockHeader = fh
+		h.inBlock = true
+	case frameContinuation:
+		if !h.inBlock || fh.streamID != h.blockHeader.streamID {
+			return nil, errContinuation
+		}
+		if len(h.block)+len(payload) > maxHeaderBlockSize {
+			return nil, errFrameTooLarge
+		}
+		h.block = append(h.block, payload...)
+		if fh.has(flagEndHeaders) {
+			h.inBlock = false
+			return c.decodeBlock(h, ts, h.blockHeader, h.block)
+		}
+	case frameSettings:
+		if fh.has(flagAck) {
+			return nil, nil
+		}
+		for i := 0; i+6 <= len(payload); i += 6 {
+			id := binary.BigEndian.Uint16(payload[i:])
+			val := binary.BigEndian.Uint32(payload[i+2:])
+			if id == settingHeaderTableSize {
+				// the setting bounds the dynamic table used to encode the headers
+				// sent to the peer announcing it
+				peer := &c.server
+				if !h.fromClient {
+					peer = &c.client
+				}
+				peer.decoder.SetAllowedMaxDynamicTableSize(val)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// decodeBlock decodes the given header block, which was started by the frame
+// described by fh.
+func (c *Conn) decodeBlock(h *halfConn, ts uint64, fh frameHeader, block []byte) (*Transaction, error) {
+	// header blocks must always be decoded to keep the HPACK dynamic table in sync
+	fields, err := h.decoder.DecodeFull(block)
+	if err != nil {
+		return nil, err
+	}
+	if fh.typ == framePushPromise {
+		return nil, nil
+	}
+
+	if h.fromClient {
+		if _, ok := c.streams[fh.streamID]; ok || len(c.streams) >= c.maxStreams {
+			// either trailers sent by the client, or too many streams
+			return nil, nil
+		}
+		s := &stream{requestStarted: ts, grpcStatus: -1}
+		for _, f := range fields {
+			switch f.Name {
+			case ":method":
+				s.method = methodFromString(f.Value)
+			case ":path":
+				s.path = f.Value
+				if i := strings.IndexByte(s.path, '?'); i >= 0 {
+					s.path = s.path[:i]
+				}
+			}
+		}
+		c.streams[fh.streamID] = s
+		return nil, nil
+	}
+
+	s, ok := c.streams[fh.streamID]
+	if !ok {
+		return nil, nil
+	}
+	s.responseLastSeen = ts
+	for _, f := range fields {
+		switch f.Name {
+		case ":status":
+			// informational responses (1xx) precede the final one
+			if status, err := strconv.Atoi(f.Value); err == nil && status >= 200 {
+				s.status = status
+			}
+		case "grpc-status":
+			if status, err := strconv.Atoi(f.Value); err == nil {
+				s.grpcStatus = status
+			}
+		}
+	}
+	if fh.has(flagEndStream) {
+		return c.complete(fh.streamID, s), nil
+	}
+	return nil, nil
+}
+
+// complete removes the given stream, whose response ended, and returns its transaction.
+func (c *Conn) complete(id uint32, s *stream) *Transaction {
+	delete(c.streams, id)
+	return &Transaction{
+		StreamID:         id,
+		Method:           s.method,
+		Path:             s.path,
+		Status:           s.status,
+		GRPCStatus:       s.grpcStatus,
+		RequestStarted:   s.requestStarted,
+		ResponseLastSeen: s.responseLastSeen,
+	}
+}
+
+func methodFromString(m string) http.Method {
+	switch m {
+	case "GET":
+		return http.MethodGet
+	case "POST":
+		return http.MethodPost
+	case "PUT":
+		return http.MethodPut
+	case "DELETE":
+		return http.MethodDelete
+	case "HEAD":
+		return http.MethodHead
+	case "OPTIONS":
+		return http.MethodOptions
+	case "PATCH":
+		return http.MethodPatch
+	default:
+		return http.MethodUnknown
+	}
+}
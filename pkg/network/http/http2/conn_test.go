@@ -0,0 +1,234 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package http2
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// segment is a chunk of bytes read from one side of a recorded connection.
+type segment struct {
+	fromClient bool
+	data       []byte
+}
+
+// loadRecording loads a recorded connection from testdata. Each line of the file
+// holds the bytes of one read, hex encoded and prefixed with ">" when sent by the
+// client or "<" when sent by the server.
+func loadRecording(t *testing.T, name string) []segment {
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var segments []segment
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		dir, data, ok := strings.Cut(scanner.Text(), " ")
+		require.True(t, ok)
+		b, err := hex.DecodeString(data)
+		require.NoError(t, err)
+		segments = append(segments, segment{fromClient: dir == ">", data: b})
+	}
+	require.NoError(t, scanner.Err())
+	return segments
+}
+
+// replay feeds the given segments to a new Conn, splitting them in chunks of at
+// most chunkSize bytes, and returns the decoded transactions. Segment i is
+// captured at timestamp (i+1)*1000.
+func replay(t *testing.T, segments []segment, chunkSize int) []Transaction {
+	c := NewConn()
+	var txs []Transaction
+	for i, s := range segments {
+		for b := s.data; len(b) > 0; {
+			n := len(b)
+			if n > chunkSize {
+				n = chunkSize
+			}
+			res, err := c.Feed(s.fromClient, uint64(i+1)*1000, b[:n])
+			require.NoError(t, err)
+			txs = append(txs, res...)
+			b = b[n:]
+		}
+	}
+	assert.Empty(t, c.streams)
+	return txs
+}
+
+func TestConnRecordings(t *testing.T) {
+	for name, want := range map[string][]struct {
+		method     http.Method
+		path       string
+		status     int
+		grpcStatus int
+		class      int
+	}{
+		"grpc.txt": {
+			{http.MethodPost, "/grpc.health.v1.Health/Check", 200, grpcOK, 200},
+			{http.MethodPost, "/grpc.health.v1.Health/Check", 200, grpcNotFound, 400},
+			{http.MethodPost, "/pkg.Missing/Method", 200, grpcUnimplemented, 500},
+		},
+		"h2c.txt": {
+			{http.MethodGet, "/users/42", 200, -1, 200},
+			{http.MethodPost, "/items", 404, -1, 400},
+			{http.MethodDelete, "/users/42", 200, -1, 200},
+		},
+	} {
+		segments := loadRecording(t, name)
+		for _, chunkSize := range []int{1, 7, 1 << 20} {
+			txs := replay(t, segments, chunkSize)
+			require.Len(t, txs, len(want), name)
+			for i, tx := range txs {
+				assert.Equal(t, want[i].method, tx.Method, name)
+				assert.Equal(t, want[i].path, tx.Path, name)
+				assert.Equal(t, want[i].status, tx.Status, name)
+				assert.Equal(t, want[i].grpcStatus, tx.GRPCStatus, name)
+				assert.Equal(t, want[i].class, tx.StatusClass(), name)
+				assert.Equal(t, want[i].grpcStatus >= 0, tx.IsGRPC(), name)
+				assert.Greater(t, tx.RequestLatency(), float64(0), name)
+			}
+		}
+	}
+}
+
+// framer builds the byte streams sent by each side of a connection.
+type framer struct {
+	client, server bytes.Buffer
+	cfr, sfr       *http2.Framer
+	cenc, senc     *hpack.Encoder
+	cbuf, sbuf     bytes.Buffer
+}
+
+func newFramer() *framer {
+	f := &framer{}
+	f.client.WriteString(clientPreface)
+	f.cfr = http2.NewFramer(&f.client, nil)
+	f.sfr = http2.NewFramer(&f.server, nil)
+	f.cenc = hpack.NewEncoder(&f.cbuf)
+	f.senc = hpack.NewEncoder(&f.sbuf)
+	return f
+}
+
+func (f *framer) block(fromClient bool, fields ...string) []byte {
+	enc, buf := f.senc, &f.sbuf
+	if fromClient {
+		enc, buf = f.cenc, &f.cbuf
+	}
+	buf.Reset()
+	for i := 0; i < len(fields); i += 2 {
+		_ = enc.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]})
+	}
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+func TestConnFrames(t *testing.T) {
+	t.Run("continuation-padding-priority", func(t *testing.T) {
+		f := newFramer()
+		block := f.block(true, ":method", "GET", ":path", "/a?b=c", ":scheme", "http", ":authority", "localhost")
+		require.NoError(t, f.cfr.WriteHeaders(http2.HeadersFrameParam{
+			StreamID:      1,
+			BlockFragment: block[:3],
+			EndStream:     true,
+			PadLength:     4,
+			Priority:      http2.PriorityParam{StreamDep: 0, Weight: 15},
+		}))
+		require.NoError(t, f.cfr.WriteContinuation(1, true, block[3:]))
+		require.NoError(t, f.sfr.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: f.block(false, ":status", "100"), EndHeaders: true}))
+		require.NoError(t, f.sfr.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: f.block(false, ":status", "503"), EndHeaders: true}))
+		require.NoError(t, f.sfr.WriteData(1, false, []byte("unavailable")))
+		require.NoError(t, f.sfr.WriteData(1, true, nil))
+
+		c := NewConn()
+		txs, err := c.Feed(true, 10, f.client.Bytes())
+		require.NoError(t, err)
+		assert.Empty(t, txs)
+		txs, err = c.Feed(false, 25, f.server.Bytes())
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.Equal(t, Transaction{
+			StreamID:         1,
+			Method:           http.MethodGet,
+			Path:             "/a",
+			Status:           503,
+			GRPCStatus:       -1,
+			RequestStarted:   10,
+			ResponseLastSeen: 25,
+		}, txs[0])
+		assert.Equal(t, float64(15), txs[0].RequestLatency())
+	})
+
+	t.Run("grpc-trailers-only", func(t *testing.T) {
+		f := newFramer()
+		require.NoError(t, f.cfr.WriteHeaders(http2.HeadersFrameParam{StreamID: 3, BlockFragment: f.block(true, ":method", "POST", ":path", "/svc/M", "content-type", "application/grpc"), EndHeaders: true}))
+		require.NoError(t, f.cfr.WriteData(3, true, []byte{0, 0, 0, 0, 0}))
+		require.NoError(t, f.sfr.WriteHeaders(http2.HeadersFrameParam{StreamID: 3, BlockFragment: f.block(false, ":status", "200", "grpc-status", "16"), EndHeaders: true, EndStream: true}))
+
+		c := NewConn()
+		_, err := c.Feed(true, 1, f.client.Bytes())
+		require.NoError(t, err)
+		txs, err := c.Feed(false, 2, f.server.Bytes())
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		assert.True(t, txs[0].IsGRPC())
+		assert.Equal(t, grpcUnauthenticated, txs[0].GRPCStatus)
+		assert.Equal(t, 400, txs[0].StatusClass())
+	})
+
+	t.Run("rst-stream", func(t *testing.T) {
+		f := newFramer()
+		require.NoError(t, f.cfr.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: f.block(true, ":method", "GET", ":path", "/"), EndHeaders: true, EndStream: true}))
+		require.NoError(t, f.cfr.WriteRSTStream(1, http2.ErrCodeCancel))
+
+		c := NewConn()
+		txs, err := c.Feed(true, 1, f.client.Bytes())
+		require.NoError(t, err)
+		assert.Empty(t, txs)
+		assert.Empty(t, c.streams)
+	})
+
+	t.Run("max-streams", func(t *testing.T) {
+		f := newFramer()
+		for id := uint32(1); id <= 7; id += 2 {
+			require.NoError(t, f.cfr.WriteHeaders(http2.HeadersFrameParam{StreamID: id, BlockFragment: f.block(true, ":method", "GET", ":path", "/"), EndHeaders: true, EndStream: true}))
+		}
+		c := NewConn()
+		c.maxStreams = 2
+		_, err := c.Feed(true, 1, f.client.Bytes())
+		require.NoError(t, err)
+		assert.Len(t, c.streams, 2)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		f := newFramer()
+		require.NoError(t, f.cfr.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: f.block(true, ":method", "GET"), EndHeaders: false}))
+		require.NoError(t, f.cfr.WritePing(false, [8]byte{}))
+
+		c := NewConn()
+		_, err := c.Feed(true, 1, f.client.Bytes())
+		assert.Equal(t, errMissingContinuation, err)
+		_, err = c.Feed(true, 2, nil)
+		assert.Equal(t, errMissingContinuation, err, "errors are sticky")
+
+		// indexed header field referring to an empty dynamic table
+		c = NewConn()
+		_, err = c.Feed(true, 1, []byte{0, 0, 1, byte(frameHeaders), flagEndHeaders, 0, 0, 0, 1, 0xbe})
+		assert.Error(t, err)
+	})
+}
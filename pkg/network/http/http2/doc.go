@@ -0,0 +1,11 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package http2 decodes HTTP/2 traffic, including gRPC calls, from the raw bytes
+// exchanged over connections. Frames and HPACK header blocks are decoded in
+// userspace, independently of the way the bytes are captured, and the resulting
+// transactions are aggregated into the same http.Key and http.RequestStats as
+// HTTP/1.x transactions.
+package http2
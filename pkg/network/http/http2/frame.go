@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package http2
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// frameHeaderLen is the length of the header preceding every HTTP/2 frame.
+const frameHeaderLen = 9
+
+// clientPreface is the connection preface sent by HTTP/2 clients before their first frame.
+const clientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// maxHeaderBlockSize bounds the size of the header blocks we buffer. Frames
+// holding no header block are never buffered.
+const maxHeaderBlockSize = 1 << 20
+
+// defaultHeaderTableSize is the initial size of the HPACK dynamic tables.
+const defaultHeaderTableSize = 4096
+
+type frameType uint8
+
+const (
+	frameData         frameType = 0x0
+	frameHeaders      frameType = 0x1
+	framePriority     frameType = 0x2
+	frameRSTStream    frameType = 0x3
+	frameSettings     frameType = 0x4
+	framePushPromise  frameType = 0x5
+	framePing         frameType = 0x6
+	frameGoAway       frameType = 0x7
+	frameWindowUpdate frameType = 0x8
+	frameContinuation frameType = 0x9
+)
+
+const (
+	flagEndStream  uint8 = 0x1
+	flagAck        uint8 = 0x1
+	flagEndHeaders uint8 = 0x4
+	flagPadded     uint8 = 0x8
+	flagPriority   uint8 = 0x20
+)
+
+// settingHeaderTableSize is the identifier of the SETTINGS_HEADER_TABLE_SIZE setting.
+const settingHeaderTableSize = 0x1
+
+var (
+	errFrameTooLarge       = errors.New("http2: header block too large")
+	errFramePadding        = errors.New("http2: invalid frame padding")
+	errContinuation        = errors.New("http2: unexpected CONTINUATION frame")
+	errMissingContinuation = errors.New("http2: expected CONTINUATION frame")
+)
+
+// frameHeader is the header preceding every HTTP/2 frame.
+type frameHeader struct {
+	length   uint32
+	typ      frameType
+	flags    uint8
+	streamID uint32
+}
+
+func (h frameHeader) has(flag uint8) bool {
+	return h.flags&flag != 0
+}
+
+// readFrameHeader decodes the frame header at the start of b, which must hold at
+// least frameHeaderLen bytes.
+func readFrameHeader(b []byte) frameHeader {
+	return frameHeader{
+		length:   uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]),
+		typ:      frameType(b[3]),
+		flags:    b[4],
+		streamID: binary.BigEndian.Uint32(b[5:9]) & (1<<31 - 1),
+	}
+}
+
+// headerBlockFragment returns the header block fragment held by the payload of a
+// HEADERS or PUSH_PROMISE frame, without its padding, priority and promised stream
+// ID fields.
+func headerBlockFragment(h frameHeader, payload []byte) ([]byte, error) {
+	var padLen int
+	if h.has(flagPadded) {
+		if len(payload) < 1 {
+			return nil, errFramePadding
+		}
+		padLen = int(payload[0])
+		payload = payload[1:]
+	}
+	skip := 0
+	switch {
+	case h.typ == frameHeaders && h.has(flagPriority):
+		skip = 5 // stream dependency and weight
+	case h.typ == framePushPromise:
+		skip = 4 // promised stream ID
+	}
+	if len(payload) < skip+padLen {
+		return nil, errFramePadding
+	}
+	return payload[skip : len(payload)-padLen], nil
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package http2
+
+// gRPC status codes, see https://github.com/grpc/grpc/blob/master/doc/statuscodes.md
+const (
+	grpcOK                 = 0
+	grpcCanceled           = 1
+	grpcUnknown            = 2
+	grpcInvalidArgument    = 3
+	grpcDeadlineExceeded   = 4
+	grpcNotFound           = 5
+	grpcAlreadyExists      = 6
+	grpcPermissionDenied   = 7
+	grpcResourceExhausted  = 8
+	grpcFailedPrecondition = 9
+	grpcAborted            = 10
+	grpcOutOfRange         = 11
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+	grpcDataLoss           = 15
+	grpcUnauthenticated    = 16
+)
+
+// grpcToHTTPStatus returns the HTTP status code corresponding to the given gRPC
+// status code, following the mapping used by gRPC gateways.
+func grpcToHTTPStatus(code int) int {
+	switch code {
+	case grpcOK:
+		return 200
+	case grpcCanceled:
+		return 499
+	case grpcInvalidArgument, grpcFailedPrecondition, grpcOutOfRange:
+		return 400
+	case grpcDeadlineExceeded:
+		return 504
+	case grpcNotFound:
+		return 404
+	case grpcAlreadyExists, grpcAborted:
+		return 409
+	case grpcPermissionDenied:
+		return 403
+	case grpcResourceExhausted:
+		return 429
+	case grpcUnimplemented:
+		return 501
+	case grpcUnavailable:
+		return 503
+	case grpcUnauthenticated:
+		return 401
+	default: // grpcUnknown, grpcInternal, grpcDataLoss and unknown codes
+		return 500
+	}
+}
@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package http2
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/vishvananda/netns"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Monitor captures the TCP segments sent from and to the HTTP/2 ports, and
+// aggregates the HTTP/2 transactions, including gRPC calls, they hold.
+type Monitor struct {
+	source *filterpkg.AFPacketSource
+	ports  map[uint16]struct{}
+	ttl    uint64
+
+	decoder *gopacket.DecodingLayerParser
+	layers  []gopacket.LayerType
+	ipv4    layers.IPv4
+	ipv6    layers.IPv6
+	tcp     layers.TCP
+	payload gopacket.Payload
+
+	mux        sync.Mutex
+	statKeeper *StatKeeper
+
+	decodingErrors atomic.Int64
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMonitor returns a new Monitor capturing the traffic of the root network
+// namespace.
+func NewMonitor(c *config.Config) (*Monitor, error) {
+	bpfFilter, err := generateBPFFilter(c.HTTP2Ports)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bpf classic filter: %w", err)
+	}
+
+	// Create the RAW_SOCKET inside the root network namespace
+	var (
+		packetSrc *filterpkg.AFPacketSource
+		srcErr    error
+		ns        netns.NsHandle
+	)
+	if ns, err = c.GetRootNetNs(); err != nil {
+		return nil, err
+	}
+	defer ns.Close()
+
+	err = util.WithNS(c.ProcRoot, ns, func() error {
+		packetSrc, srcErr = filterpkg.NewPacketSource(nil, bpfFilter)
+		return srcErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Monitor{
+		source:     packetSrc,
+		ports:      make(map[uint16]struct{}, len(c.HTTP2Ports)),
+		ttl:        uint64(c.HTTPIdleConnectionTTL.Nanoseconds()),
+		statKeeper: NewStatKeeper(c),
+		exit:       make(chan struct{}),
+	}
+	for _, port := range c.HTTP2Ports {
+		m.ports[port] = struct{}{}
+	}
+	m.decoder = gopacket.NewDecodingLayerParser(packetSrc.PacketType(), &layers.Ethernet{}, &m.ipv4, &m.ipv6, &m.tcp, &m.payload)
+	m.decoder.IgnoreUnsupported = true
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollPackets()
+	}()
+	return m, nil
+}
+
+// GetHTTPStats returns the stats aggregated since the previous call, in the
+// same format as the HTTP/1.x stats.
+func (m *Monitor) GetHTTPStats() map[http.Key]*http.RequestStats {
+	if m == nil {
+		return nil
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.statKeeper.RemoveIdle(uint64(time.Now().UnixNano()), m.ttl)
+	return m.statKeeper.GetAndResetAllStats()
+}
+
+// GetStats returns telemetry about the decoded transactions.
+func (m *Monitor) GetStats() map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	m.mux.Lock()
+	stats := m.statKeeper.GetStats()
+	m.mux.Unlock()
+	for key, value := range m.source.Stats() {
+		stats[key] = value
+	}
+	stats["decoding_errors"] = m.decodingErrors.Load()
+	return stats
+}
+
+// Stop stops capturing packets and releases associated resources.
+func (m *Monitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
+
+func (m *Monitor) pollPackets() {
+	for {
+		err := m.source.VisitPackets(m.exit, m.processPacket)
+		if err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// processPacket feeds the TCP segment held by the given packet to the decoder of
+// its connection. The packet data can't be referenced after this call since the
+// underlying memory content gets invalidated by `afpacket`.
+func (m *Monitor) processPacket(data []byte, timestamp time.Time) error {
+	if err := m.decoder.DecodeLayers(data, &m.layers); err != nil {
+		m.decodingErrors.Inc()
+		return nil
+	}
+
+	var saddr, daddr util.Address
+	var isTCP bool
+	for _, layer := range m.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			saddr, daddr = util.AddressFromNetIP(m.ipv4.SrcIP), util.AddressFromNetIP(m.ipv4.DstIP)
+		case layers.LayerTypeIPv6:
+			saddr, daddr = util.AddressFromNetIP(m.ipv6.SrcIP), util.AddressFromNetIP(m.ipv6.DstIP)
+		case layers.LayerTypeTCP:
+			isTCP = true
+		}
+	}
+	if !isTCP {
+		return nil
+	}
+
+	// the tuple of a connection has its client as source
+	sport, dport := uint16(m.tcp.SrcPort), uint16(m.tcp.DstPort)
+	_, fromClient := m.ports[dport]
+	tup := http.NewKeyTuple(saddr, daddr, sport, dport)
+	if !fromClient {
+		tup = http.NewKeyTuple(daddr, saddr, dport, sport)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if len(m.tcp.Payload) > 0 {
+		m.statKeeper.Feed(tup, fromClient, uint64(timestamp.UnixNano()), m.tcp.Seq, m.tcp.Payload)
+	}
+	if m.tcp.RST || (m.tcp.FIN && !fromClient) {
+		// responses may still follow the FIN of the client, but nothing is
+		// sent by the server after its own FIN
+		m.statKeeper.Close(tup)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package http2
+
+import (
+	"bytes"
+
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// trackedConn is a connection being decoded, along with the timestamp (in
+// nanoseconds) of the last bytes fed to it. Conn is nil while the connection is
+// poisoned: its HPACK state got lost and nothing is decoded until a new
+// connection preface is seen on the same tuple.
+type trackedConn struct {
+	*Conn
+	lastSeen uint64
+
+	// nextSeq is the sequence number of the next byte expected from the
+	// client and from the server, once synced
+	nextSeq [2]uint32
+	synced  [2]bool
+}
+
+// accept returns the part of the given segment that wasn't fed yet, or nil if
+// the segment was already fed. It returns false if a segment went missing or
+// was reordered.
+func (c *trackedConn) accept(fromClient bool, seq uint32, data []byte) ([]byte, bool) {
+	dir := 0
+	if fromClient {
+		dir = 1
+	}
+	if c.synced[dir] {
+		diff := int32(seq - c.nextSeq[dir])
+		if diff > 0 {
+			return nil, false
+		}
+		if -int(diff) >= len(data) {
+			return nil, true
+		}
+		data = data[-diff:]
+		seq = c.nextSeq[dir]
+	}
+	c.nextSeq[dir], c.synced[dir] = seq+uint32(len(data)), true
+	return data, true
+}
+
+// StatKeeper decodes the HTTP/2 traffic of multiple connections and aggregates
+// the resulting transactions into the same stats as HTTP/1.x transactions.
+//
+// Since HPACK decoding is stateful, a connection is only decoded from its
+// preface on, and its segments must be fed in order: retransmitted segments are
+// skipped, while a missing or reordered segment poisons the connection.
+//
+// StatKeeper is not safe for concurrent use.
+type StatKeeper struct {
+	conns    map[http.KeyTuple]*trackedConn
+	maxConns uint
+
+	stats      map[http.Key]*http.RequestStats
+	maxEntries int
+
+	// replace rules for HTTP path
+	replaceRules []*config.ReplaceRule
+
+	// map containing interned path strings
+	// this is rotated with the stats map
+	interned map[string]string
+
+	hits, malformed, rejected, dropped atomic.Int64
+	retransmits, desyncs               atomic.Int64
+}
+
+// NewStatKeeper returns a new StatKeeper.
+func NewStatKeeper(c *config.Config) *StatKeeper {
+	return &StatKeeper{
+		conns:        make(map[http.KeyTuple]*trackedConn),
+		maxConns:     c.MaxTrackedConnections,
+		stats:        make(map[http.Key]*http.RequestStats),
+		maxEntries:   c.MaxHTTPStatsBuffered,
+		replaceRules: c.HTTPReplaceRules,
+		interned:     make(map[string]string),
+	}
+}
+
+// Feed decodes the TCP segment captured at the given timestamp (in nanoseconds) on
+// the connection identified by tup, whose source must be the client. fromClient
+// specifies whether the segment was sent by the client or the server, and seq
+// is its sequence number.
+func (s *StatKeeper) Feed(tup http.KeyTuple, fromClient bool, ts uint64, seq uint32, data []byte) {
+	isPreface := fromClient && bytes.HasPrefix(data, []byte(clientPreface))
+	c, ok := s.conns[tup]
+	if !ok {
+		if !isPreface {
+			// the connection was established before it could be tracked
+			return
+		}
+		if uint(len(s.conns)) >= s.maxConns {
+			s.dropped.Inc()
+			return
+		}
+		c = &trackedConn{}
+		s.conns[tup] = c
+	}
+	c.lastSeen = ts
+
+	if c.Conn == nil {
+		if !isPreface {
+			return
+		}
+		// a new connection is using the tuple
+		c.Conn = NewConn()
+		c.synced = [2]bool{}
+	}
+
+	data, inSync := c.accept(fromClient, seq, data)
+	if !inSync {
+		s.desyncs.Inc()
+		c.Conn = nil
+		return
+	}
+	if len(data) == 0 {
+		s.retransmits.Inc()
+		return
+	}
+
+	txs, err := c.Feed(fromClient, ts, data)
+	if err != nil {
+		// the HPACK state is lost, nothing can be decoded on this connection anymore
+		s.malformed.Inc()
+		c.Conn = nil
+	}
+	for i := range txs {
+		s.add(tup, &txs[i])
+	}
+}
+
+// Close releases the decoding state of the connection identified by tup.
+func (s *StatKeeper) Close(tup http.KeyTuple) {
+	delete(s.conns, tup)
+}
+
+// RemoveIdle releases the decoding state of the connections which were not fed
+// any bytes during the given ttl before now, both in nanoseconds. This covers the
+// connections whose closing was missed.
+func (s *StatKeeper) RemoveIdle(now, ttl uint64) {
+	for tup, c := range s.conns {
+		if now-c.lastSeen > ttl {
+			delete(s.conns, tup)
+		}
+	}
+}
+
+// GetAndResetAllStats returns the stats aggregated since the previous call.
+func (s *StatKeeper) GetAndResetAllStats() map[http.Key]*http.RequestStats {
+	ret := s.stats // No deep copy needed since `s.stats` gets reset
+	s.stats = make(map[http.Key]*http.RequestStats)
+	s.interned = make(map[string]string)
+	return ret
+}
+
+// GetStats returns telemetry about the decoded transactions.
+func (s *StatKeeper) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"hits":        s.hits.Load(),
+		"malformed":   s.malformed.Load(),
+		"rejected":    s.rejected.Load(),
+		"dropped":     s.dropped.Load(),
+		"retransmits": s.retransmits.Load(),
+		"desyncs":     s.desyncs.Load(),
+		"connections": len(s.conns),
+	}
+}
+
+func (s *StatKeeper) add(tup http.KeyTuple, tx *Transaction) {
+	if tx.Method == http.MethodUnknown || tx.Path == "" || tx.Status == 0 {
+		s.malformed.Inc()
+		return
+	}
+	latency := tx.RequestLatency()
+	if latency <= 0 {
+		s.malformed.Inc()
+		return
+	}
+	path, rejected := s.processPath(tx.Path)
+	if rejected {
+		s.rejected.Inc()
+		return
+	}
+
+	key := http.Key{
+		KeyTuple: tup,
+		Path: http.Path{
+			Content:  path,
+			FullPath: true,
+		},
+		Method: tx.Method,
+	}
+	stats, ok := s.stats[key]
+	if !ok {
+		if len(s.stats) >= s.maxEntries {
+			s.dropped.Inc()
+			return
+		}
+		stats = new(http.RequestStats)
+		s.stats[key] = stats
+	}
+	s.hits.Inc()
+	stats.AddRequest(tx.StatusClass(), latency, 0)
+}
+
+func (s *StatKeeper) processPath(path string) (string, bool) {
+	for _, r := range s.replaceRules {
+		if r.Re.MatchString(path) {
+			if r.Repl == "" {
+				// this is a "drop" rule
+				return "", true
+			}
+			path = r.Re.ReplaceAllString(path, r.Repl)
+		}
+	}
+	v, ok := s.interned[path]
+	if !ok {
+		v = path
+		s.interned[v] = v
+	}
+	return v, false
+}
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package http2
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+func newTestStatKeeper(rules ...*config.ReplaceRule) *StatKeeper {
+	return NewStatKeeper(&config.Config{
+		MaxTrackedConnections: 10,
+		MaxHTTPStatsBuffered:  10,
+		HTTPReplaceRules:      rules,
+	})
+}
+
+// feedRecording feeds the segments in order, the sequence numbers of both
+// directions starting at 1
+func feedRecording(s *StatKeeper, tup http.KeyTuple, segments []segment) {
+	clientSeq, serverSeq := uint32(1), uint32(1)
+	for i, seg := range segments {
+		seq := &serverSeq
+		if seg.fromClient {
+			seq = &clientSeq
+		}
+		s.Feed(tup, seg.fromClient, uint64(i+1)*1000, *seq, seg.data)
+		*seq += uint32(len(seg.data))
+	}
+}
+
+func TestStatKeeper(t *testing.T) {
+	tup := http.KeyTuple{SrcIPLow: 1, SrcPort: 45678, DstIPLow: 2, DstPort: 8080}
+	key := func(method http.Method, path string) http.Key {
+		return http.Key{KeyTuple: tup, Path: http.Path{Content: path, FullPath: true}, Method: method}
+	}
+
+	t.Run("h2c", func(t *testing.T) {
+		s := newTestStatKeeper()
+		feedRecording(s, tup, loadRecording(t, "h2c.txt"))
+		s.Close(tup)
+
+		stats := s.GetAndResetAllStats()
+		require.Len(t, stats, 3)
+		assert.Equal(t, 1, stats[key(http.MethodGet, "/users/42")].Stats(200).Count)
+		assert.Equal(t, 1, stats[key(http.MethodPost, "/items")].Stats(400).Count)
+		assert.Equal(t, 1, stats[key(http.MethodDelete, "/users/42")].Stats(200).Count)
+		assert.Empty(t, s.GetAndResetAllStats())
+		assert.Equal(t, 0, s.GetStats()["connections"])
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		s := newTestStatKeeper()
+		feedRecording(s, tup, loadRecording(t, "grpc.txt"))
+
+		stats := s.GetAndResetAllStats()
+		require.Len(t, stats, 2)
+		check := stats[key(http.MethodPost, "/grpc.health.v1.Health/Check")]
+		assert.Equal(t, 1, check.Stats(200).Count)
+		assert.Equal(t, 1, check.Stats(400).Count)
+		assert.Equal(t, 1, stats[key(http.MethodPost, "/pkg.Missing/Method")].Stats(500).Count)
+		assert.EqualValues(t, 3, s.GetStats()["hits"])
+	})
+
+	t.Run("replace-rules", func(t *testing.T) {
+		s := newTestStatKeeper(
+			&config.ReplaceRule{Re: regexp.MustCompile("/users/[0-9]+"), Repl: "/users/?"},
+			&config.ReplaceRule{Re: regexp.MustCompile("/items")},
+		)
+		feedRecording(s, tup, loadRecording(t, "h2c.txt"))
+
+		stats := s.GetAndResetAllStats()
+		require.Len(t, stats, 2)
+		assert.Contains(t, stats, key(http.MethodGet, "/users/?"))
+		assert.Contains(t, stats, key(http.MethodDelete, "/users/?"))
+		assert.EqualValues(t, 1, s.GetStats()["rejected"])
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		s := NewStatKeeper(&config.Config{MaxTrackedConnections: 1, MaxHTTPStatsBuffered: 1})
+		feedRecording(s, tup, loadRecording(t, "h2c.txt"))
+		other := tup
+		other.SrcPort++
+		s.Feed(other, true, 1, 1, []byte(clientPreface))
+
+		assert.Len(t, s.GetAndResetAllStats(), 1)
+		assert.EqualValues(t, 3, s.GetStats()["dropped"])
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		s := newTestStatKeeper()
+		s.Feed(tup, true, 1, 1, append([]byte(clientPreface), 0, 0, 1, byte(frameHeaders), flagEndHeaders, 0, 0, 0, 1, 0xbe))
+		assert.EqualValues(t, 1, s.GetStats()["malformed"])
+		// the connection is poisoned
+		require.Contains(t, s.conns, tup)
+		assert.Nil(t, s.conns[tup].Conn)
+	})
+
+	t.Run("mid-connection", func(t *testing.T) {
+		s := newTestStatKeeper()
+		segments := loadRecording(t, "h2c.txt")
+		// the preface was sent before the connection could be tracked
+		feedRecording(s, tup, segments[1:])
+		assert.Empty(t, s.GetAndResetAllStats())
+		assert.Equal(t, 0, s.GetStats()["connections"])
+	})
+
+	t.Run("retransmits", func(t *testing.T) {
+		s := newTestStatKeeper()
+		segments := loadRecording(t, "h2c.txt")
+		clientSeq, serverSeq := uint32(1), uint32(1)
+		for i, seg := range segments {
+			seq := &serverSeq
+			if seg.fromClient {
+				seq = &clientSeq
+			}
+			// every segment is seen twice
+			s.Feed(tup, seg.fromClient, uint64(i+1)*1000, *seq, seg.data)
+			s.Feed(tup, seg.fromClient, uint64(i+1)*1000, *seq, seg.data)
+			*seq += uint32(len(seg.data))
+		}
+
+		stats := s.GetAndResetAllStats()
+		require.Len(t, stats, 3)
+		assert.Equal(t, 1, stats[key(http.MethodGet, "/users/42")].Stats(200).Count)
+		assert.EqualValues(t, len(segments), s.GetStats()["retransmits"])
+		assert.EqualValues(t, 0, s.GetStats()["desyncs"])
+	})
+
+	t.Run("desync", func(t *testing.T) {
+		s := newTestStatKeeper()
+		segments := loadRecording(t, "h2c.txt")
+		clientSeq, serverSeq := uint32(1), uint32(1)
+		lost := -1
+		for i, seg := range segments {
+			seq := &serverSeq
+			if seg.fromClient {
+				seq = &clientSeq
+			}
+			if seg.fromClient && i > 0 && lost < 0 {
+				// the first client segment after the preface goes missing
+				lost = i
+			} else {
+				s.Feed(tup, seg.fromClient, uint64(i+1)*1000, *seq, seg.data)
+			}
+			*seq += uint32(len(seg.data))
+		}
+
+		// the first request completed before the missing segment, nothing is
+		// decoded past it
+		stats := s.GetAndResetAllStats()
+		require.Len(t, stats, 1)
+		assert.Contains(t, stats, key(http.MethodGet, "/users/42"))
+		assert.EqualValues(t, 1, s.GetStats()["desyncs"])
+		assert.Nil(t, s.conns[tup].Conn)
+
+		// the tuple is reused by a new connection
+		feedRecording(s, tup, segments)
+		assert.Len(t, s.GetAndResetAllStats(), 3)
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		s := newTestStatKeeper()
+		other := tup
+		other.SrcPort++
+		s.Feed(tup, true, 1000, 1, []byte(clientPreface))
+		s.Feed(other, true, 5000, 1, []byte(clientPreface))
+
+		s.RemoveIdle(6000, 2000)
+		assert.Equal(t, 1, s.GetStats()["connections"])
+		s.RemoveIdle(8000, 2000)
+		assert.Equal(t, 0, s.GetStats()["connections"])
+	})
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package kafka
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func serializePacket(t *testing.T, v6 bool, proto layers.IPProtocol, dport uint16, fragOffset uint16) []byte {
+	eth := &layers.Ethernet{SrcMAC: make(net.HardwareAddr, 6), DstMAC: make(net.HardwareAddr, 6)}
+	var ip gopacket.NetworkLayer
+	if v6 {
+		eth.EthernetType = layers.EthernetTypeIPv6
+		ip = &layers.IPv6{Version: 6, NextHeader: proto, HopLimit: 64, SrcIP: net.ParseIP("::1"), DstIP: net.ParseIP("::2")}
+	} else {
+		eth.EthernetType = layers.EthernetTypeIPv4
+		ip = &layers.IPv4{Version: 4, Protocol: proto, TTL: 64, FragOffset: fragOffset, SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}
+	}
+	var transport gopacket.SerializableLayer
+	if proto == layers.IPProtocolTCP {
+		tcp := &layers.TCP{SrcPort: 45678, DstPort: layers.TCPPort(dport), PSH: true, ACK: true}
+		_ = tcp.SetNetworkLayerForChecksum(ip)
+		transport = tcp
+	} else {
+		udp := &layers.UDP{SrcPort: 45678, DstPort: layers.UDPPort(dport)}
+		_ = udp.SetNetworkLayerForChecksum(ip)
+		transport = udp
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	err := gopacket.SerializeLayers(buf, opts, eth, ip.(gopacket.SerializableLayer), transport, gopacket.Payload("kafka"))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestBPFFilter(t *testing.T) {
+	raw, err := generateBPFFilter([]uint16{9092, 29092})
+	require.NoError(t, err)
+	insts, ok := bpf.Disassemble(raw)
+	require.True(t, ok)
+	vm, err := bpf.NewVM(insts)
+	require.NoError(t, err)
+
+	for _, v6 := range []bool{false, true} {
+		for _, tt := range []struct {
+			proto    layers.IPProtocol
+			dport    uint16
+			captured bool
+		}{
+			{layers.IPProtocolTCP, 9092, true},
+			{layers.IPProtocolTCP, 29092, true},
+			{layers.IPProtocolTCP, 9093, false},
+			{layers.IPProtocolUDP, 9092, false},
+		} {
+			n, err := vm.Run(serializePacket(t, v6, tt.proto, tt.dport, 0))
+			require.NoError(t, err)
+			assert.Equal(t, tt.captured, n > 0, "v6=%t %+v", v6, tt)
+		}
+	}
+
+	n, err := vm.Run(serializePacket(t, false, layers.IPProtocolTCP, 9092, 10))
+	require.NoError(t, err)
+	assert.Zero(t, n, "fragments are dropped")
+
+	_, err = generateBPFFilter(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package kafka
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/vishvananda/netns"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Monitor captures the TCP segments sent to Kafka brokers and aggregates the
+// produce and fetch requests they hold.
+type Monitor struct {
+	source *filterpkg.AFPacketSource
+	ports  map[uint16]struct{}
+
+	decoder *gopacket.DecodingLayerParser
+	layers  []gopacket.LayerType
+	ipv4    layers.IPv4
+	ipv6    layers.IPv6
+	tcp     layers.TCP
+	payload gopacket.Payload
+
+	mux        sync.Mutex
+	statKeeper *StatKeeper
+
+	decodingErrors atomic.Int64
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMonitor returns a new Monitor capturing the traffic of the root network
+// namespace.
+func NewMonitor(c *config.Config) (*Monitor, error) {
+	bpfFilter, err := generateBPFFilter(c.KafkaPorts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bpf classic filter: %w", err)
+	}
+
+	// Create the RAW_SOCKET inside the root network namespace
+	var (
+		packetSrc *filterpkg.AFPacketSource
+		srcErr    error
+		ns        netns.NsHandle
+	)
+	if ns, err = c.GetRootNetNs(); err != nil {
+		return nil, err
+	}
+	defer ns.Close()
+
+	err = util.WithNS(c.ProcRoot, ns, func() error {
+		packetSrc, srcErr = filterpkg.NewPacketSource(nil, bpfFilter)
+		return srcErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Monitor{
+		source:     packetSrc,
+		ports:      make(map[uint16]struct{}, len(c.KafkaPorts)),
+		statKeeper: NewStatKeeper(c),
+		exit:       make(chan struct{}),
+	}
+	for _, port := range c.KafkaPorts {
+		m.ports[port] = struct{}{}
+	}
+	m.decoder = gopacket.NewDecodingLayerParser(packetSrc.PacketType(), &layers.Ethernet{}, &m.ipv4, &m.ipv6, &m.tcp, &m.payload)
+	m.decoder.IgnoreUnsupported = true
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollPackets()
+	}()
+	return m, nil
+}
+
+// GetKafkaStats returns the stats aggregated since the previous call.
+func (m *Monitor) GetKafkaStats() map[Key]*RequestStat {
+	if m == nil {
+		return nil
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.statKeeper.GetAndResetAllStats()
+}
+
+// GetStats returns telemetry about the monitored requests.
+func (m *Monitor) GetStats() map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	stats := m.statKeeper.GetStats()
+	for key, value := range m.source.Stats() {
+		stats[key] = value
+	}
+	stats["decoding_errors"] = m.decodingErrors.Load()
+	return stats
+}
+
+// Stop stops capturing packets and releases associated resources.
+func (m *Monitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
+
+func (m *Monitor) pollPackets() {
+	for {
+		err := m.source.VisitPackets(m.exit, m.processPacket)
+		if err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// processPacket records the Kafka request held by the given packet, if any. The
+// packet data can't be referenced after this call since the underlying memory
+// content gets invalidated by `afpacket`.
+func (m *Monitor) processPacket(data []byte, _ time.Time) error {
+	if err := m.decoder.DecodeLayers(data, &m.layers); err != nil {
+		m.decodingErrors.Inc()
+		return nil
+	}
+
+	var saddr, daddr util.Address
+	var isTCP bool
+	for _, layer := range m.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			saddr, daddr = util.AddressFromNetIP(m.ipv4.SrcIP), util.AddressFromNetIP(m.ipv4.DstIP)
+		case layers.LayerTypeIPv6:
+			saddr, daddr = util.AddressFromNetIP(m.ipv6.SrcIP), util.AddressFromNetIP(m.ipv6.DstIP)
+		case layers.LayerTypeTCP:
+			isTCP = true
+		}
+	}
+	if !isTCP || len(m.tcp.Payload) == 0 {
+		return nil
+	}
+	dport := uint16(m.tcp.DstPort)
+	if _, ok := m.ports[dport]; !ok {
+		return nil
+	}
+
+	tup := http.NewKeyTuple(saddr, daddr, uint16(m.tcp.SrcPort), dport)
+	m.mux.Lock()
+	m.statKeeper.Process(tup, m.tcp.Payload)
+	m.mux.Unlock()
+	return nil
+}
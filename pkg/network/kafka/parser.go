@@ -0,0 +1,363 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// headerLen is the length of the fixed part of a request header: message size,
+	// API key, API version and correlation ID
+	headerLen = 12
+
+	// maxProduceVersion and maxFetchVersion are the latest versions whose topics
+	// can be decoded. Fetch requests identify topics by ID from version 13.
+	maxProduceVersion = 9
+	maxFetchVersion   = 12
+
+	// produceFlexibleVersion and fetchFlexibleVersion are the first versions using
+	// compact arrays and strings, and carrying tagged fields.
+	produceFlexibleVersion = 9
+	fetchFlexibleVersion   = 12
+
+	// maxTopicNameLen is the maximum length of a topic name accepted by brokers
+	maxTopicNameLen = 249
+
+	// maxTopics bounds the number of topics read from a single request
+	maxTopics = 64
+)
+
+var (
+	// ErrUnsupportedRequest is returned for valid requests that are neither
+	// produce nor fetch requests, or use an unsupported version.
+	ErrUnsupportedRequest = errors.New("kafka: unsupported request")
+
+	errMalformed = errors.New("kafka: malformed request")
+)
+
+// Request holds the fields of a produce or fetch request relevant to monitoring
+type Request struct {
+	APIKey        APIKey
+	APIVersion    uint16
+	CorrelationID int32
+	ClientID      string
+	Topics        []string
+}
+
+// ParseRequest decodes the produce or fetch request at the start of payload,
+// which is typically the first TCP segment sent by a client for that request.
+// Payloads don't need to hold the whole request: topics found before the end of
+// a truncated payload are returned, and an error is only returned if none is.
+// A nil error doesn't guarantee payload holds a Kafka request, as the protocol
+// has no magic bytes; only the values that can be checked are validated.
+func ParseRequest(payload []byte) (*Request, error) {
+	if len(payload) < headerLen {
+		return nil, errMalformed
+	}
+	size := int32(binary.BigEndian.Uint32(payload))
+	apiKey := int16(binary.BigEndian.Uint16(payload[4:]))
+	apiVersion := int16(binary.BigEndian.Uint16(payload[6:]))
+	// the size doesn't include its own field, and requests hold at least the
+	// length of the client ID
+	if int(size) < headerLen-4+2 || apiKey < 0 || apiVersion < 0 {
+		return nil, errMalformed
+	}
+
+	req := &Request{
+		APIKey:        APIKey(apiKey),
+		APIVersion:    uint16(apiVersion),
+		CorrelationID: int32(binary.BigEndian.Uint32(payload[8:])),
+	}
+	var flexible bool
+	switch req.APIKey {
+	case ProduceAPIKey:
+		if apiVersion > maxProduceVersion {
+			return nil, ErrUnsupportedRequest
+		}
+		flexible = apiVersion >= produceFlexibleVersion
+	case FetchAPIKey:
+		if apiVersion > maxFetchVersion {
+			return nil, ErrUnsupportedRequest
+		}
+		flexible = apiVersion >= fetchFlexibleVersion
+	default:
+		return nil, ErrUnsupportedRequest
+	}
+
+	// the request size doesn't include its own field
+	end := int(size) + 4
+	if end > len(payload) {
+		end = len(payload)
+	}
+	r := &reader{b: payload[headerLen:end], flexible: flexible}
+
+	clientID, ok := r.nullableString()
+	if !ok || !isPrintable(clientID) {
+		return nil, errMalformed
+	}
+	req.ClientID = clientID
+	r.taggedFields()
+
+	if req.APIKey == ProduceAPIKey {
+		parseProduce(r, req)
+	} else {
+		parseFetch(r, req)
+	}
+	if r.malformed {
+		return nil, errMalformed
+	}
+	if len(req.Topics) == 0 && r.truncated {
+		// we can't tell which topic the request is about. Note that complete fetch
+		// requests may have no topics when using incremental fetch sessions.
+		return nil, errMalformed
+	}
+	return req, nil
+}
+
+func parseProduce(r *reader, req *Request) {
+	if req.APIVersion >= 3 {
+		r.skipNullableString() // transactional_id
+	}
+	r.skip(2 + 4) // acks, timeout_ms
+
+	numTopics := r.arrayLen()
+	for i := 0; i < numTopics && r.ok(); i++ {
+		if !r.topic(req) {
+			return
+		}
+		numPartitions := r.arrayLen()
+		for j := 0; j < numPartitions && r.ok(); j++ {
+			r.skip(4)             // index
+			r.skipNullableBytes() // records
+			r.taggedFields()
+		}
+		r.taggedFields()
+	}
+}
+
+func parseFetch(r *reader, req *Request) {
+	r.skip(4 + 4 + 4) // replica_id, max_wait_ms, min_bytes
+	if req.APIVersion >= 3 {
+		r.skip(4) // max_bytes
+	}
+	if req.APIVersion >= 4 {
+		r.skip(1) // isolation_level
+	}
+	if req.APIVersion >= 7 {
+		r.skip(4 + 4) // session_id, session_epoch
+	}
+
+	partitionLen := 4 + 8 + 4 // partition, fetch_offset, partition_max_bytes
+	if req.APIVersion >= 5 {
+		partitionLen += 8 // log_start_offset
+	}
+	if req.APIVersion >= 9 {
+		partitionLen += 4 // current_leader_epoch
+	}
+	if req.APIVersion >= 12 {
+		partitionLen += 4 // last_fetched_epoch
+	}
+
+	numTopics := r.arrayLen()
+	for i := 0; i < numTopics && r.ok(); i++ {
+		if !r.topic(req) {
+			return
+		}
+		numPartitions := r.arrayLen()
+		for j := 0; j < numPartitions && r.ok(); j++ {
+			r.skip(partitionLen)
+			r.taggedFields()
+		}
+		r.taggedFields()
+	}
+}
+
+// reader decodes the primitive types of the Kafka protocol. Reading past the
+// end of the buffer sets truncated, while invalid values set malformed; reads
+// are no-ops once either is set.
+type reader struct {
+	b         []byte
+	off       int
+	flexible  bool
+	truncated bool
+	malformed bool
+}
+
+func (r *reader) ok() bool {
+	return !r.truncated && !r.malformed
+}
+
+func (r *reader) take(n int) []byte {
+	if !r.ok() {
+		return nil
+	}
+	if n < 0 {
+		r.malformed = true
+		return nil
+	}
+	if n > len(r.b)-r.off {
+		r.truncated = true
+		return nil
+	}
+	b := r.b[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) skip(n int) {
+	r.take(n)
+}
+
+func (r *reader) int16() int16 {
+	b := r.take(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (r *reader) int32() int32 {
+	b := r.take(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (r *reader) uvarint() uint64 {
+	if !r.ok() {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b[r.off:])
+	switch {
+	case n == 0:
+		r.truncated = true
+	case n < 0 || v > 1<<31:
+		r.malformed = true
+	default:
+		r.off += n
+	}
+	return v
+}
+
+// compactLen reads the length of a compact array, string or byte sequence, which
+// is stored plus one so that zero can represent null. Null values have length -1.
+func (r *reader) compactLen() int {
+	return int(r.uvarint()) - 1
+}
+
+// nullableString reads a nullable string which is never compact, as used in
+// request headers.
+func (r *reader) nullableString() (string, bool) {
+	n := r.int16()
+	if n == -1 || !r.ok() {
+		return "", r.ok()
+	}
+	b := r.take(int(n))
+	return string(b), r.ok()
+}
+
+func (r *reader) string() string {
+	var n int
+	if r.flexible {
+		n = r.compactLen()
+	} else {
+		n = int(r.int16())
+	}
+	return string(r.take(n))
+}
+
+func (r *reader) skipNullableString() {
+	var n int
+	if r.flexible {
+		n = r.compactLen()
+	} else {
+		n = int(r.int16())
+	}
+	if n != -1 {
+		r.skip(n)
+	}
+}
+
+func (r *reader) skipNullableBytes() {
+	var n int
+	if r.flexible {
+		n = r.compactLen()
+	} else {
+		n = int(r.int32())
+	}
+	if n != -1 {
+		r.skip(n)
+	}
+}
+
+func (r *reader) arrayLen() int {
+	var n int
+	if r.flexible {
+		n = r.compactLen()
+	} else {
+		n = int(r.int32())
+	}
+	if n < -1 {
+		r.malformed = true
+		return 0
+	}
+	return n
+}
+
+// taggedFields skips the tagged fields of a flexible version structure
+func (r *reader) taggedFields() {
+	if !r.flexible {
+		return
+	}
+	n := r.uvarint()
+	for i := uint64(0); i < n && r.ok(); i++ {
+		r.uvarint() // tag
+		r.skip(int(r.uvarint()))
+	}
+}
+
+// topic reads a topic name and appends it to the request topics
+func (r *reader) topic(req *Request) bool {
+	name := r.string()
+	if !r.ok() {
+		return false
+	}
+	if !isValidTopicName(name) {
+		r.malformed = true
+		return false
+	}
+	if len(req.Topics) == maxTopics {
+		return false
+	}
+	req.Topics = append(req.Topics, name)
+	return true
+}
+
+// isValidTopicName reports whether name is a topic name accepted by brokers
+func isValidTopicName(name string) bool {
+	if len(name) == 0 || len(name) > maxTopicNameLen {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func isPrintable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
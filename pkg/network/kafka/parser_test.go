@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadPayload loads a request payload from testdata. Each file holds a single
+// hex encoded request, as written on the wire by a client. All of them were
+// captured from a Go client, except for produce v9 and fetch v12 which that
+// client doesn't support and were encoded following the protocol specification.
+func loadPayload(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	b, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	require.NoError(t, err)
+	return b
+}
+
+func TestParseRequest(t *testing.T) {
+	topics := []string{"orders", "payments.eu-1"}
+	for _, tt := range []struct {
+		apiKey  APIKey
+		version uint16
+	}{
+		{ProduceAPIKey, 2},
+		{ProduceAPIKey, 3},
+		{ProduceAPIKey, 7},
+		{ProduceAPIKey, 8},
+		{ProduceAPIKey, 9},
+		{FetchAPIKey, 0},
+		{FetchAPIKey, 4},
+		{FetchAPIKey, 7},
+		{FetchAPIKey, 11},
+		{FetchAPIKey, 12},
+	} {
+		name := fmt.Sprintf("%s_v%d.hex", tt.apiKey, tt.version)
+		t.Run(name, func(t *testing.T) {
+			payload := loadPayload(t, name)
+			req, err := ParseRequest(payload)
+			require.NoError(t, err)
+			assert.Equal(t, &Request{
+				APIKey:        tt.apiKey,
+				APIVersion:    tt.version,
+				CorrelationID: int32(tt.version) + 10,
+				ClientID:      "kgo-client",
+				Topics:        topics,
+			}, req)
+
+			// bytes following the request belong to the next one
+			req, err = ParseRequest(append(payload, payload...))
+			require.NoError(t, err)
+			assert.Equal(t, topics, req.Topics)
+		})
+	}
+}
+
+func TestParseRequestTruncated(t *testing.T) {
+	payload := loadPayload(t, "produce_v3.hex")
+	first := bytes.Index(payload, []byte("orders")) + len("orders")
+	second := bytes.Index(payload, []byte("payments.eu-1")) + len("payments.eu-1")
+
+	for n := 0; n <= len(payload); n++ {
+		req, err := ParseRequest(payload[:n])
+		switch {
+		case n < first:
+			assert.Error(t, err, n)
+		case n < second:
+			require.NoError(t, err, n)
+			assert.Equal(t, []string{"orders"}, req.Topics, n)
+		default:
+			require.NoError(t, err, n)
+			assert.Equal(t, []string{"orders", "payments.eu-1"}, req.Topics, n)
+		}
+	}
+}
+
+func TestParseRequestErrors(t *testing.T) {
+	header := func(apiKey, version int16) []byte {
+		b := make([]byte, 12)
+		binary.BigEndian.PutUint32(b, 100)
+		binary.BigEndian.PutUint16(b[4:], uint16(apiKey))
+		binary.BigEndian.PutUint16(b[6:], uint16(version))
+		return b
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		for _, b := range [][]byte{
+			header(3, 9),  // metadata
+			header(18, 3), // api versions
+			header(0, maxProduceVersion+1),
+			header(1, maxFetchVersion+1),
+		} {
+			_, err := ParseRequest(b)
+			assert.Equal(t, ErrUnsupportedRequest, err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		valid := loadPayload(t, "fetch_v4.hex")
+		withClientID := func(id string) []byte {
+			b := append(header(1, 4), 0, byte(len(id)))
+			return append(b, id...)
+		}
+		invalidTopic := bytes.Replace(valid, []byte("orders"), []byte("ord/rs"), 1)
+
+		for name, b := range map[string][]byte{
+			"empty":         nil,
+			"short":         valid[:headerLen-1],
+			"negative-size": append([]byte{0xff, 0xff, 0xff, 0xff}, valid[4:]...),
+			"negative-key":  append(header(-1, 0), valid[headerLen:]...),
+			"client-id":     withClientID("kgo\x00client"),
+			"invalid-topic": invalidTopic,
+			"no-topic":      withClientID("kgo-client"),
+			// fixed fields followed by a topics array of length -2
+			"negative-array": append(append(withClientID("kgo-client"), make([]byte, 17)...), 0xff, 0xff, 0xff, 0xfe),
+		} {
+			_, err := ParseRequest(b)
+			assert.Equal(t, errMalformed, err, name)
+		}
+	})
+
+	t.Run("no-topics", func(t *testing.T) {
+		// incremental fetch sessions may not send any topic
+		b := append(header(1, 7), 0, 0)
+		// fixed fields followed by an empty topics array
+		b = append(b, make([]byte, 4*7+1)...)
+		binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+
+		req, err := ParseRequest(b)
+		require.NoError(t, err)
+		assert.Empty(t, req.Topics)
+	})
+}
+
+func TestIsValidTopicName(t *testing.T) {
+	assert.True(t, isValidTopicName("orders"))
+	assert.True(t, isValidTopicName("__consumer_offsets"))
+	assert.True(t, isValidTopicName("payments.EU-1"))
+	assert.False(t, isValidTopicName(""))
+	assert.False(t, isValidTopicName("orders/eu"))
+	assert.False(t, isValidTopicName(string(make([]byte, maxTopicNameLen+1))))
+}
@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package kafka
+
+import (
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// StatKeeper aggregates the produce and fetch requests sent on multiple
+// connections by topic.
+//
+// StatKeeper is not safe for concurrent use.
+type StatKeeper struct {
+	stats      map[Key]*RequestStat
+	maxEntries int
+
+	// map containing interned topic names
+	// this is rotated with the stats map
+	interned map[string]string
+
+	hits, malformed, skipped, dropped atomic.Int64
+}
+
+// NewStatKeeper returns a new StatKeeper.
+func NewStatKeeper(c *config.Config) *StatKeeper {
+	return &StatKeeper{
+		stats:      make(map[Key]*RequestStat),
+		maxEntries: c.MaxKafkaStatsBuffered,
+		interned:   make(map[string]string),
+	}
+}
+
+// Process decodes the request at the start of payload, sent on the connection
+// identified by tup whose source must be the client, and records it once per
+// topic.
+func (s *StatKeeper) Process(tup http.KeyTuple, payload []byte) {
+	req, err := ParseRequest(payload)
+	if err == ErrUnsupportedRequest {
+		s.skipped.Inc()
+		return
+	}
+	if err != nil {
+		// this includes the segments following the first one of large requests
+		s.malformed.Inc()
+		return
+	}
+
+	s.hits.Inc()
+	for _, topic := range req.Topics {
+		key := Key{
+			KeyTuple:       tup,
+			TopicName:      s.intern(topic),
+			RequestAPIKey:  req.APIKey,
+			RequestVersion: req.APIVersion,
+		}
+		stats, ok := s.stats[key]
+		if !ok {
+			if len(s.stats) >= s.maxEntries {
+				s.dropped.Inc()
+				continue
+			}
+			stats = new(RequestStat)
+			s.stats[key] = stats
+		}
+		stats.Count++
+	}
+}
+
+// GetAndResetAllStats returns the stats aggregated since the previous call.
+func (s *StatKeeper) GetAndResetAllStats() map[Key]*RequestStat {
+	ret := s.stats // No deep copy needed since `s.stats` gets reset
+	s.stats = make(map[Key]*RequestStat)
+	s.interned = make(map[string]string)
+	return ret
+}
+
+// GetStats returns telemetry about the processed requests.
+func (s *StatKeeper) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"hits":      s.hits.Load(),
+		"malformed": s.malformed.Load(),
+		"skipped":   s.skipped.Load(),
+		"dropped":   s.dropped.Load(),
+	}
+}
+
+func (s *StatKeeper) intern(topic string) string {
+	v, ok := s.interned[topic]
+	if !ok {
+		v = topic
+		s.interned[v] = v
+	}
+	return v
+}
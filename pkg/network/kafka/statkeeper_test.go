@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+func TestStatKeeper(t *testing.T) {
+	tup := http.KeyTuple{SrcIPLow: 1, SrcPort: 45678, DstIPLow: 2, DstPort: 9092}
+	key := func(topic string, apiKey APIKey, version uint16) Key {
+		return Key{KeyTuple: tup, TopicName: topic, RequestAPIKey: apiKey, RequestVersion: version}
+	}
+
+	t.Run("aggregation", func(t *testing.T) {
+		s := NewStatKeeper(&config.Config{MaxKafkaStatsBuffered: 10})
+		produce := loadPayload(t, "produce_v8.hex")
+		fetch := loadPayload(t, "fetch_v11.hex")
+		s.Process(tup, produce)
+		s.Process(tup, produce)
+		s.Process(tup, fetch)
+
+		stats := s.GetAndResetAllStats()
+		require.Len(t, stats, 4)
+		assert.Equal(t, 2, stats[key("orders", ProduceAPIKey, 8)].Count)
+		assert.Equal(t, 2, stats[key("payments.eu-1", ProduceAPIKey, 8)].Count)
+		assert.Equal(t, 1, stats[key("orders", FetchAPIKey, 11)].Count)
+		assert.Equal(t, 1, stats[key("payments.eu-1", FetchAPIKey, 11)].Count)
+		assert.EqualValues(t, 3, s.GetStats()["hits"])
+		assert.Empty(t, s.GetAndResetAllStats())
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		s := NewStatKeeper(&config.Config{MaxKafkaStatsBuffered: 1})
+		s.Process(tup, loadPayload(t, "produce_v3.hex"))
+
+		stats := s.GetAndResetAllStats()
+		require.Len(t, stats, 1)
+		assert.Contains(t, stats, key("orders", ProduceAPIKey, 3))
+		assert.EqualValues(t, 1, s.GetStats()["dropped"])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		s := NewStatKeeper(&config.Config{MaxKafkaStatsBuffered: 10})
+		produce := loadPayload(t, "produce_v7.hex")
+		// a segment following the first one of a request
+		s.Process(tup, produce[len(produce)/2:])
+		// a metadata request
+		s.Process(tup, []byte{0, 0, 0, 10, 0, 3, 0, 9, 0, 0, 0, 1, 0xff, 0xff})
+
+		assert.Empty(t, s.GetAndResetAllStats())
+		assert.EqualValues(t, 1, s.GetStats()["malformed"])
+		assert.EqualValues(t, 1, s.GetStats()["skipped"])
+	})
+}
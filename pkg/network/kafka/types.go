@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// APIKey identifies the type of a Kafka request
+type APIKey uint16
+
+const (
+	// ProduceAPIKey is the API key of produce requests
+	ProduceAPIKey APIKey = 0
+	// FetchAPIKey is the API key of fetch requests
+	FetchAPIKey APIKey = 1
+)
+
+func (k APIKey) String() string {
+	switch k {
+	case ProduceAPIKey:
+		return "produce"
+	case FetchAPIKey:
+		return "fetch"
+	default:
+		return fmt.Sprintf("api_key_%d", uint16(k))
+	}
+}
+
+// Key is an identifier for a group of Kafka requests
+type Key struct {
+	// this field order is intentional to help the GC pointer tracking
+	TopicName string
+	http.KeyTuple
+	RequestAPIKey  APIKey
+	RequestVersion uint16
+}
+
+// NewKey generates a new Key
+func NewKey(saddr, daddr util.Address, sport, dport uint16, topicName string, requestAPIKey APIKey, requestVersion uint16) Key {
+	return Key{
+		KeyTuple:       http.NewKeyTuple(saddr, daddr, sport, dport),
+		TopicName:      topicName,
+		RequestAPIKey:  requestAPIKey,
+		RequestVersion: requestVersion,
+	}
+}
+
+// RequestStat stores stats for Kafka requests to a particular topic
+type RequestStat struct {
+	// Count is the number of requests seen
+	Count int
+}
+
+// CombineWith merges the data in 2 RequestStat objects
+// newStats is kept as it is, while the method receiver gets mutated
+func (r *RequestStat) CombineWith(newStats *RequestStat) {
+	r.Count += newStats.Count
+}
+
+// Aggregation holds the number of Kafka requests of a given type and version sent
+// to a topic over a connection. It is the form under which the Kafka stats are
+// exposed by system-probe to its clients.
+type Aggregation struct {
+	// Client and Server are the "address:port" endpoints of the connection
+	Client     string `json:"client"`
+	Server     string `json:"server"`
+	Topic      string `json:"topic"`
+	API        string `json:"api"`
+	APIVersion uint16 `json:"api_version"`
+	Count      int    `json:"count"`
+}
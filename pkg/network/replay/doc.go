@@ -0,0 +1,9 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package replay runs recorded network traffic through the userspace parsers of
+// the network tracer, without requiring any privilege. It's meant for debugging
+// and for deterministic regression tests.
+package replay
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package replay
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+)
+
+// Result holds the stats computed from a capture file
+type Result struct {
+	DNS   dns.StatsByKeyByNameByType
+	HTTP  map[http.Key]*http.RequestStats
+	Kafka map[kafka.Key]*kafka.RequestStat
+
+	// Telemetry holds the telemetry of the DNS snooper and of the TCP decoders
+	Telemetry map[string]interface{}
+}
+
+// File replays the given pcap or pcapng capture file through the DNS parser, as
+// well as through the userspace HTTP/2 and Kafka decoders. HTTP/1.x traffic is
+// only decoded by eBPF programs, hence isn't part of the result.
+func File(cfg *config.Config, path string) (*Result, error) {
+	// each replay consumes its own source, as the snooper reads packets asynchronously
+	dnsSource, err := filter.NewPcapSource(path)
+	if err != nil {
+		return nil, err
+	}
+	dnsStats, dnsTelemetry, err := dns.Replay(cfg, dnsSource)
+	if err != nil {
+		return nil, err
+	}
+
+	tcpSource, err := filter.NewPcapSource(path)
+	if err != nil {
+		return nil, err
+	}
+	httpStats, kafkaStats, tcpTelemetry, err := replayTCP(cfg, tcpSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		DNS:   dnsStats,
+		HTTP:  httpStats,
+		Kafka: kafkaStats,
+		Telemetry: map[string]interface{}{
+			"dns": dnsTelemetry,
+			"tcp": tcpTelemetry,
+		},
+	}, nil
+}
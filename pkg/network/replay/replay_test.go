@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package replay
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestFile(t *testing.T) {
+	res, err := File(testConfig(), testCapture)
+	require.NoError(t, err)
+
+	var (
+		client = util.AddressFromString("10.0.0.1")
+		server = util.AddressFromString("10.0.0.53")
+	)
+	key := func(port uint16) dns.Key {
+		return dns.Key{ServerIP: server, ClientIP: client, ClientPort: port, Protocol: syscall.IPPROTO_UDP}
+	}
+
+	assert.Equal(t, dns.StatsByKeyByNameByType{
+		key(53123): {
+			dns.ToHostname("example.com"): {
+				dns.TypeA: {SuccessLatencySum: 2000, CountByRcode: map[uint32]uint32{0: 1}},
+			},
+		},
+		key(53124): {
+			dns.ToHostname("missing.example.com"): {
+				dns.TypeA: {FailureLatencySum: 5000, CountByRcode: map[uint32]uint32{3: 1}},
+			},
+		},
+	}, res.DNS)
+	assert.EqualValues(t, 2, res.Telemetry["dns"].(map[string]int64)["queries"])
+	assert.EqualValues(t, 1, res.Telemetry["dns"].(map[string]int64)["successes"])
+	assert.EqualValues(t, 1, res.Telemetry["dns"].(map[string]int64)["errors"])
+
+	assert.Len(t, res.HTTP, 2)
+	assert.Len(t, res.Kafka, 2)
+}
+
+func TestFileErrors(t *testing.T) {
+	_, err := File(testConfig(), "testdata/missing.pcap")
+	assert.Error(t, err)
+}
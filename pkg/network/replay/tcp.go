@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package replay
+
+import (
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/http/http2"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// flow identifies a TCP connection, regardless of the direction of its packets
+type flow struct {
+	lowAddr, highAddr util.Address
+	lowPort, highPort uint16
+}
+
+func newFlow(saddr, daddr util.Address, sport, dport uint16) flow {
+	if sport < dport || (sport == dport && saddr.String() < daddr.String()) {
+		return flow{lowAddr: saddr, highAddr: daddr, lowPort: sport, highPort: dport}
+	}
+	return flow{lowAddr: daddr, highAddr: saddr, lowPort: dport, highPort: sport}
+}
+
+type flowState struct {
+	// tuple has the client as source
+	tuple http.KeyTuple
+	// next expected sequence number, sent by the client and the server
+	clientSeq, serverSeq uint32
+	clientSynced         bool
+	serverSynced         bool
+	fins                 int
+	kafka                bool
+}
+
+// tcpReplayer feeds the payloads of the TCP segments of a capture to the
+// userspace HTTP/2 and Kafka decoders.
+//
+// Segments are expected in order: retransmissions are skipped but no reassembly
+// is attempted. The client of a connection is the sender of the SYN when the
+// handshake is part of the capture, the peer of the Kafka port otherwise, and
+// finally the first endpoint sending data.
+type tcpReplayer struct {
+	decoder *gopacket.DecodingLayerParser
+	layers  []gopacket.LayerType
+	ipv4    layers.IPv4
+	ipv6    layers.IPv6
+	tcp     layers.TCP
+	payload gopacket.Payload
+
+	kafkaPorts map[uint16]struct{}
+	flows      map[flow]*flowState
+
+	http2 *http2.StatKeeper
+	kafka *kafka.StatKeeper
+
+	decodingErrors, retransmits atomic.Int64
+}
+
+func newTCPReplayer(cfg *config.Config, packetType gopacket.LayerType) *tcpReplayer {
+	r := &tcpReplayer{
+		kafkaPorts: make(map[uint16]struct{}, len(cfg.KafkaPorts)),
+		flows:      make(map[flow]*flowState),
+		http2:      http2.NewStatKeeper(cfg),
+		kafka:      kafka.NewStatKeeper(cfg),
+	}
+	for _, port := range cfg.KafkaPorts {
+		r.kafkaPorts[port] = struct{}{}
+	}
+	r.decoder = gopacket.NewDecodingLayerParser(packetType, &layers.Ethernet{}, &r.ipv4, &r.ipv6, &r.tcp, &r.payload)
+	r.decoder.IgnoreUnsupported = true
+	return r
+}
+
+// replayTCP runs all the packets of the given capture through the userspace
+// HTTP/2 and Kafka decoders. The source is closed upon return.
+func replayTCP(cfg *config.Config, source *filter.PcapSource) (map[http.Key]*http.RequestStats, map[kafka.Key]*kafka.RequestStat, map[string]interface{}, error) {
+	defer source.Close()
+
+	r := newTCPReplayer(cfg, source.PacketType())
+	if err := source.VisitPackets(nil, r.processPacket); err != nil {
+		return nil, nil, nil, err
+	}
+	return r.http2.GetAndResetAllStats(), r.kafka.GetAndResetAllStats(), r.getStats(), nil
+}
+
+func (r *tcpReplayer) getStats() map[string]interface{} {
+	return map[string]interface{}{
+		"http2":           r.http2.GetStats(),
+		"kafka":           r.kafka.GetStats(),
+		"decoding_errors": r.decodingErrors.Load(),
+		"retransmits":     r.retransmits.Load(),
+		"open_flows":      len(r.flows),
+	}
+}
+
+func (r *tcpReplayer) processPacket(data []byte, ts time.Time) error {
+	if err := r.decoder.DecodeLayers(data, &r.layers); err != nil {
+		r.decodingErrors.Inc()
+		return nil
+	}
+
+	var saddr, daddr util.Address
+	var isTCP bool
+	for _, layer := range r.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			saddr, daddr = util.AddressFromNetIP(r.ipv4.SrcIP), util.AddressFromNetIP(r.ipv4.DstIP)
+		case layers.LayerTypeIPv6:
+			saddr, daddr = util.AddressFromNetIP(r.ipv6.SrcIP), util.AddressFromNetIP(r.ipv6.DstIP)
+		case layers.LayerTypeTCP:
+			isTCP = true
+		}
+	}
+	if !isTCP {
+		return nil
+	}
+
+	sport, dport := uint16(r.tcp.SrcPort), uint16(r.tcp.DstPort)
+	key := newFlow(saddr, daddr, sport, dport)
+	state, ok := r.flows[key]
+	if !ok {
+		fromClient := r.isClient(sport, dport)
+		if !fromClient && !r.tcp.SYN && len(r.tcp.Payload) == 0 {
+			// wait for the handshake or for data to tell which side is the client
+			return nil
+		}
+		state = &flowState{}
+		if fromClient {
+			state.tuple = http.NewKeyTuple(saddr, daddr, sport, dport)
+		} else {
+			state.tuple = http.NewKeyTuple(daddr, saddr, dport, sport)
+		}
+		_, state.kafka = r.kafkaPorts[state.tuple.DstPort]
+		r.flows[key] = state
+	}
+	fromClient := state.tuple == http.NewKeyTuple(saddr, daddr, sport, dport)
+
+	if r.tcp.RST {
+		r.closeFlow(key, state)
+		return nil
+	}
+	if len(r.tcp.Payload) > 0 && r.isRetransmit(state, fromClient) {
+		r.retransmits.Inc()
+	} else if len(r.tcp.Payload) > 0 {
+		if state.kafka {
+			if fromClient {
+				r.kafka.Process(state.tuple, r.tcp.Payload)
+			}
+		} else {
+			r.http2.Feed(state.tuple, fromClient, uint64(ts.UnixNano()), r.tcp.Seq, r.tcp.Payload)
+		}
+	}
+	if r.tcp.FIN {
+		if state.fins++; state.fins == 2 {
+			r.closeFlow(key, state)
+		}
+	}
+	return nil
+}
+
+// isClient tells whether the sender of the current segment, which starts a new
+// flow, is the client of the connection
+func (r *tcpReplayer) isClient(sport, dport uint16) bool {
+	if r.tcp.SYN {
+		return !r.tcp.ACK
+	}
+	if _, ok := r.kafkaPorts[sport]; ok {
+		return false
+	}
+	if _, ok := r.kafkaPorts[dport]; ok {
+		return true
+	}
+	return len(r.tcp.Payload) > 0
+}
+
+// isRetransmit tells whether the payload of the current segment has already been
+// seen, and records its end as the next expected sequence number otherwise
+func (r *tcpReplayer) isRetransmit(state *flowState, fromClient bool) bool {
+	next, synced := &state.serverSeq, &state.serverSynced
+	if fromClient {
+		next, synced = &state.clientSeq, &state.clientSynced
+	}
+	end := r.tcp.Seq + uint32(len(r.tcp.Payload))
+	if *synced && int32(end-*next) <= 0 {
+		return true
+	}
+	*next, *synced = end, true
+	return false
+}
+
+func (r *tcpReplayer) closeFlow(key flow, state *flowState) {
+	if !state.kafka {
+		r.http2.Close(state.tuple)
+	}
+	delete(r.flows, key)
+}
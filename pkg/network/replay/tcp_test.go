@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// capture.pcap holds, in this order:
+//   - a successful and a failed DNS query sent to 10.0.0.53 over UDP
+//   - a gRPC connection from 10.0.0.1:40000 to 10.0.0.2:8080, with its handshake and
+//     a retransmitted segment, holding the calls of http2/testdata/grpc.txt
+//   - a produce request from 10.0.0.1:40001 to a Kafka broker at 10.0.0.3:9092,
+//     captured after the handshake, holding kafka/testdata/produce_v8.hex
+var testCapture = filepath.Join("testdata", "capture.pcap")
+
+func testConfig() *config.Config {
+	return &config.Config{
+		CollectDNSStats:       true,
+		CollectDNSDomains:     true,
+		DNSTimeout:            config.New().DNSTimeout,
+		MaxDNSStats:           100,
+		MaxTrackedConnections: 100,
+		MaxHTTPStatsBuffered:  100,
+		KafkaPorts:            []uint16{9092},
+		MaxKafkaStatsBuffered: 100,
+	}
+}
+
+func TestReplayTCP(t *testing.T) {
+	src, err := filter.NewPcapSource(testCapture)
+	require.NoError(t, err)
+
+	httpStats, kafkaStats, telemetry, err := replayTCP(testConfig(), src)
+	require.NoError(t, err)
+
+	var (
+		client = util.AddressFromString("10.0.0.1")
+		server = util.AddressFromString("10.0.0.2")
+		broker = util.AddressFromString("10.0.0.3")
+	)
+
+	require.Len(t, httpStats, 2)
+	check := httpStats[http.NewKey(client, server, 40000, 8080, "/grpc.health.v1.Health/Check", true, http.MethodPost)]
+	require.NotNil(t, check)
+	assert.Equal(t, 1, check.Stats(200).Count)
+	assert.Equal(t, 1, check.Stats(400).Count)
+	missing := httpStats[http.NewKey(client, server, 40000, 8080, "/pkg.Missing/Method", true, http.MethodPost)]
+	require.NotNil(t, missing)
+	assert.Equal(t, 1, missing.Stats(500).Count)
+
+	assert.Equal(t, map[kafka.Key]*kafka.RequestStat{
+		kafka.NewKey(client, broker, 40001, 9092, "orders", kafka.ProduceAPIKey, 8):        {Count: 1},
+		kafka.NewKey(client, broker, 40001, 9092, "payments.eu-1", kafka.ProduceAPIKey, 8): {Count: 1},
+	}, kafkaStats)
+
+	assert.EqualValues(t, 1, telemetry["retransmits"])
+	assert.EqualValues(t, 0, telemetry["decoding_errors"])
+	// the Kafka connection isn't closed in the capture
+	assert.EqualValues(t, 1, telemetry["open_flows"])
+	assert.EqualValues(t, 0, telemetry["http2"].(map[string]interface{})["connections"])
+}
+
+func TestReplayTCPKafkaPorts(t *testing.T) {
+	src, err := filter.NewPcapSource(testCapture)
+	require.NoError(t, err)
+
+	cfg := testConfig()
+	cfg.KafkaPorts = nil
+	httpStats, kafkaStats, telemetry, err := replayTCP(cfg, src)
+	require.NoError(t, err)
+
+	// without the Kafka port, the produce request is handed to the HTTP/2
+	// decoder, which ignores the connection as it doesn't start with a preface
+	assert.Len(t, httpStats, 2)
+	assert.Empty(t, kafkaStats)
+	assert.EqualValues(t, 0, telemetry["http2"].(map[string]interface{})["connections"])
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
@@ -84,7 +85,7 @@ func BenchmarkConnectionsGet(b *testing.B) {
 			ns := newDefaultState()
 
 			// Initial fetch to set up client
-			ns.GetDelta(DEBUGCLIENT, latestTime.Load(), nil, nil, nil)
+			ns.GetDelta(DEBUGCLIENT, latestTime.Load(), nil, nil, nil, nil)
 
 			for _, c := range closed[:bench.closedCount] {
 				ns.StoreClosedConnections([]ConnectionStats{c})
@@ -94,7 +95,7 @@ func BenchmarkConnectionsGet(b *testing.B) {
 			b.ReportAllocs()
 
 			for n := 0; n < b.N; n++ {
-				ns.GetDelta(DEBUGCLIENT, latestTime.Load(), conns[:bench.connCount], nil, nil)
+				ns.GetDelta(DEBUGCLIENT, latestTime.Load(), conns[:bench.connCount], nil, nil, nil)
 			}
 		})
 	}
@@ -125,10 +126,10 @@ func TestRemoveConnections(t *testing.T) {
 
 	clientID := "1"
 	state := newDefaultState().(*networkState)
-	conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil).Conns
+	conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil, nil).Conns
 	assert.Equal(t, 0, len(conns))
 
-	conns = state.GetDelta(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+	conns = state.GetDelta(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 	assert.Equal(t, 1, len(conns))
 	assert.Equal(t, conn, conns[0])
 
@@ -170,7 +171,7 @@ func TestRetrieveClosedConnection(t *testing.T) {
 	t.Run("without prior registration", func(t *testing.T) {
 		state := newDefaultState()
 		state.StoreClosedConnections([]ConnectionStats{conn})
-		conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil, nil).Conns
 
 		assert.Equal(t, 0, len(conns))
 	})
@@ -182,16 +183,16 @@ func TestRetrieveClosedConnection(t *testing.T) {
 
 		state.StoreClosedConnections([]ConnectionStats{conn})
 
-		conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, conn, conns[0])
 
 		// An other client that is not registered should not have the closed connection
-		conns = state.GetDelta("2", latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta("2", latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		// It should no more have connections stored
-		conns = state.GetDelta(clientID, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientID, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 	})
 }
@@ -282,7 +283,7 @@ func TestNoPriorRegistrationActiveConnections(t *testing.T) {
 		},
 	}
 
-	delta := state.GetDelta(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil)
+	delta := state.GetDelta(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil)
 	require.NotEmpty(t, delta.Conns)
 	require.Equal(t, 1, len(delta.Conns))
 }
@@ -290,7 +291,7 @@ func TestNoPriorRegistrationActiveConnections(t *testing.T) {
 func TestCleanupClient(t *testing.T) {
 	clientID := "1"
 
-	state := NewState(100*time.Millisecond, 50000, 75000, 75000, 75000)
+	state := NewState(100*time.Millisecond, 50000, 75000, 75000, 75000, 75000)
 	clients := state.(*networkState).getClients()
 	assert.Equal(t, 0, len(clients))
 
@@ -358,15 +359,15 @@ func TestLastStats(t *testing.T) {
 	state.RegisterClient(client2)
 
 	// First get, we should not have any connections stored
-	conns := state.GetDelta(client1, latestEpochTime(), nil, nil, nil).Conns
+	conns := state.GetDelta(client1, latestEpochTime(), nil, nil, nil, nil).Conns
 	assert.Equal(t, 0, len(conns))
 
 	// Same for an other client
-	conns = state.GetDelta(client2, latestEpochTime(), nil, nil, nil).Conns
+	conns = state.GetDelta(client2, latestEpochTime(), nil, nil, nil, nil).Conns
 	assert.Equal(t, 0, len(conns))
 
 	// We should have only one connection but with last stats equal to monotonic
-	conns = state.GetDelta(client1, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+	conns = state.GetDelta(client1, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 	assert.Equal(t, 1, len(conns))
 	assert.Equal(t, conn.Monotonic[0].SentBytes, conns[0].Last.SentBytes)
 	assert.Equal(t, conn.Monotonic[0].RecvBytes, conns[0].Last.RecvBytes)
@@ -376,7 +377,7 @@ func TestLastStats(t *testing.T) {
 	assert.Equal(t, conn.Monotonic[0].Retransmits, conns[0].Monotonic[0].Retransmits)
 
 	// This client didn't collect the first connection so last stats = monotonic
-	conns = state.GetDelta(client2, latestEpochTime(), []ConnectionStats{conn2}, nil, nil).Conns
+	conns = state.GetDelta(client2, latestEpochTime(), []ConnectionStats{conn2}, nil, nil, nil).Conns
 	assert.Equal(t, 1, len(conns))
 	assert.Equal(t, conn2.Monotonic[0].SentBytes, conns[0].Last.SentBytes)
 	assert.Equal(t, conn2.Monotonic[0].RecvBytes, conns[0].Last.RecvBytes)
@@ -386,7 +387,7 @@ func TestLastStats(t *testing.T) {
 	assert.Equal(t, conn2.Monotonic[0].Retransmits, conns[0].Monotonic[0].Retransmits)
 
 	// client 1 should have conn3 - conn1 since it did not collected conn2
-	conns = state.GetDelta(client1, latestEpochTime(), []ConnectionStats{conn3}, nil, nil).Conns
+	conns = state.GetDelta(client1, latestEpochTime(), []ConnectionStats{conn3}, nil, nil, nil).Conns
 	assert.Equal(t, 1, len(conns))
 	assert.Equal(t, 2*dSent, conns[0].Last.SentBytes)
 	assert.Equal(t, 2*dRecv, conns[0].Last.RecvBytes)
@@ -396,7 +397,7 @@ func TestLastStats(t *testing.T) {
 	assert.Equal(t, conn3.Monotonic[0].Retransmits, conns[0].Monotonic[0].Retransmits)
 
 	// client 2 should have conn3 - conn2
-	conns = state.GetDelta(client2, latestEpochTime(), []ConnectionStats{conn3}, nil, nil).Conns
+	conns = state.GetDelta(client2, latestEpochTime(), []ConnectionStats{conn3}, nil, nil, nil).Conns
 	assert.Equal(t, 1, len(conns))
 	assert.Equal(t, dSent, conns[0].Last.SentBytes)
 	assert.Equal(t, dRecv, conns[0].Last.RecvBytes)
@@ -445,11 +446,11 @@ func TestLastStatsForClosedConnection(t *testing.T) {
 	state.RegisterClient(clientID)
 
 	// First get, we should not have any connections stored
-	conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil).Conns
+	conns := state.GetDelta(clientID, latestEpochTime(), nil, nil, nil, nil).Conns
 	assert.Equal(t, 0, len(conns))
 
 	// We should have one connection with last stats equal to monotonic stats
-	conns = state.GetDelta(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+	conns = state.GetDelta(clientID, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 	assert.Equal(t, 1, len(conns))
 	assert.Equal(t, conn.Monotonic[0].SentBytes, conns[0].Last.SentBytes)
 	assert.Equal(t, conn.Monotonic[0].RecvBytes, conns[0].Last.RecvBytes)
@@ -461,7 +462,7 @@ func TestLastStatsForClosedConnection(t *testing.T) {
 	state.StoreClosedConnections([]ConnectionStats{conn2})
 
 	// We should have one connection with last stats
-	conns = state.GetDelta(clientID, latestEpochTime(), nil, nil, nil).Conns
+	conns = state.GetDelta(clientID, latestEpochTime(), nil, nil, nil, nil).Conns
 
 	assert.Equal(t, 1, len(conns))
 	assert.Equal(t, dSent, conns[0].Last.SentBytes)
@@ -518,7 +519,7 @@ func TestRaceConditions(t *testing.T) {
 				case <-timer.C:
 					return
 				default:
-					state.GetDelta(c, latestEpochTime(), genConns(nConns), nil, nil)
+					state.GetDelta(c, latestEpochTime(), genConns(nConns), nil, nil, nil)
 				}
 			}
 		}(fmt.Sprintf("%d", i))
@@ -565,14 +566,14 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.RegisterClient(client)
 
 		// First get, we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		// Store the connection as closed
 		state.StoreClosedConnections([]ConnectionStats{conn})
 
 		// Second get, we should have monotonic and last stats = 3
-		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 3, int(conns[0].Last.SentBytes))
@@ -597,7 +598,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.RegisterClient(client)
 
 		// First get, we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		conn := conn.clone()
@@ -612,7 +613,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.StoreClosedConnections([]ConnectionStats{conn2})
 
 		// Second get, we should have monotonic and last stats = 8
-		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 8, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 8, int(conns[0].Last.SentBytes))
@@ -639,7 +640,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.RegisterClient(client)
 
 		// First get for client c, we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Len(t, conns, 0)
 
 		conn := ConnectionStats{
@@ -654,7 +655,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		}
 
 		// Simulate this connection starting
-		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 		require.Len(t, conns, 1)
 		assert.EqualValues(t, 1, conns[0].Last.SentBytes)
 		assert.EqualValues(t, 1, conns[0].MonotonicSum().SentBytes)
@@ -672,7 +673,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		conn2.Monotonic.Put(2, m)
 		conn2.LastUpdateEpoch = latestEpochTime()
 		// Retrieve the connections
-		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn2}, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn2}, nil, nil, nil).Conns
 		require.Len(t, conns, 1)
 		assert.EqualValues(t, uint64(2), conns[0].Last.SentBytes)
 		assert.EqualValues(t, uint64(3), conns[0].MonotonicSum().SentBytes)
@@ -683,7 +684,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		// Store the connection as closed
 		state.StoreClosedConnections([]ConnectionStats{conn2})
 
-		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		require.Len(t, conns, 1)
 		assert.EqualValues(t, 1, conns[0].Last.SentBytes)
 		assert.EqualValues(t, 2, conns[0].MonotonicSum().SentBytes)
@@ -710,7 +711,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.RegisterClient(client)
 
 		// First get, we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		conn := conn.clone()
@@ -726,7 +727,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs := []ConnectionStats{conn2}
 
 		// Second get, we should have monotonic and last stats = 5
-		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil, nil).Conns
 		require.Equal(t, 1, len(conns))
 		assert.Equal(t, 5, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 5, int(conns[0].Last.SentBytes))
@@ -746,7 +747,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs = []ConnectionStats{conn3}
 
 		// Third get, we should have monotonic = 6 and last stats = 4
-		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 6, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 4, int(conns[0].Last.SentBytes))
@@ -757,7 +758,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.StoreClosedConnections([]ConnectionStats{conn3})
 
 		// 4th get, we should have monotonic = 3 and last stats = 2
-		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 2, int(conns[0].Last.SentBytes))
@@ -782,7 +783,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.RegisterClient(client)
 
 		// First get we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		conn := conn.clone()
@@ -790,7 +791,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs := []ConnectionStats{conn}
 
 		// First get, we should have monotonic = 3 and last seen = 3
-		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 3, int(conns[0].Last.SentBytes))
@@ -800,7 +801,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.StoreClosedConnections([]ConnectionStats{conn})
 
 		// Second get, we should have monotonic = 8 and last stats = 5
-		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 8, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 5, int(conns[0].Last.SentBytes))
@@ -843,11 +844,11 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.RegisterClient(client)
 
 		// First get for client c, we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		// First get for client d, we should have nothing
-		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		// Store the connection as closed
@@ -855,7 +856,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.StoreClosedConnections([]ConnectionStats{conn})
 
 		// Second get for client d we should have monotonic and last stats = 3
-		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 3, int(conns[0].Last.SentBytes))
@@ -870,7 +871,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs := []ConnectionStats{conn2}
 
 		// Second get, for client c we should have monotonic and last stats = 5
-		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 5, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 5, int(conns[0].Last.SentBytes))
@@ -882,7 +883,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs = []ConnectionStats{conn2}
 
 		// Third get, for client d we should have monotonic = 3 and last stats = 3
-		conns = state.GetDelta(clientD, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 3, int(conns[0].Last.SentBytes))
@@ -902,7 +903,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs = []ConnectionStats{conn3}
 
 		// Third get, for client c, we should have monotonic = 6 and last stats = 4
-		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 6, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 4, int(conns[0].Last.SentBytes))
@@ -914,7 +915,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs = []ConnectionStats{conn3}
 
 		// 4th get, for client d, we should have monotonic = 7 and last stats = 4
-		conns = state.GetDelta(clientD, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 7, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 4, int(conns[0].Last.SentBytes))
@@ -926,13 +927,13 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.StoreClosedConnections([]ConnectionStats{conn3})
 
 		// 4th get, for client c we should have monotonic = 3 and last stats = 2
-		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 2, int(conns[0].Last.SentBytes))
 
 		// 5th get, for client d we should have monotonic = 3 and last stats = 1
-		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 1, int(conns[0].Last.SentBytes))
@@ -985,15 +986,15 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.RegisterClient(clientE)
 
 		// First get for client c, we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		// First get for client d, we should have nothing
-		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		// First get for client e, we should have nothing
-		conns = state.GetDelta(clientE, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientE, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		// Store the connection
@@ -1005,7 +1006,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs := []ConnectionStats{conn}
 
 		// Second get for client e we should have monotonic and last stats = 2
-		conns = state.GetDelta(clientE, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(clientE, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 2, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 2, int(conns[0].Last.SentBytes))
@@ -1017,13 +1018,13 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.StoreClosedConnections([]ConnectionStats{conn})
 
 		// Second get for client d we should have monotonic and last stats = 3
-		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 3, int(conns[0].Last.SentBytes))
 
 		// Third get for client e we should have monotonic = 3and last stats = 1
-		conns = state.GetDelta(clientE, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientE, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 1, int(conns[0].Last.SentBytes))
@@ -1037,7 +1038,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs = []ConnectionStats{conn2}
 
 		// Second get, for client c we should have monotonic and last stats = 5
-		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 5, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 5, int(conns[0].Last.SentBytes))
@@ -1049,7 +1050,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		cs = []ConnectionStats{conn2}
 
 		// Third get, for client d we should have monotonic = 3 and last stats = 3
-		conns = state.GetDelta(clientD, latestEpochTime(), cs, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), cs, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 3, int(conns[0].Last.SentBytes))
@@ -1061,7 +1062,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state.StoreClosedConnections([]ConnectionStats{conn2})
 
 		// 4th get, for client e we should have monotonic = 5 and last stats = 5
-		conns = state.GetDelta(clientE, latestEpochTime(), nil, nil, nil).Conns
+		conns = state.GetDelta(clientE, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 1, len(conns))
 		assert.Equal(t, 5, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 5, int(conns[0].Last.SentBytes))
@@ -1097,12 +1098,12 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		state := newDefaultState()
 
 		// First get for client c, we should have nothing
-		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+		conns := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 		assert.Equal(t, 0, len(conns))
 
 		conn := conn.clone()
 		// Second get for client c we should have monotonic and last stats = 3
-		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 		assert.Len(t, conns, 1)
 		assert.Equal(t, 3, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 3, int(conns[0].Last.SentBytes))
@@ -1114,7 +1115,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		conn2.LastUpdateEpoch++
 
 		// First get for client d we should have monotonic = 4 and last bytes = 4
-		conns = state.GetDelta(clientD, latestEpochTime(), []ConnectionStats{conn2}, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), []ConnectionStats{conn2}, nil, nil, nil).Conns
 		assert.Len(t, conns, 1)
 		assert.Equal(t, 4, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 4, int(conns[0].Last.SentBytes))
@@ -1125,7 +1126,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		conn3.LastUpdateEpoch++
 
 		// Third get for client c we should have monotonic = 7 and last bytes = 4
-		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn3}, nil, nil).Conns
+		conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn3}, nil, nil, nil).Conns
 		assert.Len(t, conns, 1)
 		assert.Equal(t, 7, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 4, int(conns[0].Last.SentBytes))
@@ -1136,7 +1137,7 @@ func TestSameKeyEdgeCases(t *testing.T) {
 		conn4.LastUpdateEpoch++
 
 		// Second get for client d we should have monotonic = 9 and last bytes = 5
-		conns = state.GetDelta(clientD, latestEpochTime(), []ConnectionStats{conn4}, nil, nil).Conns
+		conns = state.GetDelta(clientD, latestEpochTime(), []ConnectionStats{conn4}, nil, nil, nil).Conns
 		assert.Len(t, conns, 1)
 		assert.Equal(t, 9, int(conns[0].MonotonicSum().SentBytes))
 		assert.Equal(t, 5, int(conns[0].Last.SentBytes))
@@ -1162,7 +1163,7 @@ func TestStatsResetOnUnderflow(t *testing.T) {
 	state.RegisterClient(client)
 
 	// Get the connections once to register stats
-	conns := state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+	conns := state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 	require.Len(t, conns, 1)
 
 	// Expect LastStats to be 3
@@ -1174,7 +1175,7 @@ func TestStatsResetOnUnderflow(t *testing.T) {
 	m.SentBytes--
 	conn.Monotonic.Put(0, m.StatCounters)
 
-	conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+	conns = state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 	require.Len(t, conns, 1)
 	expected := conn
 	expected.Last.SentBytes = 0
@@ -1218,12 +1219,12 @@ func TestDoubleCloseOnTwoClients(t *testing.T) {
 
 	expectedConn.LastUpdateEpoch = conn.LastUpdateEpoch
 	// Get the connections for client1 we should have only one with stats = 2*conn
-	conns := state.GetDelta(client1, latestEpochTime(), nil, nil, nil).Conns
+	conns := state.GetDelta(client1, latestEpochTime(), nil, nil, nil, nil).Conns
 	require.Len(t, conns, 1)
 	assert.Equal(t, expectedConn, conns[0])
 
 	// Same for client2
-	conns = state.GetDelta(client2, latestEpochTime(), nil, nil, nil).Conns
+	conns = state.GetDelta(client2, latestEpochTime(), nil, nil, nil, nil).Conns
 	require.Len(t, conns, 1)
 	assert.Equal(t, expectedConn, conns[0])
 }
@@ -1258,15 +1259,15 @@ func TestUnorderedCloseEvent(t *testing.T) {
 	m.RecvBytes = 0
 	conn.Monotonic = make(StatCountersByCookie, 0)
 	conn.Monotonic.Put(0, m)
-	conns := state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil).Conns
+	conns := state.GetDelta(client, latestEpochTime(), []ConnectionStats{conn}, nil, nil, nil).Conns
 	require.Len(t, conns, 1)
 	assert.EqualValues(t, 4, conns[0].Last.SentBytes)
 	assert.EqualValues(t, 1, conns[0].Last.RecvBytes)
 
 	// Simulate some other gets
-	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns, 0)
-	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns, 0)
-	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns, 0)
+	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns, 0, nil)
+	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns, 0, nil)
+	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns, 0, nil)
 
 	// Simulate having the connection getting active again
 	conn.LastUpdateEpoch = latestEpochTime()
@@ -1274,7 +1275,7 @@ func TestUnorderedCloseEvent(t *testing.T) {
 	conn.Monotonic.Put(0, m)
 	state.StoreClosedConnections([]ConnectionStats{conn})
 
-	conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns
+	conns = state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns
 	require.Len(t, conns, 1)
 	assert.EqualValues(t, 2, conns[0].Last.SentBytes)
 	assert.EqualValues(t, 0, conns[0].Last.RecvBytes)
@@ -1282,7 +1283,7 @@ func TestUnorderedCloseEvent(t *testing.T) {
 	// Ensure we don't have underflows / unordered conns
 	assert.Zero(t, state.(*networkState).telemetry.statsUnderflows)
 
-	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil).Conns, 0)
+	assert.Len(t, state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil).Conns, 0, nil)
 }
 
 func TestAggregateClosedConnectionsTimestamp(t *testing.T) {
@@ -1311,7 +1312,7 @@ func TestAggregateClosedConnectionsTimestamp(t *testing.T) {
 	state.StoreClosedConnections([]ConnectionStats{conn})
 
 	// Make sure the connections we get has the latest timestamp
-	delta := state.GetDelta(client, latestEpochTime(), nil, nil, nil)
+	delta := state.GetDelta(client, latestEpochTime(), nil, nil, nil, nil)
 	assert.Equal(t, conn.LastUpdateEpoch, delta.Conns[0].LastUpdateEpoch)
 }
 
@@ -1364,26 +1365,26 @@ func TestDNSStatsWithMultipleClients(t *testing.T) {
 	state.RegisterClient(client2)
 
 	// We should have nothing on first call
-	assert.Len(t, state.GetDelta(client1, latestEpochTime(), nil, nil, nil).Conns, 0)
-	assert.Len(t, state.GetDelta(client2, latestEpochTime(), nil, nil, nil).Conns, 0)
+	assert.Len(t, state.GetDelta(client1, latestEpochTime(), nil, nil, nil, nil).Conns, 0, nil)
+	assert.Len(t, state.GetDelta(client2, latestEpochTime(), nil, nil, nil, nil).Conns, 0, nil)
 
 	c.LastUpdateEpoch = latestEpochTime()
 
-	delta := state.GetDelta(client1, latestEpochTime(), []ConnectionStats{c}, getStats(), nil)
+	delta := state.GetDelta(client1, latestEpochTime(), []ConnectionStats{c}, getStats(), nil, nil)
 	require.Len(t, delta.Conns, 1)
 
 	rcode := getRCodeFrom(delta, delta.Conns[0], "foo.com", dns.TypeA, DNSResponseCodeNoError)
 	assert.EqualValues(t, 1, rcode)
 
 	// Register the third client but also pass in dns stats
-	delta = state.GetDelta(client3, latestEpochTime(), []ConnectionStats{c}, getStats(), nil)
+	delta = state.GetDelta(client3, latestEpochTime(), []ConnectionStats{c}, getStats(), nil, nil)
 	require.Len(t, delta.Conns, 1)
 
 	// DNS stats should be available for the new client
 	rcode = getRCodeFrom(delta, delta.Conns[0], "foo.com", dns.TypeA, DNSResponseCodeNoError)
 	assert.EqualValues(t, 1, rcode)
 
-	delta = state.GetDelta(client2, latestEpochTime(), []ConnectionStats{c}, getStats(), nil)
+	delta = state.GetDelta(client2, latestEpochTime(), []ConnectionStats{c}, getStats(), nil, nil)
 	require.Len(t, delta.Conns, 1)
 
 	// 2nd client should get accumulated stats
@@ -1407,13 +1408,13 @@ func TestHTTPStats(t *testing.T) {
 
 	// Register client & pass in HTTP stats
 	state := newDefaultState()
-	delta := state.GetDelta("client", latestEpochTime(), []ConnectionStats{c}, nil, httpStats)
+	delta := state.GetDelta("client", latestEpochTime(), []ConnectionStats{c}, nil, httpStats, nil)
 
 	// Verify connection has HTTP data embedded in it
 	assert.Len(t, delta.HTTP, 1)
 
 	// Verify HTTP data has been flushed
-	delta = state.GetDelta("client", latestEpochTime(), []ConnectionStats{c}, nil, nil)
+	delta = state.GetDelta("client", latestEpochTime(), []ConnectionStats{c}, nil, nil, nil)
 	assert.Len(t, delta.HTTP, 0)
 }
 
@@ -1443,40 +1444,92 @@ func TestHTTPStatsWithMultipleClients(t *testing.T) {
 	state.RegisterClient(client2)
 
 	// We should have nothing on first call
-	assert.Len(t, state.GetDelta(client1, latestEpochTime(), nil, nil, nil).HTTP, 0)
-	assert.Len(t, state.GetDelta(client2, latestEpochTime(), nil, nil, nil).HTTP, 0)
+	assert.Len(t, state.GetDelta(client1, latestEpochTime(), nil, nil, nil, nil).HTTP, 0, nil)
+	assert.Len(t, state.GetDelta(client2, latestEpochTime(), nil, nil, nil, nil).HTTP, 0, nil)
 
 	// Store the connection to both clients & pass HTTP stats to the first client
 	c.LastUpdateEpoch = latestEpochTime()
 	state.StoreClosedConnections([]ConnectionStats{c})
 
-	delta := state.GetDelta(client1, latestEpochTime(), nil, nil, getStats("/testpath"))
+	delta := state.GetDelta(client1, latestEpochTime(), nil, nil, getStats("/testpath"), nil)
 	assert.Len(t, delta.HTTP, 1)
 
 	// Verify that the HTTP stats were also stored in the second client
-	delta = state.GetDelta(client2, latestEpochTime(), nil, nil, nil)
+	delta = state.GetDelta(client2, latestEpochTime(), nil, nil, nil, nil)
 	assert.Len(t, delta.HTTP, 1)
 
 	// Register a third client & verify that it does not have the HTTP stats
-	delta = state.GetDelta(client3, latestEpochTime(), []ConnectionStats{c}, nil, nil)
+	delta = state.GetDelta(client3, latestEpochTime(), []ConnectionStats{c}, nil, nil, nil)
 	assert.Len(t, delta.HTTP, 0)
 
 	c.LastUpdateEpoch = latestEpochTime()
 	state.StoreClosedConnections([]ConnectionStats{c})
 
 	// Pass in new HTTP stats to the first client
-	delta = state.GetDelta(client1, latestEpochTime(), nil, nil, getStats("/testpath2"))
+	delta = state.GetDelta(client1, latestEpochTime(), nil, nil, getStats("/testpath2"), nil)
 	assert.Len(t, delta.HTTP, 1)
 
 	// And the second client
-	delta = state.GetDelta(client2, latestEpochTime(), nil, nil, getStats("/testpath3"))
+	delta = state.GetDelta(client2, latestEpochTime(), nil, nil, getStats("/testpath3"), nil)
 	assert.Len(t, delta.HTTP, 2)
 
 	// Verify that the third client also accumulated both new HTTP stats
-	delta = state.GetDelta(client3, latestEpochTime(), nil, nil, nil)
+	delta = state.GetDelta(client3, latestEpochTime(), nil, nil, nil, nil)
 	assert.Len(t, delta.HTTP, 2)
 }
 
+func TestKafkaStats(t *testing.T) {
+	c := ConnectionStats{
+		Source: util.AddressFromString("1.1.1.1"),
+		Dest:   util.AddressFromString("0.0.0.0"),
+		SPort:  1000,
+		DPort:  9092,
+	}
+
+	getStats := func(topic string, count int) map[kafka.Key]*kafka.RequestStat {
+		key := kafka.NewKey(c.Source, c.Dest, c.SPort, c.DPort, topic, kafka.ProduceAPIKey, 8)
+		return map[kafka.Key]*kafka.RequestStat{key: {Count: count}}
+	}
+
+	client1 := "client1"
+	client2 := "client2"
+	state := newDefaultState()
+	state.RegisterClient(client1)
+	state.RegisterClient(client2)
+
+	// Pass Kafka stats to the first client
+	delta := state.GetDelta(client1, latestEpochTime(), []ConnectionStats{c}, nil, nil, getStats("orders", 2))
+	require.Len(t, delta.Kafka, 1)
+
+	// Verify Kafka data has been flushed
+	delta = state.GetDelta(client1, latestEpochTime(), []ConnectionStats{c}, nil, nil, nil)
+	assert.Len(t, delta.Kafka, 0)
+
+	// The second client accumulates all stats
+	delta = state.GetDelta(client2, latestEpochTime(), []ConnectionStats{c}, nil, nil, getStats("orders", 3))
+	require.Len(t, delta.Kafka, 1)
+	key := kafka.NewKey(c.Source, c.Dest, c.SPort, c.DPort, "orders", kafka.ProduceAPIKey, 8)
+	assert.Equal(t, 5, delta.Kafka[key].Count)
+}
+
+func TestKafkaStatsDropped(t *testing.T) {
+	state := NewState(2*time.Minute, 50000, 75000, 75000, 7500, 1)
+	client1 := "client1"
+	client2 := "client2"
+	state.RegisterClient(client1)
+	state.RegisterClient(client2)
+
+	stats := make(map[kafka.Key]*kafka.RequestStat)
+	for _, topic := range []string{"orders", "payments"} {
+		key := kafka.NewKey(util.AddressFromString("1.1.1.1"), util.AddressFromString("0.0.0.0"), 1000, 9092, topic, kafka.FetchAPIKey, 11)
+		stats[key] = &kafka.RequestStat{Count: 1}
+	}
+
+	state.GetDelta(client1, latestEpochTime(), nil, nil, nil, stats)
+	assert.Len(t, state.GetDelta(client2, latestEpochTime(), nil, nil, nil, nil).Kafka, 1)
+	assert.EqualValues(t, 2, state.(*networkState).telemetry.kafkaStatsDropped)
+}
+
 func TestDetermineConnectionIntraHost(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -1691,7 +1744,7 @@ func latestEpochTime() uint64 {
 
 func newDefaultState() State {
 	// Using values from ebpf.NewConfig()
-	return NewState(2*time.Minute, 50000, 75000, 75000, 7500)
+	return NewState(2*time.Minute, 50000, 75000, 75000, 7500, 7500)
 }
 
 func getIPProtocol(nt ConnectionType) uint8 {
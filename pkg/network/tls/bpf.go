@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package tls
+
+import (
+	"fmt"
+
+	"golang.org/x/net/bpf"
+)
+
+// maxPorts bounds the number of ports of the filter, so that its jumps fit in a byte
+const maxPorts = 32
+
+// snapLen is the number of bytes captured from a segment. It's enough for the
+// headers and the hello messages, as well as for the leaf certificate when it
+// follows the ServerHello within the same segment, and it fits in a frame of
+// the packet source.
+const snapLen = 3072
+
+// generateBPFFilter returns a classic BPF filter capturing the TCP segments sent
+// from or to one of the given ports whose payload starts with a TLS handshake
+// record. Both directions are needed, as the client and the server each send
+// part of the handshake. Only the start of the segments is captured, and the
+// encrypted traffic that follows the handshake is dropped in the kernel.
+func generateBPFFilter(ports []uint16) ([]bpf.RawInstruction, error) {
+	if len(ports) == 0 || len(ports) > maxPorts {
+		return nil, fmt.Errorf("between 1 and %d tls ports must be configured, got %d", maxPorts, len(ports))
+	}
+
+	n := len(ports)
+	ipv6Payload := 5 + n + 1 + n + 1
+	ipv4 := ipv6Payload + 7
+	ipv4Payload := ipv4 + 7 + n + 1 + n + 1
+	record := ipv4Payload + 7
+	capture := record + 3
+	drop := capture + 1
+
+	var insts []bpf.Instruction
+	// skip returns the offset of a jump from the next instruction to the one at index to
+	skip := func(to int) uint8 {
+		return uint8(to - len(insts) - 1)
+	}
+	// checkPorts jumps to the instruction at index to if the loaded port is one of ports
+	checkPorts := func(to int) {
+		for _, port := range ports {
+			insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port), SkipTrue: skip(to)})
+		}
+	}
+
+	// (000) ldh [12] -- load Ethertype
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 12})
+	// (001) jeq #0x86dd -- if IPv6, go next, else check IPv4
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x86dd, SkipFalse: skip(ipv4)})
+	// (002) ldb [20] -- load IPv6 Next Header
+	insts = append(insts, bpf.LoadAbsolute{Size: 1, Off: 20})
+	// (003) jeq #0x6 -- if TCP, go next, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x6, SkipFalse: skip(drop)})
+	// (004) ldh [54] -- load source port
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 54})
+	// (005) jeq #port -- for each port, check the payload if equal
+	checkPorts(ipv6Payload)
+	// ldh [56] -- load dest port
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 56})
+	// jeq #port -- for each port, check the payload if equal; then drop
+	checkPorts(ipv6Payload)
+	insts = append(insts, bpf.Jump{Skip: uint32(skip(drop))})
+
+	// ldb [66] -- load TCP Data Offset
+	insts = append(insts, bpf.LoadAbsolute{Size: 1, Off: 66})
+	// rsh #2, and #0x3c -- a = TCP header length
+	insts = append(insts, bpf.ALUOpConstant{Op: bpf.ALUOpShiftRight, Val: 2})
+	insts = append(insts, bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0x3c})
+	// add #54 -- a = payload offset
+	insts = append(insts, bpf.ALUOpConstant{Op: bpf.ALUOpAdd, Val: 54})
+	// tax -- x = payload offset
+	insts = append(insts, bpf.TAX{})
+	// ldb [x] -- load record type, dropping segments without payload
+	insts = append(insts, bpf.LoadIndirect{Size: 1, Off: 0})
+	insts = append(insts, bpf.Jump{Skip: uint32(skip(record))})
+
+	// jeq #0x800 -- if IPv4, go next, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x800, SkipFalse: skip(drop)})
+	// ldb [23] -- load IPv4 Protocol
+	insts = append(insts, bpf.LoadAbsolute{Size: 1, Off: 23})
+	// jeq #0x6 -- if TCP, go next, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x6, SkipFalse: skip(drop)})
+	// ldh [20] -- load Fragment Offset
+	insts = append(insts, bpf.LoadAbsolute{Size: 2, Off: 20})
+	// jset #0x1fff -- use 0x1fff as mask for fragment offset, if != 0, drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: skip(drop)})
+	// ldxb 4*([14]&0xf) -- x = IP header length
+	insts = append(insts, bpf.LoadMemShift{Off: 14})
+	// ldh [x + 14] -- load source port
+	insts = append(insts, bpf.LoadIndirect{Size: 2, Off: 14})
+	// jeq #port -- for each port, check the payload if equal
+	checkPorts(ipv4Payload)
+	// ldh [x + 16] -- load dest port
+	insts = append(insts, bpf.LoadIndirect{Size: 2, Off: 16})
+	// jeq #port -- for each port, check the payload if equal; then drop
+	checkPorts(ipv4Payload)
+	insts = append(insts, bpf.Jump{Skip: uint32(skip(drop))})
+
+	// ldb [x + 26] -- load TCP Data Offset
+	insts = append(insts, bpf.LoadIndirect{Size: 1, Off: 26})
+	// rsh #2, and #0x3c -- a = TCP header length
+	insts = append(insts, bpf.ALUOpConstant{Op: bpf.ALUOpShiftRight, Val: 2})
+	insts = append(insts, bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0x3c})
+	// add x, add #14 -- a = payload offset
+	insts = append(insts, bpf.ALUOpX{Op: bpf.ALUOpAdd})
+	insts = append(insts, bpf.ALUOpConstant{Op: bpf.ALUOpAdd, Val: 14})
+	// tax -- x = payload offset
+	insts = append(insts, bpf.TAX{})
+	// ldb [x] -- load record type, dropping segments without payload
+	insts = append(insts, bpf.LoadIndirect{Size: 1, Off: 0})
+
+	// jeq #0x16 -- if handshake record, go next, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: recordTypeHandshake, SkipFalse: skip(drop)})
+	// ldb [x + 1] -- load record major version
+	insts = append(insts, bpf.LoadIndirect{Size: 1, Off: 1})
+	// jeq #0x3 -- if SSL 3.0 or TLS, capture, else drop
+	insts = append(insts, bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x3, SkipFalse: skip(drop)})
+
+	// ret #snapLen -- capture
+	insts = append(insts, bpf.RetConstant{Val: snapLen})
+	// ret #0 -- drop
+	insts = append(insts, bpf.RetConstant{Val: 0})
+
+	return bpf.Assemble(insts)
+}
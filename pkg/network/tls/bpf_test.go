@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package tls
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func serializePacket(t *testing.T, v6 bool, proto layers.IPProtocol, sport, dport uint16, fragOffset uint16, payload []byte) []byte {
+	eth := &layers.Ethernet{SrcMAC: make(net.HardwareAddr, 6), DstMAC: make(net.HardwareAddr, 6)}
+	var ip gopacket.NetworkLayer
+	if v6 {
+		eth.EthernetType = layers.EthernetTypeIPv6
+		ip = &layers.IPv6{Version: 6, NextHeader: proto, HopLimit: 64, SrcIP: net.ParseIP("::1"), DstIP: net.ParseIP("::2")}
+	} else {
+		eth.EthernetType = layers.EthernetTypeIPv4
+		ip = &layers.IPv4{Version: 4, Protocol: proto, TTL: 64, FragOffset: fragOffset, SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}
+	}
+	var transport gopacket.SerializableLayer
+	if proto == layers.IPProtocolTCP {
+		tcp := &layers.TCP{SrcPort: layers.TCPPort(sport), DstPort: layers.TCPPort(dport), PSH: true, ACK: true}
+		if !v6 {
+			// options shift the payload
+			tcp.Options = []layers.TCPOption{{OptionType: layers.TCPOptionKindTimestamps, OptionLength: 10, OptionData: make([]byte, 8)}}
+		}
+		_ = tcp.SetNetworkLayerForChecksum(ip)
+		transport = tcp
+	} else {
+		udp := &layers.UDP{SrcPort: layers.UDPPort(sport), DstPort: layers.UDPPort(dport)}
+		_ = udp.SetNetworkLayerForChecksum(ip)
+		transport = udp
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	err := gopacket.SerializeLayers(buf, opts, eth, ip.(gopacket.SerializableLayer), transport, gopacket.Payload(payload))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestBPFFilter(t *testing.T) {
+	raw, err := generateBPFFilter([]uint16{443, 8443})
+	require.NoError(t, err)
+	insts, ok := bpf.Disassemble(raw)
+	require.True(t, ok)
+	vm, err := bpf.NewVM(insts)
+	require.NoError(t, err)
+
+	handshake := append([]byte{recordTypeHandshake, 3, 1, 0x10, 0}, make([]byte, 1<<12)...)
+	appData := append([]byte{recordTypeApplicationData, 3, 3, 0x10, 0}, make([]byte, 1<<12)...)
+	for _, v6 := range []bool{false, true} {
+		for _, tt := range []struct {
+			proto        layers.IPProtocol
+			sport, dport uint16
+			payload      []byte
+			captured     bool
+		}{
+			{layers.IPProtocolTCP, 45678, 443, handshake, true},
+			{layers.IPProtocolTCP, 443, 45678, handshake, true},
+			{layers.IPProtocolTCP, 45678, 8443, handshake, true},
+			{layers.IPProtocolTCP, 8443, 45678, handshake, true},
+			{layers.IPProtocolTCP, 45678, 443, handshake[:2], true},
+			{layers.IPProtocolTCP, 45678, 443, appData, false},
+			{layers.IPProtocolTCP, 45678, 443, []byte{recordTypeHandshake, 0x2f}, false},
+			{layers.IPProtocolTCP, 45678, 443, nil, false},
+			{layers.IPProtocolTCP, 45678, 80, handshake, false},
+			{layers.IPProtocolUDP, 45678, 443, handshake, false},
+		} {
+			n, err := vm.Run(serializePacket(t, v6, tt.proto, tt.sport, tt.dport, 0, tt.payload))
+			require.NoError(t, err)
+			assert.Equal(t, tt.captured, n > 0, "v6=%t %+v", v6, tt)
+			if tt.captured && len(tt.payload) > snapLen {
+				assert.Equal(t, snapLen, n, "only the start of the segment is captured")
+			}
+		}
+	}
+
+	n, err := vm.Run(serializePacket(t, false, layers.IPProtocolTCP, 45678, 443, 10, handshake))
+	require.NoError(t, err)
+	assert.Zero(t, n, "fragments are dropped")
+
+	_, err = generateBPFFilter(nil)
+	assert.Error(t, err)
+	_, err = generateBPFFilter(make([]uint16, maxPorts+1))
+	assert.Error(t, err)
+	_, err = generateBPFFilter(make([]uint16, maxPorts))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package tls
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/vishvananda/netns"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Monitor captures the start of the TLS handshake records sent from and to the
+// TLS ports, and records the metadata of the TLS sessions established over them.
+type Monitor struct {
+	source *filterpkg.AFPacketSource
+
+	decoder *gopacket.DecodingLayerParser
+	layers  []gopacket.LayerType
+	ipv4    layers.IPv4
+	ipv6    layers.IPv6
+	tcp     layers.TCP
+	payload gopacket.Payload
+
+	mux     sync.Mutex
+	tracker *Tracker
+
+	decodingErrors atomic.Int64
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMonitor returns a new Monitor capturing the traffic of the root network
+// namespace.
+func NewMonitor(c *config.Config) (*Monitor, error) {
+	bpfFilter, err := generateBPFFilter(c.TLSPorts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bpf classic filter: %w", err)
+	}
+
+	// Create the RAW_SOCKET inside the root network namespace
+	var (
+		packetSrc *filterpkg.AFPacketSource
+		srcErr    error
+		ns        netns.NsHandle
+	)
+	if ns, err = c.GetRootNetNs(); err != nil {
+		return nil, err
+	}
+	defer ns.Close()
+
+	err = util.WithNS(c.ProcRoot, ns, func() error {
+		packetSrc, srcErr = filterpkg.NewPacketSource(nil, bpfFilter)
+		return srcErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Monitor{
+		source:  packetSrc,
+		tracker: NewTracker(c),
+		exit:    make(chan struct{}),
+	}
+	m.decoder = gopacket.NewDecodingLayerParser(packetSrc.PacketType(), &layers.Ethernet{}, &m.ipv4, &m.ipv6, &m.tcp, &m.payload)
+	m.decoder.IgnoreUnsupported = true
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollPackets()
+	}()
+	return m, nil
+}
+
+// GetTLSInfo returns the metadata of the TLS sessions of the tracked
+// connections, by tuple whose source is the client.
+func (m *Monitor) GetTLSInfo() map[http.KeyTuple]*Info {
+	if m == nil {
+		return nil
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.tracker.RemoveExpired(time.Now())
+	return m.tracker.GetInfo()
+}
+
+// GetStats returns telemetry about the tracked handshakes.
+func (m *Monitor) GetStats() map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	stats := m.tracker.GetStats()
+	for key, value := range m.source.Stats() {
+		stats[key] = value
+	}
+	stats["decoding_errors"] = m.decodingErrors.Load()
+	return stats
+}
+
+// Stop stops capturing packets and releases associated resources.
+func (m *Monitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
+
+func (m *Monitor) pollPackets() {
+	for {
+		err := m.source.VisitPackets(m.exit, m.processPacket)
+		if err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// processPacket feeds the TCP segment held by the given packet to the tracker.
+// The packet data can't be referenced after this call since the underlying
+// memory content gets invalidated by `afpacket`.
+func (m *Monitor) processPacket(data []byte, timestamp time.Time) error {
+	if err := m.decoder.DecodeLayers(data, &m.layers); err != nil {
+		m.decodingErrors.Inc()
+		return nil
+	}
+
+	var saddr, daddr util.Address
+	var isTCP bool
+	for _, layer := range m.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			saddr, daddr = util.AddressFromNetIP(m.ipv4.SrcIP), util.AddressFromNetIP(m.ipv4.DstIP)
+		case layers.LayerTypeIPv6:
+			saddr, daddr = util.AddressFromNetIP(m.ipv6.SrcIP), util.AddressFromNetIP(m.ipv6.DstIP)
+		case layers.LayerTypeTCP:
+			isTCP = true
+		}
+	}
+	if !isTCP {
+		return nil
+	}
+
+	tup := http.NewKeyTuple(saddr, daddr, uint16(m.tcp.SrcPort), uint16(m.tcp.DstPort))
+	m.mux.Lock()
+	m.tracker.Process(tup, m.tcp.Seq, m.tcp.Payload, timestamp)
+	m.mux.Unlock()
+	return nil
+}
@@ -0,0 +1,394 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package tls
+
+import (
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	recordHeaderLen    = 5
+	handshakeHeaderLen = 4
+
+	// maxRecordLen is the maximum length of a record fragment, including the
+	// expansion allowed for encrypted records (RFC 5246, section 6.2.3)
+	maxRecordLen = 1<<14 + 2048
+	// maxHandshakeLen bounds the size of the handshake messages that get
+	// buffered. Only certificate chains get close to it.
+	maxHandshakeLen = 1 << 16
+
+	recordTypeChangeCipherSpec = 20
+	recordTypeAlert            = 21
+	recordTypeHandshake        = 22
+	recordTypeApplicationData  = 23
+
+	handshakeTypeClientHello = 1
+	handshakeTypeServerHello = 2
+	handshakeTypeCertificate = 11
+
+	extensionServerName        = 0
+	extensionSupportedVersions = 43
+	serverNameTypeHostName     = 0
+)
+
+var (
+	errMalformed      = errors.New("tls: malformed handshake")
+	errMissingSegment = errors.New("tls: missing segment")
+)
+
+// IsHandshakeStart reports whether payload starts with a handshake record
+// holding a message of the given type, which is how both ends start a TLS
+// session.
+func IsHandshakeStart(payload []byte, handshakeType byte) bool {
+	return len(payload) > recordHeaderLen &&
+		payload[0] == recordTypeHandshake &&
+		payload[1] == 3 &&
+		payload[recordHeaderLen] == handshakeType
+}
+
+// isRecordStart reports whether segment starts with the header of a handshake
+// record.
+func isRecordStart(segment []byte) bool {
+	return len(segment) > 1 && segment[0] == recordTypeHandshake && segment[1] == 3
+}
+
+// streamParser decodes the handshake messages sent by one end of a connection.
+// The stream is fed as TCP segments: records and handshake messages are
+// reassembled as they can span multiple segments and records. Since only the
+// start of the segments starting a handshake record gets captured, the parser
+// resyncs on such segments when the previous ones went missing, and decodes
+// what it can of a truncated record. Parsing stops once the end starts
+// encrypting its messages or sends the leaf certificate, or on the first
+// malformed record or missing segment.
+type streamParser struct {
+	// fromServer is true if the stream is sent by the server
+	fromServer bool
+	// done is true once there is nothing left to decode in the stream
+	done bool
+
+	// nextSeq is the sequence number of the next byte of the stream, once synced
+	nextSeq uint32
+	synced  bool
+
+	record    []byte
+	handshake []byte
+}
+
+// feed decodes the segment of the stream starting at sequence number seq,
+// updating info with the metadata it holds. Retransmitted segments, as well as
+// segments captured more than once, are skipped.
+func (p *streamParser) feed(seq uint32, segment []byte, info *Info) error {
+	if p.done || len(segment) == 0 {
+		return nil
+	}
+
+	if p.synced {
+		diff := int32(seq - p.nextSeq)
+		if diff > 0 && isRecordStart(segment) {
+			// the segments in between didn't start a handshake record, or were
+			// truncated: whatever was being reassembled is lost
+			p.record = p.record[:0]
+			p.handshake = p.handshake[:0]
+			diff = 0
+		} else if diff > 0 {
+			p.stop()
+			return errMissingSegment
+		}
+		if -int(diff) >= len(segment) {
+			return nil
+		}
+		segment = segment[-diff:]
+		seq = p.nextSeq
+	}
+	p.synced = true
+	p.nextSeq = seq + uint32(len(segment))
+
+	data := segment
+	if len(p.record) > 0 {
+		p.record = append(p.record, segment...)
+		data = p.record
+	}
+
+	for !p.done && len(data) >= recordHeaderLen {
+		recordType := data[0]
+		length := int(binary.BigEndian.Uint16(data[3:]))
+		if recordType < recordTypeChangeCipherSpec || recordType > recordTypeApplicationData || data[1] != 3 || length > maxRecordLen {
+			p.stop()
+			return errMalformed
+		}
+		if len(data) < recordHeaderLen+length {
+			break
+		}
+
+		if err := p.processRecord(recordType, data[recordHeaderLen:recordHeaderLen+length], info); err != nil {
+			p.stop()
+			return err
+		}
+		data = data[recordHeaderLen+length:]
+	}
+
+	if len(data) >= recordHeaderLen && data[0] == recordTypeHandshake && !p.done {
+		// the rest of the record may never be captured
+		p.peekRecord(data[recordHeaderLen:], info)
+	}
+	if p.done {
+		return nil
+	}
+	// keep the partial record for the next segment, without holding on to the
+	// segment itself as its memory may get reused
+	p.record = append(p.record[:0], data...)
+	return nil
+}
+
+func (p *streamParser) stop() {
+	p.done = true
+	p.record = nil
+	p.handshake = nil
+}
+
+// peekRecord decodes the messages held by the start of a handshake record,
+// including the leaf certificate of a partial Certificate message. The messages
+// are decoded again if the record gets completed, which doesn't alter info.
+func (p *streamParser) peekRecord(fragment []byte, info *Info) {
+	data := append(p.handshake[:len(p.handshake):len(p.handshake)], fragment...)
+	for len(data) >= handshakeHeaderLen {
+		msgType := data[0]
+		length := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		msg := data[handshakeHeaderLen:]
+		if len(msg) > length {
+			msg = msg[:length]
+		}
+		if err := p.processHandshake(msgType, msg, info); err != nil || len(msg) < length {
+			return
+		}
+		data = data[handshakeHeaderLen+length:]
+	}
+}
+
+func (p *streamParser) processRecord(recordType byte, fragment []byte, info *Info) error {
+	switch recordType {
+	case recordTypeChangeCipherSpec, recordTypeApplicationData:
+		// everything sent from now on is encrypted
+		p.stop()
+		return nil
+	case recordTypeAlert:
+		return nil
+	}
+
+	data := fragment
+	if len(p.handshake) > 0 {
+		p.handshake = append(p.handshake, fragment...)
+		data = p.handshake
+	}
+
+	for !p.done && len(data) >= handshakeHeaderLen {
+		msgType := data[0]
+		length := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		if length > maxHandshakeLen {
+			return errMalformed
+		}
+		if len(data) < handshakeHeaderLen+length {
+			break
+		}
+
+		if err := p.processHandshake(msgType, data[handshakeHeaderLen:handshakeHeaderLen+length], info); err != nil {
+			return err
+		}
+		data = data[handshakeHeaderLen+length:]
+	}
+
+	if p.done {
+		return nil
+	}
+	p.handshake = append(p.handshake[:0], data...)
+	return nil
+}
+
+func (p *streamParser) processHandshake(msgType byte, msg []byte, info *Info) error {
+	switch {
+	case msgType == handshakeTypeClientHello && !p.fromServer:
+		return parseClientHello(msg, info)
+	case msgType == handshakeTypeServerHello && p.fromServer:
+		return parseServerHello(msg, info)
+	case msgType == handshakeTypeCertificate && p.fromServer:
+		if err := parseCertificate(msg, info); err != nil {
+			return err
+		}
+		// nothing of interest follows the leaf certificate
+		p.stop()
+	}
+	return nil
+}
+
+// parseClientHello reads the server name indication of a ClientHello message
+// (RFC 8446, section 4.1.2 and RFC 6066, section 3)
+func parseClientHello(msg []byte, info *Info) error {
+	r := reader{b: msg}
+	r.skip(2 + 32)          // legacy_version, random
+	r.skip(int(r.uint8()))  // legacy_session_id
+	r.skip(int(r.uint16())) // cipher_suites
+	r.skip(int(r.uint8()))  // legacy_compression_methods
+	if r.err != nil {
+		return r.err
+	}
+	if r.len() == 0 {
+		// extensions are optional up to TLS 1.2
+		return nil
+	}
+
+	extensions := r.vector16()
+	if r.err != nil {
+		return r.err
+	}
+
+	for extensions.len() > 0 {
+		extType := extensions.uint16()
+		ext := extensions.vector16()
+		if extensions.err != nil {
+			return extensions.err
+		}
+		if extType != extensionServerName {
+			continue
+		}
+
+		names := ext.vector16()
+		for names.len() > 0 {
+			nameType := names.uint8()
+			name := names.vector16()
+			if names.err != nil {
+				return names.err
+			}
+			if nameType == serverNameTypeHostName {
+				info.ServerName = string(name.b)
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// parseServerHello reads the negotiated version and cipher suite of a
+// ServerHello message (RFC 8446, section 4.1.3)
+func parseServerHello(msg []byte, info *Info) error {
+	r := reader{b: msg}
+	version := r.uint16()  // legacy_version
+	r.skip(32)             // random
+	r.skip(int(r.uint8())) // legacy_session_id_echo
+	cipherSuite := r.uint16()
+	r.skip(1) // legacy_compression_method
+	if r.err != nil {
+		return r.err
+	}
+
+	// TLS 1.3 negotiates the version with the supported_versions extension, as
+	// legacy_version is set to TLS 1.2. Extensions are optional up to TLS 1.2.
+	var extensions reader
+	if r.len() > 0 {
+		extensions = r.vector16()
+		if r.err != nil {
+			return r.err
+		}
+	}
+	for extensions.len() > 0 {
+		extType := extensions.uint16()
+		ext := extensions.vector16()
+		if extensions.err != nil {
+			return extensions.err
+		}
+		if extType == extensionSupportedVersions {
+			version = ext.uint16()
+			if ext.err != nil {
+				return ext.err
+			}
+		}
+	}
+
+	info.Version = version
+	info.CipherSuite = cipherSuite
+	return nil
+}
+
+// parseCertificate reads the expiry date of the leaf certificate of a
+// Certificate message, in its TLS 1.2 format (RFC 5246, section 7.4.2). msg may
+// only hold the start of the message, as long as the leaf certificate is whole.
+func parseCertificate(msg []byte, info *Info) error {
+	r := reader{b: msg}
+	if r.uint24() == 0 {
+		// the server has no certificate, as with anonymous cipher suites
+		return r.err
+	}
+
+	leaf := r.vector24()
+	if r.err != nil {
+		return r.err
+	}
+
+	cert, err := x509.ParseCertificate(leaf.b)
+	if err != nil {
+		return err
+	}
+	info.CertNotAfter = cert.NotAfter
+	return nil
+}
+
+// reader decodes the vectors and integers of the TLS presentation language.
+// Reading past the end sets err, after which reads return zero values.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) len() int {
+	return len(r.b)
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.b) {
+		r.err = errMalformed
+		r.b = nil
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) skip(n int) {
+	r.next(n)
+}
+
+func (r *reader) uint8() uint8 {
+	if v := r.next(1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if v := r.next(2); v != nil {
+		return binary.BigEndian.Uint16(v)
+	}
+	return 0
+}
+
+func (r *reader) uint24() int {
+	if v := r.next(3); v != nil {
+		return int(v[0])<<16 | int(v[1])<<8 | int(v[2])
+	}
+	return 0
+}
+
+func (r *reader) vector16() reader {
+	n := int(r.uint16())
+	return reader{b: r.next(n), err: r.err}
+}
+
+func (r *reader) vector24() reader {
+	n := r.uint24()
+	return reader{b: r.next(n), err: r.err}
+}
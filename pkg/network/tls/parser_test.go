@@ -0,0 +1,170 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package tls
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the recorded handshakes are made with a server certificate expiring on
+// certNotAfter, by a client connecting to api.example.com
+var certNotAfter = time.Date(2031, 6, 30, 12, 0, 0, 0, time.UTC)
+
+// loadStream returns the bytes sent by one end of a recorded connection: the
+// handshake, followed by a few bytes of application data
+func loadStream(t *testing.T, name string) []byte {
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	b, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	require.NoError(t, err)
+	return b
+}
+
+// feedStream feeds the stream to the parser in segments of at most segmentLen bytes
+func feedStream(t *testing.T, p *streamParser, stream []byte, segmentLen int, info *Info) {
+	seq := uint32(1000)
+	for len(stream) > 0 {
+		n := segmentLen
+		if n > len(stream) {
+			n = len(stream)
+		}
+		require.NoError(t, p.feed(seq, stream[:n], info))
+		seq += uint32(n)
+		stream = stream[n:]
+	}
+}
+
+func TestParseRecordedHandshakes(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		version      uint16
+		cipherSuite  string
+		certNotAfter time.Time
+	}{
+		{"tls10", VersionTLS10, "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA", certNotAfter},
+		{"tls12", VersionTLS12, "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", certNotAfter},
+		// the certificate is encrypted
+		{"tls13", VersionTLS13, "TLS_AES_128_GCM_SHA256", time.Time{}},
+	} {
+		for _, segmentLen := range []int{1, 7, 100, 1460} {
+			var info Info
+			client := &streamParser{}
+			server := &streamParser{fromServer: true}
+			feedStream(t, client, loadStream(t, tt.name+"_client.hex"), segmentLen, &info)
+			feedStream(t, server, loadStream(t, tt.name+"_server.hex"), segmentLen, &info)
+
+			assert.Equal(t, tt.version, info.Version, "%s, segments of %d bytes", tt.name, segmentLen)
+			assert.Equal(t, tt.cipherSuite, info.CipherSuiteName(), "%s, segments of %d bytes", tt.name, segmentLen)
+			assert.Equal(t, "api.example.com", info.ServerName, "%s, segments of %d bytes", tt.name, segmentLen)
+			assert.True(t, tt.certNotAfter.Equal(info.CertNotAfter), "%s, segments of %d bytes", tt.name, segmentLen)
+
+			// parsing stops once the handshake gets encrypted
+			assert.True(t, client.done)
+			assert.True(t, server.done)
+			assert.Nil(t, client.record)
+			assert.Nil(t, server.handshake)
+		}
+	}
+}
+
+func TestParseDuplicateSegments(t *testing.T) {
+	stream := loadStream(t, "tls12_server.hex")
+
+	var info Info
+	p := &streamParser{fromServer: true}
+	// every segment is seen twice, and the second one overlaps with the first
+	require.NoError(t, p.feed(1, stream[:100], &info))
+	require.NoError(t, p.feed(1, stream[:100], &info))
+	require.NoError(t, p.feed(51, stream[50:200], &info))
+	require.NoError(t, p.feed(101, stream[100:200], &info))
+	require.NoError(t, p.feed(201, stream[200:], &info))
+	assert.Equal(t, VersionTLS12, info.Version)
+	assert.True(t, certNotAfter.Equal(info.CertNotAfter))
+
+	// a gap in the stream stops the parsing
+	info = Info{}
+	p = &streamParser{fromServer: true}
+	require.NoError(t, p.feed(1, stream[:10], &info))
+	assert.Equal(t, errMissingSegment, p.feed(21, stream[20:], &info))
+	assert.True(t, p.done)
+	assert.Zero(t, info.Version)
+}
+
+func TestParseCapturedSegments(t *testing.T) {
+	stream := loadStream(t, "tls12_server.hex")
+
+	// the segments starting a handshake record are truncated, and the ones in
+	// between aren't captured: the parser resyncs on the next record
+	var info Info
+	p := &streamParser{fromServer: true}
+	require.NoError(t, p.feed(1, stream[:150], &info))
+	assert.Equal(t, VersionTLS12, info.Version)
+	require.NoError(t, p.feed(408, stream[407:], &info))
+	assert.True(t, p.done)
+	assert.Zero(t, info.CertNotAfter)
+
+	// the leaf certificate is read out of a truncated Certificate message,
+	// after which the parsing stops
+	leaf := stream[80:407]
+	chainLen := len(leaf) + 1000
+	segment := append([]byte{}, stream[:68]...)
+	segment = append(segment, recordTypeHandshake, 3, 3, byte((chainLen+7)>>8), byte(chainLen+7))
+	segment = append(segment, handshakeTypeCertificate, 0, byte((chainLen+3)>>8), byte(chainLen+3))
+	segment = append(segment, 0, byte(chainLen>>8), byte(chainLen))
+	segment = append(segment, leaf...)
+
+	info = Info{}
+	p = &streamParser{fromServer: true}
+	require.NoError(t, p.feed(1, segment, &info))
+	assert.Equal(t, VersionTLS12, info.Version)
+	assert.True(t, certNotAfter.Equal(info.CertNotAfter))
+	assert.True(t, p.done)
+}
+
+func TestParseMalformed(t *testing.T) {
+	stream := loadStream(t, "tls12_client.hex")
+
+	for name, segment := range map[string][]byte{
+		"not tls":           []byte("GET / HTTP/1.1\r\nHost: api.example.com\r\n\r\n"),
+		"oversized record":  {recordTypeHandshake, 3, 3, 0xff, 0xff},
+		"truncated message": append([]byte{recordTypeHandshake, 3, 1, 0, 8, handshakeTypeClientHello, 0, 0, 4}, stream[9:13]...),
+	} {
+		var info Info
+		p := &streamParser{}
+		assert.Error(t, p.feed(0, segment, &info), name)
+		assert.True(t, p.done, name)
+		assert.Empty(t, info.ServerName, name)
+	}
+}
+
+func TestIsHandshakeStart(t *testing.T) {
+	assert.True(t, IsHandshakeStart(loadStream(t, "tls12_client.hex"), handshakeTypeClientHello))
+	assert.False(t, IsHandshakeStart(loadStream(t, "tls12_client.hex"), handshakeTypeServerHello))
+	assert.True(t, IsHandshakeStart(loadStream(t, "tls13_server.hex"), handshakeTypeServerHello))
+	assert.False(t, IsHandshakeStart([]byte{recordTypeHandshake, 3, 1}, handshakeTypeClientHello))
+}
+
+func TestInfoNames(t *testing.T) {
+	var info Info
+	assert.Empty(t, info.VersionName())
+	assert.Empty(t, info.CipherSuiteName())
+
+	info = Info{Version: VersionTLS13, CipherSuite: 0x1302}
+	assert.Equal(t, "tls_1.3", info.VersionName())
+	assert.Equal(t, "TLS_AES_256_GCM_SHA384", info.CipherSuiteName())
+
+	info = Info{Version: 0x7f1c, CipherSuite: 0xfefe}
+	assert.Equal(t, "0x7f1c", info.VersionName())
+	assert.Equal(t, "0xFEFE", info.CipherSuiteName())
+}
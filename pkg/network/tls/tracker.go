@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package tls
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// ttl is how long the metadata of a connection is kept after its last handshake
+// record, so that every client gets it before it's removed. Nothing is learned
+// from a connection once its handshake is over, as the following segments
+// aren't captured.
+const ttl = 2 * time.Minute
+
+type connection struct {
+	info           Info
+	client, server streamParser
+
+	lastSeen time.Time
+}
+
+// handshakeOver reports whether there is nothing left to learn from the
+// handshake: the server sent its leaf certificate, started encrypting its
+// messages, or its stream can't be decoded any further.
+func (c *connection) handshakeOver() bool {
+	return c.server.done
+}
+
+func newConnection() *connection {
+	return &connection{server: streamParser{fromServer: true}}
+}
+
+// Tracker follows the TLS handshakes of multiple connections from the TCP
+// segments they're made of, and keeps the metadata of the sessions for a while
+// after the handshakes are over. Connections are identified by a tuple whose
+// source is the client, which is told apart from the server by the handshake
+// messages it sends.
+//
+// Tracker is not safe for concurrent use.
+type Tracker struct {
+	conns      map[http.KeyTuple]*connection
+	maxEntries int
+
+	handshakes, malformed, missed, dropped atomic.Int64
+}
+
+// NewTracker returns a new Tracker.
+func NewTracker(c *config.Config) *Tracker {
+	return &Tracker{
+		conns:      make(map[http.KeyTuple]*connection),
+		maxEntries: c.MaxTrackedTLSConnections,
+	}
+}
+
+// Process decodes the TCP segment sent on the connection identified by tup,
+// whose source is the sender of the segment. seq is the sequence number of the
+// segment, and now the time at which it was seen.
+func (t *Tracker) Process(tup http.KeyTuple, seq uint32, payload []byte, now time.Time) {
+	isClientHello := IsHandshakeStart(payload, handshakeTypeClientHello)
+
+	key, fromServer := tup, false
+	conn := t.conns[key]
+	if conn == nil {
+		key, fromServer = reverse(tup), true
+		conn = t.conns[key]
+	}
+
+	switch {
+	case conn == nil && isClientHello:
+		key, fromServer = tup, false
+	case conn == nil && IsHandshakeStart(payload, handshakeTypeServerHello):
+		// the ClientHello went missing, the server metadata is still of use
+	case conn == nil:
+		// the handshake happened before the connection was tracked
+		return
+	case isClientHello && !fromServer && conn.handshakeOver():
+		// the tuple of a previous connection is being reused
+		delete(t.conns, key)
+		conn = nil
+	case conn.handshakeOver():
+		return
+	}
+
+	if conn == nil {
+		if len(t.conns) >= t.maxEntries {
+			t.dropped.Inc()
+			return
+		}
+		conn = newConnection()
+		t.conns[key] = conn
+		t.handshakes.Inc()
+	}
+
+	conn.lastSeen = now
+	parser := &conn.client
+	if fromServer {
+		parser = &conn.server
+	}
+	if err := parser.feed(seq, payload, &conn.info); err == errMissingSegment {
+		t.missed.Inc()
+	} else if err != nil {
+		t.malformed.Inc()
+	}
+	if conn.handshakeOver() {
+		// the client has nothing left to send that's of interest either
+		conn.client.stop()
+	}
+}
+
+// GetInfo returns the metadata of the tracked sessions, by tuple whose source
+// is the client. Connections whose handshake didn't carry any metadata yet are
+// left out.
+func (t *Tracker) GetInfo() map[http.KeyTuple]*Info {
+	ret := make(map[http.KeyTuple]*Info, len(t.conns))
+	for key, conn := range t.conns {
+		if conn.info.Version == 0 && conn.info.ServerName == "" {
+			continue
+		}
+		info := conn.info
+		ret[key] = &info
+	}
+	return ret
+}
+
+// RemoveExpired stops tracking the connections on which no handshake record was
+// seen for long enough.
+func (t *Tracker) RemoveExpired(now time.Time) {
+	for key, conn := range t.conns {
+		if now.Sub(conn.lastSeen) > ttl {
+			delete(t.conns, key)
+		}
+	}
+}
+
+// GetStats returns telemetry about the tracked handshakes.
+func (t *Tracker) GetStats() map[string]interface{} {
+	return map[string]interface{}{
+		"handshakes": t.handshakes.Load(),
+		"malformed":  t.malformed.Load(),
+		"missed":     t.missed.Load(),
+		"dropped":    t.dropped.Load(),
+	}
+}
+
+func reverse(tup http.KeyTuple) http.KeyTuple {
+	return http.KeyTuple{
+		SrcIPHigh: tup.DstIPHigh,
+		SrcIPLow:  tup.DstIPLow,
+		DstIPHigh: tup.SrcIPHigh,
+		DstIPLow:  tup.SrcIPLow,
+		SrcPort:   tup.DstPort,
+		DstPort:   tup.SrcPort,
+	}
+}
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package tls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+func TestTracker(t *testing.T) {
+	client := http.KeyTuple{SrcIPLow: 1, SrcPort: 45678, DstIPLow: 2, DstPort: 443}
+	server := reverse(client)
+	clientStream := loadStream(t, "tls12_client.hex")
+	serverStream := loadStream(t, "tls12_server.hex")
+	now := time.Now()
+
+	t.Run("handshake", func(t *testing.T) {
+		tr := NewTracker(&config.Config{MaxTrackedTLSConnections: 10})
+		// segments of an untracked connection are ignored
+		tr.Process(server, 1, []byte("ping"), now)
+		assert.Empty(t, tr.conns)
+
+		tr.Process(client, 1, clientStream[:200], now)
+		tr.Process(client, 201, clientStream[200:], now)
+		tr.Process(server, 1, serverStream, now)
+
+		infos := tr.GetInfo()
+		require.Len(t, infos, 1)
+		info := infos[client]
+		require.NotNil(t, info)
+		assert.Equal(t, VersionTLS12, info.Version)
+		assert.Equal(t, "api.example.com", info.ServerName)
+		assert.True(t, certNotAfter.Equal(info.CertNotAfter))
+		assert.EqualValues(t, 1, tr.GetStats()["handshakes"])
+		assert.EqualValues(t, 0, tr.GetStats()["malformed"])
+
+		// the returned metadata is a copy
+		info.ServerName = "other"
+		assert.Equal(t, "api.example.com", tr.GetInfo()[client].ServerName)
+	})
+
+	t.Run("missing client hello", func(t *testing.T) {
+		tr := NewTracker(&config.Config{MaxTrackedTLSConnections: 10})
+		tr.Process(server, 1, serverStream, now)
+
+		info := tr.GetInfo()[client]
+		require.NotNil(t, info)
+		assert.Equal(t, VersionTLS12, info.Version)
+		assert.Empty(t, info.ServerName)
+	})
+
+	t.Run("handshake over", func(t *testing.T) {
+		tr := NewTracker(&config.Config{MaxTrackedTLSConnections: 10})
+		tr.Process(client, 1, clientStream[:200], now)
+		tr.Process(server, 1, serverStream, now)
+		conn := tr.conns[client]
+		require.NotNil(t, conn)
+		assert.True(t, conn.handshakeOver(), "nothing is decoded past the leaf certificate")
+		assert.True(t, conn.client.done)
+
+		// the segments that follow don't keep the connection around
+		later := now.Add(ttl)
+		tr.Process(client, 201, clientStream[200:], later)
+		tr.RemoveExpired(now.Add(ttl))
+		assert.Len(t, tr.GetInfo(), 1, "the metadata is kept for a while")
+		tr.RemoveExpired(now.Add(ttl + time.Second))
+		assert.Empty(t, tr.GetInfo())
+	})
+
+	t.Run("reused tuple", func(t *testing.T) {
+		tr := NewTracker(&config.Config{MaxTrackedTLSConnections: 10})
+		tr.Process(client, 1, clientStream, now)
+		tr.Process(server, 1, serverStream, now)
+		// a new connection gets established with the same tuple, resuming the
+		// session: only the ClientHello is seen
+		tr.Process(client, 5000, clientStream, now)
+
+		info := tr.GetInfo()[client]
+		require.NotNil(t, info)
+		assert.Zero(t, info.Version)
+		assert.Equal(t, "api.example.com", info.ServerName)
+		assert.EqualValues(t, 2, tr.GetStats()["handshakes"])
+	})
+
+	t.Run("unfinished handshake", func(t *testing.T) {
+		tr := NewTracker(&config.Config{MaxTrackedTLSConnections: 10})
+		tr.Process(client, 1, clientStream, now)
+		tr.RemoveExpired(now.Add(ttl))
+		assert.Len(t, tr.GetInfo(), 1)
+		tr.RemoveExpired(now.Add(ttl + time.Second))
+		assert.Empty(t, tr.GetInfo())
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		tr := NewTracker(&config.Config{MaxTrackedTLSConnections: 1})
+		tr.Process(client, 1, clientStream, now)
+		other := client
+		other.SrcPort++
+		tr.Process(other, 1, clientStream, now)
+		assert.Len(t, tr.GetInfo(), 1)
+		assert.EqualValues(t, 1, tr.GetStats()["dropped"])
+	})
+}
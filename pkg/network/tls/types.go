@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package tls extracts the metadata of TLS sessions, such as the negotiated
+// version and cipher suite, from the handshakes seen on the wire.
+package tls
+
+import (
+	cryptotls "crypto/tls"
+	"fmt"
+	"time"
+)
+
+// TLS protocol versions, as sent on the wire
+const (
+	VersionSSL30 uint16 = 0x0300
+	VersionTLS10 uint16 = 0x0301
+	VersionTLS11 uint16 = 0x0302
+	VersionTLS12 uint16 = 0x0303
+	VersionTLS13 uint16 = 0x0304
+)
+
+// Info holds the metadata of the TLS session of a connection. Fields are left
+// to their zero value when the message carrying them hasn't been seen.
+type Info struct {
+	// ServerName is the server name indication sent by the client
+	ServerName string
+	// CertNotAfter is the expiry date of the leaf certificate sent by the
+	// server. It's only available up to TLS 1.2, as TLS 1.3 encrypts
+	// certificates.
+	CertNotAfter time.Time
+	// Version is the protocol version negotiated by the server
+	Version uint16
+	// CipherSuite is the cipher suite negotiated by the server
+	CipherSuite uint16
+}
+
+// VersionName returns a human readable name of the negotiated version, or an
+// empty string if the version isn't known yet.
+func (i *Info) VersionName() string {
+	switch i.Version {
+	case 0:
+		return ""
+	case VersionSSL30:
+		return "ssl_3.0"
+	case VersionTLS10:
+		return "tls_1.0"
+	case VersionTLS11:
+		return "tls_1.1"
+	case VersionTLS12:
+		return "tls_1.2"
+	case VersionTLS13:
+		return "tls_1.3"
+	default:
+		return fmt.Sprintf("0x%04x", i.Version)
+	}
+}
+
+// CipherSuiteName returns the standard name of the negotiated cipher suite,
+// such as TLS_AES_128_GCM_SHA256, or an empty string if the cipher suite isn't
+// known yet.
+func (i *Info) CipherSuiteName() string {
+	if i.Version == 0 {
+		return ""
+	}
+	// CipherSuiteName falls back to the hexadecimal value for unknown suites
+	return cryptotls.CipherSuiteName(i.CipherSuite)
+}
+
+func (i *Info) String() string {
+	return fmt.Sprintf("[TLS] version=%s cipher_suite=%s server_name=%q cert_not_after=%s",
+		i.VersionName(), i.CipherSuiteName(), i.ServerName, i.CertNotAfter.Format(time.RFC3339))
+}
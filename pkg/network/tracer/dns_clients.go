@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package tracer
+
+import (
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+)
+
+const (
+	dnsPort = 53
+	// dnsClientTTL is how long the client of a closed DNS connection is
+	// remembered. It covers the delay between the query log flushes.
+	dnsClientTTL = 2 * time.Minute
+	// maxDNSClients bounds the number of closed DNS connections remembered
+	maxDNSClients = 10000
+)
+
+type dnsClient struct {
+	pid    uint32
+	closed time.Time
+}
+
+// dnsClientCache remembers the process that owned the DNS connections that got
+// closed, as most of them are closed by the time the queries sent over them get
+// logged.
+type dnsClientCache struct {
+	mux     sync.Mutex
+	clients map[dns.Key]dnsClient
+}
+
+func newDNSClientCache() *dnsClientCache {
+	return &dnsClientCache{clients: make(map[dns.Key]dnsClient)}
+}
+
+// add remembers the client of the DNS connections among the given closed
+// connections
+func (c *dnsClientCache) add(conns []network.ConnectionStats, now time.Time) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for i := range conns {
+		if conns[i].DPort != dnsPort || conns[i].Pid == 0 {
+			continue
+		}
+		if len(c.clients) >= maxDNSClients {
+			return
+		}
+		c.clients[dnsKey(&conns[i])] = dnsClient{pid: conns[i].Pid, closed: now}
+	}
+}
+
+// get returns the pid of the client of the closed DNS connection identified by
+// key, or 0 if it isn't known
+func (c *dnsClientCache) get(key dns.Key) uint32 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.clients[key].pid
+}
+
+func (c *dnsClientCache) removeExpired(now time.Time) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for key, client := range c.clients {
+		if now.Sub(client.closed) > dnsClientTTL {
+			delete(c.clients, key)
+		}
+	}
+}
+
+// dnsKey returns the dns.Key of the queries sent over the given connection
+func dnsKey(c *network.ConnectionStats) dns.Key {
+	key := dns.Key{
+		ServerIP:   c.Dest,
+		ClientIP:   c.Source,
+		ClientPort: c.SPort,
+		Protocol:   syscall.IPPROTO_UDP,
+	}
+	if c.Type == network.TCP {
+		key.Protocol = syscall.IPPROTO_TCP
+	}
+	return key
+}
@@ -33,7 +33,10 @@ import (
 	netebpf "github.com/DataDog/datadog-agent/pkg/network/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/http/http2"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
 	"github.com/DataDog/datadog-agent/pkg/network/netlink"
+	"github.com/DataDog/datadog-agent/pkg/network/tls"
 	"github.com/DataDog/datadog-agent/pkg/network/tracer/connection"
 	"github.com/DataDog/datadog-agent/pkg/network/tracer/connection/kprobe"
 	"github.com/DataDog/datadog-agent/pkg/process/procutil"
@@ -46,12 +49,18 @@ import (
 const defaultUDPConnTimeoutNanoSeconds = uint64(time.Duration(120) * time.Second)
 
 type Tracer struct {
-	config      *config.Config
-	state       network.State
-	conntracker netlink.Conntracker
-	reverseDNS  dns.ReverseDNS
-	httpMonitor *http.Monitor
-	ebpfTracer  connection.Tracer
+	config       *config.Config
+	state        network.State
+	conntracker  netlink.Conntracker
+	reverseDNS   dns.ReverseDNS
+	httpMonitor  *http.Monitor
+	http2Monitor *http2.Monitor
+	kafkaMonitor *kafka.Monitor
+	tlsMonitor   *tls.Monitor
+	ebpfTracer   connection.Tracer
+
+	// dnsClients is only set when the DNS query log is enabled
+	dnsClients *dnsClientCache
 
 	// Telemetry
 	skippedConns *atomic.Int64 `stats:""`
@@ -160,6 +169,7 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		config.MaxConnectionsStateBuffered,
 		config.MaxDNSStatsBuffered,
 		config.MaxHTTPStatsBuffered,
+		config.MaxKafkaStatsBuffered,
 	)
 
 	gwLookup := newGatewayLookup(config)
@@ -172,6 +182,9 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		state:                      state,
 		reverseDNS:                 newReverseDNS(config),
 		httpMonitor:                newHTTPMonitor(config, ebpfTracer, constantEditors),
+		http2Monitor:               newHTTP2Monitor(config),
+		kafkaMonitor:               newKafkaMonitor(config),
+		tlsMonitor:                 newTLSMonitor(config),
 		activeBuffer:               network.NewConnectionBuffer(512, 256),
 		conntracker:                conntracker,
 		sourceExcludes:             network.ParseConnectionFilters(config.ExcludedSourceConnections),
@@ -188,6 +201,9 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		connStatsMapSize: atomic.NewInt64(0),
 		lastCheck:        atomic.NewInt64(0),
 	}
+	if config.EnableDNSQueryLog {
+		tr.dnsClients = newDNSClientCache()
+	}
 
 	err = ebpfTracer.Start(tr.storeClosedConnections)
 	if err != nil {
@@ -324,6 +340,9 @@ func (t *Tracer) storeClosedConnections(connections []network.ConnectionStats) {
 	connections = connections[rejected:]
 	t.closedConns.Add(int64(len(connections)))
 	t.skippedConns.Add(int64(rejected))
+	if t.dnsClients != nil {
+		t.dnsClients.add(connections, time.Now())
+	}
 	t.state.StoreClosedConnections(connections)
 }
 
@@ -331,6 +350,9 @@ func (t *Tracer) Stop() {
 	t.reverseDNS.Close()
 	t.ebpfTracer.Stop()
 	t.httpMonitor.Stop()
+	t.http2Monitor.Stop()
+	t.kafkaMonitor.Stop()
+	t.tlsMonitor.Stop()
 	t.conntracker.Close()
 }
 
@@ -346,8 +368,9 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	}
 	active := t.activeBuffer.Connections()
 
-	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.httpMonitor.GetHTTPStats())
+	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.getHTTPStats(), t.kafkaMonitor.GetKafkaStats())
 	t.activeBuffer.Reset()
+	addTLSInfo(delta.Conns, t.tlsMonitor.GetTLSInfo())
 
 	ips := make([]util.Address, 0, len(delta.Conns)*2)
 	for _, conn := range delta.Conns {
@@ -363,11 +386,51 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		DNS:                         names,
 		DNSStats:                    delta.DNSStats,
 		HTTP:                        delta.HTTP,
+		Kafka:                       delta.Kafka,
 		ConnTelemetry:               ctm,
 		CompilationTelemetryByAsset: rctm,
 	}, nil
 }
 
+// GetDNSQueryLog returns the DNS queries logged since the last call. Queries are
+// attributed to the process that sent them when the connection they were sent
+// over is known.
+func (t *Tracer) GetDNSQueryLog() ([]dns.QueryRecord, error) {
+	records := t.reverseDNS.GetQueryLog()
+	if len(records) == 0 {
+		return records, nil
+	}
+
+	pids := make(map[dns.Key]uint32, len(records))
+	for i := range records {
+		pids[records[i].Key()] = 0
+	}
+	// the connections are only looked up, none gets buffered
+	err := t.ebpfTracer.GetConnections(network.NewConnectionBuffer(0, 0), func(c *network.ConnectionStats) bool {
+		key := dnsKey(c)
+		if _, ok := pids[key]; ok && c.Pid != 0 {
+			pids[key] = c.Pid
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving connections: %s", err)
+	}
+
+	for i := range records {
+		key := records[i].Key()
+		if pid := pids[key]; pid != 0 {
+			records[i].Pid = pid
+		} else if t.dnsClients != nil {
+			records[i].Pid = t.dnsClients.get(key)
+		}
+	}
+	if t.dnsClients != nil {
+		t.dnsClients.removeExpired(time.Now())
+	}
+	return records, nil
+}
+
 func (t *Tracer) RegisterClient(clientID string) error {
 	t.state.RegisterClient(clientID)
 	return nil
@@ -588,8 +651,11 @@ const (
 	epbfStats
 	gatewayLookupStats
 	httpStats
+	http2Stats
+	kafkaStats
 	kprobesStats
 	stateStats
+	tlsStats
 	tracerStats
 )
 
@@ -599,8 +665,11 @@ var allStats = []statsComp{
 	epbfStats,
 	gatewayLookupStats,
 	httpStats,
+	http2Stats,
+	kafkaStats,
 	kprobesStats,
 	stateStats,
+	tlsStats,
 	tracerStats,
 }
 
@@ -626,10 +695,16 @@ func (t *Tracer) getStats(comps ...statsComp) (map[string]interface{}, error) {
 			ret["gateway_lookup"] = t.gwLookup.GetStats()
 		case httpStats:
 			ret["http"] = t.httpMonitor.GetStats()
+		case http2Stats:
+			ret["http2"] = t.http2Monitor.GetStats()
+		case kafkaStats:
+			ret["kafka"] = t.kafkaMonitor.GetStats()
 		case kprobesStats:
 			ret["kprobes"] = ddebpf.GetProbeStats()
 		case stateStats:
 			ret["state"] = t.state.GetStats()["telemetry"]
+		case tlsStats:
+			ret["tls"] = t.tlsMonitor.GetStats()
 		case tracerStats:
 			tracerStats := atomicstats.Report(t)
 			tracerStats["runtime"] = runtime.Tracer.GetTelemetry()
@@ -824,3 +899,97 @@ func newHTTPMonitor(c *config.Config, tracer connection.Tracer, offsets []manage
 	log.Info("http monitoring enabled")
 	return monitor
 }
+
+func newHTTP2Monitor(c *config.Config) *http2.Monitor {
+	if !c.EnableHTTP2Monitoring {
+		return nil
+	}
+
+	monitor, err := http2.NewMonitor(c)
+	if err != nil {
+		log.Errorf("could not enable http2 monitoring: %s", err)
+		return nil
+	}
+
+	log.Info("http2 monitoring enabled")
+	return monitor
+}
+
+// getHTTPStats returns the stats of the HTTP/1.x transactions captured by the
+// eBPF programs, merged with the stats of the HTTP/2 transactions decoded in
+// userspace, since both are reported the same way.
+func (t *Tracer) getHTTPStats() map[http.Key]*http.RequestStats {
+	stats := t.httpMonitor.GetHTTPStats()
+	http2Stats := t.http2Monitor.GetHTTPStats()
+	if len(http2Stats) == 0 {
+		return stats
+	}
+	if stats == nil {
+		return http2Stats
+	}
+
+	for key, s := range http2Stats {
+		if prev, ok := stats[key]; ok {
+			prev.CombineWith(s)
+		} else {
+			stats[key] = s
+		}
+	}
+	return stats
+}
+
+func newKafkaMonitor(c *config.Config) *kafka.Monitor {
+	if !c.EnableKafkaMonitoring {
+		return nil
+	}
+
+	monitor, err := kafka.NewMonitor(c)
+	if err != nil {
+		log.Errorf("could not enable kafka monitoring: %s", err)
+		return nil
+	}
+
+	log.Info("kafka monitoring enabled")
+	return monitor
+}
+
+func newTLSMonitor(c *config.Config) *tls.Monitor {
+	if !c.EnableTLSMetadata {
+		return nil
+	}
+
+	monitor, err := tls.NewMonitor(c)
+	if err != nil {
+		log.Errorf("could not enable tls metadata collection: %s", err)
+		return nil
+	}
+
+	log.Info("tls metadata collection enabled")
+	return monitor
+}
+
+// addTLSInfo attaches to the given connections the metadata of their TLS
+// session. The metadata is indexed by tuple whose source is the client, and
+// may have been captured before or after address translation.
+func addTLSInfo(conns []network.ConnectionStats, infos map[http.KeyTuple]*tls.Info) {
+	if len(infos) == 0 {
+		return
+	}
+
+	for i := range conns {
+		c := &conns[i]
+		laddr, lport := network.GetNATLocalAddress(*c)
+		raddr, rport := network.GetNATRemoteAddress(*c)
+		for _, tup := range []http.KeyTuple{
+			http.NewKeyTuple(c.Source, c.Dest, c.SPort, c.DPort),
+			http.NewKeyTuple(c.Dest, c.Source, c.DPort, c.SPort),
+			http.NewKeyTuple(laddr, raddr, lport, rport),
+			http.NewKeyTuple(raddr, laddr, rport, lport),
+		} {
+			if info, ok := infos[tup]; ok {
+				c.TLS = info
+				break
+			}
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
 )
 
 // Tracer is not implemented
@@ -32,6 +33,11 @@ func (t *Tracer) GetActiveConnections(_ string) (*network.Connections, error) {
 	return nil, ebpf.ErrNotImplemented
 }
 
+// GetDNSQueryLog is not implemented on this OS for Tracer
+func (t *Tracer) GetDNSQueryLog() ([]dns.QueryRecord, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // RegisterClient registers the client
 func (t *Tracer) RegisterClient(clientID string) error {
 	return ebpf.ErrNotImplemented
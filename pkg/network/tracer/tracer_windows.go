@@ -71,6 +71,7 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		config.MaxConnectionsStateBuffered,
 		config.MaxDNSStatsBuffered,
 		config.MaxHTTPStatsBuffered,
+		config.MaxKafkaStatsBuffered,
 	)
 
 	reverseDNS := dns.NewNullReverseDNS()
@@ -125,7 +126,7 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	t.state.RemoveExpiredClients(time.Now())
 
 	t.state.StoreClosedConnections(closedConnStats)
-	delta := t.state.GetDelta(clientID, uint64(time.Now().Nanosecond()), activeConnStats, t.reverseDNS.GetDNSStats(), nil)
+	delta := t.state.GetDelta(clientID, uint64(time.Now().Nanosecond()), activeConnStats, t.reverseDNS.GetDNSStats(), nil, nil)
 
 	t.activeBuffer.Reset()
 	t.closedBuffer.Reset()
@@ -144,6 +145,12 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	}, nil
 }
 
+// GetDNSQueryLog returns the DNS queries logged since the last call. Queries
+// aren't attributed to processes on Windows.
+func (t *Tracer) GetDNSQueryLog() ([]dns.QueryRecord, error) {
+	return t.reverseDNS.GetQueryLog(), nil
+}
+
 // RegisterClient registers the client
 func (t *Tracer) RegisterClient(clientID string) error {
 	t.state.RegisterClient(clientID)
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import "strings"
+
+// defaultAWSKeepParams holds the AWS SDK request parameters which are known to not carry
+// sensitive data. DynamoDB expressions refer to values through placeholders (e.g. ":val"),
+// the values themselves being found in the ExpressionAttributeValues parameter.
+var defaultAWSKeepParams = []string{
+	"TableName",
+	"IndexName",
+	"Select",
+	"Limit",
+	"ConsistentRead",
+	"ReturnValues",
+	"ReturnConsumedCapacity",
+	"KeyConditionExpression",
+	"FilterExpression",
+	"ProjectionExpression",
+	"UpdateExpression",
+	"ConditionExpression",
+	"ExpressionAttributeNames",
+	"Bucket",
+	"QueueName",
+	"QueueUrl",
+	"TopicArn",
+	"StreamName",
+	"FunctionName",
+}
+
+// awsObfuscator obfuscates AWS SDK request parameters.
+type awsObfuscator struct {
+	keep map[string]bool // the values of these parameters will not be obfuscated
+	json *jsonObfuscator // obfuscates JSON encoded parameter values
+}
+
+func newAWSObfuscator(cfg *AWSConfig, o *Obfuscator) *awsObfuscator {
+	keep := make(map[string]bool, len(defaultAWSKeepParams)+len(cfg.KeepParams))
+	for _, k := range defaultAWSKeepParams {
+		keep[k] = true
+	}
+	for _, k := range cfg.KeepParams {
+		keep[k] = true
+	}
+	return &awsObfuscator{
+		keep: keep,
+		json: newJSONObfuscator(&JSONConfig{}, o),
+	}
+}
+
+// ObfuscateAWSRequestParam obfuscates the value of the AWS SDK request parameter having the
+// given name. Names may be flattened (e.g. "Key.id.S"), in which case only the top-level
+// parameter name is matched against the kept parameters. JSON encoded values, such as
+// DynamoDB's ExpressionAttributeValues, keep their structure but have all their values
+// replaced with "?". Other values are entirely replaced with "?".
+//
+// If AWS obfuscation is disabled, the value is returned unchanged.
+func (o *Obfuscator) ObfuscateAWSRequestParam(name, val string) string {
+	if o.aws == nil || val == "" {
+		return val
+	}
+	if i := strings.IndexByte(name, '.'); i > -1 {
+		name = name[:i]
+	}
+	if o.aws.keep[name] {
+		return val
+	}
+	if v := strings.TrimSpace(val); strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[") {
+		return obfuscateJSONString(v, o.aws.json)
+	}
+	return "?"
+}
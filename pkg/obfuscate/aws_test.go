@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateAWSRequestParam(t *testing.T) {
+	o := NewObfuscator(Config{AWS: AWSConfig{Enabled: true, KeepParams: []string{"Custom"}}})
+	for _, tt := range []struct {
+		name, in, out string
+	}{
+		{"TableName", "users", "users"},
+		{"KeyConditionExpression", "id = :id AND age > :age", "id = :id AND age > :age"},
+		{"Custom", "kept", "kept"},
+		{"Custom.Nested", "kept", "kept"},
+		{"MessageBody", "secret", "?"},
+		{"Key.id.S", "1234", "?"},
+		{"Empty", "", ""},
+		{
+			"ExpressionAttributeValues",
+			`{":id": {"S": "1234"}, ":age": {"N": "42"}}`,
+			`{":id":{"S":"?"},":age":{"N":"?"}}`,
+		},
+		{
+			"Item",
+			` [{"name": {"S": "Jim"}}]`,
+			`[{"name":{"S":"?"}}]`,
+		},
+		{"Item", `{} "secret"`, `{} "?"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.out, o.ObfuscateAWSRequestParam(tt.name, tt.in))
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		assert.Equal(t, `{"a":{"b":"?"...`, o.ObfuscateAWSRequestParam("Item", `{"a": {"b": "c"`))
+		assert.Equal(t, `[] "?"`, o.ObfuscateAWSRequestParam("Item", `[] "secret"`))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		o := NewObfuscator(Config{})
+		assert.Equal(t, "secret", o.ObfuscateAWSRequestParam("MessageBody", "secret"))
+	})
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build go1.18
+
+package obfuscate
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func FuzzObfuscateGraphQL(f *testing.F) {
+	o := NewObfuscator(Config{})
+	for _, seed := range []string{
+		`{ user(id: 4) { name } }`,
+		`query Users($n: Int = 10) { users(first: $n, ids: [1, 2, 3], name: "Jim") { id } }`,
+		`mutation { createUser(input: {name: "Jim", bio: """long"""}) { id ...Fields } }`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		out, err := o.ObfuscateGraphQLString(query)
+		if err != nil {
+			t.Skipf("Skipping invalid query: %v", err)
+		}
+		if strings.ContainsAny(out, "\"\n\t,#") {
+			t.Fatalf("Obfuscated query %q contains strings, comments or ignored tokens: %q", query, out)
+		}
+		// placeholders are not valid GraphQL, replace them with literals before re-obfuscating
+		again, err := o.ObfuscateGraphQLString(strings.ReplaceAll(out, "?", "0"))
+		if err != nil {
+			t.Fatalf("Couldn't tokenize obfuscated query %q: %v", out, err)
+		}
+		if again != out {
+			t.Fatalf("Obfuscation is not idempotent: (%q) is different from (%q)", again, out)
+		}
+	})
+}
+
+func FuzzObfuscateAWSRequestParam(f *testing.F) {
+	o := NewObfuscator(Config{AWS: AWSConfig{Enabled: true}})
+	f.Add("ExpressionAttributeValues", `{":id": {"S": "1234"}}`)
+	f.Add("TableName", "users")
+	f.Add("Item", `[{"name": {"S": "Jim"}}]`)
+	f.Add("0", "{}")
+	f.Fuzz(func(t *testing.T, name, val string) {
+		out := o.ObfuscateAWSRequestParam(name, val)
+		if val == "" || o.aws.keep[strings.SplitN(name, ".", 2)[0]] {
+			return
+		}
+		if scalar, ok := survivingScalar(val, out); ok {
+			t.Fatalf("Value %q of parameter %q was not obfuscated: %q", scalar, name, out)
+		}
+	})
+}
+
+// survivingScalar returns a scalar (string, number or bool) of val which can still be found in its obfuscated
+// version out. Values holding no scalar, such as {}, [] or whitespace, can't leak anything.
+func survivingScalar(val, out string) (string, bool) {
+	v := strings.TrimSpace(val)
+	in, ok := jsonScalars(v)
+	if !ok || !(strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[")) {
+		// not a JSON object or array, the value itself is the scalar
+		if strings.TrimSpace(val) == "" || val == "?" || out != val {
+			return "", false
+		}
+		return val, true
+	}
+	kept, ok := jsonScalars(out)
+	if !ok {
+		// a valid JSON value must be obfuscated into valid JSON
+		return out, true
+	}
+	for scalar := range in {
+		if scalar != `"?"` && kept[scalar] {
+			return scalar, true
+		}
+	}
+	return "", false
+}
+
+// jsonScalars returns the JSON encoded scalars, except the object keys, of a JSON value, or false if s isn't one.
+func jsonScalars(s string) (map[string]bool, bool) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		// trailing data
+		return nil, false
+	}
+	scalars := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case nil:
+		default:
+			b, _ := json.Marshal(v)
+			scalars[string(b)] = true
+		}
+	}
+	walk(v)
+	return scalars, true
+}
+
+func FuzzObfuscateMessageTags(f *testing.F) {
+	o := NewObfuscator(Config{Message: MessageConfig{Enabled: true}})
+	f.Add("messaging.message.body", "secret")
+	f.Add("messaging.message.headers.auth", "token")
+	f.Add("messaging.destination", "orders")
+	f.Fuzz(func(t *testing.T, k, v string) {
+		meta := map[string]string{k: v}
+		o.ObfuscateMessageTags(meta)
+		if o.msg.redacts(k) && v != "" && meta[k] != "?" {
+			t.Fatalf("Tag %q was not redacted: %q", k, meta[k])
+		}
+		if !o.msg.redacts(k) && meta[k] != v {
+			t.Fatalf("Tag %q was unexpectedly changed: (%q) is different from (%q)", k, meta[k], v)
+		}
+	})
+}
+
+func FuzzObfuscateSQLForDBMS(f *testing.F) {
+	o := NewObfuscator(Config{SQL: SQLConfig{TableNames: true, CollectCommands: true, CollectProcedures: true, DBMSDialect: true}})
+	for _, seed := range []string{
+		"SELECT * FROM `db`.`users` WHERE `id` = 1",
+		"EXEC @ret = [dbo].[update_user] @id = 1",
+		"SELECT * FROM users WHERE id = $1 AND data->>'a' = 'b'",
+		"SELECT * FROM users WHERE id = :id AND name IN (:1, :2)",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		for _, dbms := range []string{"", DBMSMySQL, DBMSSQLServer, DBMSPostgres, DBMSOracle} {
+			oq, err := o.ObfuscateSQLStringForDBMS(query, dbms)
+			if err != nil {
+				continue
+			}
+			if oq.Query == "" {
+				t.Fatalf("Obfuscating %q for %q returned an empty query", query, dbms)
+			}
+			if oq.Cost()-int64(len(oq.Query)) != oq.Metadata.Size {
+				t.Fatalf("Metadata size %d of %q for %q does not match its cost", oq.Metadata.Size, query, dbms)
+			}
+		}
+	})
+}
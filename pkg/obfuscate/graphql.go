@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+)
+
+// ObfuscateGraphQLString normalizes and obfuscates the given GraphQL document. Int, float
+// and string literals (such as inline arguments or variable default values) are replaced
+// with "?", lists of literals are collapsed into a single "?", and ignored tokens (white
+// space, commas and comments) are removed. Variable references, names, booleans, null and
+// enum values are kept as they are. An error is returned if the document can not be
+// tokenized, in which case the caller should discard it.
+//
+// For example:
+//
+//	query Users($n: Int = 10) { users(first: $n, ids: [1, 2, 3], name: "Jim") { id } }
+//
+// becomes:
+//
+//	query Users($n: Int = ?) { users(first: $n ids: [?] name: ?) { id } }
+func (*Obfuscator) ObfuscateGraphQLString(query string) (string, error) {
+	var (
+		out   strings.Builder
+		prev  string // previously written token
+		lists []bool // stack of open lists and objects; true if list
+	)
+	out.Grow(len(query))
+	write := func(tok string) {
+		if out.Len() > 0 && graphqlNeedsSpace(prev, tok) {
+			out.WriteByte(' ')
+		}
+		out.WriteString(tok)
+		prev = tok
+	}
+	tokenizer := newGraphQLTokenizer([]byte(query))
+	for {
+		tok, typ, err := tokenizer.scan()
+		if err != nil {
+			return "", err
+		}
+		switch typ {
+		case graphqlTokenEOF:
+			return out.String(), nil
+		case graphqlTokenInt, graphqlTokenFloat, graphqlTokenString:
+			if len(lists) > 0 && lists[len(lists)-1] && prev == "?" {
+				// collapse all literals within a list into a single one
+				continue
+			}
+			write("?")
+		case graphqlTokenPunctuator:
+			switch tok[0] {
+			case '[':
+				lists = append(lists, true)
+			case '{':
+				lists = append(lists, false)
+			case ']', '}':
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+			}
+			write(string(tok))
+		default:
+			write(string(tok))
+		}
+	}
+}
+
+// graphqlNeedsSpace reports whether a space should separate the tokens prev and next
+// in a normalized GraphQL document.
+func graphqlNeedsSpace(prev, next string) bool {
+	switch prev {
+	case "(", "[", "$", "@":
+		return false
+	}
+	switch next {
+	case "(", ")", "]", ":", "!":
+		return false
+	}
+	return true
+}
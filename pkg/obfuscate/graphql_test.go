@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQLTokenizer(t *testing.T) {
+	type testResult struct {
+		tok string
+		typ graphqlTokenType
+	}
+	for _, tt := range []struct {
+		in  string
+		out []testResult
+	}{
+		{
+			in:  "",
+			out: nil,
+		},
+		{
+			in: "{ user(id: 4) { name } }",
+			out: []testResult{
+				{"{", graphqlTokenPunctuator},
+				{"user", graphqlTokenName},
+				{"(", graphqlTokenPunctuator},
+				{"id", graphqlTokenName},
+				{":", graphqlTokenPunctuator},
+				{"4", graphqlTokenInt},
+				{")", graphqlTokenPunctuator},
+				{"{", graphqlTokenPunctuator},
+				{"name", graphqlTokenName},
+				{"}", graphqlTokenPunctuator},
+				{"}", graphqlTokenPunctuator},
+			},
+		},
+		{
+			in: "# comment\n-1.5e+3, \"a \\\" b\" \"\"\"block \\\"\"\" string\"\"\" ...Frag",
+			out: []testResult{
+				{"-1.5e+3", graphqlTokenFloat},
+				{`"a \" b"`, graphqlTokenString},
+				{`"""block \""" string"""`, graphqlTokenString},
+				{"...", graphqlTokenPunctuator},
+				{"Frag", graphqlTokenName},
+			},
+		},
+		{
+			in: "\xef\xbb\xbf$var_1",
+			out: []testResult{
+				{"$", graphqlTokenPunctuator},
+				{"var_1", graphqlTokenName},
+			},
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			tokenizer := newGraphQLTokenizer([]byte(tt.in))
+			var out []testResult
+			for {
+				tok, typ, err := tokenizer.scan()
+				assert.NoError(t, err)
+				if typ == graphqlTokenEOF {
+					break
+				}
+				out = append(out, testResult{string(tok), typ})
+			}
+			assert.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func TestGraphQLTokenizerErrors(t *testing.T) {
+	for _, in := range []string{
+		`{ user(name: "unterminated) }`,
+		`{ user(name: """unterminated) }`,
+		"{ user(name: \"new\nline\") }",
+		`{ user(id: %) }`,
+		`{ ..user }`,
+		`{ user(id: -) }`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			_, err := NewObfuscator(Config{}).ObfuscateGraphQLString(in)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestObfuscateGraphQL(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			"{ user { name } }",
+			"{ user { name } }",
+		},
+		{
+			`query Users($n: Int = 10) { users(first: $n, ids: [1, 2, 3], name: "Jim") { id } }`,
+			`query Users($n: Int = ?) { users(first: $n ids: [?] name: ?) { id } }`,
+		},
+		{
+			`mutation {
+				# create a new user
+				createUser(input: {name: "Jim", age: 42, score: 1.5, admin: true, role: ADMIN, manager: null}) {
+					id
+				}
+			}`,
+			`mutation { createUser(input: { name: ? age: ? score: ? admin: true role: ADMIN manager: null }) { id } }`,
+		},
+		{
+			`query Q($ids: [ID!]!) @cached(ttl: 60) { nodes(ids: $ids, tags: [[1, 2], ["a", $t, "b"]]) { ...NodeFields ... on User { email } } }`,
+			`query Q($ids: [ID!]!) @cached(ttl: ?) { nodes(ids: $ids tags: [[?] [? $t ?]]) { ... NodeFields ... on User { email } } }`,
+		},
+		{
+			`{ search(text: """multi
+			line""") { id } }`,
+			`{ search(text: ?) { id } }`,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			out, err := NewObfuscator(Config{}).ObfuscateGraphQLString(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func BenchmarkObfuscateGraphQL(b *testing.B) {
+	o := NewObfuscator(Config{})
+	query := `query Users($n: Int = 10) { users(first: $n, ids: [1, 2, 3], name: "Jim") { id name friends(first: 5) { id } } }`
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = o.ObfuscateGraphQLString(query)
+	}
+}
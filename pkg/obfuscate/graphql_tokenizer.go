@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// graphqlTokenType specifies the token type returned by the GraphQL tokenizer.
+type graphqlTokenType int
+
+const (
+	// graphqlTokenEOF marks the end of the document.
+	graphqlTokenEOF graphqlTokenType = iota
+
+	// graphqlTokenPunctuator is one of ! $ & ( ) ... : = @ [ ] { | }.
+	graphqlTokenPunctuator
+
+	// graphqlTokenName is a name, such as a field, argument, type or keyword.
+	graphqlTokenName
+
+	// graphqlTokenInt is an integer value.
+	graphqlTokenInt
+
+	// graphqlTokenFloat is a float value.
+	graphqlTokenFloat
+
+	// graphqlTokenString is a quoted or block string value.
+	graphqlTokenString
+)
+
+// String implements fmt.Stringer.
+func (t graphqlTokenType) String() string {
+	return map[graphqlTokenType]string{
+		graphqlTokenEOF:        "EOF",
+		graphqlTokenPunctuator: "punctuator",
+		graphqlTokenName:       "name",
+		graphqlTokenInt:        "int",
+		graphqlTokenFloat:      "float",
+		graphqlTokenString:     "string",
+	}[t]
+}
+
+// errGraphQLUnterminatedString is returned when a string value is not closed.
+var errGraphQLUnterminatedString = errors.New("unterminated string")
+
+// graphqlTokenizer tokenizes a GraphQL document. Ignored tokens, such as white
+// space, line terminators, commas, comments and the unicode BOM are skipped,
+// as described in the specification:
+// https://spec.graphql.org/October2021/#sec-Language.Source-Text.Ignored-Tokens
+type graphqlTokenizer struct {
+	data []byte
+	off  int
+}
+
+// newGraphQLTokenizer returns a new tokenizer for the given data.
+func newGraphQLTokenizer(data []byte) *graphqlTokenizer {
+	return &graphqlTokenizer{data: data}
+}
+
+// scan returns the next token and its type. An error is returned if the
+// document contains invalid characters or unterminated strings.
+func (t *graphqlTokenizer) scan() (tok []byte, typ graphqlTokenType, err error) {
+	t.skipIgnored()
+	if t.off >= len(t.data) {
+		return nil, graphqlTokenEOF, nil
+	}
+	start := t.off
+	ch := t.data[t.off]
+	switch {
+	case ch == '.':
+		if t.off+2 < len(t.data) && t.data[t.off+1] == '.' && t.data[t.off+2] == '.' {
+			t.off += 3
+			return t.data[start:t.off], graphqlTokenPunctuator, nil
+		}
+		return nil, graphqlTokenEOF, fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+	case isGraphQLPunctuator(ch):
+		t.off++
+		return t.data[start:t.off], graphqlTokenPunctuator, nil
+	case isGraphQLNameStart(ch):
+		for t.off < len(t.data) && isGraphQLNameContinue(t.data[t.off]) {
+			t.off++
+		}
+		return t.data[start:t.off], graphqlTokenName, nil
+	case ch == '-' || isDigit(rune(ch)):
+		typ := t.scanNumber()
+		if t.off == start+1 && ch == '-' {
+			return nil, graphqlTokenEOF, fmt.Errorf("unexpected character %q at position %d", ch, start)
+		}
+		return t.data[start:t.off], typ, nil
+	case ch == '"':
+		if err := t.scanString(); err != nil {
+			return nil, graphqlTokenEOF, err
+		}
+		return t.data[start:t.off], graphqlTokenString, nil
+	}
+	return nil, graphqlTokenEOF, fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+}
+
+// skipIgnored advances the cursor past any ignored tokens.
+func (t *graphqlTokenizer) skipIgnored() {
+	for t.off < len(t.data) {
+		switch ch := t.data[t.off]; ch {
+		case ' ', '\t', '\n', '\r', ',':
+			t.off++
+		case '#':
+			for t.off < len(t.data) && t.data[t.off] != '\n' && t.data[t.off] != '\r' {
+				t.off++
+			}
+		case 0xEF:
+			// unicode BOM (U+FEFF) encoded in UTF-8
+			if t.off+2 < len(t.data) && t.data[t.off+1] == 0xBB && t.data[t.off+2] == 0xBF {
+				t.off += 3
+				continue
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+// scanNumber scans an int or float value and returns its type. Validation is
+// lenient: anything that starts like a number and continues with digits, signs,
+// exponents or dots is consumed as a single literal.
+func (t *graphqlTokenizer) scanNumber() graphqlTokenType {
+	typ := graphqlTokenInt
+	t.off++ // first digit or sign
+	for t.off < len(t.data) {
+		switch ch := t.data[t.off]; {
+		case isDigit(rune(ch)):
+		case ch == '.' || ch == 'e' || ch == 'E':
+			typ = graphqlTokenFloat
+		case (ch == '+' || ch == '-') && (t.data[t.off-1] == 'e' || t.data[t.off-1] == 'E'):
+		default:
+			return typ
+		}
+		t.off++
+	}
+	return typ
+}
+
+// scanString scans a quoted string or a block string, including its quotes.
+func (t *graphqlTokenizer) scanString() error {
+	if t.off+2 < len(t.data) && t.data[t.off+1] == '"' && t.data[t.off+2] == '"' {
+		// block string
+		t.off += 3
+		for t.off < len(t.data) {
+			switch {
+			case t.data[t.off] == '\\' && t.hasPrefix(`\"""`):
+				t.off += 4
+			case t.hasPrefix(`"""`):
+				t.off += 3
+				return nil
+			default:
+				t.off++
+			}
+		}
+		return errGraphQLUnterminatedString
+	}
+	t.off++ // opening quote
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case '\\':
+			t.off += 2
+		case '"':
+			t.off++
+			return nil
+		case '\n', '\r':
+			return errGraphQLUnterminatedString
+		default:
+			t.off++
+		}
+	}
+	return errGraphQLUnterminatedString
+}
+
+// hasPrefix reports whether the data at the current offset starts with s.
+func (t *graphqlTokenizer) hasPrefix(s string) bool {
+	if t.off+len(s) > len(t.data) {
+		return false
+	}
+	return string(t.data[t.off:t.off+len(s)]) == s
+}
+
+func isGraphQLPunctuator(ch byte) bool {
+	switch ch {
+	case '!', '$', '&', '(', ')', ':', '=', '@', '[', ']', '{', '|', '}':
+		return true
+	}
+	return false
+}
+
+func isGraphQLNameStart(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func isGraphQLNameContinue(ch byte) bool {
+	return isGraphQLNameStart(ch) || isDigit(rune(ch))
+}
@@ -92,7 +92,7 @@ func sqlObfuscationTransformer(o *Obfuscator) func(string) string {
 // value scan has ended.
 func (p *jsonObfuscator) setKey() {
 	n := len(p.closures)
-	p.key = n == 0 || p.closures[n-1] // true if we are at top level or in an object
+	p.key = n > 0 && p.closures[n-1] // true if we are in an object
 	p.wiped = false
 }
 
@@ -102,7 +102,12 @@ func (p *jsonObfuscator) obfuscate(data []byte) (string, error) {
 	keyBuf := make([]byte, 0, 10) // recording key token
 	valBuf := make([]byte, 0, 10) // recording value
 
+	// the state left by a previous invalid input must not change how this one is obfuscated
 	p.scan.reset()
+	p.closures = p.closures[:0]
+	p.key, p.wiped = false, false
+	p.keeping, p.keepDepth = false, 0
+	p.transformingValue = false
 	for _, c := range data {
 		p.scan.bytes++
 		op := p.scan.step(p.scan, c)
@@ -122,7 +127,7 @@ func (p *jsonObfuscator) obfuscate(data []byte) (string, error) {
 
 		case scanEndArray, scanEndObject:
 			// array or object closing
-			if n := len(p.closures) - 1; n > 0 {
+			if n := len(p.closures) - 1; n >= 0 {
 				p.closures = p.closures[:n]
 			}
 			fallthrough
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import "strings"
+
+// defaultMessageRedactTags holds the tags which are redacted when message obfuscation is
+// enabled but no tags were configured.
+var defaultMessageRedactTags = []string{
+	"messaging.message.body",
+	"messaging.message.headers.*",
+	"messaging.kafka.message.key",
+	"messaging.rabbitmq.message.headers.*",
+}
+
+// messageObfuscator redacts message header and body tags.
+type messageObfuscator struct {
+	tags     map[string]bool // exact tag names
+	prefixes []string        // tag name prefixes, from patterns ending in "*"
+}
+
+func newMessageObfuscator(cfg *MessageConfig) *messageObfuscator {
+	tags := cfg.RedactTags
+	if len(tags) == 0 {
+		tags = defaultMessageRedactTags
+	}
+	mo := &messageObfuscator{tags: make(map[string]bool, len(tags))}
+	for _, t := range tags {
+		if strings.HasSuffix(t, "*") {
+			mo.prefixes = append(mo.prefixes, strings.TrimSuffix(t, "*"))
+			continue
+		}
+		mo.tags[t] = true
+	}
+	return mo
+}
+
+// redacts reports whether the tag k should be redacted.
+func (mo *messageObfuscator) redacts(k string) bool {
+	if mo.tags[k] {
+		return true
+	}
+	for _, p := range mo.prefixes {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ObfuscateMessageTags replaces with "?" the values of all the tags in meta that match
+// the configured message header and body tags. A configured tag ending in "*" matches
+// all tags having it as a prefix. If message obfuscation is disabled, meta is unchanged.
+func (o *Obfuscator) ObfuscateMessageTags(meta map[string]string) {
+	if o.msg == nil {
+		return
+	}
+	for k, v := range meta {
+		if v != "" && o.msg.redacts(k) {
+			meta[k] = "?"
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateMessageTags(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		meta := map[string]string{
+			"messaging.message.body":            "secret",
+			"messaging.message.headers.auth":    "token",
+			"messaging.kafka.message.key":       "user-1",
+			"messaging.destination":             "orders",
+			"messaging.message.headers.missing": "",
+		}
+		NewObfuscator(Config{Message: MessageConfig{Enabled: true}}).ObfuscateMessageTags(meta)
+		assert.Equal(t, map[string]string{
+			"messaging.message.body":            "?",
+			"messaging.message.headers.auth":    "?",
+			"messaging.kafka.message.key":       "?",
+			"messaging.destination":             "orders",
+			"messaging.message.headers.missing": "",
+		}, meta)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		meta := map[string]string{
+			"amqp.body":             "secret",
+			"amqp.headers.x-user":   "jim",
+			"messaging.destination": "orders",
+		}
+		NewObfuscator(Config{Message: MessageConfig{
+			Enabled:    true,
+			RedactTags: []string{"amqp.body", "amqp.headers.*"},
+		}}).ObfuscateMessageTags(meta)
+		assert.Equal(t, map[string]string{
+			"amqp.body":             "?",
+			"amqp.headers.x-user":   "?",
+			"messaging.destination": "orders",
+		}, meta)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		meta := map[string]string{"messaging.message.body": "secret"}
+		NewObfuscator(Config{}).ObfuscateMessageTags(meta)
+		assert.Equal(t, "secret", meta["messaging.message.body"])
+	})
+}
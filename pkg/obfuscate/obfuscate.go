@@ -23,10 +23,12 @@ import (
 // concurrent use.
 type Obfuscator struct {
 	opts                 *Config
-	es                   *jsonObfuscator // nil if disabled
-	mongo                *jsonObfuscator // nil if disabled
-	sqlExecPlan          *jsonObfuscator // nil if disabled
-	sqlExecPlanNormalize *jsonObfuscator // nil if disabled
+	es                   *jsonObfuscator    // nil if disabled
+	mongo                *jsonObfuscator    // nil if disabled
+	sqlExecPlan          *jsonObfuscator    // nil if disabled
+	sqlExecPlanNormalize *jsonObfuscator    // nil if disabled
+	aws                  *awsObfuscator     // nil if disabled
+	msg                  *messageObfuscator // nil if disabled
 	// sqlLiteralEscapes reports whether we should treat escape characters literally or as escape characters.
 	// Different SQL engines behave in different ways and the tokenizer needs to be generic.
 	sqlLiteralEscapes *atomic.Bool
@@ -82,6 +84,12 @@ type Config struct {
 	// HTTP holds the obfuscation settings for HTTP URLs.
 	HTTP HTTPConfig
 
+	// AWS holds the obfuscation settings for AWS SDK request parameters.
+	AWS AWSConfig
+
+	// Message holds the obfuscation settings for message queue (e.g. Kafka, AMQP) tags.
+	Message MessageConfig
+
 	// Statsd specifies the statsd client to use for reporting metrics.
 	Statsd StatsClient
 
@@ -102,6 +110,12 @@ type SQLConfig struct {
 	// Valid values for this can be found at https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/trace/semantic_conventions/database.md#connection-level-attributes
 	DBMS string `json:"dbms"`
 
+	// DBMSDialect specifies whether the dialect-specific constructs of DBMS that the obfuscator did not
+	// always handle are tokenized as such: SQL Server bracket and MySQL backtick delimited identifiers
+	// are scanned as single (multi-part) identifiers and Oracle bind variables are replaced. It is
+	// opt-in since it changes the obfuscated output for those database management systems.
+	DBMSDialect bool `json:"dbms_dialect"`
+
 	// TableNames specifies whether the obfuscator should also extract the table names that a query addresses,
 	// in addition to obfuscating.
 	TableNames bool `json:"table_names"`
@@ -112,6 +126,11 @@ type SQLConfig struct {
 	// CollectComments specifies whether the obfuscator should extract and return comments as SQL metadata when obfuscating.
 	CollectComments bool `json:"collect_comments"`
 
+	// CollectProcedures specifies whether the obfuscator should extract and return the names of the stored procedures
+	// called (e.g. CALL or EXEC) as SQL metadata when obfuscating. CALL and EXEC are only reported as commands
+	// when it is enabled.
+	CollectProcedures bool `json:"collect_procedures"`
+
 	// ReplaceDigits specifies whether digits in table names and identifiers should be obfuscated.
 	ReplaceDigits bool `json:"replace_digits"`
 
@@ -141,6 +160,8 @@ type SQLMetadata struct {
 	Commands []string `json:"commands"`
 	// Comments holds comments in an SQL statement.
 	Comments []string `json:"comments"`
+	// Procedures holds the names of the stored procedures called in an SQL statement.
+	Procedures []string `json:"procedures"`
 }
 
 // HTTPConfig holds the configuration settings for HTTP obfuscation.
@@ -152,6 +173,27 @@ type HTTPConfig struct {
 	RemovePathDigits bool
 }
 
+// AWSConfig holds the configuration settings for AWS SDK request parameter obfuscation.
+type AWSConfig struct {
+	// Enabled specifies whether AWS request parameters should be obfuscated.
+	Enabled bool
+
+	// KeepParams specifies a set of request parameters for which their values will not
+	// be obfuscated, in addition to a default set of known safe parameters such as
+	// TableName or KeyConditionExpression.
+	KeepParams []string
+}
+
+// MessageConfig holds the configuration settings for message queue tag obfuscation.
+type MessageConfig struct {
+	// Enabled specifies whether message header and body tags should be redacted.
+	Enabled bool
+
+	// RedactTags specifies the tags to redact. Entries ending in "*" match all tags
+	// having the given prefix. If empty, a default set of tags is used.
+	RedactTags []string
+}
+
 // JSONConfig holds the obfuscation configuration for sensitive
 // data found in JSON objects.
 type JSONConfig struct {
@@ -189,6 +231,12 @@ func NewObfuscator(cfg Config) *Obfuscator {
 	if cfg.SQLExecPlanNormalize.Enabled {
 		o.sqlExecPlanNormalize = newJSONObfuscator(&cfg.SQLExecPlanNormalize, &o)
 	}
+	if cfg.AWS.Enabled {
+		o.aws = newAWSObfuscator(&cfg.AWS, &o)
+	}
+	if cfg.Message.Enabled {
+		o.msg = newMessageObfuscator(&cfg.Message)
+	}
 	if cfg.Statsd == nil {
 		cfg.Statsd = &statsd.NoOpClient{}
 	}
@@ -22,6 +22,7 @@ type metadataFinderFilter struct {
 	collectTableNames bool
 	collectCommands   bool
 	collectComments   bool
+	collectProcedures bool
 	replaceDigits     bool
 
 	// size holds the byte size of the metadata collected by the filter.
@@ -34,6 +35,10 @@ type metadataFinderFilter struct {
 	commands []string
 	// comments keeps track of comments encountered by the filter.
 	comments []string
+	// procedures keeps track of the stored procedures called in the query.
+	procedures []string
+	// expectProcedure reports whether the next identifier is expected to be a procedure name.
+	expectProcedure bool
 }
 
 func (f *metadataFinderFilter) Filter(token, lastToken TokenKind, buffer []byte) (TokenKind, []byte, error) {
@@ -45,21 +50,52 @@ func (f *metadataFinderFilter) Filter(token, lastToken TokenKind, buffer []byte)
 	}
 	if f.collectCommands {
 		switch token {
+		case Call, Exec:
+			if !isStatementStart(lastToken) {
+				// e.g. a column named "call"
+				break
+			}
+			fallthrough
 		case Select, Update, Insert, Delete, Join, Alter, Drop, Create, Grant, Revoke, Commit, Begin, Truncate:
 			command := strings.ToUpper(token.String())
 			f.size += int64(len(command))
 			f.commands = append(f.commands, command)
 		}
 	}
+	if f.collectProcedures {
+		switch {
+		case (token == Call || token == Exec) && isStatementStart(lastToken):
+			// CALL [procedureName]
+			// EXEC [procedureName]
+			// {? = CALL [procedureName]}
+			f.expectProcedure = true
+		case f.expectProcedure && token == ID:
+			if buffer[0] == '@' {
+				// SQL Server return status variable, e.g. EXEC @ret = [procedureName]
+				break
+			}
+			name := string(buffer)
+			if f.replaceDigits {
+				nameCopy := make([]byte, len(buffer))
+				copy(nameCopy, buffer)
+				name = string(replaceDigits(nameCopy))
+			}
+			f.size += int64(len(name))
+			f.procedures = append(f.procedures, name)
+			f.expectProcedure = false
+		case token != '=':
+			f.expectProcedure = false
+		}
+	}
 	if f.collectTableNames {
 		switch lastToken {
 		case From, Join:
 			// SELECT ... FROM [tableName]
 			// DELETE FROM [tableName]
 			// ... JOIN [tableName]
-			if r, _ := utf8.DecodeRune(buffer); !unicode.IsLetter(r) {
-				// first character in buffer is not a letter; we might have a nested
-				// query like SELECT * FROM (SELECT ...)
+			if r, _ := utf8.DecodeRune(buffer); !unicode.IsLetter(r) && !(token == ID && (r == '[' || r == '`')) {
+				// first character in buffer is neither a letter nor an identifier delimiter;
+				// we might have a nested query like SELECT * FROM (SELECT ...)
 				break
 			}
 			fallthrough
@@ -79,6 +115,18 @@ func (f *metadataFinderFilter) Filter(token, lastToken TokenKind, buffer []byte)
 	return token, buffer, nil
 }
 
+// isStatementStart reports whether a token following lastToken may be the first one of a statement,
+// or of a JDBC escape sequence such as {? = call procedureName}.
+func isStatementStart(lastToken TokenKind) bool {
+	switch lastToken {
+	case 0, '{', '=', Begin, Filtered, FilteredGroupable:
+		// Filtered and FilteredGroupable are respectively discarded comments and statement
+		// separators (';') or the result placeholder of a JDBC escape sequence ('?').
+		return true
+	}
+	return false
+}
+
 func (f *metadataFinderFilter) storeTableName(name string) {
 	if _, ok := f.tablesSeen[name]; ok {
 		return
@@ -98,10 +146,11 @@ func (f *metadataFinderFilter) storeTableName(name string) {
 // Results returns metadata collected by the filter for an SQL statement.
 func (f *metadataFinderFilter) Results() SQLMetadata {
 	return SQLMetadata{
-		Size:      f.size,
-		TablesCSV: f.tablesCSV.String(),
-		Commands:  f.commands,
-		Comments:  f.comments,
+		Size:       f.size,
+		TablesCSV:  f.tablesCSV.String(),
+		Commands:   f.commands,
+		Comments:   f.comments,
+		Procedures: f.procedures,
 	}
 }
 
@@ -114,6 +163,8 @@ func (f *metadataFinderFilter) Reset() {
 	f.tablesCSV.Reset()
 	f.commands = f.commands[:0]
 	f.comments = f.comments[:0]
+	f.procedures = f.procedures[:0]
+	f.expectProcedure = false
 }
 
 // discardFilter is a token filter which discards certain elements from a query, such as
@@ -174,6 +225,9 @@ func (f *discardFilter) Reset() {}
 // with the "?" character.
 type replaceFilter struct {
 	replaceDigits bool
+	// replaceBindVars reports whether named and positional bind variables (e.g. :name or :1) should
+	// be replaced, similarly to prepared statement parameters (e.g. $1).
+	replaceBindVars bool
 }
 
 // Filter the given token so that it will be replaced if in the token replacement list
@@ -194,6 +248,11 @@ func (f *replaceFilter) Filter(token, lastToken TokenKind, buffer []byte) (token
 	case '?':
 		// Cases like 'ARRAY [ ?, ? ]' should be collapsed into 'ARRAY [ ? ]'
 		return markFilteredGroupable(token), questionMark, nil
+	case ValueArg:
+		if f.replaceBindVars {
+			return markFilteredGroupable(token), questionMark, nil
+		}
+		return token, buffer, nil
 	case TableName, ID:
 		if f.replaceDigits {
 			return token, replaceDigits(buffer), nil
@@ -296,17 +355,34 @@ func (o *Obfuscator) ObfuscateSQLString(in string) (*ObfuscatedQuery, error) {
 // to quantize and obfuscate the given input SQL query string. Quantization removes some elements such as comments
 // and aliases and obfuscation attempts to hide sensitive information in strings and numbers by redacting them.
 func (o *Obfuscator) ObfuscateSQLStringWithOptions(in string, opts *SQLConfig) (*ObfuscatedQuery, error) {
-	if v, ok := o.queryCache.Get(in); ok {
+	key := in
+	if opts.DBMS != "" {
+		// the same query may be obfuscated differently depending on the dialect
+		key = opts.DBMS + ":" + in
+	}
+	if v, ok := o.queryCache.Get(key); ok {
 		return v.(*ObfuscatedQuery), nil
 	}
 	oq, err := o.obfuscateSQLString(in, opts)
 	if err != nil {
 		return oq, err
 	}
-	o.queryCache.Set(in, oq, oq.Cost())
+	o.queryCache.Set(key, oq, oq.Cost())
 	return oq, nil
 }
 
+// ObfuscateSQLStringForDBMS quantizes and obfuscates the given input SQL query string using the
+// obfuscator's SQL configuration, with dialect-specific handling for the given database management
+// system (e.g. DBMSMySQL or DBMSSQLServer). If dbms is empty or unknown, the generic tokenizer is used.
+func (o *Obfuscator) ObfuscateSQLStringForDBMS(in, dbms string) (*ObfuscatedQuery, error) {
+	if dbms == "" || dbms == o.opts.SQL.DBMS {
+		return o.ObfuscateSQLString(in)
+	}
+	opts := o.opts.SQL
+	opts.DBMS = dbms
+	return o.ObfuscateSQLStringWithOptions(in, &opts)
+}
+
 func (o *Obfuscator) obfuscateSQLString(in string, opts *SQLConfig) (*ObfuscatedQuery, error) {
 	lesc := o.useSQLLiteralEscapes()
 	tok := NewSQLTokenizer(in, lesc, opts)
@@ -347,10 +423,14 @@ func attemptObfuscation(tokenizer *SQLTokenizer) (*ObfuscatedQuery, error) {
 			collectTableNames: tokenizer.cfg.TableNames,
 			collectCommands:   tokenizer.cfg.CollectCommands,
 			collectComments:   tokenizer.cfg.CollectComments,
+			collectProcedures: tokenizer.cfg.CollectProcedures,
 			replaceDigits:     tokenizer.cfg.ReplaceDigits,
 		}
-		discard  = discardFilter{keepSQLAlias: tokenizer.cfg.KeepSQLAlias}
-		replace  = replaceFilter{replaceDigits: tokenizer.cfg.ReplaceDigits}
+		discard = discardFilter{keepSQLAlias: tokenizer.cfg.KeepSQLAlias}
+		replace = replaceFilter{
+			replaceDigits:   tokenizer.cfg.ReplaceDigits,
+			replaceBindVars: tokenizer.cfg.DBMS == DBMSOracle && tokenizer.cfg.DBMSDialect,
+		}
 		grouping groupingFilter
 	)
 	defer metadata.Reset()
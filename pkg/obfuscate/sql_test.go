@@ -347,6 +347,59 @@ TABLE T4 UNION CORRESPONDING TABLE T3`,
 				},
 			},
 		},
+		{
+			`CALL update_user(1, 'Jim'); SELECT call FROM calls`,
+			"CALL update_user ( ? ) SELECT call FROM calls",
+			SQLConfig{
+				TableNames:        true,
+				CollectCommands:   true,
+				CollectProcedures: true,
+			},
+			SQLMetadata{
+				TablesCSV:  "calls",
+				Commands:   []string{"CALL", "SELECT"},
+				Procedures: []string{"update_user"},
+			},
+		},
+		{
+			`CALL update_user(1, 'Jim'); SELECT call FROM calls`,
+			"CALL update_user ( ? ) SELECT call FROM calls",
+			SQLConfig{
+				TableNames:      true,
+				CollectCommands: true,
+			},
+			SQLMetadata{
+				TablesCSV: "calls",
+				Commands:  []string{"SELECT"},
+			},
+		},
+		{
+			`{? = call get_user2(?, ?)}`,
+			"{ ? = call get_user? ( ? ) }",
+			SQLConfig{
+				CollectCommands:   true,
+				CollectProcedures: true,
+				ReplaceDigits:     true,
+			},
+			SQLMetadata{
+				Commands:   []string{"CALL"},
+				Procedures: []string{"get_user?"},
+			},
+		},
+		{
+			`/* audit */ EXEC @ret = [dbo].[update_user] @id = 1; EXECUTE sp_who`,
+			"EXEC @ret = [dbo].[update_user] @id = ? EXECUTE sp_who",
+			SQLConfig{
+				DBMS:              DBMSSQLServer,
+				DBMSDialect:       true,
+				CollectCommands:   true,
+				CollectProcedures: true,
+			},
+			SQLMetadata{
+				Commands:   []string{"EXEC", "EXEC"},
+				Procedures: []string{"[dbo].[update_user]", "sp_who"},
+			},
+		},
 	} {
 		t.Run("", func(t *testing.T) {
 			oq, err := NewObfuscator(Config{SQL: tt.cfg}).ObfuscateSQLString(tt.in)
@@ -355,6 +408,7 @@ TABLE T4 UNION CORRESPONDING TABLE T3`,
 			assert.Equal(tt.metadata.TablesCSV, oq.Metadata.TablesCSV)
 			assert.Equal(tt.metadata.Commands, oq.Metadata.Commands)
 			assert.Equal(tt.metadata.Comments, oq.Metadata.Comments)
+			assert.Equal(tt.metadata.Procedures, oq.Metadata.Procedures)
 			// Cost() includes the query text size, exclude it to see if it matches the size the metadata filter collected.
 			assert.Equal(oq.Cost()-int64(len(oq.Query)), oq.Metadata.Size)
 		})
@@ -1261,6 +1315,53 @@ func TestObfuscatorDBMSBehavior(t *testing.T) {
 				DBMS: DBMSSQLServer,
 			},
 		},
+		{
+			"SELECT * FROM [dbo].[users] WHERE [id] = 1",
+			"SELECT * FROM [ dbo ] . [ users ] WHERE [ id ] = ?",
+			SQLConfig{
+				DBMS: DBMSSQLServer,
+			},
+		},
+		{
+			"SELECT [u].[name] AS [n], [o].[total] FROM [dbo].[users] [u] JOIN [dbo].[orders] AS [o] ON [o].[user id] = [u].[id] WHERE [u].[id] = 1",
+			"SELECT [u].[name], [o].[total] FROM [dbo].[users] [u] JOIN [dbo].[orders] ON [o].[user id] = [u].[id] WHERE [u].[id] = ?",
+			SQLConfig{
+				DBMS:        DBMSSQLServer,
+				DBMSDialect: true,
+			},
+		},
+		{
+			"SELECT * FROM [db].dbo.[we]]ird] WHERE [] = 1",
+			"SELECT * FROM [db].dbo.[we]]ird] WHERE [] = ?",
+			SQLConfig{
+				DBMS:        DBMSSQLServer,
+				DBMSDialect: true,
+			},
+		},
+		{
+			"SELECT `u`.`name` FROM `shop`.`users` AS `u` WHERE `u`.`id` = 1",
+			"SELECT `u`.`name` FROM `shop`.`users` WHERE `u`.`id` = ?",
+			SQLConfig{
+				DBMS:        DBMSMySQL,
+				DBMSDialect: true,
+			},
+		},
+		{
+			"SELECT * FROM `shop`.users WHERE `we``ird` = 'a'",
+			"SELECT * FROM `shop`.users WHERE `we``ird` = ?",
+			SQLConfig{
+				DBMS:        DBMSMySQL,
+				DBMSDialect: true,
+			},
+		},
+		{
+			"SELECT * FROM users WHERE id = :id AND name IN (:1, :2) AND created > :created_at",
+			"SELECT * FROM users WHERE id = ? AND name IN ( ? ) AND created > ?",
+			SQLConfig{
+				DBMS:        DBMSOracle,
+				DBMSDialect: true,
+			},
+		},
 	} {
 		t.Run(tt.cfg.DBMS, func(t *testing.T) {
 			oq, err := NewObfuscator(Config{SQL: tt.cfg}).ObfuscateSQLString(tt.in)
@@ -1270,6 +1371,24 @@ func TestObfuscatorDBMSBehavior(t *testing.T) {
 	}
 }
 
+func TestObfuscateSQLStringForDBMS(t *testing.T) {
+	assert := assert.New(t)
+	o := NewObfuscator(Config{SQL: SQLConfig{TableNames: true, DBMSDialect: true, Cache: true}})
+	defer o.Stop()
+	query := "SELECT * FROM [dbo].[users] WHERE [id] = 1"
+
+	oq, err := o.ObfuscateSQLStringForDBMS(query, "")
+	assert.NoError(err)
+	assert.Equal("SELECT * FROM [ dbo ] . [ users ] WHERE [ id ] = ?", oq.Query)
+	assert.Equal("", oq.Metadata.TablesCSV)
+
+	// the cached result of the generic obfuscation must not be returned for a dialect
+	oq, err = o.ObfuscateSQLStringForDBMS(query, DBMSSQLServer)
+	assert.NoError(err)
+	assert.Equal("SELECT * FROM [dbo].[users] WHERE [id] = ?", oq.Query)
+	assert.Equal("[dbo].[users]", oq.Metadata.TablesCSV)
+}
+
 func TestSQLTokenizerIgnoreEscapeFalse(t *testing.T) {
 	cases := []sqlTokenizerTestCase{
 		{
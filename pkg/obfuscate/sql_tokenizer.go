@@ -71,6 +71,8 @@ const (
 	Insert
 	Into
 	Join
+	Call
+	Exec
 	TableName
 	ColonCast
 
@@ -146,6 +148,8 @@ var tokenKindStrings = map[TokenKind]string{
 	Insert:                       "Insert",
 	Into:                         "Into",
 	Join:                         "Join",
+	Call:                         "Call",
+	Exec:                         "Exec",
 	TableName:                    "TableName",
 	ColonCast:                    "ColonCast",
 	FilteredGroupable:            "FilteredGroupable",
@@ -177,6 +181,10 @@ const (
 	DBMSSQLServer = "mssql"
 	// DBMSPostgres is a PostgreSQL Server
 	DBMSPostgres = "postgresql"
+	// DBMSMySQL is a MySQL Server
+	DBMSMySQL = "mysql"
+	// DBMSOracle is an Oracle Server
+	DBMSOracle = "oracle"
 )
 
 const escapeCharacter = '\\'
@@ -243,6 +251,9 @@ var keywords = map[string]TokenKind{
 	"INSERT":    Insert,
 	"INTO":      Into,
 	"JOIN":      Join,
+	"CALL":      Call,
+	"EXEC":      Exec,
+	"EXECUTE":   Exec,
 }
 
 // Err returns the last error that the tokenizer encountered, or nil.
@@ -322,7 +333,13 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 				}
 			}
 			fallthrough
-		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', '[', ']':
+		case '[':
+			if tkn.cfg.DBMS == DBMSSQLServer && tkn.cfg.DBMSDialect {
+				// SQL Server delimited identifier, e.g. [dbo].[users]
+				return tkn.scanQuotedIdentifier('[', ']')
+			}
+			return TokenKind(ch), tkn.bytes()
+		case '=', ',', ';', '(', ')', '+', '*', '&', '|', '^', ']':
 			return TokenKind(ch), tkn.bytes()
 		case '.':
 			if isDigit(tkn.lastChar) {
@@ -453,6 +470,10 @@ func (tkn *SQLTokenizer) Scan() (TokenKind, []byte) {
 		case '"':
 			return tkn.scanString(ch, DoubleQuotedString)
 		case '`':
+			if tkn.cfg.DBMS == DBMSMySQL && tkn.cfg.DBMSDialect {
+				// MySQL quoted identifier, e.g. `db`.`users`
+				return tkn.scanQuotedIdentifier('`', '`')
+			}
 			return tkn.scanString(ch, ID)
 		case '%':
 			if tkn.lastChar == '(' {
@@ -585,11 +606,54 @@ func (tkn *SQLTokenizer) scanIdentifier() (TokenKind, []byte) {
 	var space [256]byte
 	upper := toUpper(t, space[:0])
 	if keywordID, found := keywords[string(upper)]; found {
+		if (keywordID == Call || keywordID == Exec) && !tkn.cfg.CollectProcedures {
+			// procedure calls are only told apart from identifiers when they are collected
+			return ID, t
+		}
 		return keywordID, t
 	}
 	return ID, t
 }
 
+// scanQuotedIdentifier scans an identifier delimited by the given opening and closing characters,
+// such as MySQL's `name` or SQL Server's [name], the opening one having already been consumed.
+// Multi-part identifiers (e.g. [db].[dbo].[users] or `db`.users) are scanned as a single one.
+// The delimiters are kept, since the identifier may contain characters (e.g. spaces or dots)
+// that would make the query ambiguous without them.
+func (tkn *SQLTokenizer) scanQuotedIdentifier(opening, closing rune) (TokenKind, []byte) {
+	for {
+		for {
+			ch := tkn.lastChar
+			tkn.advance()
+			if ch == EndChar {
+				tkn.setErr("unexpected EOF in quoted identifier")
+				return LexError, tkn.bytes()
+			}
+			if ch == closing {
+				if tkn.lastChar != closing {
+					break
+				}
+				// a doubled closing delimiter is an escaped one
+				tkn.advance()
+			}
+		}
+		if tkn.lastChar != '.' {
+			return ID, tkn.bytes()
+		}
+		tkn.advance()
+		// the next part may not be quoted, e.g. [db].dbo.[users]
+		last := '.'
+		for isLetter(tkn.lastChar) || isDigit(tkn.lastChar) || tkn.lastChar == '.' || tkn.lastChar == '*' {
+			last = tkn.lastChar
+			tkn.advance()
+		}
+		if tkn.lastChar != opening || last != '.' {
+			return ID, tkn.bytes()
+		}
+		tkn.advance()
+	}
+}
+
 func (tkn *SQLTokenizer) scanVariableIdentifier(prefix rune) (TokenKind, []byte) {
 	for tkn.advance(); tkn.lastChar != ')' && tkn.lastChar != EndChar; tkn.advance() {
 	}
@@ -13,6 +13,8 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 	"text/template"
 	"time"
 
@@ -57,7 +59,7 @@ var (
 		"timeMilli": func(v int64) string { return time.UnixMilli(v).UTC().Format(time.RFC3339) },
 		"timeNano":  func(v int64) string { return time.Unix(0, v).UTC().Format(time.RFC3339) },
 		"time":      func(v int64) string { return time.Unix(v, 0).UTC().Format(time.RFC3339) },
-		"cpupct":    func(v float32) string { return humanize.FtoaWithDigits(math.Round(float64(v)*100)/100, 2) + "%" },
+		"cpupct":    formatCPUPct,
 		"io": func(v float32) string {
 			if v < 0 {
 				return "-"
@@ -67,6 +69,10 @@ var (
 	}
 )
 
+func formatCPUPct(v float32) string {
+	return humanize.FtoaWithDigits(math.Round(float64(v)*100)/100, 2) + "%"
+}
+
 // HumanFormat takes the messages produced by a check run and outputs them in a human-readable format
 func HumanFormat(check string, msgs []model.MessageBody, w io.Writer) error {
 	switch check {
@@ -371,6 +377,46 @@ func HumanFormatProcessEvents(msgs []model.MessageBody, w io.Writer, checkOutput
 	)
 }
 
+// HumanFormatProcessList outputs a list of processes as a table, one process per line
+func HumanFormatProcessList(entries []*ProcessListEntry, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PID\tPPID\tUSER\tCPU\tRSS\tREAD/S\tWRITE/S\tCONTAINER\tCOMMAND")
+	for _, e := range entries {
+		var (
+			ppid    int32
+			user    string
+			cmdline string
+			read    = "-"
+			write   = "-"
+		)
+		if e.Command != nil {
+			ppid = e.Command.Ppid
+			cmdline = strings.Join(e.Command.Args, " ")
+		}
+		if e.User != nil {
+			user = e.User.Name
+			if user == "" {
+				user = strconv.Itoa(int(e.User.Uid))
+			}
+		}
+		if e.IoStat != nil && e.IoStat.ReadBytesRate >= 0 {
+			read = humanize.Bytes(uint64(e.IoStat.ReadBytesRate))
+		}
+		if e.IoStat != nil && e.IoStat.WriteBytesRate >= 0 {
+			write = humanize.Bytes(uint64(e.IoStat.WriteBytesRate))
+		}
+		containerID := e.ContainerId
+		if len(containerID) > 12 {
+			containerID = containerID[:12]
+		}
+
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Pid, ppid, user, formatCPUPct(cpuPct(e.Process)), humanize.Bytes(rss(e.Process)),
+			read, write, containerID, cmdline)
+	}
+	return tw.Flush()
+}
+
 func renderTemplates(w io.Writer, data interface{}, templates ...string) error {
 	for idx, name := range templates {
 		t := template.Must(template.New("tmpl-" + strconv.Itoa(idx)).Funcs(fnMap).Parse(name))
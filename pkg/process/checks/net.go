@@ -14,16 +14,22 @@ import (
 	model "github.com/DataDog/agent-payload/v5/process"
 	"go.uber.org/atomic"
 
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/process/config"
 	"github.com/DataDog/datadog-agent/pkg/process/dockerproxy"
 	"github.com/DataDog/datadog-agent/pkg/process/net"
+	"github.com/DataDog/datadog-agent/pkg/process/net/export"
 	"github.com/DataDog/datadog-agent/pkg/process/net/resolver"
 	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/statsd"
 	putil "github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/util/cloudproviders"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -52,6 +58,10 @@ type ConnectionsCheck struct {
 	// it's in format map[int32][]*model.Connections
 	lastConnsByPID *atomic.Value
 	probe          procutil.Probe
+	// exporter streams the collected connections to a local destination, when enabled
+	exporter *export.Exporter
+	// kafkaStats reports whether system-probe monitors Kafka requests, which are then sent as metrics
+	kafkaStats bool
 }
 
 // Init initializes a ConnectionsCheck instance.
@@ -82,6 +92,15 @@ func (c *ConnectionsCheck) Init(cfg *config.AgentConfig, _ *model.SystemInfo) {
 		log.Infof("no network ID detected: %s", err)
 	}
 	c.networkID = networkID
+
+	c.kafkaStats = ddconfig.Datadog.GetBool("network_config.enable_kafka_monitoring")
+
+	if ddconfig.Datadog.GetBool("process_config.connections_export.enabled") {
+		c.exporter, err = export.NewExporter(export.ReadConfig(cfg.HostName), containerTags, statsd.Client)
+		if err != nil {
+			log.Errorf("connections won't be exported: %s", err)
+		}
+	}
 }
 
 // Name returns the name of the ConnectionsCheck.
@@ -121,13 +140,26 @@ func (c *ConnectionsCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.
 	LocalResolver.Resolve(conns)
 
 	c.lastConnsByPID.Store(getConnectionsByPID(conns))
+	if c.kafkaStats {
+		c.reportKafkaStats()
+	}
+
+	conns.Conns = c.enrichConnections(conns.Conns)
+	if c.exporter != nil {
+		// connections must be exported before being batched, which rewrites their indexes
+		c.exporter.Export(conns)
+	}
 
 	log.Debugf("collected connections in %s", time.Since(start))
-	return batchConnections(cfg, groupID, c.enrichConnections(conns.Conns), conns.Dns, c.networkID, conns.ConnTelemetryMap, conns.CompilationTelemetryByAsset, conns.Domains, conns.Routes, conns.Tags, conns.AgentConfiguration), nil
+	return batchConnections(cfg, groupID, conns.Conns, conns.Dns, c.networkID, conns.ConnTelemetryMap, conns.CompilationTelemetryByAsset, conns.Domains, conns.Routes, conns.Tags, conns.AgentConfiguration), nil
 }
 
 // Cleanup frees any resource held by the ConnectionsCheck before the agent exits
-func (c *ConnectionsCheck) Cleanup() {}
+func (c *ConnectionsCheck) Cleanup() {
+	if c.exporter != nil {
+		c.exporter.Stop()
+	}
+}
 
 func (c *ConnectionsCheck) getConnections() (*model.Connections, error) {
 	tu, err := net.GetRemoteSystemProbeUtil()
@@ -140,6 +172,22 @@ func (c *ConnectionsCheck) getConnections() (*model.Connections, error) {
 	return tu.GetConnections(c.tracerClientID)
 }
 
+// reportKafkaStats sends the Kafka requests monitored by system-probe since the
+// previous run as metrics. They are served apart from the connections, since the
+// connections payload doesn't have a dedicated Kafka message.
+func (c *ConnectionsCheck) reportKafkaStats() {
+	tu, err := net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return
+	}
+	aggregations, err := tu.GetKafkaStats(c.tracerClientID)
+	if err != nil {
+		log.Debugf("could not retrieve kafka stats from system-probe: %s", err)
+		return
+	}
+	sendKafkaRequestCounts(statsd.Client, aggregations)
+}
+
 func (c *ConnectionsCheck) enrichConnections(conns []*model.Connection) []*model.Connection {
 	// Process create-times required to construct unique process hash keys on the backend
 	createTimeForPID := ProcessNotify.GetCreateTimes(connectionPIDs(conns))
@@ -153,6 +201,15 @@ func (c *ConnectionsCheck) enrichConnections(conns []*model.Connection) []*model
 	return conns
 }
 
+// containerTags returns the tags of the given container, to be added to the exported connections
+func containerTags(containerID string) []string {
+	tags, err := tagger.Tag(containers.BuildTaggerEntityName(containerID), collectors.HighCardinality)
+	if err != nil {
+		log.Debugf("could not collect tags for container %q: %s", containerID, err)
+	}
+	return tags
+}
+
 func (c *ConnectionsCheck) getLastConnectionsByPID() map[int32][]*model.Connection {
 	if result := c.lastConnsByPID.Load(); result != nil {
 		return result.(map[int32][]*model.Connection)
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package checks
+
+import (
+	"strconv"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+)
+
+// kafkaRequestsKey identifies a group of Kafka requests of the same type and
+// version, sent to the same topic of the same broker.
+type kafkaRequestsKey struct {
+	broker     string
+	topic      string
+	api        string
+	apiVersion uint16
+}
+
+// sendKafkaRequestCounts sends the number of Kafka requests monitored by system-probe
+// as metrics, aggregated by broker, topic, request type and version. The client
+// side of the connections is left out to bound the number of contexts.
+func sendKafkaRequestCounts(client statsd.ClientInterface, aggregations []kafka.Aggregation) {
+	counts := make(map[kafkaRequestsKey]int64)
+	for _, a := range aggregations {
+		key := kafkaRequestsKey{broker: a.Server, topic: a.Topic, api: a.API, apiVersion: a.APIVersion}
+		counts[key] += int64(a.Count)
+	}
+
+	for key, count := range counts {
+		tags := []string{
+			"kafka_broker:" + key.broker,
+			"kafka_topic:" + key.topic,
+			"kafka_api:" + key.api,
+			"kafka_api_version:" + key.api + "_v" + strconv.Itoa(int(key.apiVersion)),
+		}
+		client.Count("network.kafka.requests", count, tags, 1) //nolint:errcheck
+	}
+}
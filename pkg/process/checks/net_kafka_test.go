@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package checks
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
+)
+
+type recordingCountsClient struct {
+	statsd.NoOpClient
+	counts map[string]int64
+}
+
+func (c *recordingCountsClient) Count(name string, value int64, tags []string, _ float64) error {
+	c.counts[name+"|"+strings.Join(tags, ",")] += value
+	return nil
+}
+
+func TestSendKafkaRequestCounts(t *testing.T) {
+	client := &recordingCountsClient{counts: make(map[string]int64)}
+	sendKafkaRequestCounts(client, []kafka.Aggregation{
+		{Client: "10.0.0.1:52800", Server: "10.0.0.2:9092", Topic: "orders", API: "produce", APIVersion: 8, Count: 2},
+		{Client: "10.0.0.3:41000", Server: "10.0.0.2:9092", Topic: "orders", API: "produce", APIVersion: 8, Count: 3},
+		{Client: "10.0.0.1:52800", Server: "10.0.0.2:9092", Topic: "orders", API: "fetch", APIVersion: 12, Count: 1},
+	})
+
+	keys := make([]string, 0, len(client.counts))
+	for k := range client.counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{
+		"network.kafka.requests|kafka_broker:10.0.0.2:9092,kafka_topic:orders,kafka_api:fetch,kafka_api_version:fetch_v12",
+		"network.kafka.requests|kafka_broker:10.0.0.2:9092,kafka_topic:orders,kafka_api:produce,kafka_api_version:produce_v8",
+	}, keys)
+	assert.EqualValues(t, 1, client.counts[keys[0]])
+	assert.EqualValues(t, 5, client.counts[keys[1]])
+}
@@ -13,6 +13,7 @@ import (
 	model "github.com/DataDog/agent-payload/v5/process"
 	"github.com/DataDog/gopsutil/cpu"
 
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/process/config"
 	"github.com/DataDog/datadog-agent/pkg/process/net"
 	"github.com/DataDog/datadog-agent/pkg/process/procutil"
@@ -61,6 +62,12 @@ type ProcessCheck struct {
 
 	maxBatchSize  int
 	maxBatchBytes int
+
+	// networkMetricsEnabled tells the process check whether to report the network activity of processes as metrics
+	networkMetricsEnabled bool
+
+	// anomalies runs anomaly detectors on the processes collected by each run, when enabled
+	anomalies *anomalyDetector
 }
 
 // Init initializes the singleton ProcessCheck.
@@ -79,6 +86,10 @@ func (p *ProcessCheck) Init(_ *config.AgentConfig, info *model.SystemInfo) {
 
 	p.maxBatchSize = getMaxBatchSize()
 	p.maxBatchBytes = getMaxBatchBytes()
+	p.networkMetricsEnabled = ddconfig.Datadog.GetBool("process_config.process_collection.network_metrics.enabled")
+	if ddconfig.Datadog.GetBool("process_config.anomaly_detection.enabled") {
+		p.anomalies = newAnomalyDetector(newAnomalyDetectorConfig(), statsd.Client)
+	}
 }
 
 // Name returns the name of the ProcessCheck.
@@ -172,7 +183,11 @@ func (p *ProcessCheck) run(cfg *config.AgentConfig, groupID int32, collectRealTi
 
 	connsByPID := Connections.getLastConnectionsByPID()
 	procsByCtr := fmtProcesses(cfg, procs, p.lastProcs, pidToCid, cpuTimes[0], p.lastCPUTime, p.lastRun, connsByPID)
+	p.detectAnomalies(procsByCtr, procs)
 	messages, totalProcs, totalContainers := createProcCtrMessages(procsByCtr, containers, cfg, p.maxBatchSize, p.maxBatchBytes, p.sysInfo, groupID, p.networkID)
+	if p.networkMetricsEnabled {
+		reportProcessNetworks(statsd.Client, aggregateProcessNetworks(procs, pidToCid, connsByPID, cfg.CheckIntervals[config.ConnectionsCheckName], containerTags))
+	}
 
 	// Store the last state for comparison on the next run.
 	// Note: not storing the filtered in case there are new processes that haven't had a chance to show up twice.
@@ -221,6 +236,14 @@ func (p *ProcessCheck) run(cfg *config.AgentConfig, groupID int32, collectRealTi
 	return result, nil
 }
 
+// detectAnomalies runs the anomaly detectors, if enabled, on the processes collected by a standard run, so that
+// they do not depend on realtime mode being active
+func (p *ProcessCheck) detectAnomalies(procsByCtr map[string][]*model.Process, procs map[int32]*procutil.Process) {
+	if p.anomalies != nil {
+		p.anomalies.process(time.Now(), procsByCtr, procs)
+	}
+}
+
 func procsToStats(procs map[int32]*procutil.Process) map[int32]*procutil.Stats {
 	stats := map[int32]*procutil.Stats{}
 	for pid, proc := range procs {
@@ -0,0 +1,303 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/dustin/go-humanize"
+
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Kinds of anomalies detected on the processes collected by the process check
+const (
+	anomalyRSSGrowth    = "rss_growth"
+	anomalyCPUPegged    = "cpu_pegged"
+	anomalyFDExhaustion = "fd_exhaustion"
+)
+
+// anomalyDetectorConfig holds the thresholds of the detectors. A detector is disabled when its threshold is 0.
+type anomalyDetectorConfig struct {
+	maxTrackedProcesses int
+
+	// rssGrowthWindow is the period the RSS growth slope is computed over
+	rssGrowthWindow time.Duration
+	// rssGrowthMinRate is the slope, in bytes per second, past which the RSS growth is anomalous
+	rssGrowthMinRate float64
+
+	// cpuThreshold is the CPU usage percentage, 100 being a full core, a process must stay above...
+	cpuThreshold float32
+	// ...for cpuDuration to be anomalous
+	cpuDuration time.Duration
+
+	// fdLimitRatio is the share of its RLIMIT_NOFILE soft limit past which the open files of a process are anomalous
+	fdLimitRatio float64
+}
+
+func newAnomalyDetectorConfig() anomalyDetectorConfig {
+	return anomalyDetectorConfig{
+		maxTrackedProcesses: ddconfig.Datadog.GetInt("process_config.anomaly_detection.max_tracked_processes"),
+		rssGrowthWindow:     ddconfig.Datadog.GetDuration("process_config.anomaly_detection.rss_growth.window"),
+		rssGrowthMinRate:    ddconfig.Datadog.GetFloat64("process_config.anomaly_detection.rss_growth.min_bytes_per_second"),
+		cpuThreshold:        float32(ddconfig.Datadog.GetFloat64("process_config.anomaly_detection.cpu.threshold")),
+		cpuDuration:         ddconfig.Datadog.GetDuration("process_config.anomaly_detection.cpu.duration"),
+		fdLimitRatio:        ddconfig.Datadog.GetFloat64("process_config.anomaly_detection.open_files.limit_ratio"),
+	}
+}
+
+type rssSample struct {
+	ts  time.Time
+	rss uint64
+}
+
+// processAnomalyState is what the detectors remember about a process between two samples
+type processAnomalyState struct {
+	// createTime tells apart the processes that successively get the same PID
+	createTime int64
+
+	rssSamples []rssSample
+	// cpuHighSince is when the CPU usage of the process went above the threshold, zero if it is below
+	cpuHighSince time.Time
+
+	fdLimit     uint64
+	fdLimitRead bool
+
+	active map[string]bool
+}
+
+// anomalyDetector looks for processes whose RSS keeps growing, which peg the CPU, or which run out of file
+// descriptors, and sends an event when they start and stop being anomalous. It keeps state only for the
+// processes of the last samples, and for at most maxTrackedProcesses of them.
+type anomalyDetector struct {
+	cfg    anomalyDetectorConfig
+	states map[int32]*processAnomalyState
+
+	client         statsd.ClientInterface
+	openFilesLimit func(pid int32) (uint64, error)
+	ctrTags        func(containerID string) []string
+}
+
+func newAnomalyDetector(cfg anomalyDetectorConfig, client statsd.ClientInterface) *anomalyDetector {
+	return &anomalyDetector{
+		cfg:            cfg,
+		states:         make(map[int32]*processAnomalyState),
+		client:         client,
+		openFilesLimit: openFilesLimit,
+		ctrTags:        containerTags,
+	}
+}
+
+// process runs the detectors on the processes collected by a run of the process check, grouped by container.
+// procs holds their metadata, used to tag the events.
+func (d *anomalyDetector) process(now time.Time, procsByCtr map[string][]*model.Process, procs map[int32]*procutil.Process) {
+	// Forget the processes that exited first, to make room for the new ones
+	seen := make(map[int32]struct{}, len(d.states))
+	for _, ctrProcs := range procsByCtr {
+		for _, stat := range ctrProcs {
+			seen[stat.Pid] = struct{}{}
+		}
+	}
+	for pid := range d.states {
+		if _, ok := seen[pid]; !ok {
+			delete(d.states, pid)
+		}
+	}
+
+	for _, ctrProcs := range procsByCtr {
+		for _, stat := range ctrProcs {
+			state := d.getState(stat)
+			if state == nil {
+				continue
+			}
+
+			proc := procs[stat.Pid]
+			anomalous, details := d.rssGrowing(state, now, stat)
+			d.update(state, anomalyRSSGrowth, anomalous, details, stat, proc)
+			anomalous, details = d.cpuPegged(state, now, stat)
+			d.update(state, anomalyCPUPegged, anomalous, details, stat, proc)
+			anomalous, details = d.fdExhausted(state, stat)
+			d.update(state, anomalyFDExhaustion, anomalous, details, stat, proc)
+		}
+	}
+}
+
+// getState returns the state of the process of a sample, resetting it if the PID got reused
+func (d *anomalyDetector) getState(stat *model.Process) *processAnomalyState {
+	state, ok := d.states[stat.Pid]
+	if ok && state.createTime == stat.CreateTime {
+		return state
+	}
+	if !ok && len(d.states) >= d.cfg.maxTrackedProcesses {
+		return nil
+	}
+
+	state = &processAnomalyState{createTime: stat.CreateTime, active: make(map[string]bool)}
+	d.states[stat.Pid] = state
+	return state
+}
+
+func (d *anomalyDetector) rssGrowing(state *processAnomalyState, now time.Time, stat *model.Process) (bool, string) {
+	if d.cfg.rssGrowthWindow <= 0 || d.cfg.rssGrowthMinRate <= 0 || stat.Memory == nil {
+		return false, ""
+	}
+
+	// Keep the samples of the window, plus the last one before it so that the window is fully covered
+	state.rssSamples = append(state.rssSamples, rssSample{ts: now, rss: stat.Memory.Rss})
+	start := now.Add(-d.cfg.rssGrowthWindow)
+	drop := 0
+	for drop+1 < len(state.rssSamples) && !state.rssSamples[drop+1].ts.After(start) {
+		drop++
+	}
+	state.rssSamples = append(state.rssSamples[:0], state.rssSamples[drop:]...)
+	if len(state.rssSamples) < 2 || state.rssSamples[0].ts.After(start) {
+		return false, ""
+	}
+
+	slope := rssSlope(state.rssSamples)
+	period := state.rssSamples[len(state.rssSamples)-1].ts.Sub(state.rssSamples[0].ts)
+	return slope >= d.cfg.rssGrowthMinRate,
+		fmt.Sprintf("RSS grew by %s/s over the last %s, to %s", humanize.Bytes(uint64(slope)), period, humanize.Bytes(stat.Memory.Rss))
+}
+
+// rssSlope returns the slope, in bytes per second, of the least squares regression of the RSS samples
+func rssSlope(samples []rssSample) float64 {
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x := s.ts.Sub(samples[0].ts).Seconds()
+		y := float64(s.rss)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+func (d *anomalyDetector) cpuPegged(state *processAnomalyState, now time.Time, stat *model.Process) (bool, string) {
+	if d.cfg.cpuThreshold <= 0 || d.cfg.cpuDuration <= 0 || stat.Cpu == nil {
+		return false, ""
+	}
+
+	if stat.Cpu.TotalPct < d.cfg.cpuThreshold {
+		state.cpuHighSince = time.Time{}
+		return false, ""
+	}
+	if state.cpuHighSince.IsZero() {
+		state.cpuHighSince = now
+	}
+	high := now.Sub(state.cpuHighSince)
+	return high >= d.cfg.cpuDuration,
+		fmt.Sprintf("CPU usage stayed above %s for %s, at %s", formatCPUPct(d.cfg.cpuThreshold), high, formatCPUPct(stat.Cpu.TotalPct))
+}
+
+func (d *anomalyDetector) fdExhausted(state *processAnomalyState, stat *model.Process) (bool, string) {
+	if d.cfg.fdLimitRatio <= 0 || stat.OpenFdCount <= 0 {
+		return false, ""
+	}
+
+	if !state.fdLimitRead {
+		state.fdLimitRead = true
+		limit, err := d.openFilesLimit(stat.Pid)
+		if err != nil {
+			log.Debugf("could not read the open files limit of pid %d: %s", stat.Pid, err)
+		}
+		state.fdLimit = limit
+	}
+	if state.fdLimit == 0 {
+		return false, ""
+	}
+
+	return float64(stat.OpenFdCount) >= d.cfg.fdLimitRatio*float64(state.fdLimit),
+		fmt.Sprintf("%d files are open, out of a limit of %d", stat.OpenFdCount, state.fdLimit)
+}
+
+// update sends an event when a process starts or stops being anomalous
+func (d *anomalyDetector) update(state *processAnomalyState, kind string, anomalous bool, details string, stat *model.Process, proc *procutil.Process) {
+	if anomalous == state.active[kind] {
+		return
+	}
+	state.active[kind] = anomalous
+
+	name := strconv.Itoa(int(stat.Pid))
+	if proc != nil {
+		if n := processName(proc); n != "" {
+			name = n
+		}
+	}
+
+	tags := []string{"anomaly:" + kind, "process_name:" + name}
+	if stat.ContainerId != "" {
+		tags = append(tags, d.ctrTags(stat.ContainerId)...)
+	}
+
+	event := &statsd.Event{
+		AggregationKey: fmt.Sprintf("process_anomaly:%s:%d:%d", kind, stat.Pid, stat.CreateTime),
+		SourceTypeName: "process",
+		Tags:           tags,
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "PID: %d", stat.Pid)
+	if proc != nil && len(proc.Cmdline) > 0 {
+		fmt.Fprintf(&text, "\nCommand: %s", strings.Join(proc.Cmdline, " "))
+	}
+	if stat.ContainerId != "" {
+		fmt.Fprintf(&text, "\nContainer: %s", stat.ContainerId)
+	}
+
+	if anomalous {
+		event.Title = fmt.Sprintf("Process %s: %s", name, anomalyTitles[kind])
+		event.AlertType = statsd.Warning
+		fmt.Fprintf(&text, "\n%s", details)
+	} else {
+		event.Title = fmt.Sprintf("Process %s: %s recovered", name, anomalyTitles[kind])
+		event.AlertType = statsd.Success
+	}
+	event.Text = text.String()
+
+	if err := d.client.Event(event); err != nil {
+		log.Debugf("could not send process anomaly event: %s", err)
+	}
+}
+
+var anomalyTitles = map[string]string{
+	anomalyRSSGrowth:    "sustained memory growth",
+	anomalyCPUPegged:    "sustained high CPU usage",
+	anomalyFDExhaustion: "open files approaching the limit",
+}
+
+// parseOpenFilesLimit returns the soft limit of open files from the content of /proc/[pid]/limits, 0 meaning unlimited
+func parseOpenFilesLimit(content string) (uint64, error) {
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, nil
+		}
+		return strconv.ParseUint(fields[0], 10, 64)
+	}
+	return 0, errors.New("no open files limit found")
+}
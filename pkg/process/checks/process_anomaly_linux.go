@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package checks
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// openFilesLimit returns the RLIMIT_NOFILE soft limit of a process, 0 meaning unlimited
+func openFilesLimit(pid int32) (uint64, error) {
+	content, err := os.ReadFile(util.HostProc(strconv.Itoa(int(pid)), "limits"))
+	if err != nil {
+		return 0, err
+	}
+	return parseOpenFilesLimit(string(content))
+}
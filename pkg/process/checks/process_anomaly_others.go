@@ -0,0 +1,16 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package checks
+
+import "errors"
+
+// openFilesLimit is only supported on Linux
+func openFilesLimit(_ int32) (uint64, error) {
+	return 0, errors.New("the open files limit of processes is only available on linux")
+}
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package checks
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+type recordingEventsClient struct {
+	statsd.NoOpClient
+	events []*statsd.Event
+}
+
+func (c *recordingEventsClient) Event(e *statsd.Event) error {
+	c.events = append(c.events, e)
+	return nil
+}
+
+func newTestAnomalyDetector(cfg anomalyDetectorConfig) (*anomalyDetector, *recordingEventsClient) {
+	client := &recordingEventsClient{}
+	d := newAnomalyDetector(cfg, client)
+	d.openFilesLimit = func(int32) (uint64, error) { return 0, errors.New("no limit") }
+	d.ctrTags = func(string) []string { return nil }
+	return d, client
+}
+
+func anomalyProcs(procs ...*model.Process) map[string][]*model.Process {
+	return map[string][]*model.Process{"": procs}
+}
+
+func anomalyProc(pid int32, createTime int64, rss uint64, cpu float32, fds int32) *model.Process {
+	return &model.Process{
+		Pid:         pid,
+		CreateTime:  createTime,
+		Memory:      &model.MemoryStat{Rss: rss},
+		Cpu:         &model.CPUStat{TotalPct: cpu},
+		OpenFdCount: fds,
+	}
+}
+
+func TestAnomalyDetectorRSSGrowth(t *testing.T) {
+	d, client := newTestAnomalyDetector(anomalyDetectorConfig{
+		maxTrackedProcesses: 10,
+		rssGrowthWindow:     30 * time.Second,
+		rssGrowthMinRate:    100,
+	})
+	procs := map[int32]*procutil.Process{1: {Pid: 1, Cmdline: []string{"/usr/bin/leaky", "--serve"}}}
+
+	now := time.Now()
+	// The window is not covered until 30s after the first run
+	for i := 0; i < 3; i++ {
+		d.process(now.Add(time.Duration(i)*10*time.Second), anomalyProcs(anomalyProc(1, 100, uint64(i)*2000, 0, 0)), procs)
+	}
+	assert.Empty(t, client.events)
+
+	d.process(now.Add(30*time.Second), anomalyProcs(anomalyProc(1, 100, 6000, 0, 0)), procs)
+	require.Len(t, client.events, 1)
+	assert.Equal(t, "Process leaky: sustained memory growth", client.events[0].Title)
+	assert.Equal(t, statsd.Warning, client.events[0].AlertType)
+	assert.ElementsMatch(t, []string{"anomaly:rss_growth", "process_name:leaky"}, client.events[0].Tags)
+	assert.Contains(t, client.events[0].Text, "/usr/bin/leaky --serve")
+	assert.Contains(t, client.events[0].Text, "over the last 30s")
+
+	// Growing still: no new event
+	d.process(now.Add(40*time.Second), anomalyProcs(anomalyProc(1, 100, 8000, 0, 0)), procs)
+	require.Len(t, client.events, 1)
+	assert.Len(t, d.states[1].rssSamples, 4, "only the samples of the window should be kept")
+
+	for i := 5; i < 9; i++ {
+		d.process(now.Add(time.Duration(i)*10*time.Second), anomalyProcs(anomalyProc(1, 100, 8000, 0, 0)), procs)
+	}
+	require.Len(t, client.events, 2)
+	assert.Equal(t, "Process leaky: sustained memory growth recovered", client.events[1].Title)
+	assert.Equal(t, statsd.Success, client.events[1].AlertType)
+	assert.Equal(t, client.events[0].AggregationKey, client.events[1].AggregationKey)
+}
+
+func TestAnomalyDetectorCPUPegged(t *testing.T) {
+	d, client := newTestAnomalyDetector(anomalyDetectorConfig{
+		maxTrackedProcesses: 10,
+		cpuThreshold:        90,
+		cpuDuration:         20 * time.Second,
+	})
+
+	now := time.Now()
+	for i, pct := range []float32{95, 99, 50, 95, 95} {
+		d.process(now.Add(time.Duration(i)*10*time.Second), anomalyProcs(anomalyProc(1, 100, 0, pct, 0)), nil)
+	}
+	assert.Empty(t, client.events)
+
+	d.process(now.Add(50*time.Second), anomalyProcs(anomalyProc(1, 100, 0, 100, 0)), nil)
+	require.Len(t, client.events, 1)
+	assert.Equal(t, "Process 1: sustained high CPU usage", client.events[0].Title)
+	assert.Contains(t, client.events[0].Text, "for 20s")
+
+	d.process(now.Add(60*time.Second), anomalyProcs(anomalyProc(1, 100, 0, 10, 0)), nil)
+	require.Len(t, client.events, 2)
+	assert.Equal(t, statsd.Success, client.events[1].AlertType)
+}
+
+func TestAnomalyDetectorFDExhaustion(t *testing.T) {
+	d, client := newTestAnomalyDetector(anomalyDetectorConfig{
+		maxTrackedProcesses: 10,
+		fdLimitRatio:        0.9,
+	})
+	reads := 0
+	d.openFilesLimit = func(pid int32) (uint64, error) {
+		reads++
+		return 1024, nil
+	}
+
+	now := time.Now()
+	d.process(now, anomalyProcs(anomalyProc(1, 100, 0, 0, 900)), nil)
+	assert.Empty(t, client.events)
+
+	d.process(now, anomalyProcs(anomalyProc(1, 100, 0, 0, 1000)), nil)
+	require.Len(t, client.events, 1)
+	assert.Equal(t, "Process 1: open files approaching the limit", client.events[0].Title)
+	assert.Contains(t, client.events[0].Text, "1000 files are open, out of a limit of 1024")
+	assert.Equal(t, 1, reads, "the limit should be read once per process")
+}
+
+func TestAnomalyDetectorPIDReuse(t *testing.T) {
+	d, client := newTestAnomalyDetector(anomalyDetectorConfig{
+		maxTrackedProcesses: 10,
+		cpuThreshold:        90,
+		cpuDuration:         10 * time.Second,
+	})
+
+	now := time.Now()
+	d.process(now, anomalyProcs(anomalyProc(1, 100, 0, 95, 0)), nil)
+	// Same PID, new process: the high CPU usage of the previous one must not count
+	d.process(now.Add(10*time.Second), anomalyProcs(anomalyProc(1, 200, 0, 95, 0)), nil)
+	assert.Empty(t, client.events)
+	assert.Equal(t, int64(200), d.states[1].createTime)
+
+	d.process(now.Add(20*time.Second), anomalyProcs(anomalyProc(1, 200, 0, 95, 0)), nil)
+	assert.Len(t, client.events, 1)
+}
+
+func TestAnomalyDetectorBoundedState(t *testing.T) {
+	d, _ := newTestAnomalyDetector(anomalyDetectorConfig{
+		maxTrackedProcesses: 2,
+		cpuThreshold:        90,
+		cpuDuration:         10 * time.Second,
+	})
+
+	now := time.Now()
+	d.process(now, map[string][]*model.Process{"": {anomalyProc(1, 100, 0, 0, 0), anomalyProc(2, 100, 0, 0, 0), anomalyProc(3, 100, 0, 0, 0)}}, nil)
+	assert.Len(t, d.states, 2)
+
+	// Exited processes are forgotten, making room for new ones
+	d.process(now, map[string][]*model.Process{"ctr": {anomalyProc(4, 100, 0, 0, 0)}}, nil)
+	assert.Len(t, d.states, 1)
+	assert.Contains(t, d.states, int32(4))
+}
+
+func TestParseOpenFilesLimit(t *testing.T) {
+	limits := `Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max open files            1024                 524288               files
+Max locked memory         8388608              8388608              bytes
+`
+	limit, err := parseOpenFilesLimit(limits)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1024), limit)
+
+	limit, err = parseOpenFilesLimit("Max open files            unlimited            unlimited            files\n")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), limit)
+
+	_, err = parseOpenFilesLimit("Max cpu time              unlimited            unlimited            seconds\n")
+	assert.Error(t, err)
+}
@@ -17,6 +17,7 @@ import (
 
 	payload "github.com/DataDog/agent-payload/v5/process"
 
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/process/config"
 	"github.com/DataDog/datadog-agent/pkg/process/events"
 	"github.com/DataDog/datadog-agent/pkg/process/events/model"
@@ -35,11 +36,15 @@ type ProcessEventsCheck struct {
 	listener *events.SysProbeListener
 	sysInfo  *payload.SystemInfo
 
+	// outputs are the destinations events are sent to in addition to the check payload
+	outputs  []events.Output
+	scrubber *config.DataScrubber
+
 	maxBatchSize int
 }
 
 // Init initializes the ProcessEventsCheck.
-func (e *ProcessEventsCheck) Init(_ *config.AgentConfig, info *payload.SystemInfo) {
+func (e *ProcessEventsCheck) Init(cfg *config.AgentConfig, info *payload.SystemInfo) {
 	e.initMutex.Lock()
 	defer e.initMutex.Unlock()
 
@@ -58,11 +63,10 @@ func (e *ProcessEventsCheck) Init(_ *config.AgentConfig, info *payload.SystemInf
 		return
 	}
 	e.store = store
+	e.scrubber = cfg.Scrubber
+	e.outputs = newEventOutputs()
 
-	listener, err := events.NewListener(func(e *model.ProcessEvent) {
-		// push events to the store asynchronously without checking for errors
-		_ = store.Push(e, nil)
-	})
+	listener, err := events.NewListener(e.handleEvent)
 	if err != nil {
 		log.Errorf("Event Listener can't be created: %v", err)
 		return
@@ -73,6 +77,49 @@ func (e *ProcessEventsCheck) Init(_ *config.AgentConfig, info *payload.SystemInf
 	log.Info("process_events check correctly set up")
 }
 
+// newEventOutputs returns the outputs enabled in the configuration
+func newEventOutputs() []events.Output {
+	var outputs []events.Output
+
+	if ddconfig.Datadog.GetBool("process_config.event_collection.logs.enabled") {
+		o, err := events.NewLogsOutput(statsd.Client)
+		if err != nil {
+			log.Errorf("Process events can't be sent as logs: %v", err)
+		} else {
+			outputs = append(outputs, o)
+		}
+	}
+
+	if patterns := ddconfig.Datadog.GetStringSlice("process_config.event_collection.dogstatsd_events.command_patterns"); len(patterns) > 0 {
+		o, err := events.NewStatsdEventsOutput(statsd.Client, patterns)
+		if err != nil {
+			log.Errorf("Process events can't be sent as DogStatsD events: %v", err)
+		} else {
+			outputs = append(outputs, o)
+		}
+	}
+
+	return outputs
+}
+
+// handleEvent pushes an event to the store, and sends it to the outputs with its command line scrubbed
+func (e *ProcessEventsCheck) handleEvent(ev *model.ProcessEvent) {
+	// push events to the store asynchronously without checking for errors
+	_ = e.store.Push(ev, nil)
+
+	if len(e.outputs) == 0 {
+		return
+	}
+
+	scrubbed := *ev
+	if e.scrubber != nil {
+		scrubbed.Cmdline = e.scrubber.ScrubCmdline(ev.Cmdline)
+	}
+	for _, o := range e.outputs {
+		o.Send(&scrubbed)
+	}
+}
+
 // start kicks off process lifecycle events collection and keep them in memory until they're fetched in the next check run
 func (e *ProcessEventsCheck) start() {
 	e.store.Run()
@@ -127,6 +174,10 @@ func (e *ProcessEventsCheck) Cleanup() {
 	if e.store != nil {
 		e.store.Stop()
 	}
+
+	for _, o := range e.outputs {
+		o.Stop()
+	}
 	log.Info("process_events check cleaned up")
 }
 
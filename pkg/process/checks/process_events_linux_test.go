@@ -346,3 +346,36 @@ func TestProcessEventsChunking(t *testing.T) {
 		assert.Len(t, chunks, tc.chunkCount)
 	}
 }
+
+type outputRecorder struct {
+	events  []*model.ProcessEvent
+	stopped bool
+}
+
+func (o *outputRecorder) Send(e *model.ProcessEvent) { o.events = append(o.events, e) }
+func (o *outputRecorder) Stop()                      { o.stopped = true }
+
+func TestProcessEventsOutputs(t *testing.T) {
+	store, err := events.NewRingStore(&statsd.NoOpClient{})
+	require.NoError(t, err)
+
+	output := &outputRecorder{}
+	scrubber := config.NewDefaultDataScrubber()
+	check := &ProcessEventsCheck{
+		store:    store,
+		outputs:  []events.Output{output},
+		scrubber: scrubber,
+	}
+
+	e := model.NewMockedExecEvent(time.Now(), 42, "/usr/bin/mysql", []string{"mysql", "--password=secret"})
+	check.handleEvent(e)
+
+	require.Len(t, output.events, 1)
+	assert.Equal(t, []string{"mysql", "--password=********"}, output.events[0].Cmdline)
+	assert.Equal(t, uint32(42), output.events[0].Pid)
+	// the event pushed to the store is left untouched
+	assert.Equal(t, []string{"mysql", "--password=secret"}, e.Cmdline)
+
+	check.Cleanup()
+	assert.True(t, output.stopped)
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package checks
+
+import (
+	"path/filepath"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+// processNetworkStats holds the network activity of a group of processes
+// sharing the same name and container. Rates are per second.
+type processNetworkStats struct {
+	tags []string
+
+	bytesSentRate     float64
+	bytesReceivedRate float64
+	retransmitsRate   float64
+	establishedRate   float64
+	closedRate        float64
+	openConnections   float64
+}
+
+type processNetworkKey struct {
+	name        string
+	containerID string
+}
+
+// aggregateProcessNetworks joins the connections collected by the last run of
+// the connections check with the processes that own them, and aggregates their
+// activity by process name and container. interval is the interval of the
+// connections check, over which the connection counters are relative.
+func aggregateProcessNetworks(
+	procs map[int32]*procutil.Process,
+	pidToCid map[int]string,
+	connsByPID map[int32][]*model.Connection,
+	interval time.Duration,
+	ctrTags func(containerID string) []string,
+) []*processNetworkStats {
+	seconds := interval.Seconds()
+	if seconds <= 0 || len(connsByPID) == 0 {
+		return nil
+	}
+
+	byKey := make(map[processNetworkKey]*processNetworkStats)
+	for pid, conns := range connsByPID {
+		proc, ok := procs[pid]
+		if !ok || len(conns) == 0 {
+			continue
+		}
+
+		key := processNetworkKey{name: processName(proc), containerID: pidToCid[int(pid)]}
+		stats, ok := byKey[key]
+		if !ok {
+			stats = &processNetworkStats{tags: processNetworkTags(key, ctrTags)}
+			byKey[key] = stats
+		}
+
+		for _, conn := range conns {
+			stats.bytesSentRate += float64(conn.LastBytesSent) / seconds
+			stats.bytesReceivedRate += float64(conn.LastBytesReceived) / seconds
+			stats.retransmitsRate += float64(conn.LastRetransmits) / seconds
+			stats.establishedRate += float64(conn.LastTcpEstablished) / seconds
+			stats.closedRate += float64(conn.LastTcpClosed) / seconds
+			if conn.Type == model.ConnectionType_udp || conn.LastTcpClosed == 0 {
+				stats.openConnections++
+			}
+		}
+	}
+
+	result := make([]*processNetworkStats, 0, len(byKey))
+	for _, stats := range byKey {
+		result = append(result, stats)
+	}
+	return result
+}
+
+// reportProcessNetworks sends the network activity of the processes as metrics
+func reportProcessNetworks(client statsd.ClientInterface, stats []*processNetworkStats) {
+	for _, s := range stats {
+		client.Gauge("process.network.bytes_sent", s.bytesSentRate, s.tags, 1)                //nolint:errcheck
+		client.Gauge("process.network.bytes_received", s.bytesReceivedRate, s.tags, 1)        //nolint:errcheck
+		client.Gauge("process.network.retransmits", s.retransmitsRate, s.tags, 1)             //nolint:errcheck
+		client.Gauge("process.network.connections.established", s.establishedRate, s.tags, 1) //nolint:errcheck
+		client.Gauge("process.network.connections.closed", s.closedRate, s.tags, 1)           //nolint:errcheck
+		client.Gauge("process.network.connections.open", s.openConnections, s.tags, 1)        //nolint:errcheck
+	}
+}
+
+func processNetworkTags(key processNetworkKey, ctrTags func(containerID string) []string) []string {
+	tags := []string{"process_name:" + key.name}
+	if key.containerID == "" {
+		return tags
+	}
+
+	extra := ctrTags(key.containerID)
+	if len(extra) == 0 {
+		extra = []string{"container_id:" + key.containerID}
+	}
+	return append(tags, extra...)
+}
+
+// processName returns the name of the executable of the process, falling back
+// to the first argument of its command line
+func processName(p *procutil.Process) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	if len(p.Cmdline) > 0 {
+		return filepath.Base(p.Cmdline[0])
+	}
+	return ""
+}
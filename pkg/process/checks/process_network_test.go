@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package checks
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+func TestAggregateProcessNetworks(t *testing.T) {
+	nginx1 := makeProcess(1, "/usr/sbin/nginx -g daemon off;")
+	nginx2 := makeProcess(2, "/usr/sbin/nginx -g daemon off;")
+	curl := makeProcess(3, "curl example.com")
+	curl.Name = "curl"
+	idle := makeProcess(4, "sleep 60")
+	procs := map[int32]*procutil.Process{1: nginx1, 2: nginx2, 3: curl, 4: idle}
+	pidToCid := map[int]string{1: "cid-1", 2: "cid-1"}
+
+	connsByPID := map[int32][]*model.Connection{
+		1: {
+			{Pid: 1, Type: model.ConnectionType_tcp, LastBytesSent: 100, LastBytesReceived: 200, LastRetransmits: 2, LastTcpEstablished: 1},
+			{Pid: 1, Type: model.ConnectionType_tcp, LastBytesSent: 300, LastTcpClosed: 1},
+		},
+		2: {
+			{Pid: 2, Type: model.ConnectionType_udp, LastBytesSent: 600, LastBytesReceived: 600},
+		},
+		3: {
+			{Pid: 3, Type: model.ConnectionType_tcp, LastBytesReceived: 1000, LastTcpEstablished: 1, LastTcpClosed: 1},
+		},
+		// connections of a process that already exited are left out
+		5: {
+			{Pid: 5, Type: model.ConnectionType_tcp, LastBytesSent: 1000},
+		},
+	}
+	ctrTags := func(containerID string) []string {
+		return []string{"container_id:" + containerID, "image_name:nginx"}
+	}
+
+	stats := aggregateProcessNetworks(procs, pidToCid, connsByPID, 10*time.Second, ctrTags)
+	require.Len(t, stats, 2)
+	sort.Slice(stats, func(i, j int) bool { return stats[i].tags[0] < stats[j].tags[0] })
+
+	assert.Equal(t, &processNetworkStats{
+		tags:              []string{"process_name:curl"},
+		bytesReceivedRate: 100,
+		establishedRate:   0.1,
+		closedRate:        0.1,
+	}, stats[0])
+	assert.Equal(t, &processNetworkStats{
+		tags:              []string{"process_name:nginx", "container_id:cid-1", "image_name:nginx"},
+		bytesSentRate:     100,
+		bytesReceivedRate: 80,
+		retransmitsRate:   0.2,
+		establishedRate:   0.1,
+		closedRate:        0.1,
+		openConnections:   2,
+	}, stats[1])
+}
+
+func TestAggregateProcessNetworksNoConnections(t *testing.T) {
+	procs := map[int32]*procutil.Process{1: makeProcess(1, "nginx")}
+	assert.Empty(t, aggregateProcessNetworks(procs, nil, nil, 30*time.Second, nil))
+	assert.Empty(t, aggregateProcessNetworks(procs, nil, map[int32][]*model.Connection{1: {{Pid: 1}}}, 0, nil))
+}
+
+func TestProcessNetworkTags(t *testing.T) {
+	noTags := func(string) []string { return nil }
+	assert.Equal(t, []string{"process_name:java"}, processNetworkTags(processNetworkKey{name: "java"}, noTags))
+	assert.Equal(t, []string{"process_name:java", "container_id:abc"}, processNetworkTags(processNetworkKey{name: "java", containerID: "abc"}, noTags))
+}
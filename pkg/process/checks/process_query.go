@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+)
+
+// Sort orders supported by a ProcessQuery
+const (
+	SortByPID = "pid"
+	SortByCPU = "cpu"
+	SortByRSS = "rss"
+	SortByIO  = "io"
+)
+
+// ProcessQuery filters and sorts the processes collected by the process check
+type ProcessQuery struct {
+	// ContainerID keeps the processes whose container ID starts with it
+	ContainerID string
+	// User keeps the processes run by the user with this name or uid
+	User string
+	// Cmdline keeps the processes whose command line matches it
+	Cmdline *regexp.Regexp
+	// SortBy is one of the SortBy* constants, SortByPID by default
+	SortBy string
+	// Limit is the maximum number of processes returned, 0 meaning no limit
+	Limit int
+}
+
+// ProcessListEntry is a process, as sent by the process check, along with the tags of its container
+type ProcessListEntry struct {
+	*model.Process
+	ContainerTags []string `json:"containerTags,omitempty"`
+}
+
+// Validate returns an error if the sort order of the query is unknown
+func (q *ProcessQuery) Validate() error {
+	switch q.SortBy {
+	case "", SortByPID, SortByCPU, SortByRSS, SortByIO:
+	default:
+		return fmt.Errorf("invalid sort order %q, choose from: %s, %s, %s, %s", q.SortBy, SortByPID, SortByCPU, SortByRSS, SortByIO)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("invalid limit %d", q.Limit)
+	}
+	return nil
+}
+
+// QueryProcesses returns the processes of the messages produced by a process check run that match the query
+func QueryProcesses(msgs []model.MessageBody, q *ProcessQuery) ([]*ProcessListEntry, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		entries       []*ProcessListEntry
+		containerTags = map[string][]string{}
+	)
+	for _, m := range msgs {
+		proc, ok := m.(*model.CollectorProc)
+		if !ok {
+			return nil, ErrUnexpectedMessageType
+		}
+		for _, c := range proc.Containers {
+			containerTags[c.Id] = c.Tags
+		}
+		for _, p := range proc.Processes {
+			if q.matches(p) {
+				entries = append(entries, &ProcessListEntry{Process: p})
+			}
+		}
+	}
+
+	for _, e := range entries {
+		e.ContainerTags = containerTags[e.ContainerId]
+	}
+
+	sortProcessEntries(entries, q.SortBy)
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+func (q *ProcessQuery) matches(p *model.Process) bool {
+	if q.ContainerID != "" && !strings.HasPrefix(p.ContainerId, q.ContainerID) {
+		return false
+	}
+
+	if q.User != "" {
+		user := p.User
+		if user == nil || (user.Name != q.User && strconv.Itoa(int(user.Uid)) != q.User) {
+			return false
+		}
+	}
+
+	if q.Cmdline != nil {
+		var args []string
+		if p.Command != nil {
+			args = p.Command.Args
+		}
+		if !q.Cmdline.MatchString(strings.Join(args, " ")) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortProcessEntries sorts the processes by decreasing resource usage, or by increasing pid
+func sortProcessEntries(entries []*ProcessListEntry, sortBy string) {
+	var less func(a, b *model.Process) bool
+	switch sortBy {
+	case SortByCPU:
+		less = func(a, b *model.Process) bool { return cpuPct(a) > cpuPct(b) }
+	case SortByRSS:
+		less = func(a, b *model.Process) bool { return rss(a) > rss(b) }
+	case SortByIO:
+		less = func(a, b *model.Process) bool { return ioBytesRate(a) > ioBytesRate(b) }
+	default:
+		less = func(a, b *model.Process) bool { return a.Pid < b.Pid }
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Process, entries[j].Process
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Pid < b.Pid
+	})
+}
+
+func cpuPct(p *model.Process) float32 {
+	if p.Cpu == nil {
+		return 0
+	}
+	return p.Cpu.TotalPct
+}
+
+func rss(p *model.Process) uint64 {
+	if p.Memory == nil {
+		return 0
+	}
+	return p.Memory.Rss
+}
+
+// ioBytesRate returns the bytes read and written per second by a process. Rates are negative when unavailable.
+func ioBytesRate(p *model.Process) float32 {
+	if p.IoStat == nil {
+		return 0
+	}
+
+	var rate float32
+	if p.IoStat.ReadBytesRate > 0 {
+		rate += p.IoStat.ReadBytesRate
+	}
+	if p.IoStat.WriteBytesRate > 0 {
+		rate += p.IoStat.WriteBytesRate
+	}
+	return rate
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryTestMessages() []model.MessageBody {
+	return []model.MessageBody{
+		&model.CollectorProc{
+			Processes: []*model.Process{
+				{
+					Pid:         1,
+					ContainerId: "0123456789abcdef",
+					Command:     &model.Command{Args: []string{"nginx", "-g", "daemon off;"}, Ppid: 0},
+					User:        &model.ProcessUser{Name: "root", Uid: 0},
+					Memory:      &model.MemoryStat{Rss: 300},
+					Cpu:         &model.CPUStat{TotalPct: 1},
+					IoStat:      &model.IOStat{ReadBytesRate: -1, WriteBytesRate: -1},
+				},
+				{
+					Pid:     20,
+					Command: &model.Command{Args: []string{"postgres", "-D", "/var/lib/postgresql"}, Ppid: 1},
+					User:    &model.ProcessUser{Name: "postgres", Uid: 999},
+					Memory:  &model.MemoryStat{Rss: 1000},
+					Cpu:     &model.CPUStat{TotalPct: 0.5},
+					IoStat:  &model.IOStat{ReadBytesRate: 500, WriteBytesRate: 100},
+				},
+			},
+			Containers: []*model.Container{
+				{Id: "0123456789abcdef", Tags: []string{"image_name:nginx"}},
+			},
+		},
+		&model.CollectorProc{
+			Processes: []*model.Process{
+				{
+					Pid:     3,
+					Command: &model.Command{Args: []string{"postgres: checkpointer"}, Ppid: 20},
+					User:    &model.ProcessUser{Uid: 999},
+					Cpu:     &model.CPUStat{TotalPct: 10},
+				},
+			},
+		},
+	}
+}
+
+func pids(entries []*ProcessListEntry) []int32 {
+	pids := make([]int32, 0, len(entries))
+	for _, e := range entries {
+		pids = append(pids, e.Pid)
+	}
+	return pids
+}
+
+func TestQueryProcesses(t *testing.T) {
+	msgs := queryTestMessages()
+
+	for _, tc := range []struct {
+		name     string
+		query    ProcessQuery
+		expected []int32
+	}{
+		{name: "all", expected: []int32{1, 3, 20}},
+		{name: "container prefix", query: ProcessQuery{ContainerID: "0123"}, expected: []int32{1}},
+		{name: "user name", query: ProcessQuery{User: "postgres"}, expected: []int32{20}},
+		{name: "uid", query: ProcessQuery{User: "999"}, expected: []int32{3, 20}},
+		{name: "cmdline", query: ProcessQuery{Cmdline: regexp.MustCompile("^postgres")}, expected: []int32{3, 20}},
+		{name: "cpu", query: ProcessQuery{SortBy: SortByCPU}, expected: []int32{3, 1, 20}},
+		{name: "rss", query: ProcessQuery{SortBy: SortByRSS}, expected: []int32{20, 1, 3}},
+		{name: "io", query: ProcessQuery{SortBy: SortByIO}, expected: []int32{20, 1, 3}},
+		{name: "limit", query: ProcessQuery{SortBy: SortByCPU, Limit: 2}, expected: []int32{3, 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := QueryProcesses(msgs, &tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, pids(entries))
+		})
+	}
+
+	entries, err := QueryProcesses(msgs, &ProcessQuery{ContainerID: "0123"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image_name:nginx"}, entries[0].ContainerTags)
+}
+
+func TestQueryProcessesErrors(t *testing.T) {
+	_, err := QueryProcesses(queryTestMessages(), &ProcessQuery{SortBy: "mem"})
+	assert.Error(t, err)
+
+	_, err = QueryProcesses(queryTestMessages(), &ProcessQuery{Limit: -1})
+	assert.Error(t, err)
+
+	_, err = QueryProcesses([]model.MessageBody{&model.CollectorContainer{}}, &ProcessQuery{})
+	assert.Equal(t, ErrUnexpectedMessageType, err)
+}
+
+func TestHumanFormatProcessList(t *testing.T) {
+	entries, err := QueryProcesses(queryTestMessages(), &ProcessQuery{})
+	require.NoError(t, err)
+
+	var b strings.Builder
+	require.NoError(t, HumanFormatProcessList(entries, &b))
+
+	assert.Equal(t, strings.Join([]string{
+		"PID  PPID  USER      CPU   RSS     READ/S  WRITE/S  CONTAINER     COMMAND",
+		"1    0     root      1%    300 B   -       -        0123456789ab  nginx -g daemon off;",
+		"3    20    999       10%   0 B     -       -                      postgres: checkpointer",
+		"20   1     postgres  0.5%  1.0 kB  500 B   100 B                  postgres -D /var/lib/postgresql",
+		"",
+	}, "\n"), b.String())
+}
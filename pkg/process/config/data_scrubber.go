@@ -146,6 +146,22 @@ func (ds *DataScrubber) ScrubProcessCommand(p *procutil.Process) []string {
 	return p.Cmdline
 }
 
+// ScrubCmdline strips or scrubs a cmdline according to the settings of the
+// DataScrubber, without caching the result. It is meant for cmdlines seen only
+// once, such as the ones of process lifecycle events.
+func (ds *DataScrubber) ScrubCmdline(cmdline []string) []string {
+	if ds.StripAllArguments {
+		return ds.stripArguments(cmdline)
+	}
+
+	if !ds.Enabled {
+		return cmdline
+	}
+
+	scrubbed, _ := ds.ScrubCommand(cmdline)
+	return scrubbed
+}
+
 // IncrementCacheAge increments one cycle of cache memory age. If it reaches
 // cacheMaxCycles, the cache is restarted
 func (ds *DataScrubber) IncrementCacheAge() {
@@ -306,6 +306,23 @@ func TestBlacklistedArgs(t *testing.T) {
 	}
 }
 
+func TestScrubCmdline(t *testing.T) {
+	cases := setupSensitiveCmdlines()
+	scrubber := setupDataScrubber(t)
+
+	for i := range cases {
+		assert.Equal(t, cases[i].parsedCmdline, scrubber.ScrubCmdline(cases[i].cmdline))
+	}
+	assert.Empty(t, scrubber.seenProcess)
+
+	cmdline := []string{"agent", "-password", "1234"}
+	scrubber.Enabled = false
+	assert.Equal(t, cmdline, scrubber.ScrubCmdline(cmdline))
+
+	scrubber.StripAllArguments = true
+	assert.Equal(t, []string{"agent"}, scrubber.ScrubCmdline(cmdline))
+}
+
 func TestBlacklistedArgsWhenDisabled(t *testing.T) {
 	cases := []struct {
 		cmdline       []string
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package events
+
+import (
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/events/model"
+)
+
+// Output is a destination, other than the process_events check payload, process events are sent to
+type Output interface {
+	// Send sends an event to the output. It must not block the listener.
+	Send(e *model.ProcessEvent)
+	// Stop flushes and frees the resources held by the output
+	Stop()
+}
+
+// LogEvent is the structured log representation of a process event
+type LogEvent struct {
+	EventType   string    `json:"event_type"`
+	Timestamp   time.Time `json:"timestamp"`
+	Pid         uint32    `json:"pid"`
+	Ppid        uint32    `json:"ppid"`
+	ContainerID string    `json:"container_id,omitempty"`
+	User        string    `json:"user,omitempty"`
+	UID         uint32    `json:"uid"`
+	Group       string    `json:"group,omitempty"`
+	GID         uint32    `json:"gid"`
+	Exe         string    `json:"exe"`
+	Cmdline     string    `json:"cmdline"`
+	// ExitCode and DurationMillis are only set for exit events
+	ExitCode       *uint32 `json:"exit_code,omitempty"`
+	DurationMillis *int64  `json:"duration_ms,omitempty"`
+}
+
+// NewLogEvent builds the LogEvent of a process event
+func NewLogEvent(e *model.ProcessEvent) *LogEvent {
+	le := &LogEvent{
+		EventType:   e.EventType.String(),
+		Timestamp:   eventTime(e),
+		Pid:         e.Pid,
+		Ppid:        e.Ppid,
+		ContainerID: e.ContainerID,
+		User:        e.Username,
+		UID:         e.UID,
+		Group:       e.Group,
+		GID:         e.GID,
+		Exe:         e.Exe,
+		Cmdline:     strings.Join(e.Cmdline, " "),
+	}
+
+	if e.EventType == model.Exit {
+		code := e.ExitCode
+		le.ExitCode = &code
+		if d, ok := lifetime(e); ok {
+			ms := d.Milliseconds()
+			le.DurationMillis = &ms
+		}
+	}
+	return le
+}
+
+// eventTime returns the time at which the event happened, falling back to the time it was collected at
+func eventTime(e *model.ProcessEvent) time.Time {
+	var ts time.Time
+	switch e.EventType {
+	case model.Exec:
+		ts = e.ExecTime
+	case model.Exit:
+		ts = e.ExitTime
+	}
+	if ts.IsZero() {
+		return e.CollectionTime
+	}
+	return ts
+}
+
+// lifetime returns how long the process of an exit event ran for, from its exec or else its fork
+func lifetime(e *model.ProcessEvent) (time.Duration, bool) {
+	start := e.ExecTime
+	if start.IsZero() {
+		start = e.ForkTime
+	}
+	if start.IsZero() || e.ExitTime.IsZero() || e.ExitTime.Before(start) {
+		return 0, false
+	}
+	return e.ExitTime.Sub(start), true
+}
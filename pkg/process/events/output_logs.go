@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	logsconfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/process/events/model"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/startstop"
+)
+
+const (
+	logsSourceName = "process_events"
+	logsSourceType = "process_events"
+
+	// logsIntakeTrackType and logsIntakeProtocol are the ones of the logs agent, so events
+	// are ingested like any other log
+	logsIntakeTrackType = "logs"
+	logsIntakeProtocol  = "agent-json"
+)
+
+// LogsOutput sends process events as structured logs to the logs intake configured in logs_config
+type LogsOutput struct {
+	logSource    *sources.LogSource
+	logChan      chan *message.Message
+	statsdClient statsd.ClientInterface
+	stopper      startstop.Stopper
+}
+
+var _ Output = &LogsOutput{}
+
+// NewLogsOutput starts a logs pipeline and returns an Output sending events to it
+func NewLogsOutput(statsdClient statsd.ClientInterface) (*LogsOutput, error) {
+	endpoints, err := logsconfig.BuildHTTPEndpoints(logsIntakeTrackType, logsIntakeProtocol, logsconfig.DefaultIntakeOrigin)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range endpoints.GetStatus() {
+		log.Info(status)
+	}
+
+	destinationsCtx := newDestinationsContext()
+	health := health.RegisterLiveness(logsSourceType)
+
+	auditor := auditor.New(coreconfig.Datadog.GetString("logs_config.run_path"), logsSourceType+"-registry.json", coreconfig.DefaultAuditorTTL, health)
+	auditor.Start()
+
+	pipelineProvider := pipeline.NewProvider(logsconfig.NumberOfPipelines, auditor, &diagnostic.NoopMessageReceiver{}, nil, endpoints, destinationsCtx)
+	pipelineProvider.Start()
+
+	stopper := startstop.NewSerialStopper(pipelineProvider, auditor, destinationsCtx)
+	logSource := sources.NewLogSource(logsSourceName, &logsconfig.LogsConfig{
+		Type:    logsSourceType,
+		Service: logsSourceName,
+		Source:  logsSourceName,
+	})
+
+	return newLogsOutput(logSource, pipelineProvider.NextPipelineChan(), statsdClient, stopper), nil
+}
+
+func newLogsOutput(logSource *sources.LogSource, logChan chan *message.Message, statsdClient statsd.ClientInterface, stopper startstop.Stopper) *LogsOutput {
+	return &LogsOutput{
+		logSource:    logSource,
+		logChan:      logChan,
+		statsdClient: statsdClient,
+		stopper:      stopper,
+	}
+}
+
+func newDestinationsContext() *client.DestinationsContext {
+	ctx := client.NewDestinationsContext()
+	ctx.Start()
+	return ctx
+}
+
+// Send sends an event as a JSON log. The event is dropped if the pipeline is full.
+func (o *LogsOutput) Send(e *model.ProcessEvent) {
+	content, err := json.Marshal(NewLogEvent(e))
+	if err != nil {
+		log.Errorf("Could not serialize process event: %v", err)
+		return
+	}
+
+	origin := message.NewOrigin(o.logSource)
+	if e.ContainerID != "" {
+		origin.SetTags([]string{"container_id:" + e.ContainerID})
+	}
+
+	status := message.StatusInfo
+	if e.EventType == model.Exit && e.ExitCode != 0 {
+		status = message.StatusError
+	}
+
+	msg := message.NewMessage(content, origin, status, time.Now().UnixNano())
+	select {
+	case o.logChan <- msg:
+	default:
+		if err := o.statsdClient.Count("datadog.process.events.expired", 1, []string{"type:logs_full"}, 1.0); err != nil {
+			log.Warnf("Error sending process events stats: %v", err)
+		}
+	}
+}
+
+// Stop stops the logs pipeline
+func (o *LogsOutput) Stop() {
+	if o.stopper != nil {
+		o.stopper.Stop()
+	}
+}
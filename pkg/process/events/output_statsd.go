@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package events
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/process/events/model"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// StatsdEventsOutput sends the events of the processes whose command line matches one of the configured patterns
+// as DogStatsD events
+type StatsdEventsOutput struct {
+	client   statsd.ClientInterface
+	patterns []*regexp.Regexp
+}
+
+var _ Output = &StatsdEventsOutput{}
+
+// NewStatsdEventsOutput returns a StatsdEventsOutput for the given command line patterns. Invalid patterns are
+// ignored, and an error is returned if none of them is valid.
+func NewStatsdEventsOutput(client statsd.ClientInterface, patterns []string) (*StatsdEventsOutput, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			log.Warnf("Ignoring invalid process events command pattern %s: %v", p, err)
+			continue
+		}
+		compiled = append(compiled, r)
+	}
+
+	if len(compiled) == 0 {
+		return nil, fmt.Errorf("no valid command pattern in %v", patterns)
+	}
+
+	return &StatsdEventsOutput{
+		client:   client,
+		patterns: compiled,
+	}, nil
+}
+
+// Send sends an event through DogStatsD if the command line of its process matches
+func (o *StatsdEventsOutput) Send(e *model.ProcessEvent) {
+	cmdline := strings.Join(e.Cmdline, " ")
+	if !o.matches(cmdline) {
+		return
+	}
+
+	if err := o.client.Event(newStatsdEvent(e, cmdline)); err != nil {
+		log.Debugf("Could not send process event through DogStatsD: %v", err)
+	}
+}
+
+// Stop is a no-op, as the DogStatsD client is shared with the rest of the process-agent
+func (o *StatsdEventsOutput) Stop() {}
+
+func (o *StatsdEventsOutput) matches(cmdline string) bool {
+	for _, r := range o.patterns {
+		if r.MatchString(cmdline) {
+			return true
+		}
+	}
+	return false
+}
+
+func newStatsdEvent(e *model.ProcessEvent, cmdline string) *statsd.Event {
+	name := filepath.Base(e.Exe)
+	tags := []string{"event_type:" + e.EventType.String(), "process_name:" + name}
+	if e.ContainerID != "" {
+		tags = append(tags, "container_id:"+e.ContainerID)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Command: %s\nPID: %d\nPPID: %d\nUser: %s", cmdline, e.Pid, e.Ppid, e.Username)
+	if e.ContainerID != "" {
+		fmt.Fprintf(&text, "\nContainer: %s", e.ContainerID)
+	}
+
+	ev := &statsd.Event{
+		Timestamp:      eventTime(e),
+		AggregationKey: e.Exe,
+		SourceTypeName: "process",
+		AlertType:      statsd.Info,
+		Tags:           tags,
+	}
+
+	switch e.EventType {
+	case model.Exec:
+		ev.Title = fmt.Sprintf("Process %s started", name)
+	case model.Exit:
+		ev.Title = fmt.Sprintf("Process %s exited with code %d", name, e.ExitCode)
+		fmt.Fprintf(&text, "\nExit code: %d", e.ExitCode)
+		if d, ok := lifetime(e); ok {
+			fmt.Fprintf(&text, "\nDuration: %s", d)
+		}
+		if e.ExitCode != 0 {
+			ev.AlertType = statsd.Error
+		}
+	default:
+		ev.Title = fmt.Sprintf("Process %s %s", name, e.EventType)
+	}
+
+	ev.Text = text.String()
+	return ev
+}
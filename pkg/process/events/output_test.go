@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logsconfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/process/events/model"
+)
+
+func TestLogEventJSON(t *testing.T) {
+	now := time.Date(2022, 6, 12, 12, 0, 0, 0, time.UTC)
+	args := []string{"/usr/bin/backup", "--all"}
+
+	b, err := json.Marshal(NewLogEvent(model.NewMockedExecEvent(now, 42, "/usr/bin/backup", args)))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"event_type": "exec",
+		"timestamp": "2022-06-12T12:00:00Z",
+		"pid": 42,
+		"ppid": 1,
+		"container_id": "01234567890abcedf",
+		"user": "dog",
+		"uid": 100,
+		"group": "dd-agent",
+		"gid": 100,
+		"exe": "/usr/bin/backup",
+		"cmdline": "/usr/bin/backup --all"
+	}`, string(b))
+
+	b, err = json.Marshal(NewLogEvent(model.NewMockedExitEvent(now, 42, "/usr/bin/backup", args, 0)))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"event_type": "exit",
+		"timestamp": "2022-06-12T12:00:00Z",
+		"pid": 42,
+		"ppid": 1,
+		"container_id": "01234567890abcedf",
+		"user": "dog",
+		"uid": 100,
+		"group": "dd-agent",
+		"gid": 100,
+		"exe": "/usr/bin/backup",
+		"cmdline": "/usr/bin/backup --all",
+		"exit_code": 0,
+		"duration_ms": 10000
+	}`, string(b))
+}
+
+func TestLogEventWithoutTimes(t *testing.T) {
+	e := model.NewMockedExitEvent(time.Now(), 42, "/usr/bin/backup", nil, 1)
+	e.ExitTime = time.Time{}
+
+	le := NewLogEvent(e)
+	assert.Equal(t, e.CollectionTime, le.Timestamp)
+	assert.Equal(t, uint32(1), *le.ExitCode)
+	assert.Nil(t, le.DurationMillis)
+}
+
+func TestLogsOutput(t *testing.T) {
+	logChan := make(chan *message.Message, 2)
+	source := sources.NewLogSource(logsSourceName, &logsconfig.LogsConfig{Type: logsSourceType, Source: logsSourceName})
+	o := newLogsOutput(source, logChan, &statsd.NoOpClient{}, nil)
+	defer o.Stop()
+
+	now := time.Now()
+	o.Send(model.NewMockedExecEvent(now, 42, "/usr/bin/backup", []string{"backup"}))
+	o.Send(model.NewMockedExitEvent(now, 42, "/usr/bin/backup", []string{"backup"}, 2))
+	// the pipeline is full, the event is dropped rather than blocking the listener
+	o.Send(model.NewMockedExitEvent(now, 43, "/usr/bin/backup", []string{"backup"}, 0))
+
+	require.Len(t, logChan, 2)
+	exec, exit := <-logChan, <-logChan
+
+	assert.Equal(t, message.StatusInfo, exec.GetStatus())
+	assert.Equal(t, []string{"container_id:01234567890abcedf"}, exec.Origin.Tags())
+	var le LogEvent
+	require.NoError(t, json.Unmarshal(exec.Content, &le))
+	assert.Equal(t, "exec", le.EventType)
+
+	assert.Equal(t, message.StatusError, exit.GetStatus())
+	require.NoError(t, json.Unmarshal(exit.Content, &le))
+	assert.Equal(t, "exit", le.EventType)
+	assert.Equal(t, uint32(2), *le.ExitCode)
+}
+
+type eventsRecorder struct {
+	statsd.NoOpClient
+	events []*statsd.Event
+}
+
+func (r *eventsRecorder) Event(e *statsd.Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestStatsdEventsOutput(t *testing.T) {
+	client := &eventsRecorder{}
+	o, err := NewStatsdEventsOutput(client, []string{"[", "^/usr/bin/backup "})
+	require.NoError(t, err)
+	defer o.Stop()
+
+	now := time.Now()
+	o.Send(model.NewMockedExecEvent(now, 42, "/usr/bin/backup", []string{"/usr/bin/backup", "--all"}))
+	o.Send(model.NewMockedExecEvent(now, 43, "/usr/bin/curl", []string{"/usr/bin/curl", "/usr/bin/backup"}))
+	o.Send(model.NewMockedExitEvent(now, 42, "/usr/bin/backup", []string{"/usr/bin/backup", "--all"}, 137))
+
+	require.Len(t, client.events, 2)
+	exec, exit := client.events[0], client.events[1]
+
+	assert.Equal(t, "Process backup started", exec.Title)
+	assert.Equal(t, statsd.Info, exec.AlertType)
+	assert.Equal(t, "/usr/bin/backup", exec.AggregationKey)
+	assert.Equal(t, []string{"event_type:exec", "process_name:backup", "container_id:01234567890abcedf"}, exec.Tags)
+	assert.Equal(t, "Command: /usr/bin/backup --all\nPID: 42\nPPID: 1\nUser: dog\nContainer: 01234567890abcedf", exec.Text)
+
+	assert.Equal(t, "Process backup exited with code 137", exit.Title)
+	assert.Equal(t, statsd.Error, exit.AlertType)
+	assert.Equal(t, now, exit.Timestamp)
+	assert.Contains(t, exit.Text, "\nExit code: 137\nDuration: 10s")
+}
+
+func TestStatsdEventsOutputInvalidPatterns(t *testing.T) {
+	_, err := NewStatsdEventsOutput(&statsd.NoOpClient{}, []string{"("})
+	assert.Error(t, err)
+}
@@ -21,7 +21,9 @@ import (
 
 	model "github.com/DataDog/agent-payload/v5/process"
 
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	netEncoding "github.com/DataDog/datadog-agent/pkg/network/encoding"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
 	procEncoding "github.com/DataDog/datadog-agent/pkg/process/encoding"
 	reqEncoding "github.com/DataDog/datadog-agent/pkg/process/encoding/request"
 	"github.com/DataDog/datadog-agent/pkg/proto/pbgo"
@@ -194,6 +196,65 @@ func (r *RemoteSysProbeUtil) GetStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
+// GetDNSQueryLog returns the DNS queries logged by the system probe since the last call
+func (r *RemoteSysProbeUtil) GetDNSQueryLog() ([]dns.QueryRecord, error) {
+	req, err := http.NewRequest("GET", dnsQueryLogURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dns query log request failed: Path %s, url: %s, status code: %d", r.path, dnsQueryLogURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []dns.QueryRecord
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetKafkaStats returns the Kafka requests monitored by the system probe since
+// the last call of the given client
+func (r *RemoteSysProbeUtil) GetKafkaStats(clientID string) ([]kafka.Aggregation, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s", kafkaStatsURL, clientID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kafka stats request failed: Path %s, url: %s, status code: %d", r.path, kafkaStatsURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var aggregations []kafka.Aggregation
+	if err := json.Unmarshal(body, &aggregations); err != nil {
+		return nil, err
+	}
+	return aggregations, nil
+}
+
 // Register registers the client to system probe
 func (r *RemoteSysProbeUtil) Register(clientID string) error {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s", registerURL, clientID), nil)
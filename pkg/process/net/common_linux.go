@@ -19,6 +19,8 @@ const (
 	connectionsURL = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/connections"
 	procStatsURL   = "http://unix/" + string(sysconfig.ProcessModule) + "/stats"
 	registerURL    = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/register"
+	dnsQueryLogURL = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/dns_query_log"
+	kafkaStatsURL  = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/kafka_stats"
 	statsURL       = "http://unix/debug/stats"
 	netType        = "unix"
 )
@@ -12,6 +12,8 @@ import (
 	model "github.com/DataDog/agent-payload/v5/process"
 
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/network/kafka"
 )
 
 // RemoteSysProbeUtil is not supported
@@ -47,6 +49,16 @@ func (r *RemoteSysProbeUtil) GetProcStats(pids []int32) (*model.ProcStatsWithPer
 	return nil, ebpf.ErrNotImplemented
 }
 
+// GetDNSQueryLog is not supported
+func (r *RemoteSysProbeUtil) GetDNSQueryLog() ([]dns.QueryRecord, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// GetKafkaStats is not supported
+func (r *RemoteSysProbeUtil) GetKafkaStats(clientID string) ([]kafka.Aggregation, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // Register is not supported
 func (r *RemoteSysProbeUtil) Register(clientID string) error {
 	return ebpf.ErrNotImplemented
@@ -17,6 +17,8 @@ import (
 const (
 	connectionsURL = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/connections"
 	registerURL    = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/register"
+	dnsQueryLogURL = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/dns_query_log"
+	kafkaStatsURL  = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/kafka_stats"
 	statsURL       = "http://localhost:3333/debug/stats"
 	netType        = "tcp"
 
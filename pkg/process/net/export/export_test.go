@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+)
+
+func testConnections() *model.Connections {
+	return &model.Connections{
+		Conns: []*model.Connection{
+			{
+				Pid:                 42,
+				Laddr:               &model.Addr{Ip: "10.0.0.1", Port: 52800, ContainerId: "client"},
+				Raddr:               &model.Addr{Ip: "10.0.0.2", Port: 9092, ContainerId: "broker"},
+				Family:              model.ConnectionFamily_v4,
+				Type:                model.ConnectionType_tcp,
+				Direction:           model.ConnectionDirection_outgoing,
+				LastBytesSent:       1024,
+				LastBytesReceived:   2048,
+				LastPacketsSent:     3,
+				LastPacketsReceived: 4,
+				LastRetransmits:     1,
+				Rtt:                 250,
+				Tags:                []uint32{1},
+				RouteIdx:            -1,
+			},
+			{
+				Pid:                 43,
+				Laddr:               &model.Addr{Ip: "fd00::1", Port: 53124},
+				Raddr:               &model.Addr{Ip: "fd00::53", Port: 53},
+				Family:              model.ConnectionFamily_v6,
+				Type:                model.ConnectionType_udp,
+				Direction:           model.ConnectionDirection_incoming,
+				LastBytesSent:       64,
+				LastBytesReceived:   128,
+				LastPacketsSent:     1,
+				LastPacketsReceived: 1,
+				RouteIdx:            -1,
+			},
+		},
+		Dns: map[string]*model.DNSEntry{
+			"10.0.0.2": {Names: []string{"kafka.example.com"}},
+		},
+		Tags: []string{"http_method:get", "kafka_topic:orders"},
+	}
+}
+
+func testContainerTags(containerID string) []string {
+	return []string{"container_name:" + containerID}
+}
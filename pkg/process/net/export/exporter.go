@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package export streams the connections collected by the connections check to a
+// local file or collector, either as JSON lines or as IPFIX messages.
+package export
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"go.uber.org/atomic"
+
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// FormatJSON writes a JSON document per connection, one per line
+	FormatJSON = "json"
+	// FormatIPFIX writes IPFIX messages, as specified by RFC 7011
+	FormatIPFIX = "ipfix"
+
+	statsInterval = 20 * time.Second
+)
+
+// Config holds the settings of an Exporter
+type Config struct {
+	// Format is either FormatJSON or FormatIPFIX
+	Format string
+	// Destination is either a file path or a URL whose scheme is one of tcp, udp,
+	// unix and unixgram
+	Destination string
+	// MaxFileSize is the size, in bytes, at which a destination file gets rotated
+	MaxFileSize int64
+	// MaxFiles is the number of rotated files to keep
+	MaxFiles int
+	// QueueSize is the number of payloads waiting to be written, past which new
+	// payloads are dropped
+	QueueSize int
+	// Hostname identifies the host in the exported records
+	Hostname string
+}
+
+// ReadConfig reads the settings of an Exporter from the agent configuration
+func ReadConfig(hostname string) Config {
+	return Config{
+		Format:      ddconfig.Datadog.GetString("process_config.connections_export.format"),
+		Destination: ddconfig.Datadog.GetString("process_config.connections_export.destination"),
+		MaxFileSize: ddconfig.Datadog.GetInt64("process_config.connections_export.max_file_size"),
+		MaxFiles:    ddconfig.Datadog.GetInt("process_config.connections_export.max_files"),
+		QueueSize:   ddconfig.Datadog.GetInt("process_config.connections_export.queue_size"),
+		Hostname:    hostname,
+	}
+}
+
+type encoder interface {
+	// encode returns the frames holding the given records. A frame is the unit
+	// written to datagram destinations.
+	encode(records []record, now time.Time) ([][]byte, error)
+}
+
+// Exporter writes the connections of each run of the connections check to a
+// destination. Encoding happens synchronously, while writing happens in a
+// dedicated routine so that a slow destination never delays the check: payloads
+// are dropped when too many of them are waiting to be written.
+type Exporter struct {
+	encoder       encoder
+	writer        writer
+	containerTags TagsProvider
+
+	queue chan [][]byte
+	exit  chan struct{}
+	wg    sync.WaitGroup
+
+	statsdClient statsd.ClientInterface
+	written      *atomic.Int64 // how many payloads have been written
+	dropped      *atomic.Int64 // how many payloads have been dropped due to a full queue
+	errors       *atomic.Int64 // how many payloads couldn't be encoded or written
+}
+
+// NewExporter returns a new Exporter and starts its routine. containerTags is
+// used to add the tags of the containers of both ends of connections, and may be
+// nil. statsdClient may be nil as well.
+func NewExporter(cfg Config, containerTags TagsProvider, statsdClient statsd.ClientInterface) (*Exporter, error) {
+	var enc encoder
+	switch cfg.Format {
+	case FormatJSON:
+		enc = newJSONEncoder(cfg.Hostname)
+	case FormatIPFIX:
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(cfg.Hostname))
+		enc = newIPFIXEncoder(h.Sum32())
+	default:
+		return nil, fmt.Errorf("unsupported connections export format %q", cfg.Format)
+	}
+
+	if cfg.QueueSize <= 0 {
+		return nil, fmt.Errorf("invalid connections export queue size %d", cfg.QueueSize)
+	}
+
+	w, err := newWriter(cfg.Destination, cfg.MaxFileSize, cfg.MaxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exporter{
+		encoder:       enc,
+		writer:        w,
+		containerTags: containerTags,
+		queue:         make(chan [][]byte, cfg.QueueSize),
+		exit:          make(chan struct{}),
+		statsdClient:  statsdClient,
+		written:       atomic.NewInt64(0),
+		dropped:       atomic.NewInt64(0),
+		errors:        atomic.NewInt64(0),
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.run()
+	}()
+	log.Infof("exporting connections as %s to %s", cfg.Format, cfg.Destination)
+	return e, nil
+}
+
+// Export queues the given connections to be written. It must be called before the
+// connections get batched, as batching rewrites the indexes they hold.
+func (e *Exporter) Export(conns *model.Connections) {
+	frames, err := e.encoder.encode(newRecords(conns, e.containerTags), time.Now())
+	if err != nil {
+		log.Debugf("error encoding connections: %s", err)
+		e.errors.Inc()
+		return
+	}
+	if len(frames) == 0 {
+		return
+	}
+
+	select {
+	case e.queue <- frames:
+	default:
+		log.Trace("connections export queue is full, dropping payload")
+		e.dropped.Inc()
+	}
+}
+
+// Stop writes the pending payloads and stops the routine of the Exporter
+func (e *Exporter) Stop() {
+	close(e.exit)
+	e.wg.Wait()
+}
+
+func (e *Exporter) run() {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case frames := <-e.queue:
+			e.write(frames)
+		case <-ticker.C:
+			e.sendStats()
+		case <-e.exit:
+			// drain the queue before returning
+			for {
+				select {
+				case frames := <-e.queue:
+					e.write(frames)
+				default:
+					e.sendStats()
+					if err := e.writer.close(); err != nil {
+						log.Debugf("error closing connections export destination: %s", err)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *Exporter) write(frames [][]byte) {
+	if err := e.writer.write(frames); err != nil {
+		log.Warnf("error exporting connections: %s", err)
+		e.errors.Inc()
+		return
+	}
+	e.written.Inc()
+}
+
+func (e *Exporter) sendStats() {
+	if e.statsdClient == nil {
+		return
+	}
+
+	for status, count := range map[string]*atomic.Int64{
+		"written": e.written,
+		"dropped": e.dropped,
+		"error":   e.errors,
+	} {
+		if err := e.statsdClient.Count("datadog.process.connections.exported", count.Swap(0), []string{"status:" + status}, 1.0); err != nil {
+			log.Debug(err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conns.json")
+	e, err := NewExporter(Config{
+		Format:      FormatJSON,
+		Destination: path,
+		MaxFileSize: 1 << 20,
+		MaxFiles:    1,
+		QueueSize:   4,
+		Hostname:    "host-a",
+	}, testContainerTags, nil)
+	require.NoError(t, err)
+
+	e.Export(testConnections())
+	e.Export(testConnections())
+	// pending payloads are written on Stop
+	e.Stop()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	lines := 0
+	for s := bufio.NewScanner(f); s.Scan(); lines++ {
+		var line jsonLine
+		require.NoError(t, json.Unmarshal(s.Bytes(), &line))
+		assert.Equal(t, "host-a", line.Hostname)
+	}
+	assert.Equal(t, 4, lines)
+	assert.EqualValues(t, 2, e.written.Load())
+	assert.EqualValues(t, 0, e.dropped.Load())
+}
+
+func TestExporterConfig(t *testing.T) {
+	valid := Config{Format: FormatIPFIX, Destination: "udp://127.0.0.1:4739", QueueSize: 1}
+	e, err := NewExporter(valid, nil, nil)
+	require.NoError(t, err)
+	e.Stop()
+
+	for name, update := range map[string]func(*Config){
+		"format":      func(c *Config) { c.Format = "netflow" },
+		"destination": func(c *Config) { c.Destination = "" },
+		"queue size":  func(c *Config) { c.QueueSize = 0 },
+	} {
+		cfg := valid
+		update(&cfg)
+		_, err := NewExporter(cfg, nil, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+// blockingWriter blocks writes until released
+type blockingWriter struct {
+	release chan struct{}
+	writes  chan [][]byte
+}
+
+func (b *blockingWriter) write(frames [][]byte) error {
+	<-b.release
+	b.writes <- frames
+	return errors.New("destination unavailable")
+}
+
+func (b *blockingWriter) close() error { return nil }
+
+func TestExporterBackpressure(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{}), writes: make(chan [][]byte, 10)}
+	e := &Exporter{
+		encoder: newJSONEncoder(""),
+		writer:  w,
+		queue:   make(chan [][]byte, 1),
+		exit:    make(chan struct{}),
+	}
+	e.written, e.dropped, e.errors = atomic.NewInt64(0), atomic.NewInt64(0), atomic.NewInt64(0)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.run()
+	}()
+
+	// the first payload is picked up by the routine, which gets blocked writing it
+	e.Export(testConnections())
+	require.Eventually(t, func() bool { return len(e.queue) == 0 }, 5*time.Second, 10*time.Millisecond)
+	// the second payload waits in the queue, the third one is dropped
+	e.Export(testConnections())
+	e.Export(testConnections())
+	assert.EqualValues(t, 1, e.dropped.Load())
+
+	close(w.release)
+	e.Stop()
+	assert.Len(t, w.writes, 2)
+	assert.EqualValues(t, 2, e.errors.Load())
+	assert.EqualValues(t, 0, e.written.Load())
+}
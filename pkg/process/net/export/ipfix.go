@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+)
+
+// IPFIX constants, see RFC 7011 and the IANA IPFIX information elements registry
+const (
+	ipfixVersion         = 10
+	ipfixHeaderLen       = 16
+	ipfixSetHeaderLen    = 4
+	ipfixTemplateSetID   = 2
+	ipfixTemplateIDv4    = 256
+	ipfixTemplateIDv6    = 257
+	ipfixMaxMessageLen   = 1400 // fits in a single datagram over most links
+	ipfixEnterpriseBit   = 0x8000
+	reverseEnterpriseNum = 29305 // RFC 5103 reverse information elements
+
+	ieOctetDeltaCount          = 1
+	iePacketDeltaCount         = 2
+	ieProtocolIdentifier       = 4
+	ieSourceTransportPort      = 7
+	ieSourceIPv4Address        = 8
+	ieDestinationTransportPort = 11
+	ieDestinationIPv4Address   = 12
+	ieSourceIPv6Address        = 27
+	ieDestinationIPv6Address   = 28
+	ieFlowDirection            = 61
+
+	flowDirectionIngress = 0
+	flowDirectionEgress  = 1
+	flowDirectionUnknown = 0xff
+
+	protocolTCP = 6
+	protocolUDP = 17
+)
+
+type ipfixField struct {
+	id         uint16
+	length     uint16
+	enterprise uint32
+}
+
+func ipfixTemplate(addrID, addrLen, dstAddrID uint16) []ipfixField {
+	return []ipfixField{
+		{id: addrID, length: addrLen},
+		{id: dstAddrID, length: addrLen},
+		{id: ieSourceTransportPort, length: 2},
+		{id: ieDestinationTransportPort, length: 2},
+		{id: ieProtocolIdentifier, length: 1},
+		{id: ieFlowDirection, length: 1},
+		{id: ieOctetDeltaCount, length: 8},
+		{id: iePacketDeltaCount, length: 8},
+		{id: ieOctetDeltaCount, length: 8, enterprise: reverseEnterpriseNum},
+		{id: iePacketDeltaCount, length: 8, enterprise: reverseEnterpriseNum},
+	}
+}
+
+var (
+	ipfixTemplateV4 = ipfixTemplate(ieSourceIPv4Address, net.IPv4len, ieDestinationIPv4Address)
+	ipfixTemplateV6 = ipfixTemplate(ieSourceIPv6Address, net.IPv6len, ieDestinationIPv6Address)
+)
+
+func recordLen(template []ipfixField) int {
+	n := 0
+	for _, f := range template {
+		n += int(f.length)
+	}
+	return n
+}
+
+// ipfixEncoder writes IPFIX messages holding the tuple, direction, and byte and
+// packet counts of the connections. The sent counts are reported as the forward
+// direction and the received counts as the RFC 5103 reverse direction. RTT,
+// retransmits, DNS names and tags have no standard information element and are
+// only available in the JSON lines format.
+//
+// Every message carries the templates, so that each message can be decoded on its
+// own regardless of the destination being a rotated file, a stream or datagrams.
+type ipfixEncoder struct {
+	observationDomainID uint32
+	sequence            uint32
+}
+
+func newIPFIXEncoder(observationDomainID uint32) *ipfixEncoder {
+	return &ipfixEncoder{observationDomainID: observationDomainID}
+}
+
+func (e *ipfixEncoder) encode(records []record, now time.Time) ([][]byte, error) {
+	var v4, v6 []*model.Connection
+	for _, r := range records {
+		isV6 := r.conn.Family == model.ConnectionFamily_v6
+		if ipfixAddr(r.conn.Laddr, isV6) == nil || ipfixAddr(r.conn.Raddr, isV6) == nil {
+			continue
+		}
+		if isV6 {
+			v6 = append(v6, r.conn)
+		} else {
+			v4 = append(v4, r.conn)
+		}
+	}
+
+	var frames [][]byte
+	for _, set := range []struct {
+		id       uint16
+		template []ipfixField
+		conns    []*model.Connection
+	}{
+		{ipfixTemplateIDv4, ipfixTemplateV4, v4},
+		{ipfixTemplateIDv6, ipfixTemplateV6, v6},
+	} {
+		frames = append(frames, e.encodeSet(set.id, set.template, set.conns, now)...)
+	}
+	return frames, nil
+}
+
+// encodeSet encodes the given connections as data records of the given template,
+// splitting them across as many messages as needed
+func (e *ipfixEncoder) encodeSet(id uint16, template []ipfixField, conns []*model.Connection, now time.Time) [][]byte {
+	templateSet := appendTemplateSet(nil, id, template)
+	perMessage := (ipfixMaxMessageLen - ipfixHeaderLen - len(templateSet) - ipfixSetHeaderLen) / recordLen(template)
+
+	var msgs [][]byte
+	for len(conns) > 0 {
+		n := perMessage
+		if n > len(conns) {
+			n = len(conns)
+		}
+
+		msg := make([]byte, ipfixHeaderLen, ipfixMaxMessageLen)
+		msg = append(msg, templateSet...)
+		setStart := len(msg)
+		msg = append(msg, 0, 0, 0, 0)
+		for _, c := range conns[:n] {
+			msg = appendDataRecord(msg, c, id == ipfixTemplateIDv6)
+		}
+		binary.BigEndian.PutUint16(msg[setStart:], id)
+		binary.BigEndian.PutUint16(msg[setStart+2:], uint16(len(msg)-setStart))
+
+		binary.BigEndian.PutUint16(msg[0:], ipfixVersion)
+		binary.BigEndian.PutUint16(msg[2:], uint16(len(msg)))
+		binary.BigEndian.PutUint32(msg[4:], uint32(now.Unix()))
+		// the sequence number counts the data records sent before this message
+		binary.BigEndian.PutUint32(msg[8:], e.sequence)
+		binary.BigEndian.PutUint32(msg[12:], e.observationDomainID)
+		e.sequence += uint32(n)
+
+		msgs = append(msgs, msg)
+		conns = conns[n:]
+	}
+	return msgs
+}
+
+func appendTemplateSet(b []byte, id uint16, template []ipfixField) []byte {
+	start := len(b)
+	b = append(b, 0, ipfixTemplateSetID, 0, 0)
+	b = appendUint16(b, id)
+	b = appendUint16(b, uint16(len(template)))
+	for _, f := range template {
+		if f.enterprise != 0 {
+			b = appendUint16(b, f.id|ipfixEnterpriseBit)
+			b = appendUint16(b, f.length)
+			b = appendUint32(b, f.enterprise)
+			continue
+		}
+		b = appendUint16(b, f.id)
+		b = appendUint16(b, f.length)
+	}
+	binary.BigEndian.PutUint16(b[start+2:], uint16(len(b)-start))
+	return b
+}
+
+// ipfixAddr returns the given address encoded for the given family, or nil if it
+// isn't valid. Connections with invalid addresses are skipped.
+func ipfixAddr(a *model.Addr, v6 bool) net.IP {
+	if a == nil {
+		return nil
+	}
+	ip := net.ParseIP(a.Ip)
+	if v6 {
+		return ip.To16()
+	}
+	return ip.To4()
+}
+
+func appendDataRecord(b []byte, c *model.Connection, v6 bool) []byte {
+	b = append(b, ipfixAddr(c.Laddr, v6)...)
+	b = append(b, ipfixAddr(c.Raddr, v6)...)
+	b = appendUint16(b, uint16(c.Laddr.Port))
+	b = appendUint16(b, uint16(c.Raddr.Port))
+
+	proto := byte(protocolTCP)
+	if c.Type == model.ConnectionType_udp {
+		proto = protocolUDP
+	}
+	direction := byte(flowDirectionUnknown)
+	switch c.Direction {
+	case model.ConnectionDirection_incoming:
+		direction = flowDirectionIngress
+	case model.ConnectionDirection_outgoing:
+		direction = flowDirectionEgress
+	}
+	b = append(b, proto, direction)
+
+	b = appendUint64(b, c.LastBytesSent)
+	b = appendUint64(b, c.LastPacketsSent)
+	b = appendUint64(b, c.LastBytesReceived)
+	b = appendUint64(b, c.LastPacketsReceived)
+	return b
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v>>32)), uint32(v))
+}
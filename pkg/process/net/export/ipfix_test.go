@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ipfixMessage struct {
+	exportTime, sequence, domain uint32
+	templates                    map[uint16][]ipfixField
+	records                      map[uint16][][]byte
+}
+
+// decodeIPFIX decodes a message, checking that it's well-formed
+func decodeIPFIX(t *testing.T, msg []byte) ipfixMessage {
+	require.GreaterOrEqual(t, len(msg), ipfixHeaderLen)
+	require.LessOrEqual(t, len(msg), ipfixMaxMessageLen)
+	require.EqualValues(t, ipfixVersion, binary.BigEndian.Uint16(msg))
+	require.EqualValues(t, len(msg), binary.BigEndian.Uint16(msg[2:]))
+
+	m := ipfixMessage{
+		exportTime: binary.BigEndian.Uint32(msg[4:]),
+		sequence:   binary.BigEndian.Uint32(msg[8:]),
+		domain:     binary.BigEndian.Uint32(msg[12:]),
+		templates:  make(map[uint16][]ipfixField),
+		records:    make(map[uint16][][]byte),
+	}
+	for b := msg[ipfixHeaderLen:]; len(b) > 0; {
+		require.GreaterOrEqual(t, len(b), ipfixSetHeaderLen)
+		id, length := binary.BigEndian.Uint16(b), int(binary.BigEndian.Uint16(b[2:]))
+		require.LessOrEqual(t, length, len(b))
+		set := b[ipfixSetHeaderLen:length]
+		b = b[length:]
+
+		if id == ipfixTemplateSetID {
+			templateID, count := binary.BigEndian.Uint16(set), int(binary.BigEndian.Uint16(set[2:]))
+			set = set[4:]
+			for i := 0; i < count; i++ {
+				f := ipfixField{id: binary.BigEndian.Uint16(set), length: binary.BigEndian.Uint16(set[2:])}
+				set = set[4:]
+				if f.id&ipfixEnterpriseBit != 0 {
+					f.id &^= ipfixEnterpriseBit
+					f.enterprise = binary.BigEndian.Uint32(set)
+					set = set[4:]
+				}
+				m.templates[templateID] = append(m.templates[templateID], f)
+			}
+			require.Empty(t, set)
+			continue
+		}
+
+		template, ok := m.templates[id]
+		require.True(t, ok, "data set %d without template", id)
+		n := recordLen(template)
+		require.Zero(t, len(set)%n)
+		for ; len(set) > 0; set = set[n:] {
+			m.records[id] = append(m.records[id], set[:n])
+		}
+	}
+	return m
+}
+
+func TestIPFIXEncoder(t *testing.T) {
+	now := time.Unix(1654084800, 0)
+	enc := newIPFIXEncoder(7)
+
+	msgs, err := enc.encode(newRecords(testConnections(), nil), now)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+
+	v4 := decodeIPFIX(t, msgs[0])
+	assert.EqualValues(t, now.Unix(), v4.exportTime)
+	assert.EqualValues(t, 0, v4.sequence)
+	assert.EqualValues(t, 7, v4.domain)
+	assert.Equal(t, ipfixTemplateV4, v4.templates[ipfixTemplateIDv4])
+	require.Len(t, v4.records[ipfixTemplateIDv4], 1)
+
+	r := v4.records[ipfixTemplateIDv4][0]
+	assert.Equal(t, net.ParseIP("10.0.0.1").To4(), net.IP(r[0:4]))
+	assert.Equal(t, net.ParseIP("10.0.0.2").To4(), net.IP(r[4:8]))
+	assert.EqualValues(t, 52800, binary.BigEndian.Uint16(r[8:]))
+	assert.EqualValues(t, 9092, binary.BigEndian.Uint16(r[10:]))
+	assert.EqualValues(t, protocolTCP, r[12])
+	assert.EqualValues(t, flowDirectionEgress, r[13])
+	assert.EqualValues(t, 1024, binary.BigEndian.Uint64(r[14:]))
+	assert.EqualValues(t, 3, binary.BigEndian.Uint64(r[22:]))
+	assert.EqualValues(t, 2048, binary.BigEndian.Uint64(r[30:]))
+	assert.EqualValues(t, 4, binary.BigEndian.Uint64(r[38:]))
+
+	v6 := decodeIPFIX(t, msgs[1])
+	assert.EqualValues(t, 1, v6.sequence)
+	require.Len(t, v6.records[ipfixTemplateIDv6], 1)
+	r = v6.records[ipfixTemplateIDv6][0]
+	assert.Equal(t, net.ParseIP("fd00::1"), net.IP(r[0:16]))
+	assert.Equal(t, net.ParseIP("fd00::53"), net.IP(r[16:32]))
+	assert.EqualValues(t, protocolUDP, r[36])
+	assert.EqualValues(t, flowDirectionIngress, r[37])
+
+	// the sequence number keeps counting across payloads
+	msgs, err = enc.encode(newRecords(testConnections(), nil), now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, decodeIPFIX(t, msgs[0]).sequence)
+}
+
+func TestIPFIXEncoderSplitsMessages(t *testing.T) {
+	conns := &model.Connections{}
+	for i := 0; i < 100; i++ {
+		conns.Conns = append(conns.Conns, &model.Connection{
+			Laddr: &model.Addr{Ip: "10.0.0.1", Port: int32(40000 + i)},
+			Raddr: &model.Addr{Ip: "10.0.0.2", Port: 443},
+		})
+	}
+	// connections with invalid addresses are skipped
+	conns.Conns = append(conns.Conns, &model.Connection{
+		Laddr: &model.Addr{Ip: "not an ip"},
+		Raddr: &model.Addr{Ip: "10.0.0.2", Port: 443},
+	})
+
+	msgs, err := newIPFIXEncoder(0).encode(newRecords(conns, nil), time.Now())
+	require.NoError(t, err)
+	require.Greater(t, len(msgs), 1)
+
+	total := 0
+	for _, msg := range msgs {
+		m := decodeIPFIX(t, msg)
+		assert.EqualValues(t, total, m.sequence)
+		total += len(m.records[ipfixTemplateIDv4])
+	}
+	assert.Equal(t, 100, total)
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+)
+
+// jsonLine is the document written on each line of the JSON lines format
+type jsonLine struct {
+	Timestamp           int64           `json:"timestamp"`
+	Hostname            string          `json:"hostname,omitempty"`
+	Connection          json.RawMessage `json:"connection"`
+	Tags                []string        `json:"tags,omitempty"`
+	DNSNames            []string        `json:"dns_names,omitempty"`
+	LocalContainerTags  []string        `json:"local_container_tags,omitempty"`
+	RemoteContainerTags []string        `json:"remote_container_tags,omitempty"`
+}
+
+// jsonEncoder writes one JSON document per connection. The connection itself is
+// serialized the same way as by the JSON marshaler of pkg/network/encoding.
+type jsonEncoder struct {
+	hostname   string
+	marshaller jsonpb.Marshaler
+	buf        bytes.Buffer
+}
+
+func newJSONEncoder(hostname string) *jsonEncoder {
+	return &jsonEncoder{
+		hostname: hostname,
+		marshaller: jsonpb.Marshaler{
+			EmitDefaults: true,
+		},
+	}
+}
+
+func (e *jsonEncoder) encode(records []record, now time.Time) ([][]byte, error) {
+	frames := make([][]byte, 0, len(records))
+	for _, r := range records {
+		e.buf.Reset()
+		if err := e.marshaller.Marshal(&e.buf, r.conn); err != nil {
+			return nil, err
+		}
+
+		line, err := json.Marshal(jsonLine{
+			Timestamp:           now.Unix(),
+			Hostname:            e.hostname,
+			Connection:          e.buf.Bytes(),
+			Tags:                r.tags,
+			DNSNames:            r.dnsNames,
+			LocalContainerTags:  r.localContainerTags,
+			RemoteContainerTags: r.remoteContainerTags,
+		})
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, append(line, '\n'))
+	}
+	return frames, nil
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	conns := testConnections()
+	now := time.Unix(1654084800, 0)
+
+	frames, err := newJSONEncoder("host-a").encode(newRecords(conns, testContainerTags), now)
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+
+	for i, frame := range frames {
+		require.Equal(t, byte('\n'), frame[len(frame)-1])
+		assert.Equal(t, -1, bytes.IndexByte(frame[:len(frame)-1], '\n'))
+
+		var line jsonLine
+		require.NoError(t, json.Unmarshal(frame, &line))
+		assert.Equal(t, now.Unix(), line.Timestamp)
+		assert.Equal(t, "host-a", line.Hostname)
+
+		var conn model.Connection
+		require.NoError(t, jsonpb.Unmarshal(bytes.NewReader(line.Connection), &conn))
+		assert.Equal(t, conns.Conns[i].Laddr, conn.Laddr)
+		assert.Equal(t, conns.Conns[i].Raddr, conn.Raddr)
+		assert.Equal(t, conns.Conns[i].LastBytesSent, conn.LastBytesSent)
+		assert.Equal(t, conns.Conns[i].LastRetransmits, conn.LastRetransmits)
+		assert.Equal(t, conns.Conns[i].Rtt, conn.Rtt)
+
+		if i == 0 {
+			assert.Equal(t, []string{"kafka_topic:orders"}, line.Tags)
+			assert.Equal(t, []string{"kafka.example.com"}, line.DNSNames)
+			assert.Equal(t, []string{"container_name:client"}, line.LocalContainerTags)
+			assert.Equal(t, []string{"container_name:broker"}, line.RemoteContainerTags)
+		} else {
+			assert.Empty(t, line.Tags)
+			assert.Empty(t, line.DNSNames)
+			assert.Empty(t, line.LocalContainerTags)
+			assert.Empty(t, line.RemoteContainerTags)
+		}
+	}
+}
+
+func TestNewRecordsCachesContainerTags(t *testing.T) {
+	conns := testConnections()
+	conns.Conns[1].Laddr.ContainerId = "client"
+
+	calls := 0
+	records := newRecords(conns, func(containerID string) []string {
+		calls++
+		return testContainerTags(containerID)
+	})
+	require.Len(t, records, 2)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []string{"container_name:client"}, records[1].localContainerTags)
+
+	// container tags are optional
+	records = newRecords(conns, nil)
+	assert.Nil(t, records[0].localContainerTags)
+}
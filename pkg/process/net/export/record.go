@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+)
+
+// TagsProvider returns the tags of the container with the given ID
+type TagsProvider func(containerID string) []string
+
+// record is a connection along with the data that's only referenced by index in
+// the model.Connections payload
+type record struct {
+	conn *model.Connection
+
+	// tags are the connection tags, e.g. the HTTP or Kafka ones
+	tags []string
+	// dnsNames are the names the remote address was resolved to
+	dnsNames []string
+	// localContainerTags and remoteContainerTags are the tags of the containers
+	// of both ends of the connection, when they run on this host
+	localContainerTags  []string
+	remoteContainerTags []string
+}
+
+// newRecords resolves the indexes of the connections of the given payload, which
+// must not have been batched yet
+func newRecords(conns *model.Connections, containerTags TagsProvider) []record {
+	cache := make(map[string][]string)
+	tagsFor := func(containerID string) []string {
+		if containerID == "" || containerTags == nil {
+			return nil
+		}
+		tags, ok := cache[containerID]
+		if !ok {
+			tags = containerTags(containerID)
+			cache[containerID] = tags
+		}
+		return tags
+	}
+
+	records := make([]record, 0, len(conns.Conns))
+	for _, c := range conns.Conns {
+		r := record{conn: c}
+		for _, idx := range c.Tags {
+			if int(idx) < len(conns.Tags) {
+				r.tags = append(r.tags, conns.Tags[idx])
+			}
+		}
+		if c.Raddr != nil {
+			if entry, ok := conns.Dns[c.Raddr.Ip]; ok {
+				r.dnsNames = entry.Names
+			}
+			r.remoteContainerTags = tagsFor(c.Raddr.ContainerId)
+		}
+		if c.Laddr != nil {
+			r.localContainerTags = tagsFor(c.Laddr.ContainerId)
+		}
+		records = append(records, r)
+	}
+	return records
+}
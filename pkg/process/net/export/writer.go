@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+	// redialDelay is the minimum delay between two connection attempts to a collector
+	redialDelay = 10 * time.Second
+)
+
+// writer writes the frames produced by an encoder to a destination. Frames are the
+// unit of datagram destinations, they're concatenated for other destinations.
+//
+// A writer is only used by the routine of the Exporter, hence doesn't need to be
+// safe for concurrent use.
+type writer interface {
+	write(frames [][]byte) error
+	close() error
+}
+
+// newWriter returns the writer of the given destination, which is either a file
+// path or a URL whose scheme is one of tcp, udp, unix and unixgram
+func newWriter(destination string, maxFileSize int64, maxFiles int) (writer, error) {
+	scheme, address := "file", destination
+	if i := strings.Index(destination, "://"); i >= 0 {
+		scheme, address = destination[:i], destination[i+3:]
+	}
+	if address == "" {
+		return nil, fmt.Errorf("invalid destination %q", destination)
+	}
+
+	switch scheme {
+	case "file":
+		return newRotatingFile(address, maxFileSize, maxFiles)
+	case "tcp", "unix":
+		return &streamWriter{network: scheme, address: address}, nil
+	case "udp", "unixgram":
+		return &datagramWriter{network: scheme, address: address}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in destination %q", scheme, destination)
+	}
+}
+
+// rotatingFile appends the frames to a file which is rotated once it reaches
+// maxSize bytes. Up to maxFiles rotated files are kept, named after the file with
+// a .1 to .<maxFiles> suffix, .1 being the most recent one.
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	f    *os.File
+	size int64
+}
+
+func newRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid maximum file size %d", maxSize)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		log.Debugf("error closing %s: %s", r.path, err)
+	}
+	r.f = nil
+
+	if r.maxFiles <= 0 {
+		if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.open()
+	}
+
+	for i := r.maxFiles - 1; i > 0; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) write(frames [][]byte) error {
+	if r.f == nil {
+		// a previous rotation failed
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, frame := range frames {
+		// frames are never split across files
+		if r.size > 0 && r.size+int64(len(frame)) > r.maxSize {
+			if err := r.rotate(); err != nil {
+				return fmt.Errorf("error rotating %s: %w", r.path, err)
+			}
+		}
+		n, err := r.f.Write(frame)
+		r.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rotatingFile) close() error {
+	if r.f == nil {
+		return nil
+	}
+	return r.f.Close()
+}
+
+// streamWriter writes the frames to a TCP or unix stream socket. The connection is
+// established lazily and re-established after errors, at most every redialDelay.
+type streamWriter struct {
+	network, address string
+
+	conn     net.Conn
+	lastDial time.Time
+}
+
+func (s *streamWriter) write(frames [][]byte) error {
+	if s.conn == nil {
+		if time.Since(s.lastDial) < redialDelay {
+			return fmt.Errorf("not connected to %s://%s", s.network, s.address)
+		}
+		s.lastDial = time.Now()
+		conn, err := net.DialTimeout(s.network, s.address, dialTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	buffers := net.Buffers(frames)
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := buffers.WriteTo(s.conn); err != nil {
+		// the collector can't tell where the partially written frame ends, start over
+		_ = s.close()
+		return err
+	}
+	return nil
+}
+
+func (s *streamWriter) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// datagramWriter writes each frame as a datagram to a UDP or unixgram socket
+type datagramWriter struct {
+	network, address string
+
+	conn net.Conn
+}
+
+func (d *datagramWriter) write(frames [][]byte) error {
+	if d.conn == nil {
+		conn, err := net.DialTimeout(d.network, d.address, dialTimeout)
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+	}
+
+	for _, frame := range frames {
+		if _, err := d.conn.Write(frame); err != nil {
+			_ = d.close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *datagramWriter) close() error {
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
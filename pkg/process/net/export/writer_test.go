@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package export
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWriter(t *testing.T) {
+	dir := t.TempDir()
+
+	for destination, expected := range map[string]interface{}{
+		filepath.Join(dir, "conns.json"):                    &rotatingFile{},
+		"file://" + filepath.Join(dir, "nested", "c.ipfix"): &rotatingFile{},
+		"tcp://127.0.0.1:4739":                              &streamWriter{},
+		"unix:///var/run/collector.sock":                    &streamWriter{},
+		"udp://127.0.0.1:4739":                              &datagramWriter{},
+		"unixgram:///var/run/collector.sock":                &datagramWriter{},
+	} {
+		w, err := newWriter(destination, 1024, 1)
+		require.NoError(t, err, destination)
+		assert.IsType(t, expected, w, destination)
+		require.NoError(t, w.close())
+	}
+
+	for _, destination := range []string{"", "file://", "http://localhost:8080"} {
+		_, err := newWriter(destination, 1024, 1)
+		assert.Error(t, err, destination)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conns.json")
+	w, err := newRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	defer w.close()
+
+	write := func(frames ...string) {
+		var b [][]byte
+		for _, f := range frames {
+			b = append(b, []byte(f))
+		}
+		require.NoError(t, w.write(b))
+	}
+	read := func(path string) string {
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	write("aaaa\n", "bbbb\n")
+	assert.Equal(t, "aaaa\nbbbb\n", read(path))
+
+	// frames are never split across files
+	write("cccc\n")
+	assert.Equal(t, "cccc\n", read(path))
+	assert.Equal(t, "aaaa\nbbbb\n", read(path+".1"))
+
+	// frames larger than the maximum size still get written
+	write("dddddddddddd\n")
+	write("eeee\n")
+	assert.Equal(t, "eeee\n", read(path))
+	assert.Equal(t, "dddddddddddd\n", read(path+".1"))
+	assert.Equal(t, "cccc\n", read(path+".2"))
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	// the size of an existing file is accounted for
+	require.NoError(t, w.close())
+	w, err = newRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	write("ffff\n", "gggg\n")
+	assert.Equal(t, "gggg\n", read(path))
+	assert.Equal(t, "eeee\nffff\n", read(path+".1"))
+}
+
+func TestRotatingFileWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conns.json")
+	w, err := newRotatingFile(path, 5, 0)
+	require.NoError(t, err)
+	defer w.close()
+
+	require.NoError(t, w.write([][]byte{[]byte("aaaa\n"), []byte("bbbb\n")}))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "bbbb\n", string(b))
+	_, err = os.Stat(path + ".1")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStreamWriter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	w := &streamWriter{network: "tcp", address: l.Addr().String()}
+	defer w.close()
+	require.NoError(t, w.write([][]byte{[]byte("aaaa\n"), []byte("bbbb\n")}))
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, expected := range []string{"aaaa\n", "bbbb\n"} {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+}
+
+func TestStreamWriterRedialDelay(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	w := &streamWriter{network: "tcp", address: address}
+	assert.Error(t, w.write([][]byte{[]byte("aaaa\n")}))
+	dialed := w.lastDial
+
+	// no connection attempt is made before the redial delay
+	assert.Error(t, w.write([][]byte{[]byte("aaaa\n")}))
+	assert.Equal(t, dialed, w.lastDial)
+}
+
+func TestDatagramWriter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	w := &datagramWriter{network: "udp", address: pc.LocalAddr().String()}
+	defer w.close()
+	require.NoError(t, w.write([][]byte{[]byte("first"), []byte("second")}))
+
+	buf := make([]byte, 64)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, expected := range []string{"first", "second"} {
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buf[:n]))
+	}
+}
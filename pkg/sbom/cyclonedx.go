@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	cycloneDXFormat      = "CycloneDX"
+	cycloneDXSpecVersion = "1.4"
+
+	// propertyLocation is the property holding the paths a package was read from
+	propertyLocation = "datadog:location"
+)
+
+// CycloneDX component types
+const (
+	ComponentTypeLibrary         = "library"
+	ComponentTypeContainer       = "container"
+	ComponentTypeOperatingSystem = "operating-system"
+)
+
+// BOM is a CycloneDX software bill of materials, as specified by https://cyclonedx.org/docs/1.4/json/
+type BOM struct {
+	BOMFormat    string      `json:"bomFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	SerialNumber string      `json:"serialNumber"`
+	Version      int         `json:"version"`
+	Metadata     BOMMetadata `json:"metadata"`
+	Components   []Component `json:"components"`
+}
+
+// BOMMetadata describes the subject of a bill of materials and how it was generated
+type BOMMetadata struct {
+	Timestamp string     `json:"timestamp"`
+	Tools     []Tool     `json:"tools"`
+	Component *Component `json:"component,omitempty"`
+}
+
+// Tool is a tool which generated a bill of materials
+type Tool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Component is a component of a bill of materials
+type Component struct {
+	BOMRef      string     `json:"bom-ref,omitempty"`
+	Type        string     `json:"type"`
+	Name        string     `json:"name"`
+	Version     string     `json:"version,omitempty"`
+	Description string     `json:"description,omitempty"`
+	PURL        string     `json:"purl,omitempty"`
+	Properties  []Property `json:"properties,omitempty"`
+}
+
+// Property is a name-value pair of a component
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewBOM returns the bill of materials of the inventory of a root filesystem, whose subject is the host or the
+// container image the root filesystem belongs to
+func NewBOM(subject Component, inventory *Inventory, now time.Time) *BOM {
+	bom := &BOM{
+		BOMFormat:    cycloneDXFormat,
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + uuid.New().String(),
+		Version:      1,
+		Metadata: BOMMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools: []Tool{{
+				Vendor:  "Datadog",
+				Name:    "datadog-agent",
+				Version: version.AgentVersion,
+			}},
+			Component: &subject,
+		},
+		Components: []Component{},
+	}
+
+	if release := inventory.OS; release.ID != "" {
+		bom.Components = append(bom.Components, Component{
+			BOMRef:      "os:" + release.ID + "@" + release.VersionID,
+			Type:        ComponentTypeOperatingSystem,
+			Name:        release.ID,
+			Version:     release.VersionID,
+			Description: release.PrettyName,
+		})
+	}
+
+	// a package can be listed in several lockfiles, while the references of the components must be unique
+	indexes := make(map[string]int)
+	for _, pkg := range inventory.Packages {
+		purl := packageURL(pkg, inventory.OS)
+		location := Property{Name: propertyLocation, Value: pkg.Location}
+
+		if i, exists := indexes[purl]; exists {
+			bom.Components[i].Properties = append(bom.Components[i].Properties, location)
+			continue
+		}
+
+		indexes[purl] = len(bom.Components)
+		bom.Components = append(bom.Components, Component{
+			BOMRef:     purl,
+			Type:       ComponentTypeLibrary,
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			PURL:       purl,
+			Properties: []Property{location},
+		})
+	}
+
+	return bom
+}
+
+// packageURL returns the package URL of a package, as specified by https://github.com/package-url/purl-spec
+func packageURL(pkg Package, release OSRelease) string {
+	var namespace string
+	name := pkg.Name
+	version := pkg.Version
+	qualifiers := make(map[string]string)
+
+	switch pkg.Manager {
+	case ManagerDpkg, ManagerRpm, ManagerApk:
+		namespace = release.ID
+		if pkg.Architecture != "" {
+			qualifiers["arch"] = pkg.Architecture
+		}
+		if release.ID != "" && release.VersionID != "" {
+			qualifiers["distro"] = release.ID + "-" + release.VersionID
+		}
+		if pkg.Manager == ManagerRpm {
+			if epoch, rest, found := strings.Cut(version, ":"); found {
+				qualifiers["epoch"] = epoch
+				version = rest
+			}
+		}
+	case ManagerNpm, ManagerComposer:
+		// `@scope/name` for npm, `vendor/name` for composer
+		if i := strings.LastIndex(name, "/"); i != -1 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	case ManagerPypi:
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("pkg:")
+	sb.WriteString(pkg.Manager)
+	sb.WriteString("/")
+	if namespace != "" {
+		sb.WriteString(escapePURLSegment(namespace))
+		sb.WriteString("/")
+	}
+	sb.WriteString(escapePURLSegment(name))
+	if version != "" {
+		sb.WriteString("@")
+		sb.WriteString(escapePURLSegment(version))
+	}
+
+	if len(qualifiers) > 0 {
+		keys := make([]string, 0, len(qualifiers))
+		for key := range qualifiers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for i, key := range keys {
+			if i == 0 {
+				sb.WriteString("?")
+			} else {
+				sb.WriteString("&")
+			}
+			sb.WriteString(key)
+			sb.WriteString("=")
+			sb.WriteString(url.QueryEscape(qualifiers[key]))
+		}
+	}
+
+	return sb.String()
+}
+
+// purlSegmentReplacer percent-encodes the characters left as is by url.PathEscape but not allowed in the segments of
+// a package URL, such as the `@` of the npm scopes and the `+` of the debian versions
+var purlSegmentReplacer = strings.NewReplacer("@", "%40", "+", "%2B")
+
+// escapePURLSegment percent-encodes a segment of a package URL
+func escapePURLSegment(segment string) string {
+	return purlSegmentReplacer.Replace(url.PathEscape(segment))
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageURL(t *testing.T) {
+	debian := OSRelease{ID: "debian", VersionID: "11"}
+
+	tests := []struct {
+		name    string
+		pkg     Package
+		release OSRelease
+		want    string
+	}{
+		{
+			name:    "deb",
+			pkg:     Package{Name: "libc6", Version: "2.31-13+deb11u5", Architecture: "amd64", Manager: ManagerDpkg},
+			release: debian,
+			want:    "pkg:deb/debian/libc6@2.31-13%2Bdeb11u5?arch=amd64&distro=debian-11",
+		},
+		{
+			name:    "rpm with epoch",
+			pkg:     Package{Name: "openssl-libs", Version: "1:3.0.1-41.el9", Architecture: "x86_64", Manager: ManagerRpm},
+			release: OSRelease{ID: "rhel", VersionID: "9.0"},
+			want:    "pkg:rpm/rhel/openssl-libs@3.0.1-41.el9?arch=x86_64&distro=rhel-9.0&epoch=1",
+		},
+		{
+			name: "npm scoped",
+			pkg:  Package{Name: "@babel/core", Version: "7.19.3", Manager: ManagerNpm},
+			want: "pkg:npm/%40babel/core@7.19.3",
+		},
+		{
+			name: "composer",
+			pkg:  Package{Name: "monolog/monolog", Version: "3.2.0", Manager: ManagerComposer},
+			want: "pkg:composer/monolog/monolog@3.2.0",
+		},
+		{
+			name: "pypi normalized",
+			pkg:  Package{Name: "Typing_Extensions", Version: "4.4.0", Manager: ManagerPypi},
+			want: "pkg:pypi/typing-extensions@4.4.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, packageURL(tt.pkg, tt.release))
+		})
+	}
+}
+
+func TestNewBOM(t *testing.T) {
+	inventory := &Inventory{
+		OS: OSRelease{ID: "alpine", VersionID: "3.16.2", PrettyName: "Alpine Linux v3.16"},
+		Packages: []Package{
+			{Name: "musl", Version: "1.2.3-r0", Architecture: "x86_64", Manager: ManagerApk, Location: "/lib/apk/db/installed"},
+			{Name: "lodash", Version: "4.17.21", Manager: ManagerNpm, Location: "/app/package-lock.json"},
+			{Name: "lodash", Version: "4.17.21", Manager: ManagerNpm, Location: "/app/yarn.lock"},
+		},
+	}
+	subject := Component{BOMRef: "sha256:1234", Type: ComponentTypeContainer, Name: "nginx", Version: "1.23"}
+	now := time.Date(2022, 11, 8, 10, 0, 0, 0, time.UTC)
+
+	bom := NewBOM(subject, inventory, now)
+
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, "1.4", bom.SpecVersion)
+	assert.Regexp(t, "^urn:uuid:[0-9a-f-]{36}$", bom.SerialNumber)
+	assert.Equal(t, "2022-11-08T10:00:00Z", bom.Metadata.Timestamp)
+	assert.Equal(t, &subject, bom.Metadata.Component)
+
+	// packages listed in several lockfiles are a single component
+	assert.Equal(t, []Component{
+		{
+			BOMRef:      "os:alpine@3.16.2",
+			Type:        ComponentTypeOperatingSystem,
+			Name:        "alpine",
+			Version:     "3.16.2",
+			Description: "Alpine Linux v3.16",
+		},
+		{
+			BOMRef:     "pkg:apk/alpine/musl@1.2.3-r0?arch=x86_64&distro=alpine-3.16.2",
+			Type:       ComponentTypeLibrary,
+			Name:       "musl",
+			Version:    "1.2.3-r0",
+			PURL:       "pkg:apk/alpine/musl@1.2.3-r0?arch=x86_64&distro=alpine-3.16.2",
+			Properties: []Property{{Name: "datadog:location", Value: "/lib/apk/db/installed"}},
+		},
+		{
+			BOMRef:  "pkg:npm/lodash@4.17.21",
+			Type:    ComponentTypeLibrary,
+			Name:    "lodash",
+			Version: "4.17.21",
+			PURL:    "pkg:npm/lodash@4.17.21",
+			Properties: []Property{
+				{Name: "datadog:location", Value: "/app/package-lock.json"},
+				{Name: "datadog:location", Value: "/app/yarn.lock"},
+			},
+		},
+	}, bom.Components)
+
+	raw, err := json.Marshal(bom)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"bomFormat":"CycloneDX"`)
+	assert.Contains(t, string(raw), `"bom-ref":"sha256:1234"`)
+}
@@ -0,0 +1,339 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// lockfileParser returns the packages of a lockfile
+type lockfileParser func(r io.Reader, location string) ([]Package, error)
+
+// lockfileParsers are the parsers of the supported language lockfiles, by file name
+var lockfileParsers = map[string]lockfileParser{
+	"package-lock.json": parseNpmLockfile,
+	"yarn.lock":         parseYarnLockfile,
+	"Pipfile.lock":      parsePipfileLock,
+	"poetry.lock":       parseTOMLPackagesLockfile(ManagerPypi),
+	"Gemfile.lock":      parseGemfileLock,
+	"composer.lock":     parseComposerLockfile,
+	"Cargo.lock":        parseTOMLPackagesLockfile(ManagerCargo),
+}
+
+// lockfileSkippedDirs are the directories holding the installed dependencies of the projects, whose lockfiles are
+// already covered by the lockfile of the project
+var lockfileSkippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	".git":         true,
+}
+
+// scanLockfiles returns the packages of the lockfiles found in the lockfile directories of a root filesystem. The
+// lockfile directories are resolved inside the root filesystem, the symlinks found while walking them aren't followed.
+func scanLockfiles(root string, opts ScanOptions) []Package {
+	var packages []Package
+
+	for _, dir := range opts.LockfileDirs {
+		dirPath, err := securejoin.SecureJoin(root, dir)
+		if err != nil {
+			log.Debugf("failed to resolve lockfile directory %s: %v", dir, err)
+			continue
+		}
+		baseDepth := strings.Count(dirPath, string(filepath.Separator))
+
+		err = filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// the directory doesn't exist, or can't be read
+				return fs.SkipDir
+			}
+
+			if entry.IsDir() {
+				if path != dirPath && (lockfileSkippedDirs[entry.Name()] || (opts.LockfileMaxDepth > 0 && strings.Count(path, string(filepath.Separator))-baseDepth >= opts.LockfileMaxDepth)) {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			parser, ok := lockfileParsers[entry.Name()]
+			if !ok || !entry.Type().IsRegular() {
+				return nil
+			}
+
+			location := "/" + strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(path, root)), "/")
+			pkgs, err := readLockfile(path, location, parser, opts.MaxFileSize)
+			if err != nil {
+				log.Debugf("failed to read lockfile %s: %v", location, err)
+				return nil
+			}
+			packages = append(packages, pkgs...)
+			return nil
+		})
+		if err != nil {
+			log.Debugf("failed to search lockfiles in %s: %v", dir, err)
+		}
+	}
+
+	return packages
+}
+
+func readLockfile(path string, location string, parser lockfileParser, maxFileSize int64) ([]Package, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if maxFileSize > 0 {
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if fi.Size() > maxFileSize {
+			return nil, fmt.Errorf("file larger than %d bytes", maxFileSize)
+		}
+	}
+
+	return parser(f, location)
+}
+
+// parseNpmLockfile returns the packages of a npm lockfile. The packages of the lockfiles of version 2 and 3 are
+// listed by install path, while the lockfiles of version 1 only have nested dependencies.
+func parseNpmLockfile(r io.Reader, location string) ([]Package, error) {
+	type npmDependency struct {
+		Version      string                    `json:"version"`
+		Dependencies map[string]*npmDependency `json:"dependencies"`
+	}
+	var lockfile struct {
+		Packages map[string]struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			Link    bool   `json:"link"`
+		} `json:"packages"`
+		Dependencies map[string]*npmDependency `json:"dependencies"`
+	}
+	if err := json.NewDecoder(r).Decode(&lockfile); err != nil {
+		return nil, err
+	}
+
+	var packages []Package
+	seen := make(map[string]bool)
+	add := func(name, version string) {
+		if name == "" || version == "" || seen[name+"@"+version] {
+			return
+		}
+		seen[name+"@"+version] = true
+		packages = append(packages, Package{Name: name, Version: version, Manager: ManagerNpm, Location: location})
+	}
+
+	if len(lockfile.Packages) > 0 {
+		for path, pkg := range lockfile.Packages {
+			// the empty path is the project itself, and links are the packages of a workspace
+			i := strings.LastIndex(path, "node_modules/")
+			if i == -1 || pkg.Link {
+				continue
+			}
+			name := pkg.Name
+			if name == "" {
+				name = path[i+len("node_modules/"):]
+			}
+			add(name, pkg.Version)
+		}
+		return packages, nil
+	}
+
+	var walk func(dependencies map[string]*npmDependency)
+	walk = func(dependencies map[string]*npmDependency) {
+		for name, dep := range dependencies {
+			add(name, dep.Version)
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lockfile.Dependencies)
+
+	return packages, nil
+}
+
+// parseYarnLockfile returns the packages of a yarn lockfile, made of entries whose header lists the version ranges
+// resolved to the version of the entry
+func parseYarnLockfile(r io.Reader, location string) ([]Package, error) {
+	var packages []Package
+	seen := make(map[string]bool)
+
+	var name string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(strings.TrimSpace(line)) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if line[0] != ' ' {
+			// an entry header such as `"@babel/core@^7.0.0", "@babel/core@^7.1.0":`
+			spec := strings.Trim(strings.SplitN(strings.TrimSuffix(line, ":"), ",", 2)[0], `" `)
+			name = ""
+			if i := strings.LastIndex(spec, "@"); i > 0 {
+				name = spec[:i]
+			}
+			continue
+		}
+
+		// the fields of the entry are indented with two spaces, the dependencies being indented further
+		if name == "" || !strings.HasPrefix(line, "  version") {
+			continue
+		}
+		version := strings.Trim(strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "  version"), ":")), `"`)
+		if version != "" && !seen[name+"@"+version] {
+			seen[name+"@"+version] = true
+			packages = append(packages, Package{Name: name, Version: version, Manager: ManagerNpm, Location: location})
+		}
+		name = ""
+	}
+
+	return packages, scanner.Err()
+}
+
+// parsePipfileLock returns the packages of a pipenv lockfile, whose versions are pinned with `==`
+func parsePipfileLock(r io.Reader, location string) ([]Package, error) {
+	var lockfile map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&lockfile); err != nil {
+		return nil, err
+	}
+
+	var packages []Package
+	for _, section := range []string{"default", "develop"} {
+		raw, ok := lockfile[section]
+		if !ok {
+			continue
+		}
+
+		var dependencies map[string]struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(raw, &dependencies); err != nil {
+			return nil, fmt.Errorf("invalid %s section: %w", section, err)
+		}
+
+		for name, dep := range dependencies {
+			if version := strings.TrimPrefix(dep.Version, "=="); version != "" {
+				packages = append(packages, Package{Name: name, Version: version, Manager: ManagerPypi, Location: location})
+			}
+		}
+	}
+
+	return packages, nil
+}
+
+// parseGemfileLock returns the packages of a bundler lockfile, listed with their version in the `specs` of its source
+// sections, such as `GEM`
+func parseGemfileLock(r io.Reader, location string) ([]Package, error) {
+	var packages []Package
+
+	inSpecs := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case len(line) > 0 && line[0] != ' ':
+			inSpecs = false
+		case line == "  specs:":
+			inSpecs = true
+		case inSpecs && strings.HasPrefix(line, "    ") && !strings.HasPrefix(line, "     "):
+			// `    rack (2.2.4)`, the dependencies of the gem being indented further
+			name, version, found := strings.Cut(strings.TrimSpace(line), " ")
+			if found {
+				packages = append(packages, Package{Name: name, Version: strings.Trim(version, "()"), Manager: ManagerGem, Location: location})
+			}
+		}
+	}
+
+	return packages, scanner.Err()
+}
+
+// parseComposerLockfile returns the packages of a composer lockfile
+func parseComposerLockfile(r io.Reader, location string) ([]Package, error) {
+	type composerPackage struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var lockfile struct {
+		Packages    []composerPackage `json:"packages"`
+		PackagesDev []composerPackage `json:"packages-dev"`
+	}
+	if err := json.NewDecoder(r).Decode(&lockfile); err != nil {
+		return nil, err
+	}
+
+	var packages []Package
+	for _, pkg := range append(lockfile.Packages, lockfile.PackagesDev...) {
+		packages = append(packages, Package{Name: pkg.Name, Version: strings.TrimPrefix(pkg.Version, "v"), Manager: ManagerComposer, Location: location})
+	}
+	return packages, nil
+}
+
+// parseTOMLPackagesLockfile returns a parser of the lockfiles listing their packages as `[[package]]` tables with a
+// name and a version, such as the lockfiles of poetry and cargo. The lockfiles are generated with one key per line,
+// which is all that is parsed.
+func parseTOMLPackagesLockfile(manager string) lockfileParser {
+	return func(r io.Reader, location string) ([]Package, error) {
+		var packages []Package
+
+		var current *Package
+		flush := func() {
+			if current != nil && current.Name != "" && current.Version != "" {
+				packages = append(packages, *current)
+			}
+			current = nil
+		}
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+
+			if strings.HasPrefix(line, "[") {
+				flush()
+				if line == "[[package]]" {
+					current = &Package{Manager: manager, Location: location}
+				}
+				continue
+			}
+			if current == nil {
+				continue
+			}
+
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				continue
+			}
+			unquoted, err := strconv.Unquote(strings.TrimSpace(value))
+			if err != nil {
+				continue
+			}
+
+			switch strings.TrimSpace(key) {
+			case "name":
+				current.Name = unquoted
+			case "version":
+				current.Version = unquoted
+			}
+		}
+		flush()
+
+		return packages, scanner.Err()
+	}
+}
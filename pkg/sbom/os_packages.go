@@ -0,0 +1,219 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+)
+
+const (
+	dpkgStatusPath = "/var/lib/dpkg/status"
+	// dpkgStatusDir holds a status file per package in distroless images
+	dpkgStatusDir = "/var/lib/dpkg/status.d"
+	// ApkInstalledPath is the location of the apk database
+	ApkInstalledPath = "/lib/apk/db/installed"
+
+	// RpmQueryFormat prints the name, epoch:version-release and architecture of the packages, tab separated
+	RpmQueryFormat = `%{NAME}\t%|EPOCH?{%{EPOCH}:}:{}|%{VERSION}-%{RELEASE}\t%{ARCH}\n`
+)
+
+// RpmDBPaths are the locations of the rpm database, the newer distributions storing it in /usr
+var RpmDBPaths = []string{"/var/lib/rpm", "/usr/lib/sysimage/rpm"}
+
+// errNoDatabase is returned when a root filesystem doesn't have the database of a package manager
+var errNoDatabase = errors.New("no package database")
+
+// readDpkgDatabase returns the packages of the dpkg status file, or of the status files of distroless images
+func readDpkgDatabase(_ context.Context, root *os.File) ([]Package, error) {
+	paths := []string{dpkgStatusPath}
+	if dir, err := filesystem.OpenInRoot(root, dpkgStatusDir); err == nil {
+		entries, _ := dir.ReadDir(-1)
+		dir.Close()
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(dpkgStatusDir, entry.Name()))
+			}
+		}
+	}
+
+	var packages []Package
+	found := false
+	for _, path := range paths {
+		f, err := filesystem.OpenInRoot(root, path)
+		if err != nil {
+			continue
+		}
+		found = true
+
+		pkgs, err := ParseDpkgStatus(f, path)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		packages = append(packages, pkgs...)
+	}
+
+	if !found {
+		return nil, errNoDatabase
+	}
+	return packages, nil
+}
+
+// ParseDpkgStatus returns the installed packages of a dpkg status file, made of paragraphs of `Field: value` lines
+// separated by blank lines. The location is the path of the file, relative to its root filesystem.
+func ParseDpkgStatus(r io.Reader, location string) ([]Package, error) {
+	var packages []Package
+
+	err := parseParagraphs(r, func(fields map[string]string) {
+		// the last word of the status is the state of the package
+		status := strings.Fields(fields["Status"])
+		if fields["Package"] == "" || (len(status) > 0 && status[len(status)-1] != "installed") {
+			return
+		}
+		source := fields["Source"]
+		// the source can be followed by its version, when it differs from the one of the package
+		if i := strings.IndexByte(source, ' '); i != -1 {
+			source = source[:i]
+		}
+		packages = append(packages, Package{
+			Name:         fields["Package"],
+			Version:      fields["Version"],
+			Architecture: fields["Architecture"],
+			Manager:      ManagerDpkg,
+			Location:     location,
+			Source:       source,
+		})
+	})
+	return packages, err
+}
+
+// readApkDatabase returns the packages of the apk database of Alpine Linux
+func readApkDatabase(_ context.Context, root *os.File) ([]Package, error) {
+	f, err := filesystem.OpenInRoot(root, ApkInstalledPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errNoDatabase
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseApkInstalled(f, ApkInstalledPath)
+}
+
+// ParseApkInstalled returns the packages of an apk database, made of paragraphs of `K:value` lines, the key being a
+// single letter. The location is the path of the database, relative to its root filesystem.
+func ParseApkInstalled(r io.Reader, location string) ([]Package, error) {
+	var packages []Package
+
+	err := parseParagraphs(r, func(fields map[string]string) {
+		if fields["P"] == "" {
+			return
+		}
+		packages = append(packages, Package{
+			Name:         fields["P"],
+			Version:      fields["V"],
+			Architecture: fields["A"],
+			Manager:      ManagerApk,
+			Location:     location,
+		})
+	})
+	return packages, err
+}
+
+// parseParagraphs calls a function with the fields of each paragraph of a file made of `key:value` lines
+// separated by blank lines. The continuation lines, starting with a space, are ignored.
+func parseParagraphs(r io.Reader, fn func(fields map[string]string)) error {
+	fields := make(map[string]string)
+	flush := func() {
+		if len(fields) > 0 {
+			fn(fields)
+			fields = make(map[string]string)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case line[0] == ' ' || line[0] == '\t':
+			// continuation of a multiline field, such as the description
+		default:
+			if i := strings.IndexByte(line, ':'); i != -1 {
+				fields[line[:i]] = strings.TrimSpace(line[i+1:])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	flush()
+
+	return nil
+}
+
+// readRpmDatabase lists the packages of the rpm database with the rpm command, as the database format depends on the
+// version of rpm. The path of the database given to rpm is resolved inside the root filesystem, so that a symlink
+// can't make it read a database of the host.
+func readRpmDatabase(ctx context.Context, root *os.File) ([]Package, error) {
+	for _, dbPath := range RpmDBPaths {
+		resolved, err := securejoin.SecureJoin(root.Name(), dbPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(resolved); err != nil {
+			continue
+		}
+
+		rpm, err := exec.LookPath("rpm")
+		if err != nil {
+			return nil, fmt.Errorf("an rpm database was found but the rpm command isn't available: %w", err)
+		}
+
+		output, err := exec.CommandContext(ctx, rpm, "--dbpath", resolved, "-qa", "--queryformat", RpmQueryFormat).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the rpm database: %w", err)
+		}
+		return ParseRpmQuery(string(output), dbPath), nil
+	}
+	return nil, errNoDatabase
+}
+
+// ParseRpmQuery returns the packages of the output of a rpm query with RpmQueryFormat. The location is the path of the
+// database, relative to its root filesystem.
+func ParseRpmQuery(output string, location string) []Package {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(line, "\t")
+		// gpg-pubkey entries are the keys imported in the database, not packages
+		if len(parts) != 3 || parts[0] == "" || parts[0] == "gpg-pubkey" {
+			continue
+		}
+		packages = append(packages, Package{
+			Name:         parts[0],
+			Version:      parts[1],
+			Architecture: parts[2],
+			Manager:      ManagerRpm,
+			Location:     location,
+		})
+	}
+	return packages
+}
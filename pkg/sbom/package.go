@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package sbom inventories the packages installed in a root filesystem, the one of the host or of a container, and
+// encodes the inventory as a CycloneDX software bill of materials.
+package sbom
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Package managers, which are also the purl types of their packages
+const (
+	ManagerDpkg     = "deb"
+	ManagerRpm      = "rpm"
+	ManagerApk      = "apk"
+	ManagerNpm      = "npm"
+	ManagerPypi     = "pypi"
+	ManagerGem      = "gem"
+	ManagerComposer = "composer"
+	ManagerCargo    = "cargo"
+)
+
+// Package is a package installed in a root filesystem
+type Package struct {
+	Name         string
+	Version      string
+	Architecture string
+	Manager      string
+	// Location is the path of the database or lockfile the package was read from, relative to the root filesystem
+	Location string
+	// Source is the name of the source package the package was built from, when the package manager reports it
+	Source string
+}
+
+// OSRelease is the operating system of a root filesystem, as described by its os-release file
+type OSRelease struct {
+	ID         string
+	VersionID  string
+	PrettyName string
+}
+
+// Inventory is the list of the packages of a root filesystem
+type Inventory struct {
+	OS       OSRelease
+	Packages []Package
+}
+
+// ScanOptions defines how a root filesystem is scanned
+type ScanOptions struct {
+	// LockfileDirs are the directories of the root filesystem searched for language lockfiles
+	LockfileDirs []string
+	// LockfileMaxDepth is the maximum depth of the lockfiles in the lockfile directories, 0 meaning no limit
+	LockfileMaxDepth int
+	// MaxFileSize is the maximum size of the lockfiles read
+	MaxFileSize int64
+}
+
+// packageDatabase reads the packages of a package manager from a root filesystem, whose paths must be resolved inside
+// it with filesystem.OpenInRoot
+type packageDatabase func(ctx context.Context, root *os.File) ([]Package, error)
+
+var packageDatabases = map[string]packageDatabase{
+	ManagerDpkg: readDpkgDatabase,
+	ManagerRpm:  readRpmDatabase,
+	ManagerApk:  readApkDatabase,
+}
+
+// Scan returns the inventory of the packages of a root filesystem. The failure to read a package database or a
+// lockfile doesn't prevent the others from being read. The root filesystem may be the one of a container, the
+// symlinks it holds are resolved inside it.
+func Scan(ctx context.Context, root string, opts ScanOptions) (*Inventory, error) {
+	rootDir, err := os.Open(root)
+	if err != nil {
+		return nil, err
+	}
+	defer rootDir.Close()
+
+	inventory := &Inventory{
+		OS: readOSRelease(rootDir),
+	}
+
+	for manager, database := range packageDatabases {
+		packages, err := database(ctx, rootDir)
+		if errors.Is(err, errNoDatabase) {
+			continue
+		} else if err != nil {
+			log.Debugf("failed to read the %s packages of %s: %v", manager, root, err)
+			continue
+		}
+		inventory.Packages = append(inventory.Packages, packages...)
+	}
+
+	inventory.Packages = append(inventory.Packages, scanLockfiles(root, opts)...)
+
+	sort.Slice(inventory.Packages, func(i, j int) bool {
+		a, b := inventory.Packages[i], inventory.Packages[j]
+		if a.Manager != b.Manager {
+			return a.Manager < b.Manager
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Location < b.Location
+	})
+
+	return inventory, nil
+}
+
+// readOSRelease returns the operating system of a root filesystem, which is unknown for the images built from scratch
+func readOSRelease(root *os.File) OSRelease {
+	var release OSRelease
+
+	for _, path := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		f, err := filesystem.OpenInRoot(root, path)
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+
+		for _, line := range strings.Split(string(content), "\n") {
+			key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+			if !found {
+				continue
+			}
+			value = strings.Trim(value, `"'`)
+
+			switch key {
+			case "ID":
+				release.ID = value
+			case "VERSION_ID":
+				release.VersionID = value
+			case "PRETTY_NAME":
+				release.PrettyName = value
+			}
+		}
+		break
+	}
+
+	return release
+}
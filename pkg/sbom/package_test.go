@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sbom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanDebian(t *testing.T) {
+	inventory, err := Scan(context.Background(), filepath.Join("testdata", "debian"), ScanOptions{
+		LockfileDirs:     []string{"/app"},
+		LockfileMaxDepth: 4,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, OSRelease{ID: "debian", VersionID: "11", PrettyName: "Debian GNU/Linux 11 (bullseye)"}, inventory.OS)
+	assert.Equal(t, []Package{
+		{Name: "base-files", Version: "11.1+deb11u5", Architecture: "amd64", Manager: ManagerDpkg, Location: "/var/lib/dpkg/status.d/base-files"},
+		{Name: "libc6", Version: "2.31-13+deb11u5", Architecture: "amd64", Manager: ManagerDpkg, Location: "/var/lib/dpkg/status"},
+		{Name: "tzdata", Version: "2021a-1+deb11u8", Architecture: "all", Manager: ManagerDpkg, Location: "/var/lib/dpkg/status"},
+		{Name: "@babel/core", Version: "7.19.3", Manager: ManagerNpm, Location: "/app/web/package-lock.json"},
+		{Name: "@babel/core", Version: "7.19.3", Manager: ManagerNpm, Location: "/app/web/yarn.lock"},
+		{Name: "lodash", Version: "4.17.21", Manager: ManagerNpm, Location: "/app/web/package-lock.json"},
+		{Name: "lodash", Version: "4.17.21", Manager: ManagerNpm, Location: "/app/web/yarn.lock"},
+		{Name: "semver", Version: "6.3.0", Manager: ManagerNpm, Location: "/app/web/package-lock.json"},
+		{Name: "Flask", Version: "2.2.2", Manager: ManagerPypi, Location: "/app/api/Pipfile.lock"},
+		{Name: "flask", Version: "2.2.2", Manager: ManagerPypi, Location: "/app/api/poetry.lock"},
+		{Name: "pytest", Version: "7.2.0", Manager: ManagerPypi, Location: "/app/api/Pipfile.lock"},
+		{Name: "typing_extensions", Version: "4.4.0", Manager: ManagerPypi, Location: "/app/api/Pipfile.lock"},
+		{Name: "werkzeug", Version: "2.2.2", Manager: ManagerPypi, Location: "/app/api/poetry.lock"},
+	}, inventory.Packages)
+}
+
+func TestScanAlpine(t *testing.T) {
+	inventory, err := Scan(context.Background(), filepath.Join("testdata", "alpine"), ScanOptions{
+		LockfileDirs: []string{"/srv", "/missing"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, OSRelease{ID: "alpine", VersionID: "3.16.2", PrettyName: "Alpine Linux v3.16"}, inventory.OS)
+	assert.Equal(t, []Package{
+		{Name: "busybox", Version: "1.35.0-r17", Architecture: "x86_64", Manager: ManagerApk, Location: "/lib/apk/db/installed"},
+		{Name: "musl", Version: "1.2.3-r0", Architecture: "x86_64", Manager: ManagerApk, Location: "/lib/apk/db/installed"},
+		{Name: "serde", Version: "1.0.147", Manager: ManagerCargo, Location: "/srv/shop/Cargo.lock"},
+		{Name: "shop", Version: "0.1.0", Manager: ManagerCargo, Location: "/srv/shop/Cargo.lock"},
+		{Name: "monolog/monolog", Version: "3.2.0", Manager: ManagerComposer, Location: "/srv/shop/composer.lock"},
+		{Name: "phpunit/phpunit", Version: "9.5.26", Manager: ManagerComposer, Location: "/srv/shop/composer.lock"},
+		{Name: "rack", Version: "2.2.4", Manager: ManagerGem, Location: "/srv/shop/Gemfile.lock"},
+		{Name: "sinatra", Version: "3.0.2", Manager: ManagerGem, Location: "/srv/shop/Gemfile.lock"},
+	}, inventory.Packages)
+}
+
+func TestScanSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on Windows")
+	}
+
+	// the files outside of the root filesystem must not be read, even if the symlinks of the root filesystem
+	// point to them
+	outside, err := filepath.Abs(filepath.Join("testdata", "alpine"))
+	require.NoError(t, err)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "lib", "apk"), 0755))
+	require.NoError(t, os.Symlink(outside+"/etc", filepath.Join(root, "etc")))
+	require.NoError(t, os.Symlink("../../../../../../../../.."+outside+"/lib/apk/db", filepath.Join(root, "lib", "apk", "db")))
+	require.NoError(t, os.Symlink(outside+"/srv", filepath.Join(root, "srv")))
+
+	inventory, err := Scan(context.Background(), root, ScanOptions{LockfileDirs: []string{"/srv"}})
+	require.NoError(t, err)
+	assert.Equal(t, OSRelease{}, inventory.OS)
+	assert.Empty(t, inventory.Packages)
+}
+
+func TestScanLockfileLimits(t *testing.T) {
+	root := filepath.Join("testdata", "debian")
+
+	// the lockfiles are two levels below /app
+	packages := scanLockfiles(root, ScanOptions{LockfileDirs: []string{"/app"}, LockfileMaxDepth: 1})
+	assert.Empty(t, packages)
+
+	packages = scanLockfiles(root, ScanOptions{LockfileDirs: []string{"/app/web"}, MaxFileSize: 400})
+	require.Len(t, packages, 2)
+	for _, pkg := range packages {
+		assert.Equal(t, "/app/web/yarn.lock", pkg.Location)
+	}
+}
+
+func TestScanMissingRoot(t *testing.T) {
+	_, err := Scan(context.Background(), filepath.Join("testdata", "missing"), ScanOptions{})
+	assert.Error(t, err)
+}
+
+func TestParseNpmLockfileV1(t *testing.T) {
+	packages, err := parseNpmLockfile(strings.NewReader(`{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "express": {
+      "version": "4.18.2",
+      "dependencies": {
+        "debug": {"version": "2.6.9"}
+      }
+    },
+    "debug": {"version": "2.6.9"}
+  }
+}`), "/app/package-lock.json")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []Package{
+		{Name: "express", Version: "4.18.2", Manager: ManagerNpm, Location: "/app/package-lock.json"},
+		{Name: "debug", Version: "2.6.9", Manager: ManagerNpm, Location: "/app/package-lock.json"},
+	}, packages)
+}
+
+func TestParseYarnBerryLockfile(t *testing.T) {
+	packages, err := parseYarnLockfile(strings.NewReader(`__metadata:
+  version: 6
+  cacheKey: 8
+
+"@types/node@npm:*, @types/node@npm:^18.0.0":
+  version: 18.11.9
+  resolution: "@types/node@npm:18.11.9"
+  checksum: cc5b8c8d
+  languageName: node
+  linkType: hard
+`), "/app/yarn.lock")
+	require.NoError(t, err)
+
+	// the metadata entry has no name
+	assert.Equal(t, []Package{
+		{Name: "@types/node", Version: "18.11.9", Manager: ManagerNpm, Location: "/app/yarn.lock"},
+	}, packages)
+}
+
+func TestParseRpmQuery(t *testing.T) {
+	packages := ParseRpmQuery("bash\t5.1.8-4.el9\tx86_64\ngpg-pubkey\t8483c65d-5ccc5b19\t(none)\nopenssl-libs\t1:3.0.1-41.el9\tx86_64\n", "/var/lib/rpm")
+
+	assert.Equal(t, []Package{
+		{Name: "bash", Version: "5.1.8-4.el9", Architecture: "x86_64", Manager: ManagerRpm, Location: "/var/lib/rpm"},
+		{Name: "openssl-libs", Version: "1:3.0.1-41.el9", Architecture: "x86_64", Manager: ManagerRpm, Location: "/var/lib/rpm"},
+	}, packages)
+}
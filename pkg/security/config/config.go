@@ -8,6 +8,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/system-probe/config"
@@ -97,6 +98,9 @@ type Config struct {
 	SelfTestSendReport bool
 	// EnvsWithValue lists environnement variables that will be fully exported
 	EnvsWithValue []string
+	// ResponseActionsEnabled defines if the response actions of the rules are taken. When disabled, they are
+	// only reported as dry runs.
+	ResponseActionsEnabled bool
 
 	// ActivityDumpEnabled defines if the activity dump manager should be enabled
 	ActivityDumpEnabled bool
@@ -146,6 +150,33 @@ type Config struct {
 	// ActivityDumpSyscallMonitorPeriod defines the minimum amount of time to wait between 2 syscalls event for the same
 	// process.
 	ActivityDumpSyscallMonitorPeriod time.Duration
+	// ActivityDumpPolicyPathDepth defines the number of directories kept in the file paths of the policies exported
+	// from activity dumps. Deeper files are matched with a `**` glob. Set this parameter to 0 to keep full paths.
+	ActivityDumpPolicyPathDepth int
+	// ActivityDumpDriftBaselineDirectory defines the directory of the baseline dumps the running activity dumps are
+	// compared to, by image name. Leave this parameter empty to disable drift detection.
+	ActivityDumpDriftBaselineDirectory string
+
+	// FIMBaselineEnabled defines if the file integrity monitoring baseline mode should be enabled. The files matching
+	// FIMBaselinePaths are hashed on first sight per container image, and a file_changed event is sent when a write
+	// changes their content.
+	FIMBaselineEnabled bool
+	// FIMBaselinePaths defines the glob patterns of the files monitored by the file integrity monitoring baseline
+	FIMBaselinePaths []string
+	// FIMBaselineHashAlgorithm defines the algorithm used to hash the monitored files: md5, sha1, sha256 or sha512
+	FIMBaselineHashAlgorithm string
+	// FIMBaselineMaxBaselines defines the maximum number of file baselines kept in memory. When the limit is reached,
+	// the least recently used baselines are evicted first.
+	FIMBaselineMaxBaselines int
+	// FIMBaselineMaxFileSize defines the maximum size, in bytes, of the files hashed by the file integrity monitoring
+	// baseline
+	FIMBaselineMaxFileSize int64
+	// FIMBaselineFile defines the file the baselines are persisted to across restarts. Leave this parameter empty to
+	// keep the baselines in memory only.
+	FIMBaselineFile string
+	// FIMBaselineCheckPeriod defines the period at which the files written to are checked, a file being hashed again
+	// once its writer closed it
+	FIMBaselineCheckPeriod time.Duration
 
 	// RuntimeMonitor defines if the runtime monitor should be enabled
 	RuntimeMonitor bool
@@ -228,6 +259,7 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		EventStreamUseRingBuffer:           coreconfig.Datadog.GetBool("runtime_security_config.event_stream.use_ring_buffer"),
 		EventStreamBufferSize:              coreconfig.Datadog.GetInt("runtime_security_config.event_stream.buffer_size"),
 		EnvsWithValue:                      coreconfig.Datadog.GetStringSlice("runtime_security_config.envs_with_value"),
+		ResponseActionsEnabled:             coreconfig.Datadog.GetBool("runtime_security_config.response_actions.enabled"),
 
 		// runtime compilation
 		RuntimeCompilationEnabled:       coreconfig.Datadog.GetBool("runtime_security_config.runtime_compilation.enabled"),
@@ -252,6 +284,17 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		ActivityDumpRemoteStorageCompression:  coreconfig.Datadog.GetBool("runtime_security_config.activity_dump.remote_storage.compression"),
 		ActivityDumpSyscallMonitor:            coreconfig.Datadog.GetBool("runtime_security_config.activity_dump.syscall_monitor.enabled"),
 		ActivityDumpSyscallMonitorPeriod:      time.Duration(coreconfig.Datadog.GetInt("runtime_security_config.activity_dump.syscall_monitor.period")) * time.Second,
+		ActivityDumpPolicyPathDepth:           coreconfig.Datadog.GetInt("runtime_security_config.activity_dump.policy_export.path_depth"),
+		ActivityDumpDriftBaselineDirectory:    coreconfig.Datadog.GetString("runtime_security_config.activity_dump.drift.baseline_directory"),
+
+		// file integrity monitoring baseline
+		FIMBaselineEnabled:       coreconfig.Datadog.GetBool("runtime_security_config.fim_baseline.enabled"),
+		FIMBaselinePaths:         coreconfig.Datadog.GetStringSlice("runtime_security_config.fim_baseline.paths"),
+		FIMBaselineHashAlgorithm: strings.ToLower(coreconfig.Datadog.GetString("runtime_security_config.fim_baseline.hash_algorithm")),
+		FIMBaselineMaxBaselines:  coreconfig.Datadog.GetInt("runtime_security_config.fim_baseline.max_baselines"),
+		FIMBaselineMaxFileSize:   coreconfig.Datadog.GetInt64("runtime_security_config.fim_baseline.max_file_size"),
+		FIMBaselineFile:          coreconfig.Datadog.GetString("runtime_security_config.fim_baseline.baseline_file"),
+		FIMBaselineCheckPeriod:   time.Duration(coreconfig.Datadog.GetInt("runtime_security_config.fim_baseline.check_period")) * time.Second,
 	}
 
 	// if runtime is enabled then we force fim
@@ -165,6 +165,19 @@ var (
 	// Tags: format, compression
 	MetricActivityDumpEntityTooLarge = newAgentMetric(".activity_dump.entity_too_large")
 
+	// File integrity monitoring baseline metrics
+
+	// MetricFIMBaselineCount is the name of the metric used to report the number of file baselines held in memory
+	// Tags: -
+	MetricFIMBaselineCount = newRuntimeMetric(".fim_baseline.baselines")
+	// MetricFIMBaselineDroppedRequests is the name of the metric used to count the number of file hash requests that
+	// were dropped because the hash queue was full
+	// Tags: -
+	MetricFIMBaselineDroppedRequests = newRuntimeMetric(".fim_baseline.dropped_requests")
+	// MetricFIMBaselineFileChanged is the name of the metric used to count the number of file changes detected
+	// Tags: -
+	MetricFIMBaselineFileChanged = newRuntimeMetric(".fim_baseline.file_changed")
+
 	// Namespace resolver metrics
 
 	// MetricNamespaceResolverNetNSHandle is the name of the metric used to report the count of netns handles
@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package module
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/gopsutil/process"
+	"golang.org/x/sys/unix"
+	"golang.org/x/time/rate"
+
+	seclog "github.com/DataDog/datadog-agent/pkg/security/log"
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+const (
+	// defaultActionRateLimit is the number of times per minute a response action is taken when its rule doesn't
+	// specify a limit
+	defaultActionRateLimit = 10
+	// processStartTimeTolerance is the maximum difference between the start time of a running process and the fork
+	// time of the event process for them to be considered the same process. procfs start times are relative to a
+	// boot time with a one second granularity.
+	processStartTimeTolerance = 2 * time.Second
+
+	actionKill         = "kill"
+	actionQuarantine   = "quarantine"
+	actionActivityDump = "activity_dump"
+
+	// actionStatusPerformed means the action was taken
+	actionStatusPerformed = "performed"
+	// actionStatusDryRun means the action was not taken because of a dry run
+	actionStatusDryRun = "dry_run"
+	// actionStatusRateLimited means the action was not taken because of its rate limit
+	actionStatusRateLimited = "rate_limited"
+	// actionStatusFailed means taking the action failed
+	actionStatusFailed = "failed"
+)
+
+var errNoContainer = errors.New("the event has no container")
+
+type actionKey struct {
+	ruleID rules.RuleID
+	index  int
+}
+
+// ActionExecutor takes the response actions of the rules that matched an event, and reports each of them with
+// a rule_action event. When response actions are disabled in the configuration, they are all run as dry runs.
+type ActionExecutor struct {
+	sync.RWMutex
+	enabled  bool
+	limiters map[actionKey]*rate.Limiter
+
+	dumpContainer func(containerID string, timeout time.Duration) error
+	sendEvent     func(rule *rules.Rule, event *sprobe.CustomEvent, extTagsCb func() []string)
+}
+
+// NewActionExecutor returns a new action executor
+func NewActionExecutor(enabled bool, probe *sprobe.Probe, sendEvent func(rule *rules.Rule, event *sprobe.CustomEvent, extTagsCb func() []string)) *ActionExecutor {
+	return &ActionExecutor{
+		enabled:  enabled,
+		limiters: make(map[actionKey]*rate.Limiter),
+		dumpContainer: func(containerID string, timeout time.Duration) error {
+			monitor := probe.GetMonitor()
+			if monitor == nil {
+				return errors.New("monitor not configured")
+			}
+			return monitor.DumpContainerActivity(containerID, timeout)
+		},
+		sendEvent: sendEvent,
+	}
+}
+
+// Apply sets up the rate limiters of the response actions of a rule set
+func (e *ActionExecutor) Apply(rs *rules.RuleSet) {
+	e.Lock()
+	defer e.Unlock()
+
+	limiters := make(map[actionKey]*rate.Limiter)
+	for id, rule := range rs.GetRules() {
+		for i, action := range rule.Definition.Actions {
+			if !action.IsResponse() {
+				continue
+			}
+
+			limit := action.RateLimit
+			if limit == 0 {
+				limit = defaultActionRateLimit
+			}
+			limiters[actionKey{ruleID: id, index: i}] = rate.NewLimiter(rate.Every(time.Minute/time.Duration(limit)), limit)
+		}
+	}
+	e.limiters = limiters
+}
+
+func (e *ActionExecutor) allow(ruleID rules.RuleID, index int) bool {
+	e.RLock()
+	defer e.RUnlock()
+
+	limiter, found := e.limiters[actionKey{ruleID: ruleID, index: index}]
+	return found && limiter.Allow()
+}
+
+// Execute takes the response actions of a rule that matched an event
+func (e *ActionExecutor) Execute(rule *rules.Rule, event *sprobe.Event, extTagsCb func() []string) {
+	for i, action := range rule.Definition.Actions {
+		if !action.IsResponse() {
+			continue
+		}
+
+		report := sprobe.RuleActionEvent{
+			RuleID:      rule.ID,
+			DryRun:      action.DryRun || !e.enabled,
+			ContainerID: event.ContainerContext.ID,
+		}
+		var forkTime time.Time
+		if event.ProcessContext != nil {
+			report.Pid = event.ProcessContext.Pid
+			forkTime = event.ProcessContext.ForkTime
+		}
+
+		var perform func() error
+		switch {
+		case action.Kill != nil:
+			report.Action = actionKill
+			report.Signal = action.Kill.GetSignal()
+			perform = func() error {
+				return killProcess(report.Pid, forkTime, report.Signal)
+			}
+		case action.Quarantine != nil:
+			report.Action = actionQuarantine
+			perform = func() error {
+				if report.ContainerID == "" {
+					return errNoContainer
+				}
+				quarantineRule, quarantineEvent := sprobe.NewContainerQuarantineEvent(event, rule.ID, action.Quarantine.Reason)
+				e.sendEvent(quarantineRule, quarantineEvent, extTagsCb)
+				return nil
+			}
+		case action.ActivityDump != nil:
+			report.Action = actionActivityDump
+			perform = func() error {
+				if report.ContainerID == "" {
+					return errNoContainer
+				}
+				return e.dumpContainer(report.ContainerID, action.ActivityDump.GetTimeout())
+			}
+		}
+
+		switch {
+		case !e.allow(rule.ID, i):
+			report.Status = actionStatusRateLimited
+		case report.DryRun:
+			report.Status = actionStatusDryRun
+		default:
+			if err := perform(); err != nil {
+				report.Status = actionStatusFailed
+				report.Error = err.Error()
+			} else {
+				report.Status = actionStatusPerformed
+			}
+		}
+		seclog.Debugf("%s action of rule `%s` %s", report.Action, rule.ID, report.Status)
+
+		actionRule, actionEvent := sprobe.NewRuleActionEvent(event, report)
+		e.sendEvent(actionRule, actionEvent, extTagsCb)
+	}
+}
+
+// killProcess signals the process having the given pid, as long as it is still the process that was forked at
+// forkTime. The process is signaled through a pidfd when the kernel supports it, so that the pid can't be reused
+// between the check and the signal.
+func killProcess(pid uint32, forkTime time.Time, signal string) error {
+	if pid <= 1 || int(pid) == os.Getpid() {
+		return fmt.Errorf("refusing to kill pid %d", pid)
+	}
+
+	sig := unix.SignalNum(signal)
+	if sig == 0 {
+		return fmt.Errorf("unknown signal %s", signal)
+	}
+
+	pidfd, err := unix.PidfdOpen(int(pid), 0)
+	hasPidfd := err == nil
+	if hasPidfd {
+		defer unix.Close(pidfd)
+	} else if !errors.Is(err, unix.ENOSYS) {
+		return fmt.Errorf("couldn't open pid %d: %w", pid, err)
+	}
+
+	if err := checkProcessStartTime(pid, forkTime); err != nil {
+		return err
+	}
+
+	if hasPidfd {
+		return unix.PidfdSendSignal(pidfd, sig, nil, 0)
+	}
+	return syscall.Kill(int(pid), sig)
+}
+
+// checkProcessStartTime returns an error if the process having the given pid wasn't forked at forkTime, meaning
+// that the event process exited and its pid was reused
+func checkProcessStartTime(pid uint32, forkTime time.Time) error {
+	if forkTime.IsZero() {
+		return fmt.Errorf("unknown start time of pid %d", pid)
+	}
+
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return fmt.Errorf("couldn't find pid %d: %w", pid, err)
+	}
+	createTime, err := proc.CreateTime()
+	if err != nil {
+		return fmt.Errorf("couldn't get the start time of pid %d: %w", pid, err)
+	}
+
+	startTime := time.Unix(0, createTime*int64(time.Millisecond))
+	if diff := startTime.Sub(forkTime); diff > processStartTimeTolerance || diff < -processStartTimeTolerance {
+		return fmt.Errorf("pid %d was reused: process started at %s instead of %s", pid, startTime, forkTime)
+	}
+	return nil
+}
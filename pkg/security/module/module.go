@@ -73,6 +73,7 @@ type Module struct {
 	policyOpts       rules.PolicyLoaderOpts
 	selfTester       *selftests.SelfTester
 	policyMonitor    *PolicyMonitor
+	actionExecutor   *ActionExecutor
 }
 
 // Register the runtime security agent module
@@ -196,6 +197,11 @@ func (m *Module) Start() error {
 		}
 	}
 
+	// file integrity monitoring baseline rules
+	if fimBaselineMonitor := m.probe.GetMonitor().GetFIMBaselineMonitor(); fimBaselineMonitor != nil {
+		policyProviders = append(policyProviders, fimBaselineMonitor)
+	}
+
 	if err := m.LoadPolicies(policyProviders, true); err != nil {
 		seclog.Errorf("failed to load policies: %s", err)
 	}
@@ -396,6 +402,7 @@ func (m *Module) LoadPolicies(policyProviders []rules.PolicyProvider, sendLoaded
 
 	m.apiServer.Apply(ruleIDs)
 	m.rateLimiter.Apply(ruleIDs)
+	m.actionExecutor.Apply(ruleSet)
 
 	m.displayReport(report)
 
@@ -469,6 +476,11 @@ func (m *Module) HandleCustomEvent(rule *rules.Rule, event *sprobe.CustomEvent)
 
 // RuleMatch is called by the ruleset when a rule matches
 func (m *Module) RuleMatch(rule *rules.Rule, event eval.Event) {
+	// the rules of the file integrity monitoring baseline only feed the baseline
+	if rule.Definition.Policy.Source == sprobe.FIMBaselinePolicySource {
+		return
+	}
+
 	// prepare the event
 	m.probe.OnRuleMatch(rule, event.(*sprobe.Event))
 
@@ -496,6 +508,7 @@ func (m *Module) RuleMatch(rule *rules.Rule, event eval.Event) {
 	// send if not selftest related events
 	if m.selfTester == nil || !m.selfTester.IsExpectedEvent(rule, event) {
 		m.SendEvent(rule, event, extTagsCb, service)
+		m.actionExecutor.Execute(rule, event.(*sprobe.Event), extTagsCb)
 	}
 }
 
@@ -645,6 +658,11 @@ func NewModule(cfg *sconfig.Config, opts ...Opts) (module.Module, error) {
 		policyMonitor:  NewPolicyMonitor(statsdClient),
 	}
 	m.apiServer.module = m
+	// response actions are rate limited by the executor itself, their rule_action and container_quarantine reports
+	// bypass the per rule rate limiter so that none of them is dropped
+	m.actionExecutor = NewActionExecutor(cfg.ResponseActionsEnabled, probe, func(rule *rules.Rule, event *sprobe.CustomEvent, extTagsCb func() []string) {
+		m.apiServer.SendEvent(rule, event, extTagsCb, "")
+	})
 
 	seclog.SetPatterns(cfg.LogPatterns...)
 	seclog.SetTags(cfg.LogTags...)
@@ -98,6 +98,8 @@ type ActivityDump struct {
 
 	shouldMergePaths bool
 	pathMergedCount  *atomic.Uint64
+	policyPathDepth  int
+	reportedDrift    map[ActivityDumpDiffEntry]bool
 	nodeStats        ActivityDumpNodeStats
 
 	// standard attributes used by the intake
@@ -116,16 +118,29 @@ type ActivityDump struct {
 }
 
 // NewEmptyActivityDump returns a new zero-like instance of an ActivityDump
-func NewEmptyActivityDump() *ActivityDump {
-	return &ActivityDump{
-		Mutex: &sync.Mutex{},
+func NewEmptyActivityDump(options ...WithDumpOption) *ActivityDump {
+	ad := &ActivityDump{
+		Mutex:           &sync.Mutex{},
+		policyPathDepth: DefaultPolicyPathDepth,
+	}
+
+	for _, option := range options {
+		option(ad)
 	}
+	return ad
 }
 
 // WithDumpOption can be used to configure an ActivityDump
 //msgp:ignore WithDumpOption
 type WithDumpOption func(ad *ActivityDump)
 
+// WithPolicyPathDepth sets the number of directories kept in the file paths of the policies exported from the dump
+func WithPolicyPathDepth(depth int) WithDumpOption {
+	return func(ad *ActivityDump) {
+		ad.policyPathDepth = depth
+	}
+}
+
 // NewActivityDump returns a new instance of an ActivityDump
 func NewActivityDump(adm *ActivityDumpManager, options ...WithDumpOption) *ActivityDump {
 	ad := ActivityDump{
@@ -149,6 +164,7 @@ func NewActivityDump(adm *ActivityDumpManager, options ...WithDumpOption) *Activ
 		addedSnapshotCount: make(map[model.EventType]*atomic.Uint64),
 		shouldMergePaths:   adm.probe.config.ActivityDumpPathMergeEnabled,
 		pathMergedCount:    atomic.NewUint64(0),
+		policyPathDepth:    adm.probe.config.ActivityDumpPolicyPathDepth,
 		StorageRequests:    make(map[dump.StorageFormat][]dump.StorageRequest),
 	}
 
@@ -188,6 +204,7 @@ func NewActivityDumpFromMessage(msg *api.ActivityDumpMessage) (*ActivityDump, er
 		addedRuntimeCount:  make(map[model.EventType]*atomic.Uint64),
 		addedSnapshotCount: make(map[model.EventType]*atomic.Uint64),
 		StorageRequests:    make(map[dump.StorageFormat][]dump.StorageRequest),
+		policyPathDepth:    DefaultPolicyPathDepth,
 		Host:               msg.GetHost(),
 		Service:            msg.GetService(),
 		Source:             msg.GetSource(),
@@ -697,6 +714,10 @@ func (ad *ActivityDump) Encode(format dump.StorageFormat) (*bytes.Buffer, error)
 		return ad.EncodeDOT()
 	case dump.Profile:
 		return ad.EncodeProfile()
+	case dump.Policy:
+		return ad.EncodePolicy()
+	case dump.Seccomp:
+		return ad.EncodeSeccomp()
 	default:
 		return nil, fmt.Errorf("couldn't encode activity dump [%s] as [%s]: unknown format", ad.GetSelectorStr(), format)
 	}
@@ -798,6 +819,8 @@ func (ad *ActivityDump) DecodeFromReader(reader io.Reader, format dump.StorageFo
 	switch format {
 	case dump.PROTOBUF:
 		return ad.DecodeProtobuf(reader)
+	case dump.JSON:
+		return ad.DecodeJSON(reader)
 	default:
 		return fmt.Errorf("unsupported input format: %s", format)
 	}
@@ -823,6 +846,26 @@ func (ad *ActivityDump) DecodeProtobuf(reader io.Reader) error {
 	return nil
 }
 
+// DecodeJSON decodes an activity dump as JSON
+func (ad *ActivityDump) DecodeJSON(reader io.Reader) error {
+	ad.Lock()
+	defer ad.Unlock()
+
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("couldn't open activity dump file: %w", err)
+	}
+
+	inter := &adproto.ActivityDump{}
+	if err := protojson.Unmarshal(raw, inter); err != nil {
+		return fmt.Errorf("couldn't decode json activity dump file: %w", err)
+	}
+
+	protoToActivityDump(ad, inter)
+
+	return nil
+}
+
 // ProcessActivityNode holds the activity of a process
 type ProcessActivityNode struct {
 	Process        model.Process
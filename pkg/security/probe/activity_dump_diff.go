@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// lineageSeparator separates the executables of a process lineage, from the root of the tree to the process
+const lineageSeparator = " > "
+
+// ActivityDumpDiffEntry describes an activity of a process lineage. Processes are identified by the path of their
+// executable and the ones of their ancestors, so that PIDs and timestamps don't matter when comparing dumps.
+type ActivityDumpDiffEntry struct {
+	Lineage string `json:"lineage"`
+	Value   string `json:"value,omitempty"`
+}
+
+func (e ActivityDumpDiffEntry) String() string {
+	if len(e.Value) == 0 {
+		return e.Lineage
+	}
+	return e.Lineage + ": " + e.Value
+}
+
+// ActivityDumpActivities lists the activities of a dump, by kind
+type ActivityDumpActivities struct {
+	Processes    []ActivityDumpDiffEntry `json:"processes,omitempty"`
+	Files        []ActivityDumpDiffEntry `json:"files,omitempty"`
+	NetworkFlows []ActivityDumpDiffEntry `json:"network_flows,omitempty"`
+	DNSNames     []ActivityDumpDiffEntry `json:"dns_names,omitempty"`
+}
+
+// Len returns the number of activities
+func (a *ActivityDumpActivities) Len() int {
+	return len(a.Processes) + len(a.Files) + len(a.NetworkFlows) + len(a.DNSNames)
+}
+
+// ActivityDumpDiff holds the activities found in only one of two activity dumps
+type ActivityDumpDiff struct {
+	Added   ActivityDumpActivities `json:"added"`
+	Removed ActivityDumpActivities `json:"removed"`
+}
+
+// IsEmpty returns true if both dumps hold the same activities
+func (d *ActivityDumpDiff) IsEmpty() bool {
+	return d.Added.Len() == 0 && d.Removed.Len() == 0
+}
+
+// activitySet holds the activities of a dump, by kind
+type activitySet struct {
+	processes    map[ActivityDumpDiffEntry]bool
+	files        map[ActivityDumpDiffEntry]bool
+	networkFlows map[ActivityDumpDiffEntry]bool
+	dnsNames     map[ActivityDumpDiffEntry]bool
+}
+
+func newActivitySet() *activitySet {
+	return &activitySet{
+		processes:    make(map[ActivityDumpDiffEntry]bool),
+		files:        make(map[ActivityDumpDiffEntry]bool),
+		networkFlows: make(map[ActivityDumpDiffEntry]bool),
+		dnsNames:     make(map[ActivityDumpDiffEntry]bool),
+	}
+}
+
+func (pan *ProcessActivityNode) getLineageName() string {
+	if len(pan.Process.FileEvent.PathnameStr) > 0 {
+		return pan.Process.FileEvent.PathnameStr
+	}
+	return pan.Process.Comm
+}
+
+func (pan *ProcessActivityNode) collectActivities(parentLineage string, set *activitySet) {
+	lineage := pan.getLineageName()
+	if len(parentLineage) > 0 {
+		lineage = parentLineage + lineageSeparator + lineage
+	}
+	set.processes[ActivityDumpDiffEntry{Lineage: lineage}] = true
+
+	files := make(map[string]bool)
+	for _, file := range pan.Files {
+		file.collectPaths("", 0, files)
+	}
+	for file := range files {
+		set.files[ActivityDumpDiffEntry{Lineage: lineage, Value: file}] = true
+	}
+
+	for _, sock := range pan.Sockets {
+		if len(sock.Bind) == 0 {
+			set.networkFlows[ActivityDumpDiffEntry{Lineage: lineage, Value: sock.Family}] = true
+		}
+		for _, bind := range sock.Bind {
+			set.networkFlows[ActivityDumpDiffEntry{Lineage: lineage, Value: fmt.Sprintf("%s bind %s:%d", sock.Family, bind.IP, bind.Port)}] = true
+		}
+	}
+
+	for name := range pan.DNSNames {
+		set.dnsNames[ActivityDumpDiffEntry{Lineage: lineage, Value: name}] = true
+	}
+
+	for _, child := range pan.Children {
+		child.collectActivities(lineage, set)
+	}
+}
+
+// getActivities returns the activities of the dump
+func (ad *ActivityDump) getActivities() *activitySet {
+	ad.Lock()
+	defer ad.Unlock()
+
+	set := newActivitySet()
+	for _, node := range ad.ProcessActivityTree {
+		node.collectActivities("", set)
+	}
+	return set
+}
+
+// subtractEntries returns the sorted entries of a that aren't in b
+func subtractEntries(a, b map[ActivityDumpDiffEntry]bool) []ActivityDumpDiffEntry {
+	var entries []ActivityDumpDiffEntry
+	for entry := range a {
+		if !b[entry] {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Lineage != entries[j].Lineage {
+			return entries[i].Lineage < entries[j].Lineage
+		}
+		return entries[i].Value < entries[j].Value
+	})
+	return entries
+}
+
+func subtractActivities(a, b *activitySet) ActivityDumpActivities {
+	return ActivityDumpActivities{
+		Processes:    subtractEntries(a.processes, b.processes),
+		Files:        subtractEntries(a.files, b.files),
+		NetworkFlows: subtractEntries(a.networkFlows, b.networkFlows),
+		DNSNames:     subtractEntries(a.dnsNames, b.dnsNames),
+	}
+}
+
+// DiffActivityDumps compares the activities of two dumps: the process lineages, files, network flows and DNS names
+// found in the target dump but not in the base one are reported as added, the ones only found in the base dump are
+// reported as removed.
+func DiffActivityDumps(base, target *ActivityDump) *ActivityDumpDiff {
+	baseActivities, targetActivities := base.getActivities(), target.getActivities()
+
+	return &ActivityDumpDiff{
+		Added:   subtractActivities(targetActivities, baseActivities),
+		Removed: subtractActivities(baseActivities, targetActivities),
+	}
+}
+
+// String returns a human readable representation of the activities
+func (a *ActivityDumpActivities) String() string {
+	var builder strings.Builder
+	for _, kind := range []struct {
+		name    string
+		entries []ActivityDumpDiffEntry
+	}{
+		{name: "processes", entries: a.Processes},
+		{name: "files", entries: a.Files},
+		{name: "network flows", entries: a.NetworkFlows},
+		{name: "DNS names", entries: a.DNSNames},
+	} {
+		if len(kind.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "%s:\n", kind.name)
+		for _, entry := range kind.entries {
+			fmt.Fprintf(&builder, "\t%s\n", entry)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/probe/dump"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+func newTestDiffProcessNode(ad *ActivityDump, path string, pid uint32, files []string, dnsNames []string) *ProcessActivityNode {
+	pan := &ProcessActivityNode{
+		Files:    make(map[string]*FileActivityNode),
+		DNSNames: make(map[string]*DNSNode),
+	}
+	pan.Process.FileEvent.PathnameStr = path
+	pan.Process.Pid = pid
+
+	for _, file := range files {
+		ad.InsertFileEventInProcess(pan, &model.FileEvent{IsPathnameStrResolved: true, PathnameStr: file}, nil, Runtime)
+	}
+	for _, name := range dnsNames {
+		pan.DNSNames[name] = &DNSNode{Requests: []model.DNSEvent{{Name: name}}}
+	}
+	return pan
+}
+
+func newTestDiffActivityDumps() (*ActivityDump, *ActivityDump) {
+	base := NewEmptyActivityDump()
+	root := newTestDiffProcessNode(base, "/usr/bin/containerd-shim", 1, nil, nil)
+	nginx := newTestDiffProcessNode(base, "/usr/sbin/nginx", 10, []string{"/etc/nginx/nginx.conf", "/var/log/nginx/access.log"}, []string{"example.com"})
+	nginx.Sockets = []*SocketNode{{Family: "AF_INET", Bind: []*BindNode{{IP: "0.0.0.0", Port: 80}}}}
+	root.Children = append(root.Children, nginx)
+	base.ProcessActivityTree = append(base.ProcessActivityTree, root)
+
+	// same workload, different PIDs, with a new shell and without the access log
+	target := NewEmptyActivityDump()
+	root = newTestDiffProcessNode(target, "/usr/bin/containerd-shim", 100, nil, nil)
+	nginx = newTestDiffProcessNode(target, "/usr/sbin/nginx", 110, []string{"/etc/nginx/nginx.conf"}, []string{"example.com", "evil.com"})
+	nginx.Sockets = []*SocketNode{{Family: "AF_INET", Bind: []*BindNode{{IP: "0.0.0.0", Port: 80}}}}
+	nginx.Children = append(nginx.Children, newTestDiffProcessNode(target, "/bin/sh", 120, []string{"/etc/shadow"}, nil))
+	root.Children = append(root.Children, nginx)
+	target.ProcessActivityTree = append(target.ProcessActivityTree, root)
+
+	return base, target
+}
+
+func TestDiffActivityDumps(t *testing.T) {
+	base, target := newTestDiffActivityDumps()
+
+	diff := DiffActivityDumps(base, target)
+	assert.False(t, diff.IsEmpty())
+
+	shell := "/usr/bin/containerd-shim > /usr/sbin/nginx > /bin/sh"
+	nginx := "/usr/bin/containerd-shim > /usr/sbin/nginx"
+	assert.Equal(t, []ActivityDumpDiffEntry{{Lineage: shell}}, diff.Added.Processes)
+	assert.Equal(t, []ActivityDumpDiffEntry{{Lineage: shell, Value: "/etc/shadow"}}, diff.Added.Files)
+	assert.Equal(t, []ActivityDumpDiffEntry{{Lineage: nginx, Value: "evil.com"}}, diff.Added.DNSNames)
+	assert.Empty(t, diff.Added.NetworkFlows)
+
+	assert.Equal(t, []ActivityDumpDiffEntry{{Lineage: nginx, Value: "/var/log/nginx/access.log"}}, diff.Removed.Files)
+	assert.Equal(t, 1, diff.Removed.Len())
+
+	assert.True(t, DiffActivityDumps(target, target).IsEmpty())
+}
+
+func TestDecodeJSONActivityDump(t *testing.T) {
+	_, target := newTestDiffActivityDumps()
+	target.DumpMetadata.Name = "test"
+
+	raw, err := target.EncodeJSON()
+	require.NoError(t, err)
+
+	decoded := NewEmptyActivityDump()
+	require.NoError(t, decoded.DecodeFromReader(raw, dump.JSON))
+	assert.Equal(t, "test", decoded.DumpMetadata.Name)
+	assert.True(t, DiffActivityDumps(target, decoded).IsEmpty())
+}
+
+func TestActivityDumpDriftDetector(t *testing.T) {
+	base, target := newTestDiffActivityDumps()
+	target.Tags = []string{"image_name:docker.io/library/nginx", "image_tag:1.23"}
+
+	dir := t.TempDir()
+	detector := NewActivityDumpDriftDetector(dir)
+
+	// no baseline for the image
+	_, event, err := detector.Check(target)
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	raw, err := base.EncodeProtobuf()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker.io_library_nginx.protobuf"), raw.Bytes(), 0644))
+
+	rule, event, err := detector.Check(target)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, ActivityDumpDriftRuleID, rule.ID)
+	assert.Equal(t, "activity_dump_drift", event.GetType())
+	assert.Contains(t, event.GetTags(), "image_tag:1.23")
+
+	drift := event.marshaler.(ActivityDumpDriftEvent)
+	assert.Equal(t, "docker.io/library/nginx", drift.ImageName)
+	assert.Len(t, drift.Added.Processes, 1)
+	assert.Len(t, drift.Added.Files, 1)
+	assert.Len(t, drift.Added.DNSNames, 1)
+
+	// activities are reported once
+	_, event, err = detector.Check(target)
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	target.ProcessActivityTree[0].DNSNames["datadoghq.com"] = &DNSNode{}
+	_, event, err = detector.Check(target)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	drift = event.marshaler.(ActivityDumpDriftEvent)
+	assert.Equal(t, []ActivityDumpDiffEntry{{Lineage: "/usr/bin/containerd-shim", Value: "datadoghq.com"}}, drift.Added.DNSNames)
+	assert.Equal(t, 1, drift.Added.Len())
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/probe/dump"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+// baselineFormats are the formats in which the baselines of the drift detector can be stored
+var baselineFormats = []dump.StorageFormat{dump.PROTOBUF, dump.JSON}
+
+var baselineInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// driftBaseline is a baseline activity dump loaded by the drift detector
+type driftBaseline struct {
+	modTime time.Time
+	dump    *ActivityDump
+}
+
+// ActivityDumpDriftDetector compares running activity dumps against the baseline dump of their image, stored in a
+// directory as `<image_name>.protobuf` or `<image_name>.json`, the characters of the image name other than letters,
+// digits, `.`, `-` and `_` being replaced with `_`.
+type ActivityDumpDriftDetector struct {
+	baselineDirectory string
+	baselines         map[string]*driftBaseline
+}
+
+// NewActivityDumpDriftDetector returns a new drift detector reading its baselines from a directory
+func NewActivityDumpDriftDetector(baselineDirectory string) *ActivityDumpDriftDetector {
+	return &ActivityDumpDriftDetector{
+		baselineDirectory: baselineDirectory,
+		baselines:         make(map[string]*driftBaseline),
+	}
+}
+
+// findBaseline returns the path of the baseline of an image, if any
+func (d *ActivityDumpDriftDetector) findBaseline(imageName string) (string, os.FileInfo, error) {
+	name := baselineInvalidChars.ReplaceAllString(imageName, "_")
+	for _, format := range baselineFormats {
+		path := filepath.Join(d.baselineDirectory, name+"."+format.String())
+		info, err := os.Stat(path)
+		if err == nil {
+			return path, info, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", nil, err
+		}
+	}
+	return "", nil, nil
+}
+
+// loadBaseline returns the baseline of an image, decoding it again only when its file changed
+func (d *ActivityDumpDriftDetector) loadBaseline(imageName string) (string, *ActivityDump, error) {
+	path, info, err := d.findBaseline(imageName)
+	if err != nil || len(path) == 0 {
+		return "", nil, err
+	}
+
+	if baseline, ok := d.baselines[path]; ok && baseline.modTime.Equal(info.ModTime()) {
+		return path, baseline.dump, nil
+	}
+
+	ad := NewEmptyActivityDump()
+	if err = ad.Decode(path); err != nil {
+		delete(d.baselines, path)
+		return "", nil, fmt.Errorf("couldn't load baseline %s: %w", path, err)
+	}
+	d.baselines[path] = &driftBaseline{modTime: info.ModTime(), dump: ad}
+	return path, ad, nil
+}
+
+// Check compares an activity dump against the baseline of its image, and returns a drift event listing the activities
+// missing from the baseline. Each activity is reported once per dump. No event is returned when the dump has no image
+// name, when its image has no baseline, or when all its activities were already reported.
+func (d *ActivityDumpDriftDetector) Check(ad *ActivityDump) (*rules.Rule, *CustomEvent, error) {
+	ad.Lock()
+	imageName := ad.getTagValue("image_name")
+	ad.Unlock()
+
+	if len(imageName) == 0 {
+		return nil, nil, nil
+	}
+
+	path, baseline, err := d.loadBaseline(imageName)
+	if err != nil || baseline == nil {
+		return nil, nil, err
+	}
+
+	added := DiffActivityDumps(baseline, ad).Added
+
+	ad.Lock()
+	defer ad.Unlock()
+
+	if ad.reportedDrift == nil {
+		ad.reportedDrift = make(map[ActivityDumpDiffEntry]bool)
+	}
+	filter := func(entries []ActivityDumpDiffEntry) []ActivityDumpDiffEntry {
+		var unreported []ActivityDumpDiffEntry
+		for _, entry := range entries {
+			if !ad.reportedDrift[entry] {
+				ad.reportedDrift[entry] = true
+				unreported = append(unreported, entry)
+			}
+		}
+		return unreported
+	}
+	drift := ActivityDumpActivities{
+		Processes:    filter(added.Processes),
+		Files:        filter(added.Files),
+		NetworkFlows: filter(added.NetworkFlows),
+		DNSNames:     filter(added.DNSNames),
+	}
+	if drift.Len() == 0 {
+		return nil, nil, nil
+	}
+
+	rule, event := NewActivityDumpDriftEvent(ad.getSelectorStr(), imageName, path, drift)
+	event.tags = ad.Tags
+	return rule, event, nil
+}
@@ -48,6 +48,7 @@ type ActivityDumpManager struct {
 	snapshotQueue  chan *ActivityDump
 	storage        *ActivityDumpStorageManager
 	loadController *ActivityDumpLoadController
+	driftDetector  *ActivityDumpDriftDetector
 	contextTags    []string
 	hostname       string
 }
@@ -88,6 +89,9 @@ func (adm *ActivityDumpManager) Start(ctx context.Context, wg *sync.WaitGroup) {
 
 // cleanup
 func (adm *ActivityDumpManager) cleanup() {
+	// check the drift of the dumps before the expired ones are stopped
+	adm.checkDrift()
+
 	adm.Lock()
 	defer adm.Unlock()
 
@@ -113,6 +117,36 @@ func (adm *ActivityDumpManager) cleanup() {
 	}
 }
 
+// checkDrift compares the active dumps against the baseline of their image, and reports the new activities. Since
+// ProcessEvent takes the manager lock for every event, it is only held to copy the list of active dumps: loading the
+// baselines, diffing the dumps and dispatching the drift events are done without it.
+func (adm *ActivityDumpManager) checkDrift() {
+	if adm.driftDetector == nil {
+		return
+	}
+
+	adm.Lock()
+	dumps := make([]*ActivityDump, len(adm.activeDumps))
+	copy(dumps, adm.activeDumps)
+	adm.Unlock()
+
+	for _, ad := range dumps {
+		adm.checkDumpDrift(ad)
+	}
+}
+
+// checkDumpDrift compares an activity dump against the baseline of its image, and reports the new activities
+func (adm *ActivityDumpManager) checkDumpDrift(ad *ActivityDump) {
+	rule, event, err := adm.driftDetector.Check(ad)
+	if err != nil {
+		seclog.Warnf("couldn't check the drift of [%s]: %v", ad.GetSelectorStr(), err)
+		return
+	}
+	if event != nil {
+		adm.probe.DispatchCustomEvent(rule, event)
+	}
+}
+
 // resolveTags resolves activity dump container tags when they are missing
 func (adm *ActivityDumpManager) resolveTags() {
 	adm.Lock()
@@ -185,6 +219,10 @@ func NewActivityDumpManager(p *Probe) (*ActivityDumpManager, error) {
 		loadController:    loadController,
 	}
 
+	if len(p.config.ActivityDumpDriftBaselineDirectory) > 0 {
+		adm.driftDetector = NewActivityDumpDriftDetector(p.config.ActivityDumpDriftBaselineDirectory)
+	}
+
 	adm.prepareContextTags()
 	return adm, nil
 }
@@ -287,9 +325,34 @@ func (adm *ActivityDumpManager) HandleCgroupTracingEvent(event *model.CgroupTrac
 		seclog.Errorf("received a cgroup tracing event with an empty container ID")
 		return
 	}
+
+	newDump := adm.newContainerActivityDump(event.ContainerContext.ID, time.Until(adm.probe.resolvers.TimeResolver.ResolveMonotonicTimestamp(event.TimeoutRaw)))
+	if err := adm.insertActivityDump(newDump); err != nil {
+		seclog.Errorf("couldn't start tracing [%s]: %v", newDump.GetSelectorStr(), err)
+		return
+	}
+	seclog.Infof("tracing started for [%s]", newDump.GetSelectorStr())
+}
+
+// DumpContainerActivity starts an activity dump of a container, unless one is already running
+func (adm *ActivityDumpManager) DumpContainerActivity(containerID string, timeout time.Duration) error {
+	adm.Lock()
+	defer adm.Unlock()
+
+	newDump := adm.newContainerActivityDump(containerID, timeout)
+	if err := adm.insertActivityDump(newDump); err != nil {
+		return fmt.Errorf("couldn't start tracing [%s]: %w", newDump.GetSelectorStr(), err)
+	}
+	seclog.Infof("tracing started for [%s]", newDump.GetSelectorStr())
+
+	return nil
+}
+
+// newContainerActivityDump returns an activity dump of a container, stored as configured for cgroup activity dumps
+func (adm *ActivityDumpManager) newContainerActivityDump(containerID string, timeout time.Duration) *ActivityDump {
 	newDump := NewActivityDump(adm, func(ad *ActivityDump) {
-		ad.DumpMetadata.ContainerID = event.ContainerContext.ID
-		ad.DumpMetadata.Timeout = time.Until(adm.probe.resolvers.TimeResolver.ResolveMonotonicTimestamp(event.TimeoutRaw))
+		ad.DumpMetadata.ContainerID = containerID
+		ad.DumpMetadata.Timeout = timeout
 		ad.DumpMetadata.DifferentiateArgs = adm.probe.config.ActivityDumpCgroupDifferentiateArgs
 	})
 
@@ -313,11 +376,7 @@ func (adm *ActivityDumpManager) HandleCgroupTracingEvent(event *model.CgroupTrac
 		))
 	}
 
-	if err := adm.insertActivityDump(newDump); err != nil {
-		seclog.Errorf("couldn't start tracing [%s]: %v", newDump.GetSelectorStr(), err)
-		return
-	}
-	seclog.Infof("tracing started for [%s]", newDump.GetSelectorStr())
+	return newDump
 }
 
 // DumpActivity handles an activity dump request
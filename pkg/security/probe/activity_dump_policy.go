@@ -0,0 +1,334 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+// DefaultPolicyPathDepth is the default number of directories kept in the file paths of the policies exported
+// from activity dumps
+const DefaultPolicyPathDepth = 3
+
+var policyIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// policyMacro is the YAML representation of a macro of an exported policy
+type policyMacro struct {
+	ID         string `yaml:"id"`
+	Expression string `yaml:"expression"`
+}
+
+// policyRule is the YAML representation of a rule of an exported policy
+type policyRule struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description,omitempty"`
+	Expression  string `yaml:"expression"`
+}
+
+// policyFile is the YAML representation of an exported policy
+type policyFile struct {
+	Macros []policyMacro `yaml:"macros,omitempty"`
+	Rules  []policyRule  `yaml:"rules,omitempty"`
+}
+
+// PolicyAllowList holds the execs, files and DNS names observed in an activity dump
+type PolicyAllowList struct {
+	Execs    []string
+	Files    []string
+	DNSNames []string
+}
+
+// collapsePath replaces the part of a path deeper than the given number of directories with a `**` glob. A depth
+// of 0 keeps the path untouched.
+func collapsePath(path string, depth int) string {
+	if depth <= 0 {
+		return path
+	}
+
+	elements := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(elements) <= depth+1 {
+		return path
+	}
+	return "/" + strings.Join(elements[:depth], "/") + "/**"
+}
+
+func (fan *FileActivityNode) collectPaths(parent string, depth int, paths map[string]bool) {
+	path := parent + "/" + fan.Name
+	if fan.File != nil {
+		paths[collapsePath(path, depth)] = true
+	}
+	for _, child := range fan.Children {
+		child.collectPaths(path, depth, paths)
+	}
+}
+
+func (pan *ProcessActivityNode) collectAllowList(depth int, execs, files, dnsNames map[string]bool) {
+	if path := pan.Process.FileEvent.PathnameStr; len(path) > 0 {
+		execs[path] = true
+	}
+	for _, file := range pan.Files {
+		file.collectPaths("", depth, files)
+	}
+	for name := range pan.DNSNames {
+		dnsNames[name] = true
+	}
+	for _, child := range pan.Children {
+		child.collectAllowList(depth, execs, files, dnsNames)
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GenerateAllowList returns the execs, files and DNS names observed in the activity dump. The file paths deeper
+// than the given number of directories are collapsed into `**` globs.
+func (ad *ActivityDump) GenerateAllowList(depth int) PolicyAllowList {
+	ad.Lock()
+	defer ad.Unlock()
+
+	return ad.generateAllowList(depth)
+}
+
+// generateAllowList internal, thread-unsafe version of GenerateAllowList
+func (ad *ActivityDump) generateAllowList(depth int) PolicyAllowList {
+	execs, files, dnsNames := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	for _, node := range ad.ProcessActivityTree {
+		node.collectAllowList(depth, execs, files, dnsNames)
+	}
+
+	// drop the files already matched by a collapsed path
+	for path := range files {
+		if !strings.HasSuffix(path, "/**") {
+			continue
+		}
+		for other := range files {
+			if other != path && strings.HasPrefix(other, strings.TrimSuffix(path, "**")) {
+				delete(files, other)
+			}
+		}
+	}
+
+	return PolicyAllowList{
+		Execs:    sortedKeys(execs),
+		Files:    sortedKeys(files),
+		DNSNames: sortedKeys(dnsNames),
+	}
+}
+
+// policyValues returns the SECL array of a list of values, using patterns for the values holding wildcards
+func policyValues(values []string) string {
+	elements := make([]string, 0, len(values))
+	for _, value := range values {
+		if strings.Contains(value, "*") {
+			elements = append(elements, "~"+strconv.Quote(value))
+		} else {
+			elements = append(elements, strconv.Quote(value))
+		}
+	}
+	return "[ " + strings.Join(elements, ", ") + " ]"
+}
+
+// getPolicySelector returns the SECL expression matching the workload of the activity dump
+func (ad *ActivityDump) getPolicySelector() string {
+	var selectors []string
+	if len(ad.DumpMetadata.ContainerID) > 0 {
+		if imageName := ad.getTagValue("image_name"); len(imageName) > 0 {
+			selectors = append(selectors, fmt.Sprintf("container.tags == %s", strconv.Quote("image_name:"+imageName)))
+		} else {
+			selectors = append(selectors, fmt.Sprintf("container.id == %s", strconv.Quote(ad.DumpMetadata.ContainerID)))
+		}
+	}
+	if len(ad.DumpMetadata.Comm) > 0 {
+		selectors = append(selectors, fmt.Sprintf("process.comm == %s", strconv.Quote(ad.DumpMetadata.Comm)))
+	}
+	return strings.Join(selectors, " && ")
+}
+
+func (ad *ActivityDump) getTagValue(name string) string {
+	for _, tag := range ad.Tags {
+		if value := strings.TrimPrefix(tag, name+":"); value != tag {
+			return value
+		}
+	}
+	return ""
+}
+
+// GeneratePolicy generates a SECL policy from the activity dump: the execs, files and DNS names observed in the dump
+// are listed in macros, and a rule is triggered for each exec, file open or DNS request of the workload that isn't
+// listed.
+func (ad *ActivityDump) GeneratePolicy(depth int) ([]byte, error) {
+	ad.Lock()
+	defer ad.Unlock()
+
+	allowList := ad.generateAllowList(depth)
+
+	name := strings.ToLower(policyIDInvalidChars.ReplaceAllString(ad.DumpMetadata.Name, "_"))
+	if len(name) == 0 {
+		name = "activity_dump"
+	}
+
+	selector := ad.getPolicySelector()
+	if len(selector) > 0 {
+		selector = " && " + selector
+	}
+	description := fmt.Sprintf("generated from activity dump %s", ad.DumpMetadata.Name)
+
+	var policy policyFile
+	for _, entry := range []struct {
+		kind   string
+		field  string
+		values []string
+	}{
+		{kind: "exec", field: "exec.file.path", values: allowList.Execs},
+		{kind: "open", field: "open.file.path", values: allowList.Files},
+		{kind: "dns", field: "dns.question.name", values: allowList.DNSNames},
+	} {
+		if len(entry.values) == 0 {
+			continue
+		}
+
+		macroID := fmt.Sprintf("%s_allowed_%s", name, entry.kind)
+		policy.Macros = append(policy.Macros, policyMacro{
+			ID:         macroID,
+			Expression: policyValues(entry.values),
+		})
+		policy.Rules = append(policy.Rules, policyRule{
+			ID:          fmt.Sprintf("%s_unexpected_%s", name, entry.kind),
+			Description: fmt.Sprintf("%s not listed in %s, %s", entry.field, macroID, description),
+			Expression:  fmt.Sprintf("%s not in %s%s", entry.field, macroID, selector),
+		})
+	}
+
+	raw, err := yaml.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate policy: %w", err)
+	}
+	return raw, nil
+}
+
+// EncodePolicy encodes an activity dump as a SECL policy
+func (ad *ActivityDump) EncodePolicy() (*bytes.Buffer, error) {
+	raw, err := ad.GeneratePolicy(ad.policyPathDepth)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(raw), nil
+}
+
+// SeccompProfile is a seccomp profile, in the format used by container runtimes
+type SeccompProfile struct {
+	DefaultAction string                `json:"defaultAction"`
+	Architectures []string              `json:"architectures,omitempty"`
+	Syscalls      []SeccompSyscallEntry `json:"syscalls"`
+}
+
+// SeccompSyscallEntry lists syscalls sharing the same action in a seccomp profile
+type SeccompSyscallEntry struct {
+	Names  []string `json:"names"`
+	Action string   `json:"action"`
+}
+
+// seccompArchitectures maps the architectures of the syscall tables of the agent to activity dump and seccomp
+// architectures
+var seccompArchitectures = map[string]struct {
+	dumpArch    string
+	seccompArch string
+}{
+	"amd64": {dumpArch: "x64", seccompArch: "SCMP_ARCH_X86_64"},
+	"arm64": {dumpArch: "arm64", seccompArch: "SCMP_ARCH_AARCH64"},
+}
+
+// seccompSyscallName returns the kernel name of a syscall, `SysRtSigaction` being `rt_sigaction`
+func seccompSyscallName(syscall model.Syscall) string {
+	var name strings.Builder
+	for i, r := range strings.TrimPrefix(syscall.String(), "Sys") {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+// GenerateSeccompProfile generates a seccomp profile allowing the syscalls observed in the activity dump. Syscall
+// numbers depend on the architecture, so the profile can only be generated on the architecture of the dump.
+func (ad *ActivityDump) GenerateSeccompProfile() (*SeccompProfile, error) {
+	ad.Lock()
+	defer ad.Unlock()
+
+	arch, ok := seccompArchitectures[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("couldn't generate seccomp profile: unsupported architecture %s", runtime.GOARCH)
+	}
+	if len(ad.DumpMetadata.Arch) > 0 && ad.DumpMetadata.Arch != arch.dumpArch {
+		return nil, fmt.Errorf("couldn't generate seccomp profile: syscalls of a %s activity dump can't be resolved on %s", ad.DumpMetadata.Arch, arch.dumpArch)
+	}
+
+	syscalls := make(map[string]bool)
+	var collect func(node *ProcessActivityNode)
+	collect = func(node *ProcessActivityNode) {
+		for _, syscall := range node.Syscalls {
+			syscalls[seccompSyscallName(model.Syscall(syscall))] = true
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	for _, node := range ad.ProcessActivityTree {
+		collect(node)
+	}
+
+	profile := &SeccompProfile{
+		DefaultAction: "SCMP_ACT_ERRNO",
+		Architectures: []string{arch.seccompArch},
+		Syscalls:      []SeccompSyscallEntry{},
+	}
+	if len(syscalls) > 0 {
+		profile.Syscalls = append(profile.Syscalls, SeccompSyscallEntry{
+			Names:  sortedKeys(syscalls),
+			Action: "SCMP_ACT_ALLOW",
+		})
+	}
+	return profile, nil
+}
+
+// EncodeSeccomp encodes an activity dump as a seccomp profile
+func (ad *ActivityDump) EncodeSeccomp() (*bytes.Buffer, error) {
+	profile, err := ad.GenerateSeccompProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encode seccomp profile: %w", err)
+	}
+	return bytes.NewBuffer(raw), nil
+}
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	seclog "github.com/DataDog/datadog-agent/pkg/security/log"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func newTestPolicyActivityDump() *ActivityDump {
+	ad := NewEmptyActivityDump()
+	ad.DumpMetadata.Name = "activity-dump-test"
+	ad.DumpMetadata.Comm = "nginx"
+
+	pan := &ProcessActivityNode{
+		Files:    make(map[string]*FileActivityNode),
+		DNSNames: make(map[string]*DNSNode),
+		Syscalls: []int{int(model.SysRead), int(model.SysRtSigaction), int(model.SysPread64)},
+	}
+	pan.Process.FileEvent.PathnameStr = "/usr/sbin/nginx"
+
+	for _, path := range []string{
+		"/etc/nginx/nginx.conf",
+		"/etc/nginx/conf.d/default.conf",
+		"/etc/nginx/conf.d/sites/a.conf",
+		"/var/log/nginx/access.log",
+	} {
+		fileEvent := &model.FileEvent{
+			IsPathnameStrResolved: true,
+			PathnameStr:           path,
+		}
+		ad.InsertFileEventInProcess(pan, fileEvent, nil, Runtime)
+	}
+	pan.DNSNames["example.com"] = &DNSNode{}
+
+	child := &ProcessActivityNode{
+		Files:    make(map[string]*FileActivityNode),
+		DNSNames: make(map[string]*DNSNode),
+		Syscalls: []int{int(model.SysRead), int(model.SysExecve)},
+	}
+	child.Process.FileEvent.PathnameStr = "/bin/sh"
+	pan.Children = append(pan.Children, child)
+
+	ad.ProcessActivityTree = append(ad.ProcessActivityTree, pan)
+	return ad
+}
+
+func TestCollapsePath(t *testing.T) {
+	assert.Equal(t, "/etc/nginx/conf.d/default.conf", collapsePath("/etc/nginx/conf.d/default.conf", 0))
+	assert.Equal(t, "/etc/nginx/conf.d/default.conf", collapsePath("/etc/nginx/conf.d/default.conf", 3))
+	assert.Equal(t, "/etc/nginx/**", collapsePath("/etc/nginx/conf.d/default.conf", 2))
+	assert.Equal(t, "/etc/**", collapsePath("/etc/nginx/conf.d/default.conf", 1))
+	assert.Equal(t, "/etc/passwd", collapsePath("/etc/passwd", 1))
+}
+
+func TestGenerateAllowList(t *testing.T) {
+	ad := newTestPolicyActivityDump()
+
+	allowList := ad.GenerateAllowList(2)
+	assert.Equal(t, []string{"/bin/sh", "/usr/sbin/nginx"}, allowList.Execs)
+	assert.Equal(t, []string{"/etc/nginx/**", "/var/log/**"}, allowList.Files)
+
+	allowList = ad.GenerateAllowList(1)
+	assert.Equal(t, []string{"/etc/**", "/var/**"}, allowList.Files)
+	assert.Equal(t, []string{"example.com"}, allowList.DNSNames)
+
+	allowList = ad.GenerateAllowList(0)
+	assert.Len(t, allowList.Files, 4)
+}
+
+func TestGeneratePolicy(t *testing.T) {
+	ad := newTestPolicyActivityDump()
+
+	raw, err := ad.GeneratePolicy(2)
+	require.NoError(t, err)
+
+	policy, err := rules.LoadPolicy("activity_dump", "test", bytes.NewReader(raw), nil)
+	require.NoError(t, err)
+	assert.Len(t, policy.Macros, 3)
+	require.Len(t, policy.Rules, 3)
+	assert.Equal(t, "activity_dump_test_unexpected_exec", policy.Rules[0].ID)
+
+	var evalOpts eval.Opts
+	evalOpts.
+		WithConstants(model.SECLConstants).
+		WithLegacyFields(model.SECLLegacyFields)
+
+	var opts rules.Opts
+	opts.
+		WithEventTypeEnabled(map[eval.EventType]bool{"*": true}).
+		WithLogger(seclog.DefaultLogger)
+
+	rs := rules.NewRuleSet(&Model{}, func() eval.Event { return &Event{} }, &opts, &evalOpts, &eval.MacroStore{})
+	require.NoError(t, rs.AddMacros(policy.Macros).ErrorOrNil())
+	require.NoError(t, rs.AddRules(policy.Rules).ErrorOrNil())
+
+	newOpenEvent := func(comm, path string) *Event {
+		event := NewEvent(nil, nil, nil)
+		event.Type = uint32(model.FileOpenEventType)
+		event.ProcessContext = &model.ProcessContext{}
+		event.ProcessContext.Comm = comm
+		event.Open.File.IsPathnameStrResolved = true
+		event.Open.File.PathnameStr = path
+		return event
+	}
+
+	assert.False(t, rs.Evaluate(newOpenEvent("nginx", "/etc/nginx/conf.d/sites/b.conf")), "collapsed paths should be allowed")
+	assert.False(t, rs.Evaluate(newOpenEvent("nginx", "/etc/nginx/nginx.conf")))
+	assert.True(t, rs.Evaluate(newOpenEvent("nginx", "/etc/shadow")))
+	assert.False(t, rs.Evaluate(newOpenEvent("bash", "/etc/shadow")), "other workloads shouldn't be matched")
+}
+
+func TestGenerateSeccompProfile(t *testing.T) {
+	ad := newTestPolicyActivityDump()
+
+	raw, err := ad.EncodeSeccomp()
+	require.NoError(t, err)
+
+	var profile SeccompProfile
+	require.NoError(t, json.Unmarshal(raw.Bytes(), &profile))
+	assert.Equal(t, "SCMP_ACT_ERRNO", profile.DefaultAction)
+	assert.Len(t, profile.Architectures, 1)
+	require.Len(t, profile.Syscalls, 1)
+	assert.Equal(t, "SCMP_ACT_ALLOW", profile.Syscalls[0].Action)
+	assert.Equal(t, []string{"execve", "pread64", "read", "rt_sigaction"}, profile.Syscalls[0].Names)
+
+	ad.DumpMetadata.Arch = "unknown"
+	_, err = ad.GenerateSeccompProfile()
+	assert.Error(t, err)
+}
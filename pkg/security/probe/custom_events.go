@@ -33,6 +33,14 @@ const (
 	AbnormalPathRuleID = "abnormal_path"
 	// SelfTestRuleID is the rule ID for the self_test events
 	SelfTestRuleID = "self_test"
+	// RuleActionRuleID is the rule ID for the rule_action events
+	RuleActionRuleID = "rule_action"
+	// ContainerQuarantineRuleID is the rule ID for the container_quarantine events
+	ContainerQuarantineRuleID = "container_quarantine"
+	// ActivityDumpDriftRuleID is the rule ID for the activity_dump_drift events
+	ActivityDumpDriftRuleID = "activity_dump_drift"
+	// FileChangedRuleID is the rule ID for the file_changed events
+	FileChangedRuleID = "file_changed"
 )
 
 // AllCustomRuleIDs returns the list of custom rule IDs
@@ -43,6 +51,10 @@ func AllCustomRuleIDs() []string {
 		NoisyProcessRuleID,
 		AbnormalPathRuleID,
 		SelfTestRuleID,
+		RuleActionRuleID,
+		ContainerQuarantineRuleID,
+		ActivityDumpDriftRuleID,
+		FileChangedRuleID,
 	}
 }
 
@@ -350,3 +362,97 @@ func NewSelfTestEvent(success []string, fails []string) (*rules.Rule, *CustomEve
 			Fails:     fails,
 		})
 }
+
+// RuleActionEvent is used to report a response action of a rule, whether it was taken or not
+// easyjson:json
+type RuleActionEvent struct {
+	Timestamp   time.Time        `json:"date"`
+	RuleID      string           `json:"rule_id"`
+	Action      string           `json:"action"`
+	Status      string           `json:"status"`
+	DryRun      bool             `json:"dry_run,omitempty"`
+	Signal      string           `json:"signal,omitempty"`
+	Pid         uint32           `json:"pid,omitempty"`
+	ContainerID string           `json:"container_id,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Event       *EventSerializer `json:"triggering_event"`
+}
+
+// NewRuleActionEvent returns the rule and a populated custom event for a rule_action event
+func NewRuleActionEvent(event *Event, action RuleActionEvent) (*rules.Rule, *CustomEvent) {
+	action.Timestamp = time.Now()
+	action.Event = NewEventSerializer(event)
+
+	return newRule(&rules.RuleDefinition{
+		ID: RuleActionRuleID,
+	}), newCustomEvent(model.CustomRuleActionEventType, action)
+}
+
+// ContainerQuarantineEvent is used to report that a rule asked for a container to be quarantined
+// easyjson:json
+type ContainerQuarantineEvent struct {
+	Timestamp   time.Time        `json:"date"`
+	RuleID      string           `json:"rule_id"`
+	ContainerID string           `json:"container_id"`
+	Reason      string           `json:"reason,omitempty"`
+	Event       *EventSerializer `json:"triggering_event"`
+}
+
+// NewContainerQuarantineEvent returns the rule and a populated custom event for a container_quarantine event
+func NewContainerQuarantineEvent(event *Event, ruleID string, reason string) (*rules.Rule, *CustomEvent) {
+	return newRule(&rules.RuleDefinition{
+			ID: ContainerQuarantineRuleID,
+		}), newCustomEvent(model.CustomContainerQuarantineEventType, ContainerQuarantineEvent{
+			Timestamp:   time.Now(),
+			RuleID:      ruleID,
+			ContainerID: event.ContainerContext.ID,
+			Reason:      reason,
+			Event:       NewEventSerializer(event),
+		})
+}
+
+// ActivityDumpDriftEvent is used to report the activities of a running activity dump missing from the baseline of its image
+// easyjson:json
+type ActivityDumpDriftEvent struct {
+	Timestamp time.Time              `json:"date"`
+	Selector  string                 `json:"selector"`
+	ImageName string                 `json:"image_name"`
+	Baseline  string                 `json:"baseline"`
+	Added     ActivityDumpActivities `json:"added"`
+}
+
+// NewActivityDumpDriftEvent returns the rule and a populated custom event for an activity_dump_drift event
+func NewActivityDumpDriftEvent(selector string, imageName string, baseline string, added ActivityDumpActivities) (*rules.Rule, *CustomEvent) {
+	return newRule(&rules.RuleDefinition{
+			ID: ActivityDumpDriftRuleID,
+		}), newCustomEvent(model.CustomActivityDumpDriftEventType, ActivityDumpDriftEvent{
+			Timestamp: time.Now(),
+			Selector:  selector,
+			ImageName: imageName,
+			Baseline:  baseline,
+			Added:     added,
+		})
+}
+
+// FileChangedEvent is used to report that a write changed the content of a file monitored by the file integrity
+// monitoring baseline
+// easyjson:json
+type FileChangedEvent struct {
+	Timestamp     time.Time                 `json:"date"`
+	Path          string                    `json:"path"`
+	Baseline      string                    `json:"baseline"`
+	ContainerID   string                    `json:"container_id,omitempty"`
+	HashAlgorithm string                    `json:"hash_algorithm"`
+	OldHash       string                    `json:"old_hash"`
+	NewHash       string                    `json:"new_hash"`
+	Process       *ProcessContextSerializer `json:"process,omitempty"`
+}
+
+// NewFileChangedEvent returns the rule and a populated custom event for a file_changed event
+func NewFileChangedEvent(changed FileChangedEvent) (*rules.Rule, *CustomEvent) {
+	changed.Timestamp = time.Now()
+
+	return newRule(&rules.RuleDefinition{
+		ID: FileChangedRuleID,
+	}), newCustomEvent(model.CustomFileChangedEventType, changed)
+}
@@ -109,13 +109,17 @@ var (
 	DOT StorageFormat = "dot"
 	// Profile is used to request the Secl profile format
 	Profile StorageFormat = "profile"
+	// Policy is used to request the SECL policy format, listing the execs, files and DNS names allowed for a workload
+	Policy StorageFormat = "policy"
+	// Seccomp is used to request the seccomp profile format, listing the syscalls allowed for a workload
+	Seccomp StorageFormat = "seccomp"
 
 	strToFormats = make(map[string]StorageFormat)
 )
 
 // AllStorageFormats returns the list of supported formats
 func AllStorageFormats() []StorageFormat {
-	return []StorageFormat{JSON, PROTOBUF, DOT, Profile}
+	return []StorageFormat{JSON, PROTOBUF, DOT, Profile, Policy, Seccomp}
 }
 
 // ParseStorageFormat returns a storage format from a string input
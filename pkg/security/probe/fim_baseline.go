@@ -0,0 +1,510 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	seclog "github.com/DataDog/datadog-agent/pkg/security/log"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+)
+
+const (
+	// FIMBaselinePolicySource is the source of the policy holding the rules the file integrity monitoring baseline
+	// needs to receive the file events. The rules of this policy only feed the baseline and never trigger alerts.
+	FIMBaselinePolicySource = "fim-baseline"
+
+	fimBaselinePolicyName    = "datadog-agent-cws-fim-baseline-policy"
+	fimBaselinePolicyVersion = "1.0.0"
+	fimBaselineRuleIDPrefix  = "datadog_agent_cws_fim_baseline_rule"
+
+	// fimBaselineHostScope is the scope of the baselines of the files of the host
+	fimBaselineHostScope = "host"
+	// fimBaselineWriteFlags are the open flags of the opens that can change the content of a file
+	fimBaselineWriteFlags = syscall.O_WRONLY | syscall.O_RDWR | syscall.O_TRUNC | syscall.O_CREAT
+
+	fimBaselineRequestQueueSize   = 1024
+	fimBaselineMaxPendingWrites   = 4096
+	fimBaselineMaxReportedChanges = 4096
+	// fimBaselineMaxWriteChecks is the maximum number of pending writes checked per period, as each check lists the
+	// file descriptors of a process
+	fimBaselineMaxWriteChecks = 256
+	// fimBaselineMaxDeferredRequests is the maximum number of requests waiting for the image of their container
+	fimBaselineMaxDeferredRequests = 1024
+	// fimBaselineDeferTimeout is how long a request waits for the image of its container to be resolved
+	fimBaselineDeferTimeout = time.Minute
+)
+
+// fimBaselineHashAlgorithms are the hash algorithms supported by the file integrity monitoring baseline
+var fimBaselineHashAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// errFIMBaselineFileTooLarge is returned when a file is larger than the maximum size of the hashed files
+var errFIMBaselineFileTooLarge = errors.New("file too large")
+
+// fimBaselineChange identifies a change reported for a file of a container
+type fimBaselineChange struct {
+	containerID string
+	path        string
+	hash        string
+}
+
+// fimBaselineRequest is a file event matching the monitored paths, handled asynchronously as hashing files is slow.
+// It holds the root directory of the process, so that the file can still be read once the process exited. Its scope
+// is empty until the image of its container is resolved.
+type fimBaselineRequest struct {
+	key         fimBaselineKey
+	root        *os.File
+	write       bool
+	pid         uint32
+	containerID string
+	process     *ProcessContextSerializer
+	deferredAt  time.Time
+}
+
+// FIMBaselineMonitor is the file integrity monitoring baseline. It hashes the files matching the monitored paths the
+// first time they are seen for a container image, identified by its image ID, and reports a file_changed event when a
+// write changes their content. The requests of a container are deferred until the ID of its image is known, so that
+// no baseline is created for a single container. There is no close event, so a written file is hashed again once its
+// writer doesn't hold it open anymore. A change is reported once per container, while the baseline is kept so that
+// the same change is reported in the other containers of the image.
+type FIMBaselineMonitor struct {
+	globs         []*eval.Glob
+	paths         []string
+	hashAlgorithm string
+	newHash       func() hash.Hash
+	maxFileSize   int64
+	baselineFile  string
+	checkPeriod   time.Duration
+	store         *FIMBaselineStore
+
+	requests chan *fimBaselineRequest
+	pending  map[fimBaselineKey]*fimBaselineRequest
+	deferred []*fimBaselineRequest
+	reported *simplelru.LRU
+	dropped  *atomic.Uint64
+	changes  *atomic.Uint64
+
+	probe        *Probe
+	dispatch     func(rule *rules.Rule, event *CustomEvent)
+	resolveScope func(containerID string) string
+	isFileOpen   func(pid uint32, path string) bool
+}
+
+var _ rules.PolicyProvider = (*FIMBaselineMonitor)(nil)
+
+// NewFIMBaselineMonitor returns a new file integrity monitoring baseline
+func NewFIMBaselineMonitor(p *Probe) (*FIMBaselineMonitor, error) {
+	newHash, ok := fimBaselineHashAlgorithms[p.config.FIMBaselineHashAlgorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm `%s`", p.config.FIMBaselineHashAlgorithm)
+	}
+
+	if len(p.config.FIMBaselinePaths) == 0 {
+		return nil, errors.New("no path to monitor")
+	}
+
+	m := &FIMBaselineMonitor{
+		paths:         p.config.FIMBaselinePaths,
+		hashAlgorithm: p.config.FIMBaselineHashAlgorithm,
+		newHash:       newHash,
+		maxFileSize:   p.config.FIMBaselineMaxFileSize,
+		baselineFile:  p.config.FIMBaselineFile,
+		checkPeriod:   p.config.FIMBaselineCheckPeriod,
+		requests:      make(chan *fimBaselineRequest, fimBaselineRequestQueueSize),
+		pending:       make(map[fimBaselineKey]*fimBaselineRequest),
+		dropped:       atomic.NewUint64(0),
+		changes:       atomic.NewUint64(0),
+		probe:         p,
+		dispatch:      p.DispatchCustomEvent,
+		isFileOpen:    isFileOpenByProcess,
+	}
+	m.resolveScope = m.resolveContainerScope
+
+	for _, path := range m.paths {
+		glob, err := eval.NewGlob(path, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path `%s`: %w", path, err)
+		}
+		m.globs = append(m.globs, glob)
+	}
+
+	var err error
+	if m.reported, err = simplelru.NewLRU(fimBaselineMaxReportedChanges, nil); err != nil {
+		return nil, err
+	}
+	if m.store, err = NewFIMBaselineStore(p.config.FIMBaselineMaxBaselines, m.hashAlgorithm); err != nil {
+		return nil, err
+	}
+
+	if len(m.baselineFile) > 0 {
+		if err := m.store.Load(m.baselineFile); err != nil {
+			seclog.Errorf("couldn't load the file baselines: %v", err)
+		}
+	}
+
+	return m, nil
+}
+
+// LoadPolicies implements the PolicyProvider interface. The rules of the policy make sure the open and rename events
+// of the monitored paths are sent to user space.
+func (m *FIMBaselineMonitor) LoadPolicies(_ []rules.RuleFilter) ([]*rules.Policy, *multierror.Error) {
+	values := make([]string, 0, len(m.paths))
+	for _, path := range m.paths {
+		if strings.Contains(path, "*") {
+			values = append(values, "~"+strconv.Quote(path))
+		} else {
+			values = append(values, strconv.Quote(path))
+		}
+	}
+	list := "[" + strings.Join(values, ", ") + "]"
+
+	p := &rules.Policy{
+		Name:    fimBaselinePolicyName,
+		Source:  FIMBaselinePolicySource,
+		Version: fimBaselinePolicyVersion,
+	}
+	p.AddRule(&rules.RuleDefinition{
+		ID:         fimBaselineRuleIDPrefix + "_open",
+		Expression: fmt.Sprintf("open.file.path in %s", list),
+	})
+	p.AddRule(&rules.RuleDefinition{
+		ID:         fimBaselineRuleIDPrefix + "_rename",
+		Expression: fmt.Sprintf("rename.file.destination.path in %s", list),
+	})
+
+	return []*rules.Policy{p}, nil
+}
+
+// SetOnNewPoliciesReadyCb implements the PolicyProvider interface
+func (m *FIMBaselineMonitor) SetOnNewPoliciesReadyCb(cb func()) {
+}
+
+// Start implements the PolicyProvider interface
+func (m *FIMBaselineMonitor) Start() {}
+
+// Close implements the PolicyProvider interface
+func (m *FIMBaselineMonitor) Close() error {
+	return nil
+}
+
+// Run hashes the files of the queued requests, and checks periodically if the written files changed
+func (m *FIMBaselineMonitor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(m.checkPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			for _, request := range m.pending {
+				request.root.Close()
+			}
+			for _, request := range m.deferred {
+				request.root.Close()
+			}
+			if len(m.baselineFile) > 0 {
+				if err := m.store.Save(m.baselineFile); err != nil {
+					seclog.Errorf("couldn't persist the file baselines: %v", err)
+				}
+			}
+			return
+		case request := <-m.requests:
+			m.handleRequest(request)
+		case <-ticker.C:
+			m.handleDeferredRequests(time.Now())
+			m.checkPendingWrites()
+		}
+	}
+}
+
+// matches returns whether a path is monitored
+func (m *FIMBaselineMonitor) matches(path string) bool {
+	for _, glob := range m.globs {
+		if glob.Matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessEvent queues the open and rename events of the monitored paths. The event is reused once this function
+// returns, so everything needed later is copied, including the lineage of the writers.
+func (m *FIMBaselineMonitor) ProcessEvent(event *Event) {
+	var path string
+	var write bool
+
+	switch event.GetEventType() {
+	case model.FileOpenEventType:
+		if event.Open.Retval < 0 {
+			return
+		}
+		path = event.ResolveFilePath(&event.Open.File)
+		write = event.Open.Flags&fimBaselineWriteFlags != 0
+	case model.FileRenameEventType:
+		if event.Rename.Retval < 0 {
+			return
+		}
+		path = event.ResolveFilePath(&event.Rename.New)
+		write = true
+	default:
+		return
+	}
+
+	if len(path) == 0 || !m.matches(path) {
+		return
+	}
+
+	pid := event.ProcessContext.Pid
+	root, err := os.Open(utils.RootPath(int32(pid)))
+	if err != nil {
+		seclog.Debugf("couldn't open the root directory of process %d: %v", pid, err)
+		return
+	}
+
+	request := &fimBaselineRequest{
+		key: fimBaselineKey{
+			Scope: m.resolveScope(event.ContainerContext.ID),
+			Path:  path,
+		},
+		root:        root,
+		write:       write,
+		pid:         pid,
+		containerID: event.ContainerContext.ID,
+	}
+	if write {
+		request.process = newProcessContextSerializer(event.ProcessContext, event, m.probe.resolvers)
+	}
+
+	select {
+	case m.requests <- request:
+	default:
+		root.Close()
+		m.dropped.Inc()
+	}
+}
+
+// resolveContainerScope returns the ID of the image of a container, which unlike its name and tag identifies its
+// content, or an empty string if it isn't known yet
+func (m *FIMBaselineMonitor) resolveContainerScope(containerID string) string {
+	if len(containerID) == 0 {
+		return fimBaselineHostScope
+	}
+	return m.probe.resolvers.TagsResolver.GetValue(containerID, "image_id")
+}
+
+// handleRequest hashes a file seen for the first time, and keeps track of the writes until the file is closed
+func (m *FIMBaselineMonitor) handleRequest(request *fimBaselineRequest) {
+	if len(request.key.Scope) == 0 {
+		m.deferRequest(request)
+		return
+	}
+
+	if _, exists := m.store.get(request.key); !exists {
+		// the file may already be written to, which can't be helped without knowing its previous content
+		sum, err := m.hashFile(request.root, request.key.Path)
+		if err != nil {
+			seclog.Debugf("couldn't hash %s: %v", request.key.Path, err)
+		} else {
+			m.store.set(request.key, &fimBaseline{Hash: sum, FirstSeen: time.Now()})
+		}
+	}
+
+	if !request.write {
+		request.root.Close()
+		return
+	}
+
+	// only the last writer of a file is reported
+	if previous, exists := m.pending[request.key]; exists {
+		previous.root.Close()
+	} else if len(m.pending) >= fimBaselineMaxPendingWrites {
+		request.root.Close()
+		m.dropped.Inc()
+		return
+	}
+	m.pending[request.key] = request
+}
+
+// deferRequest keeps a request until the image of its container is resolved
+func (m *FIMBaselineMonitor) deferRequest(request *fimBaselineRequest) {
+	if len(m.deferred) >= fimBaselineMaxDeferredRequests {
+		request.root.Close()
+		m.dropped.Inc()
+		return
+	}
+	if request.deferredAt.IsZero() {
+		request.deferredAt = time.Now()
+	}
+	m.deferred = append(m.deferred, request)
+}
+
+// handleDeferredRequests handles the deferred requests whose container image got resolved, and drops the ones that
+// waited for too long
+func (m *FIMBaselineMonitor) handleDeferredRequests(now time.Time) {
+	deferred := m.deferred
+	m.deferred = nil
+	for _, request := range deferred {
+		if request.key.Scope = m.resolveScope(request.containerID); len(request.key.Scope) > 0 {
+			m.handleRequest(request)
+		} else if now.Sub(request.deferredAt) >= fimBaselineDeferTimeout {
+			request.root.Close()
+			m.dropped.Inc()
+		} else {
+			m.deferred = append(m.deferred, request)
+		}
+	}
+}
+
+// checkPendingWrites hashes the written files their writer closed, and reports the ones whose content changed. Since
+// each check lists the file descriptors of a process, at most fimBaselineMaxWriteChecks writes are checked per call,
+// the random iteration order of the map spreading the checks over the pending writes.
+func (m *FIMBaselineMonitor) checkPendingWrites() {
+	checks := 0
+	for key, request := range m.pending {
+		if checks >= fimBaselineMaxWriteChecks {
+			return
+		}
+		checks++
+
+		if m.isFileOpen(request.pid, key.Path) {
+			continue
+		}
+
+		delete(m.pending, key)
+		m.checkWrite(request)
+		request.root.Close()
+	}
+}
+
+func (m *FIMBaselineMonitor) checkWrite(request *fimBaselineRequest) {
+	sum, err := m.hashFile(request.root, request.key.Path)
+	if err != nil {
+		seclog.Debugf("couldn't hash %s: %v", request.key.Path, err)
+		return
+	}
+
+	baseline, exists := m.store.get(request.key)
+	if !exists {
+		m.store.set(request.key, &fimBaseline{Hash: sum, FirstSeen: time.Now()})
+		return
+	}
+	if baseline.Hash == sum {
+		return
+	}
+
+	change := fimBaselineChange{containerID: request.containerID, path: request.key.Path, hash: sum}
+	if m.reported.Contains(change) {
+		return
+	}
+	m.reported.Add(change, nil)
+
+	m.changes.Inc()
+	m.dispatch(NewFileChangedEvent(FileChangedEvent{
+		Path:          request.key.Path,
+		Baseline:      request.key.Scope,
+		ContainerID:   request.containerID,
+		HashAlgorithm: m.hashAlgorithm,
+		OldHash:       baseline.Hash,
+		NewHash:       sum,
+		Process:       request.process,
+	}))
+}
+
+// hashFile returns the hex encoded hash of the content of a file, resolved from the root directory of a process
+func (m *FIMBaselineMonitor) hashFile(root *os.File, path string) (string, error) {
+	f, err := filesystem.OpenInRoot(root, path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", fmt.Errorf("not a regular file")
+	}
+	if m.maxFileSize > 0 && fi.Size() > m.maxFileSize {
+		return "", errFIMBaselineFileTooLarge
+	}
+
+	h := m.newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SendStats sends the file integrity monitoring baseline stats
+func (m *FIMBaselineMonitor) SendStats() error {
+	if err := m.probe.statsdClient.Gauge(metrics.MetricFIMBaselineCount, float64(m.store.Len()), []string{}, 1.0); err != nil {
+		return fmt.Errorf("couldn't send MetricFIMBaselineCount metric: %w", err)
+	}
+	if dropped := m.dropped.Swap(0); dropped > 0 {
+		if err := m.probe.statsdClient.Count(metrics.MetricFIMBaselineDroppedRequests, int64(dropped), []string{}, 1.0); err != nil {
+			return fmt.Errorf("couldn't send MetricFIMBaselineDroppedRequests metric: %w", err)
+		}
+	}
+	if changes := m.changes.Swap(0); changes > 0 {
+		if err := m.probe.statsdClient.Count(metrics.MetricFIMBaselineFileChanged, int64(changes), []string{}, 1.0); err != nil {
+			return fmt.Errorf("couldn't send MetricFIMBaselineFileChanged metric: %w", err)
+		}
+	}
+	return nil
+}
+
+// isFileOpenByProcess returns whether a process holds a file descriptor to a path, as seen from the root directory of
+// the process. A process that exited doesn't hold any file.
+func isFileOpenByProcess(pid uint32, path string) bool {
+	fdDir := filepath.Join(util.HostProc(), strconv.FormatUint(uint64(pid), 10), "fd")
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		if target, err := os.Readlink(filepath.Join(fdDir, entry.Name())); err == nil && target == path {
+			return true
+		}
+	}
+	return false
+}
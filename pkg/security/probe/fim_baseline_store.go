@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// fimBaselineKey identifies the baseline of a file. The scope is the image of the container the file was seen in,
+// the container ID when the image is unknown, or `host`.
+type fimBaselineKey struct {
+	Scope string `json:"scope"`
+	Path  string `json:"path"`
+}
+
+// fimBaseline is the hash of the content of a file the first time it was seen. It's never updated, so that a change
+// is reported in every container of an image.
+type fimBaseline struct {
+	Hash      string    `json:"hash"`
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+// fimBaselineFile is the content of the file the baselines are persisted to
+type fimBaselineFile struct {
+	HashAlgorithm string              `json:"hash_algorithm"`
+	Baselines     []fimBaselineRecord `json:"baselines"`
+}
+
+type fimBaselineRecord struct {
+	fimBaselineKey
+	fimBaseline
+}
+
+// FIMBaselineStore holds a bounded number of file baselines, the least recently used baselines being evicted first
+type FIMBaselineStore struct {
+	sync.Mutex
+	hashAlgorithm string
+	baselines     *simplelru.LRU
+}
+
+// NewFIMBaselineStore returns a new store of file baselines hashed with the provided algorithm
+func NewFIMBaselineStore(maxBaselines int, hashAlgorithm string) (*FIMBaselineStore, error) {
+	baselines, err := simplelru.NewLRU(maxBaselines, nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create the baseline store: %w", err)
+	}
+
+	return &FIMBaselineStore{
+		hashAlgorithm: hashAlgorithm,
+		baselines:     baselines,
+	}, nil
+}
+
+// get returns the baseline of a file, if any
+func (s *FIMBaselineStore) get(key fimBaselineKey) (*fimBaseline, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	value, ok := s.baselines.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return value.(*fimBaseline), true
+}
+
+// set inserts or replaces the baseline of a file
+func (s *FIMBaselineStore) set(key fimBaselineKey, baseline *fimBaseline) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.baselines.Add(key, baseline)
+}
+
+// Len returns the number of baselines of the store
+func (s *FIMBaselineStore) Len() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.baselines.Len()
+}
+
+// Load reads the baselines persisted to a file. A missing file isn't an error, and the baselines hashed with another
+// algorithm are ignored.
+func (s *FIMBaselineStore) Load(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var file fimBaselineFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("couldn't parse baseline file %s: %w", path, err)
+	}
+
+	if file.HashAlgorithm != s.hashAlgorithm {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// the records are ordered from the least to the most recently used
+	for _, record := range file.Baselines {
+		baseline := record.fimBaseline
+		s.baselines.Add(record.fimBaselineKey, &baseline)
+	}
+	return nil
+}
+
+// Save persists the baselines to a file, replaced atomically
+func (s *FIMBaselineStore) Save(path string) error {
+	s.Lock()
+	file := fimBaselineFile{
+		HashAlgorithm: s.hashAlgorithm,
+		Baselines:     make([]fimBaselineRecord, 0, s.baselines.Len()),
+	}
+	for _, key := range s.baselines.Keys() {
+		if value, ok := s.baselines.Peek(key); ok {
+			file.Baselines = append(file.Baselines, fimBaselineRecord{
+				fimBaselineKey: key.(fimBaselineKey),
+				fimBaseline:    *value.(*fimBaseline),
+			})
+		}
+	}
+	s.Unlock()
+
+	content, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
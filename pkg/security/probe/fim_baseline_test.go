@@ -0,0 +1,287 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestFIMBaselineMonitor(t *testing.T) (*FIMBaselineMonitor, *[]*CustomEvent) {
+	m, err := NewFIMBaselineMonitor(&Probe{
+		config: &config.Config{
+			FIMBaselinePaths:         []string{"/etc/*.conf", "/usr/bin/**"},
+			FIMBaselineHashAlgorithm: "sha256",
+			FIMBaselineMaxBaselines:  2,
+			FIMBaselineMaxFileSize:   16,
+			FIMBaselineCheckPeriod:   time.Second,
+		},
+	})
+	require.NoError(t, err)
+
+	var events []*CustomEvent
+	m.dispatch = func(rule *rules.Rule, event *CustomEvent) {
+		assert.Equal(t, FileChangedRuleID, rule.ID)
+		events = append(events, event)
+	}
+	return m, &events
+}
+
+func newTestFIMBaselineRequest(t *testing.T, root string, path string, write bool) *fimBaselineRequest {
+	f, err := os.Open(root)
+	require.NoError(t, err)
+
+	return &fimBaselineRequest{
+		key:         fimBaselineKey{Scope: "nginx:1.23", Path: path},
+		root:        f,
+		write:       write,
+		pid:         42,
+		containerID: "c1",
+	}
+}
+
+func TestFIMBaselineMonitorPolicy(t *testing.T) {
+	m, _ := newTestFIMBaselineMonitor(t)
+
+	policies, err := m.LoadPolicies(nil)
+	assert.Nil(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, FIMBaselinePolicySource, policies[0].Source)
+	require.Len(t, policies[0].Rules, 2)
+	assert.Equal(t, `open.file.path in [~"/etc/*.conf", ~"/usr/bin/**"]`, policies[0].Rules[0].Expression)
+	assert.Equal(t, `rename.file.destination.path in [~"/etc/*.conf", ~"/usr/bin/**"]`, policies[0].Rules[1].Expression)
+
+	assert.True(t, m.matches("/etc/nginx.conf"))
+	assert.True(t, m.matches("/usr/bin/local/nginx"))
+	assert.False(t, m.matches("/etc/nginx/nginx.conf"))
+	assert.False(t, m.matches("/etc/passwd"))
+}
+
+func TestFIMBaselineMonitorFileChanged(t *testing.T) {
+	m, events := newTestFIMBaselineMonitor(t)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "etc"), 0755))
+	path := filepath.Join(root, "etc", "nginx.conf")
+	require.NoError(t, os.WriteFile(path, []byte("user nginx;"), 0644))
+
+	// first sight
+	m.handleRequest(newTestFIMBaselineRequest(t, root, "/etc/nginx.conf", false))
+	baseline, exists := m.store.get(fimBaselineKey{Scope: "nginx:1.23", Path: "/etc/nginx.conf"})
+	require.True(t, exists)
+	assert.Equal(t, sha256Hex("user nginx;"), baseline.Hash)
+
+	// the file is still open by its writer
+	fileOpen := true
+	m.isFileOpen = func(pid uint32, path string) bool {
+		assert.Equal(t, uint32(42), pid)
+		assert.Equal(t, "/etc/nginx.conf", path)
+		return fileOpen
+	}
+
+	request := newTestFIMBaselineRequest(t, root, "/etc/nginx.conf", true)
+	request.process = &ProcessContextSerializer{ProcessSerializer: &ProcessSerializer{Pid: 42}}
+	m.handleRequest(request)
+	require.NoError(t, os.WriteFile(path, []byte("user root;"), 0644))
+
+	m.checkPendingWrites()
+	assert.Empty(t, *events)
+	assert.Len(t, m.pending, 1)
+
+	// the writer closed the file
+	fileOpen = false
+	m.checkPendingWrites()
+	assert.Empty(t, m.pending)
+	require.Len(t, *events, 1)
+	assert.Equal(t, "file_changed", (*events)[0].GetType())
+
+	changed := (*events)[0].marshaler.(FileChangedEvent)
+	assert.Equal(t, "/etc/nginx.conf", changed.Path)
+	assert.Equal(t, "nginx:1.23", changed.Baseline)
+	assert.Equal(t, "sha256", changed.HashAlgorithm)
+	assert.Equal(t, sha256Hex("user nginx;"), changed.OldHash)
+	assert.Equal(t, sha256Hex("user root;"), changed.NewHash)
+	assert.Equal(t, uint32(42), changed.Process.Pid)
+
+	// the baseline is kept, and the same change isn't reported twice for a container
+	baseline, exists = m.store.get(fimBaselineKey{Scope: "nginx:1.23", Path: "/etc/nginx.conf"})
+	require.True(t, exists)
+	assert.Equal(t, sha256Hex("user nginx;"), baseline.Hash)
+	m.handleRequest(newTestFIMBaselineRequest(t, root, "/etc/nginx.conf", true))
+	m.checkPendingWrites()
+	assert.Len(t, *events, 1)
+
+	// a write restoring the baseline isn't reported
+	require.NoError(t, os.WriteFile(path, []byte("user nginx;"), 0644))
+	m.handleRequest(newTestFIMBaselineRequest(t, root, "/etc/nginx.conf", true))
+	m.checkPendingWrites()
+	assert.Len(t, *events, 1)
+
+	// files larger than the maximum size aren't hashed
+	require.NoError(t, os.WriteFile(path, []byte("user nginx nginx;"), 0644))
+	m.handleRequest(newTestFIMBaselineRequest(t, root, "/etc/nginx.conf", true))
+	m.checkPendingWrites()
+	assert.Len(t, *events, 1)
+}
+
+func TestFIMBaselineMonitorSameChangeInContainersOfImage(t *testing.T) {
+	m, events := newTestFIMBaselineMonitor(t)
+	m.isFileOpen = func(pid uint32, path string) bool { return false }
+
+	// two containers of the same image, with their own filesystem
+	var roots []string
+	for i := 0; i < 2; i++ {
+		root := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(root, "etc"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "nginx.conf"), []byte("user nginx;"), 0644))
+		roots = append(roots, root)
+	}
+	m.handleRequest(newTestFIMBaselineRequest(t, roots[0], "/etc/nginx.conf", false))
+
+	// both containers get the same modified content
+	for i, root := range roots {
+		require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "nginx.conf"), []byte("user root;"), 0644))
+		request := newTestFIMBaselineRequest(t, root, "/etc/nginx.conf", true)
+		request.containerID = fmt.Sprintf("c%d", i+1)
+		m.handleRequest(request)
+		m.checkPendingWrites()
+	}
+
+	require.Len(t, *events, 2)
+	for i, event := range *events {
+		changed := event.marshaler.(FileChangedEvent)
+		assert.Equal(t, fmt.Sprintf("c%d", i+1), changed.ContainerID)
+		assert.Equal(t, "nginx:1.23", changed.Baseline)
+		assert.Equal(t, sha256Hex("user nginx;"), changed.OldHash)
+		assert.Equal(t, sha256Hex("user root;"), changed.NewHash)
+	}
+}
+
+func TestFIMBaselineMonitorSymlink(t *testing.T) {
+	m, _ := newTestFIMBaselineMonitor(t)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "real.conf"), []byte("in root"), 0644))
+	// absolute symlinks are resolved from the root directory of the process
+	require.NoError(t, os.Symlink("/etc/real.conf", filepath.Join(root, "etc", "link.conf")))
+
+	f, err := os.Open(root)
+	require.NoError(t, err)
+	defer f.Close()
+
+	sum, err := m.hashFile(f, "/etc/link.conf")
+	require.NoError(t, err)
+	assert.Equal(t, sha256Hex("in root"), sum)
+}
+
+func TestFIMBaselineMonitorDeferredRequests(t *testing.T) {
+	m, _ := newTestFIMBaselineMonitor(t)
+	imageIDs := map[string]string{}
+	m.resolveScope = func(containerID string) string { return imageIDs[containerID] }
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "nginx.conf"), []byte("user nginx;"), 0644))
+
+	// the image of the containers isn't known yet: no baseline is created
+	now := time.Now()
+	for _, containerID := range []string{"c1", "c2"} {
+		request := newTestFIMBaselineRequest(t, root, "/etc/nginx.conf", false)
+		request.key.Scope = ""
+		request.containerID = containerID
+		m.handleRequest(request)
+	}
+	assert.Equal(t, 0, m.store.Len())
+	require.Len(t, m.deferred, 2)
+
+	// the request is handled once the image is resolved
+	imageIDs["c1"] = "sha256:0123"
+	m.handleDeferredRequests(now)
+	assert.Len(t, m.deferred, 1)
+	baseline, exists := m.store.get(fimBaselineKey{Scope: "sha256:0123", Path: "/etc/nginx.conf"})
+	require.True(t, exists)
+	assert.Equal(t, sha256Hex("user nginx;"), baseline.Hash)
+
+	// and dropped if it takes too long
+	m.handleDeferredRequests(now.Add(2 * fimBaselineDeferTimeout))
+	assert.Empty(t, m.deferred)
+	assert.Equal(t, uint64(1), m.dropped.Load())
+	assert.Equal(t, 1, m.store.Len())
+}
+
+func TestFIMBaselineMonitorBoundedWriteChecks(t *testing.T) {
+	m, _ := newTestFIMBaselineMonitor(t)
+	checks := 0
+	m.isFileOpen = func(pid uint32, path string) bool {
+		checks++
+		return true
+	}
+
+	root := t.TempDir()
+	for i := 0; i < fimBaselineMaxWriteChecks+10; i++ {
+		request := newTestFIMBaselineRequest(t, root, fmt.Sprintf("/etc/%d.conf", i), true)
+		m.pending[request.key] = request
+	}
+
+	m.checkPendingWrites()
+	assert.Equal(t, fimBaselineMaxWriteChecks, checks)
+	assert.Len(t, m.pending, fimBaselineMaxWriteChecks+10)
+}
+
+func TestFIMBaselineStore(t *testing.T) {
+	store, err := NewFIMBaselineStore(2, "sha256")
+	require.NoError(t, err)
+
+	store.set(fimBaselineKey{Scope: "host", Path: "/etc/a.conf"}, &fimBaseline{Hash: "a"})
+	store.set(fimBaselineKey{Scope: "host", Path: "/etc/b.conf"}, &fimBaseline{Hash: "b"})
+	_, _ = store.get(fimBaselineKey{Scope: "host", Path: "/etc/a.conf"})
+	store.set(fimBaselineKey{Scope: "host", Path: "/etc/c.conf"}, &fimBaseline{Hash: "c"})
+
+	// the least recently used baseline is evicted
+	assert.Equal(t, 2, store.Len())
+	_, exists := store.get(fimBaselineKey{Scope: "host", Path: "/etc/b.conf"})
+	assert.False(t, exists)
+
+	path := filepath.Join(t.TempDir(), "fim", "baselines.json")
+	require.NoError(t, store.Save(path))
+
+	loaded, err := NewFIMBaselineStore(2, "sha256")
+	require.NoError(t, err)
+	require.NoError(t, loaded.Load(path))
+	assert.Equal(t, 2, loaded.Len())
+	baseline, exists := loaded.get(fimBaselineKey{Scope: "host", Path: "/etc/a.conf"})
+	require.True(t, exists)
+	assert.Equal(t, "a", baseline.Hash)
+
+	// the baselines of another hash algorithm are ignored
+	other, err := NewFIMBaselineStore(2, "sha512")
+	require.NoError(t, err)
+	require.NoError(t, other.Load(path))
+	assert.Equal(t, 0, other.Len())
+
+	// a missing file isn't an error
+	require.NoError(t, other.Load(filepath.Join(t.TempDir(), "missing.json")))
+}
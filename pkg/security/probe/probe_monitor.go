@@ -32,6 +32,7 @@ type Monitor struct {
 	activityDumpManager *ActivityDumpManager
 	runtimeMonitor      *RuntimeMonitor
 	discarderMonitor    *DiscarderMonitor
+	fimBaselineMonitor  *FIMBaselineMonitor
 }
 
 // NewMonitor returns a new instance of a ProbeMonitor
@@ -60,6 +61,13 @@ func NewMonitor(p *Probe) (*Monitor, error) {
 		}
 	}
 
+	if p.config.FIMBaselineEnabled {
+		m.fimBaselineMonitor, err = NewFIMBaselineMonitor(p)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create the file integrity monitoring baseline: %w", err)
+		}
+	}
+
 	if p.config.RuntimeMonitor {
 		m.runtimeMonitor = NewRuntimeMonitor(p.statsdClient)
 	}
@@ -82,12 +90,20 @@ func (m *Monitor) GetActivityDumpManager() *ActivityDumpManager {
 	return m.activityDumpManager
 }
 
+// GetFIMBaselineMonitor returns the file integrity monitoring baseline
+func (m *Monitor) GetFIMBaselineMonitor() *FIMBaselineMonitor {
+	return m.fimBaselineMonitor
+}
+
 // Start triggers the goroutine of all the underlying controllers and monitors of the Monitor
 func (m *Monitor) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	delta := 1
 	if m.activityDumpManager != nil {
 		delta++
 	}
+	if m.fimBaselineMonitor != nil {
+		delta++
+	}
 	wg.Add(delta)
 
 	go m.loadController.Start(ctx, wg)
@@ -95,6 +111,10 @@ func (m *Monitor) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	if m.activityDumpManager != nil {
 		go m.activityDumpManager.Start(ctx, wg)
 	}
+
+	if m.fimBaselineMonitor != nil {
+		go m.fimBaselineMonitor.Run(ctx, wg)
+	}
 	return nil
 }
 
@@ -133,6 +153,12 @@ func (m *Monitor) SendStats() error {
 		}
 	}
 
+	if m.fimBaselineMonitor != nil {
+		if err := m.fimBaselineMonitor.SendStats(); err != nil {
+			return fmt.Errorf("failed to send file integrity monitoring baseline stats: %w", err)
+		}
+	}
+
 	if m.probe.config.RuntimeMonitor {
 		if err := m.runtimeMonitor.SendStats(); err != nil {
 			return fmt.Errorf("failed to send runtime monitor stats: %w", err)
@@ -159,6 +185,9 @@ func (m *Monitor) ProcessEvent(event *Event) {
 		if m.activityDumpManager != nil {
 			m.activityDumpManager.ProcessEvent(event)
 		}
+		if m.fimBaselineMonitor != nil {
+			m.fimBaselineMonitor.ProcessEvent(event)
+		}
 	}
 }
 
@@ -219,6 +248,14 @@ func (m *Monitor) DumpActivity(params *api.ActivityDumpParams) (*api.ActivityDum
 	return m.activityDumpManager.DumpActivity(params)
 }
 
+// DumpContainerActivity starts an activity dump of a container
+func (m *Monitor) DumpContainerActivity(containerID string, timeout time.Duration) error {
+	if !m.probe.config.ActivityDumpEnabled {
+		return ErrActivityDumpManagerDisabled
+	}
+	return m.activityDumpManager.DumpContainerActivity(containerID, timeout)
+}
+
 // ListActivityDumps returns the list of active dumps
 func (m *Monitor) ListActivityDumps(params *api.ActivityDumpListParams) (*api.ActivityDumpListMessage, error) {
 	if !m.probe.config.ActivityDumpEnabled {
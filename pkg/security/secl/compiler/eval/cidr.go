@@ -6,6 +6,7 @@
 package eval
 
 import (
+	"bytes"
 	"errors"
 	"net"
 	"strings"
@@ -31,6 +32,12 @@ func IPNetFromIP(ip net.IP) *net.IPNet {
 	}
 }
 
+// cidrPrefix indexes the networks of a set of CIDRs sharing the same mask
+type cidrPrefix struct {
+	mask     net.IPMask
+	networks map[string]bool
+}
+
 // CIDRValues describes a set of CIDRs
 type CIDRValues struct {
 	ipnets []*net.IPNet
@@ -39,6 +46,63 @@ type CIDRValues struct {
 	fieldValues []FieldValue
 
 	exists map[string]bool
+
+	// networks indexed by mask, so that looking up an IP doesn't depend on the number of CIDRs
+	prefixes []*cidrPrefix
+}
+
+// networkAndMask returns the network address and mask of an IPNet, IPv4 networks being converted to their 4 bytes
+// form the way net.IPNet.Contains does
+func networkAndMask(ipnet *net.IPNet) (net.IP, net.IPMask) {
+	ip, mask := ipnet.IP.To4(), ipnet.Mask
+	if ip == nil {
+		ip = ipnet.IP
+	}
+	if len(ip) == net.IPv4len && len(mask) == net.IPv6len {
+		mask = mask[12:]
+	}
+	if len(ip) != len(mask) {
+		return nil, nil
+	}
+	return ip.Mask(mask), mask
+}
+
+// appendIPNet adds an IPNet to the set and to its index
+func (c *CIDRValues) appendIPNet(ipnet *net.IPNet) {
+	c.ipnets = append(c.ipnets, ipnet)
+	c.fieldValues = append(c.fieldValues, FieldValue{Type: IPNetValueType, Value: *ipnet})
+
+	network, mask := networkAndMask(ipnet)
+	if network == nil {
+		// can't match any IP
+		return
+	}
+
+	for _, prefix := range c.prefixes {
+		if bytes.Equal(prefix.mask, mask) {
+			prefix.networks[string(network)] = true
+			return
+		}
+	}
+	c.prefixes = append(c.prefixes, &cidrPrefix{
+		mask:     mask,
+		networks: map[string]bool{string(network): true},
+	})
+}
+
+// containsIP returns whether an IP belongs to one of the networks of the set
+func (c *CIDRValues) containsIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	for _, prefix := range c.prefixes {
+		if len(prefix.mask) == len(ip) && prefix.networks[string(ip.Mask(prefix.mask))] {
+			return true
+		}
+	}
+
+	return false
 }
 
 // AppendCIDR append a CIDR notation
@@ -52,8 +116,7 @@ func (c *CIDRValues) AppendCIDR(cidr string) error {
 		return err
 	}
 
-	c.ipnets = append(c.ipnets, ipnet)
-	c.fieldValues = append(c.fieldValues, FieldValue{Type: IPNetValueType, Value: *ipnet})
+	c.appendIPNet(ipnet)
 
 	if c.exists == nil {
 		c.exists = make(map[string]bool)
@@ -100,8 +163,7 @@ func (c *CIDRValues) AppendIP(ip string) error {
 		return err
 	}
 
-	c.ipnets = append(c.ipnets, ipnet)
-	c.fieldValues = append(c.fieldValues, FieldValue{Type: IPNetValueType, Value: *ipnet})
+	c.appendIPNet(ipnet)
 
 	if c.exists == nil {
 		c.exists = make(map[string]bool)
@@ -113,6 +175,11 @@ func (c *CIDRValues) AppendIP(ip string) error {
 
 // Contains returns whether the values match the provided IPNet
 func (c *CIDRValues) Contains(ipnet *net.IPNet) bool {
+	// fast path for single IPs, looked up in the index
+	if ones, bits := ipnet.Mask.Size(); ones == bits && bits != 0 {
+		return c.containsIP(ipnet.IP)
+	}
+
 	for _, n := range c.ipnets {
 		if IPNetsMatch(n, ipnet) {
 			return true
@@ -124,11 +191,9 @@ func (c *CIDRValues) Contains(ipnet *net.IPNet) bool {
 
 // Match returns whether the values matches the provided IPNets
 func (c *CIDRValues) Match(ipnets []net.IPNet) bool {
-	for _, n := range c.ipnets {
-		for _, ipnet := range ipnets {
-			if IPNetsMatch(n, &ipnet) {
-				return true
-			}
+	for i := range ipnets {
+		if c.Contains(&ipnets[i]) {
+			return true
 		}
 	}
 
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"net"
+	"testing"
+)
+
+func TestCIDRValuesIndex(t *testing.T) {
+	var values CIDRValues
+	for _, value := range []string{"10.0.0.1", "10.0.0.2", "172.16.0.0/12", "2001:db8::/32", "::1"} {
+		if err := values.AppendIP(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := values.AppendCIDR("::ffff:192.168.0.0/120"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(values.prefixes) != 5 {
+		t.Errorf("expected 5 indexed masks, got %d", len(values.prefixes))
+	}
+
+	tests := []struct {
+		IP       string
+		Expected bool
+	}{
+		{IP: "10.0.0.1", Expected: true},
+		{IP: "10.0.0.3", Expected: false},
+		{IP: "::ffff:10.0.0.2", Expected: true},
+		{IP: "172.31.255.255", Expected: true},
+		{IP: "172.32.0.0", Expected: false},
+		{IP: "2001:db8:1::1", Expected: true},
+		{IP: "2001:db9::1", Expected: false},
+		{IP: "::1", Expected: true},
+		{IP: "192.168.0.42", Expected: true},
+		{IP: "192.168.1.42", Expected: false},
+	}
+
+	for _, test := range tests {
+		ipnet := IPNetFromIP(net.ParseIP(test.IP))
+		if result := values.Contains(ipnet); result != test.Expected {
+			t.Errorf("expected `%v` for %s, got `%v`", test.Expected, test.IP, result)
+		}
+
+		// the index must give the same results as comparing the networks one by one
+		var expected bool
+		for _, n := range values.ipnets {
+			if IPNetsMatch(n, ipnet) {
+				expected = true
+			}
+		}
+		if expected != test.Expected {
+			t.Errorf("index and scan disagree for %s", test.IP)
+		}
+	}
+
+	_, subnet, _ := net.ParseCIDR("172.20.0.0/16")
+	if !values.Contains(subnet) {
+		t.Error("expected the subnet to match")
+	}
+}
@@ -217,6 +217,29 @@ func arrayToEvaluator(array *ast.Array, state *State) (interface{}, lexer.Positi
 	return nil, array.Pos, NewError(array.Pos, "unknown array element type")
 }
 
+// stringValuesToCIDRValues converts a static list of IPs and CIDRs into CIDR values
+func stringValuesToCIDRValues(values *StringValuesEvaluator, pos lexer.Position) (*CIDRValuesEvaluator, error) {
+	if values.EvalFnc != nil {
+		return nil, NewError(pos, "only static lists can be matched against IPs")
+	}
+
+	var cidrs CIDRValues
+	for _, value := range values.Values.GetFieldValues() {
+		str, ok := value.Value.(string)
+		if !ok || value.Type != ScalarValueType {
+			return nil, NewError(pos, "invalid IP or CIDR '%v'", value.Value)
+		}
+		if err := cidrs.AppendIP(str); err != nil {
+			return nil, NewError(pos, "invalid IP or CIDR '%s'", str)
+		}
+	}
+
+	return &CIDRValuesEvaluator{
+		Value:     cidrs,
+		ValueType: IPNetValueType,
+	}, nil
+}
+
 func isVariableName(str string) (string, bool) {
 	if strings.HasPrefix(str, "${") && strings.HasSuffix(str, "}") {
 		return str[2 : len(str)-1], true
@@ -582,6 +605,13 @@ func nodeToEvaluator(obj interface{}, state *State) (interface{}, lexer.Position
 					return nil, pos, NewArrayTypeError(pos, reflect.Array, reflect.Int)
 				}
 			case *CIDREvaluator:
+				// lists of IPs and CIDRs, like the values macros, are matched as CIDRs
+				if values, ok := next.(*StringValuesEvaluator); ok {
+					if next, err = stringValuesToCIDRValues(values, pos); err != nil {
+						return nil, pos, err
+					}
+				}
+
 				switch nextCIDR := next.(type) {
 				case *CIDREvaluator:
 					nextIP, ok := next.(*CIDREvaluator)
@@ -636,6 +666,12 @@ func nodeToEvaluator(obj interface{}, state *State) (interface{}, lexer.Position
 					return nil, pos, NewCIDRTypeError(pos, reflect.Array, next)
 				}
 			case *CIDRArrayEvaluator:
+				if values, ok := next.(*StringValuesEvaluator); ok {
+					if next, err = stringValuesToCIDRValues(values, pos); err != nil {
+						return nil, pos, err
+					}
+				}
+
 				switch nextCIDR := next.(type) {
 				case *CIDRValuesEvaluator:
 					switch *obj.ArrayComparison.Op {
@@ -347,6 +347,8 @@ func TestStringMatcher(t *testing.T) {
 		{Expr: `process.name =~ r".*/[abc]+/bin/.*"`, Expected: false},
 		{Expr: `process.name == r".*/bin/.*"`, Expected: true},
 		{Expr: `r".*/bin/.*" == process.name`, Expected: true},
+		{Expr: `process.name in [ r"^/sbin/.*$", r"^/usr/bin/c.t$" ]`, Expected: true},
+		{Expr: `process.name in [ r"^/sbin/.*$", "/usr/bin/cat" ]`, Expected: false},
 		{Expr: `process.argv0 =~ "http://*"`, Expected: true},
 		{Expr: `process.argv0 =~ "*example.com"`, Expected: true},
 		{Expr: `open.filename == r"^((?:[A-Za-z\d+]{4})*(?:[A-Za-z\d+]{3}=|[A-Za-z\d+]{2}==)\.)*(([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\-]*[A-Za-z0-9])$"`, Expected: true},
@@ -608,6 +610,56 @@ func TestMacroList(t *testing.T) {
 	}
 }
 
+func TestMacroCIDRList(t *testing.T) {
+	model := &testModel{}
+	replCtx := newReplCtxWithParams(make(map[string]interface{}), nil)
+
+	macro, err := NewStringValuesMacro("ioc_ips", []string{"10.0.0.1", "192.168.0.0/16", "2001:db8::/32"}, replCtx.MacroStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replCtx.AddMacro(macro)
+
+	event := &testEvent{
+		network: testNetwork{
+			ip:  parseCIDR(t, "192.168.4.2"),
+			ips: []net.IPNet{parseCIDR(t, "127.0.0.1"), parseCIDR(t, "2001:db8::1")},
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `network.ip in ioc_ips`, Expected: true},
+		{Expr: `network.ip not in ioc_ips`, Expected: false},
+		{Expr: `10.0.0.1 in ioc_ips`, Expected: true},
+		{Expr: `10.0.0.2 in ioc_ips`, Expected: false},
+		{Expr: `network.ips in ioc_ips`, Expected: true},
+	}
+
+	for _, test := range tests {
+		rule, err := parseRule(test.Expr, model, replCtx)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result := rule.Eval(NewContext(unsafe.Pointer(event))); result != test.Expected {
+			t.Errorf("expected result `%v` not found, got `%v`, expression: %s", test.Expected, result, test.Expr)
+		}
+	}
+
+	macro, err = NewStringValuesMacro("not_ips", []string{"10.0.0.1", "example.com"}, replCtx.MacroStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replCtx.AddMacro(macro)
+
+	if _, err := parseRule(`network.ip in not_ips`, model, replCtx); err == nil {
+		t.Error("expected an error for a list holding values other than IPs")
+	}
+}
+
 func TestMacroExpression(t *testing.T) {
 	model := &testModel{}
 	replCtx := newReplCtxWithParams(make(map[string]interface{}), nil)
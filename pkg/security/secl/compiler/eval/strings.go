@@ -30,7 +30,9 @@ type StringValues struct {
 
 	// caches
 	scalarCache map[string]bool
-	fieldValues []FieldValue
+	// lowerScalarCache holds the lowercased scalar values compared case insensitively
+	lowerScalarCache map[string]bool
+	fieldValues      []FieldValue
 
 	exists map[interface{}]bool
 }
@@ -64,11 +66,18 @@ func (s *StringValues) AppendFieldValue(value FieldValue) {
 // Compile all the values
 func (s *StringValues) Compile(opts StringCmpOpts) error {
 	for _, value := range s.fieldValues {
-		// fast path for scalar value without specific comparison behavior
-		if opts == DefaultStringCmpOpts && value.Type == ScalarValueType {
+		// fast path for scalar values, looked up in a map whatever their number
+		if value.Type == ScalarValueType {
 			str := value.Value.(string)
 			s.scalars = append(s.scalars, str)
-			s.scalarCache[str] = true
+			if opts.ScalarCaseInsensitive {
+				if s.lowerScalarCache == nil {
+					s.lowerScalarCache = make(map[string]bool)
+				}
+				s.lowerScalarCache[strings.ToLower(str)] = true
+			} else {
+				s.scalarCache[str] = true
+			}
 		} else {
 			str, ok := value.Value.(string)
 			if !ok {
@@ -101,6 +110,7 @@ func (s *StringValues) SetFieldValues(values ...FieldValue) error {
 	// reset internal caches
 	s.stringMatchers = s.stringMatchers[:0]
 	s.scalarCache = nil
+	s.lowerScalarCache = nil
 	s.exists = nil
 
 	for _, value := range values {
@@ -120,6 +130,9 @@ func (s *StringValues) Matches(value string) bool {
 	if s.scalarCache != nil && s.scalarCache[value] {
 		return true
 	}
+	if len(s.lowerScalarCache) != 0 && s.lowerScalarCache[strings.ToLower(value)] {
+		return true
+	}
 	for _, pm := range s.stringMatchers {
 		if pm.Matches(value) {
 			return true
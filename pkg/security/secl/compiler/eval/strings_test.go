@@ -39,8 +39,16 @@ func TestStringValues(t *testing.T) {
 			t.Error("expected cache key found")
 		}
 
-		if len(values.stringMatchers) == 0 {
-			t.Error("should have a string matcher")
+		if !values.lowerScalarCache["test123"] {
+			t.Error("expected lowercase cache key not found")
+		}
+
+		if len(values.stringMatchers) != 0 {
+			t.Error("shouldn't have a string matcher")
+		}
+
+		if !values.Matches("TEST123") {
+			t.Error("should match")
 		}
 	})
 }
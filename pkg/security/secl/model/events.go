@@ -110,6 +110,14 @@ const (
 	CustomTruncatedParentsEventType
 	// CustomSelfTestEventType is the custom event used to report the results of a self test run
 	CustomSelfTestEventType
+	// CustomRuleActionEventType is the custom event used to report a response action of a rule
+	CustomRuleActionEventType
+	// CustomContainerQuarantineEventType is the custom event used to report that a container should be quarantined
+	CustomContainerQuarantineEventType
+	// CustomActivityDumpDriftEventType is the custom event used to report the activities of a workload missing from its baseline
+	CustomActivityDumpDriftEventType
+	// CustomFileChangedEventType is the custom event used to report that the content of a file changed from its baseline
+	CustomFileChangedEventType
 	// MaxAllEventType is used internally to get the maximum number of events.
 	MaxAllEventType
 )
@@ -205,6 +213,14 @@ func (t EventType) String() string {
 		return "truncated_parents"
 	case CustomSelfTestEventType:
 		return "self_test"
+	case CustomRuleActionEventType:
+		return "rule_action"
+	case CustomContainerQuarantineEventType:
+		return "container_quarantine"
+	case CustomActivityDumpDriftEventType:
+		return "activity_dump_drift"
+	case CustomFileChangedEventType:
+		return "file_changed"
 	default:
 		return "unknown"
 	}
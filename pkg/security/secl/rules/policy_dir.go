@@ -6,10 +6,13 @@
 package rules
 
 import (
+	"bufio"
 	"context"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/hashicorp/go-multierror"
@@ -27,6 +30,9 @@ type PoliciesDirProvider struct {
 	cancelFnc            func()
 	watcher              *fsnotify.Watcher
 	watchedFiles         []string
+
+	valuesFilesLock sync.RWMutex
+	valuesFiles     map[string]bool
 }
 
 // SetOnNewPoliciesReadyCb implements the policy provider interface
@@ -37,10 +43,36 @@ func (p *PoliciesDirProvider) SetOnNewPoliciesReadyCb(cb func()) {
 // Start starts the policy dir provider
 func (p *PoliciesDirProvider) Start() {}
 
-func (p *PoliciesDirProvider) loadPolicy(filename string, filters []RuleFilter) (*Policy, error) {
+// loadValuesFile reads the values of a macro from a file holding one value per line. Empty lines and lines starting
+// with `#` are ignored.
+func loadValuesFile(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		values = append(values, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// loadPolicy loads a policy file and the values files of its macros, returning the paths of the values files
+func (p *PoliciesDirProvider) loadPolicy(filename string, filters []RuleFilter) (*Policy, []string, error) {
 	f, err := os.Open(filename)
 	if err != nil {
-		return nil, &ErrPolicyLoad{Name: filename, Err: err}
+		return nil, nil, &ErrPolicyLoad{Name: filename, Err: err}
 	}
 	defer f.Close()
 
@@ -48,10 +80,70 @@ func (p *PoliciesDirProvider) loadPolicy(filename string, filters []RuleFilter)
 
 	policy, err := LoadPolicy(name, "file", f, filters)
 	if err != nil {
-		return nil, &ErrPolicyLoad{Name: name, Err: err}
+		return nil, nil, &ErrPolicyLoad{Name: name, Err: err}
+	}
+
+	var valuesFiles []string
+	for _, macro := range policy.Macros {
+		if macro.ValuesFile == "" {
+			continue
+		}
+
+		valuesFile := macro.ValuesFile
+		if !filepath.IsAbs(valuesFile) {
+			valuesFile = filepath.Join(p.PoliciesDir, valuesFile)
+		}
+
+		values, err := loadValuesFile(valuesFile)
+		if err != nil {
+			return nil, nil, &ErrPolicyLoad{Name: name, Err: &ErrMacroLoad{Definition: macro, Err: err}}
+		}
+		macro.Values = append(macro.Values, values...)
+		macro.ValuesFile = ""
+
+		valuesFiles = append(valuesFiles, valuesFile)
+	}
+
+	return policy, valuesFiles, nil
+}
+
+func (p *PoliciesDirProvider) isValuesFile(filename string) bool {
+	p.valuesFilesLock.RLock()
+	defer p.valuesFilesLock.RUnlock()
+
+	return p.valuesFiles[filename]
+}
+
+// watchValuesFiles replaces the list of watched values files. The files outside of the policy directory need their
+// own watch, the other ones are already watched with the directory.
+func (p *PoliciesDirProvider) watchValuesFiles(valuesFiles []string) *multierror.Error {
+	var errs *multierror.Error
+
+	p.valuesFilesLock.Lock()
+	defer p.valuesFilesLock.Unlock()
+
+	policiesDir := filepath.Clean(p.PoliciesDir)
+	for valuesFile := range p.valuesFiles {
+		if filepath.Dir(valuesFile) != policiesDir {
+			_ = p.watcher.Remove(valuesFile)
+		}
+	}
+
+	p.valuesFiles = make(map[string]bool)
+	for _, valuesFile := range valuesFiles {
+		if p.valuesFiles[valuesFile] {
+			continue
+		}
+		if filepath.Dir(valuesFile) != policiesDir {
+			if err := p.watcher.Add(valuesFile); err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+		}
+		p.valuesFiles[valuesFile] = true
 	}
 
-	return policy, nil
+	return errs
 }
 
 func (p *PoliciesDirProvider) getPolicyFiles() ([]string, error) {
@@ -103,12 +195,14 @@ func (p *PoliciesDirProvider) LoadPolicies(filters []RuleFilter) ([]*Policy, *mu
 	}
 
 	// Load and parse policies
+	var valuesFiles []string
 	for _, filename := range policyFiles {
-		policy, err := p.loadPolicy(filename, filters)
+		policy, policyValuesFiles, err := p.loadPolicy(filename, filters)
 		if err != nil {
 			errs = multierror.Append(errs, err)
 		} else {
 			policies = append(policies, policy)
+			valuesFiles = append(valuesFiles, policyValuesFiles...)
 
 			if p.watcher != nil {
 				if err := p.watcher.Add(filename); err != nil {
@@ -120,6 +214,12 @@ func (p *PoliciesDirProvider) LoadPolicies(filters []RuleFilter) ([]*Policy, *mu
 		}
 	}
 
+	if p.watcher != nil {
+		if err := p.watchValuesFiles(valuesFiles); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
 	return policies, errs
 }
 
@@ -160,10 +260,10 @@ func (p *PoliciesDirProvider) watch(ctx context.Context) {
 
 				if event.Op&(fsnotify.Create|fsnotify.Remove) > 0 {
 					files, _ := p.getPolicyFiles()
-					if !filesEqual(files, p.watchedFiles) {
+					if !filesEqual(files, p.watchedFiles) || p.isValuesFile(event.Name) {
 						p.onNewPoliciesReadyCb()
 					}
-				} else if event.Op&fsnotify.Write > 0 && filepath.Ext(event.Name) == policyExtension {
+				} else if event.Op&fsnotify.Write > 0 && (filepath.Ext(event.Name) == policyExtension || p.isValuesFile(event.Name)) {
 					p.onNewPoliciesReadyCb()
 				}
 			case _, ok := <-p.watcher.Errors:
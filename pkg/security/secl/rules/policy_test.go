@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/hashicorp/go-multierror"
@@ -764,3 +765,239 @@ func TestActionSetVariableInvalid(t *testing.T) {
 		}
 	})
 }
+
+func TestResponseActions(t *testing.T) {
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename == "/tmp/test"`,
+			Actions: []ActionDefinition{{
+				Kill: &KillDefinition{
+					Signal: "SIGTERM",
+				},
+				RateLimit: 5,
+			}, {
+				Quarantine: &QuarantineDefinition{
+					Reason: "miner",
+				},
+				DryRun: true,
+			}, {
+				ActivityDump: &ActivityDumpDefinition{
+					Timeout: "2m",
+				},
+			}},
+		}},
+	}
+
+	rs, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := rs.GetRules()["test_rule"].Definition.Actions
+	if assert.Len(t, actions, 3) {
+		assert.Equal(t, "SIGTERM", actions[0].Kill.GetSignal())
+		assert.Equal(t, 5, actions[0].RateLimit)
+		assert.Equal(t, "miner", actions[1].Quarantine.Reason)
+		assert.True(t, actions[1].DryRun)
+		assert.Equal(t, 2*time.Minute, actions[2].ActivityDump.GetTimeout())
+		for _, action := range actions {
+			assert.True(t, action.IsResponse())
+		}
+	}
+
+	event := &testEvent{kind: "open"}
+	event.open.filename = "/tmp/test"
+	assert.True(t, rs.Evaluate(event), "response actions shouldn't prevent the rule from matching")
+}
+
+func TestActionDefinitionCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		action ActionDefinition
+		valid  bool
+	}{
+		{name: "empty", action: ActionDefinition{}},
+		{name: "kill", action: ActionDefinition{Kill: &KillDefinition{}}, valid: true},
+		{name: "kill-signal", action: ActionDefinition{Kill: &KillDefinition{Signal: "SIGUSR1"}}, valid: true},
+		{name: "kill-unsupported-signal", action: ActionDefinition{Kill: &KillDefinition{Signal: "SIGCHLD"}}},
+		{name: "quarantine", action: ActionDefinition{Quarantine: &QuarantineDefinition{}, DryRun: true}, valid: true},
+		{name: "activity-dump", action: ActionDefinition{ActivityDump: &ActivityDumpDefinition{Timeout: "30s"}}, valid: true},
+		{name: "activity-dump-invalid-timeout", action: ActionDefinition{ActivityDump: &ActivityDumpDefinition{Timeout: "soon"}}},
+		{name: "activity-dump-long-timeout", action: ActionDefinition{ActivityDump: &ActivityDumpDefinition{Timeout: "1h"}}},
+		{name: "negative-rate-limit", action: ActionDefinition{Kill: &KillDefinition{}, RateLimit: -1}},
+		{name: "several-actions", action: ActionDefinition{Kill: &KillDefinition{}, Quarantine: &QuarantineDefinition{}}},
+		{name: "set-dry-run", action: ActionDefinition{Set: &SetDefinition{Name: "var1", Value: true}, DryRun: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.action.Check()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	assert.Equal(t, DefaultKillSignal, (&KillDefinition{}).GetSignal())
+	assert.Equal(t, DefaultActivityDumpActionTimeout, (&ActivityDumpDefinition{}).GetTimeout())
+}
+
+func TestMacroValuesFile(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "iocs.txt")
+	if err := os.WriteFile(valuesFile, []byte("# known bad files\n/tmp/miner\n\n  /tmp/backdoor  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename in ioc_files`,
+		}},
+		Macros: []*MacroDefinition{{
+			ID:         "ioc_files",
+			Values:     []string{"/tmp/dropper"},
+			ValuesFile: valuesFile,
+		}},
+	}
+
+	rs, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for filename, expected := range map[string]bool{
+		"/tmp/miner":    true,
+		"/tmp/backdoor": true,
+		"/tmp/dropper":  true,
+		"/tmp/test":     false,
+	} {
+		event := &testEvent{kind: "open"}
+		event.open.filename = filename
+		assert.Equal(t, expected, rs.Evaluate(event), filename)
+	}
+
+	testPolicy.Macros[0].ValuesFile = filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{}); err == nil {
+		t.Error("expected an error for a missing values file")
+	}
+
+	// values files can't be used outside of a policy directory
+	rs = newRuleSet()
+	if _, err := rs.AddMacro(&MacroDefinition{ID: "ioc_files", ValuesFile: valuesFile}); err == nil {
+		t.Error("expected an error for a values file outside of a policy directory")
+	}
+}
+
+type testRuleMatchCounter struct {
+	matches map[string]int
+}
+
+func (c *testRuleMatchCounter) RuleMatch(rule *Rule, event eval.Event) {
+	c.matches[rule.ID]++
+}
+
+func (c *testRuleMatchCounter) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+func TestRuleThreshold(t *testing.T) {
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename =~ "/tmp/*"`,
+			Threshold: &ThresholdDefinition{
+				Count:     3,
+				Period:    "10s",
+				GroupBy:   []string{"process.name"},
+				MaxGroups: 2,
+			},
+		}},
+	}
+
+	rs, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &testRuleMatchCounter{matches: make(map[string]int)}
+	rs.AddListener(counter)
+
+	now := time.Now()
+	rs.GetRules()["test_rule"].threshold.now = func() time.Time { return now }
+
+	open := func(processName string) {
+		event := &testEvent{kind: "open"}
+		event.open.filename = "/tmp/test"
+		event.process.name = processName
+		assert.True(t, rs.Evaluate(event), "events below the threshold still match")
+	}
+
+	open("/usr/bin/a")
+	open("/usr/bin/b")
+	open("/usr/bin/a")
+	assert.Equal(t, 0, counter.matches["test_rule"])
+
+	open("/usr/bin/a")
+	assert.Equal(t, 1, counter.matches["test_rule"], "the threshold of the group should be reached")
+
+	// the count starts over once the threshold is reached
+	open("/usr/bin/a")
+	assert.Equal(t, 1, counter.matches["test_rule"])
+
+	// matches older than the period are dropped
+	now = now.Add(11 * time.Second)
+	open("/usr/bin/b")
+	open("/usr/bin/b")
+	assert.Equal(t, 1, counter.matches["test_rule"])
+	open("/usr/bin/b")
+	assert.Equal(t, 2, counter.matches["test_rule"])
+
+	// only the most recent groups are tracked
+	open("/usr/bin/c")
+	open("/usr/bin/c")
+	open("/usr/bin/d")
+	open("/usr/bin/e")
+	open("/usr/bin/c")
+	assert.Equal(t, 2, counter.matches["test_rule"], "the group should have been evicted")
+}
+
+func TestThresholdDefinitionCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		threshold ThresholdDefinition
+		valid     bool
+	}{
+		{name: "valid", threshold: ThresholdDefinition{Count: 5, Period: "1m"}, valid: true},
+		{name: "no-count", threshold: ThresholdDefinition{Period: "1m"}},
+		{name: "count-too-high", threshold: ThresholdDefinition{Count: MaxThresholdCount + 1, Period: "1m"}},
+		{name: "no-period", threshold: ThresholdDefinition{Count: 5}},
+		{name: "negative-period", threshold: ThresholdDefinition{Count: 5, Period: "-1s"}},
+		{name: "too-many-groups", threshold: ThresholdDefinition{Count: 5, Period: "1m", MaxGroups: MaxThresholdMaxGroups + 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.threshold.Check()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	assert.Equal(t, DefaultThresholdMaxGroups, (&ThresholdDefinition{}).GetMaxGroups())
+
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename == "/tmp/test"`,
+			Threshold: &ThresholdDefinition{
+				Count:   3,
+				Period:  "10s",
+				GroupBy: []string{"unknown.field"},
+			},
+		}},
+	}
+	if _, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{}); err == nil {
+		t.Error("expected an error for an unknown group_by field")
+	}
+}
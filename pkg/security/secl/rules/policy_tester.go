@@ -0,0 +1,301 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+const testEventExtension = ".json"
+
+// TestEvent describes an event replayed by a policy tester, as the values of its fields
+type TestEvent struct {
+	Name   string                 `json:"name,omitempty"`
+	Type   eval.EventType         `json:"type"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// LoadTestEvents reads the events of a JSON file, holding either a single event or a list of events, or of
+// all the JSON files of a directory. Events without a name are named after their file, and their index in it.
+func LoadTestEvents(path string) ([]*TestEvent, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return loadTestEventsFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*TestEvent
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != testEventExtension {
+			continue
+		}
+
+		fileEvents, err := loadTestEventsFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+	}
+	return events, nil
+}
+
+func loadTestEventsFile(filename string) ([]*TestEvent, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := parseTestEvents(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse events of %s: %w", filename, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), testEventExtension)
+	for i, event := range events {
+		switch {
+		case event.Name != "":
+		case len(events) == 1:
+			event.Name = name
+		default:
+			event.Name = fmt.Sprintf("%s#%d", name, i)
+		}
+	}
+	return events, nil
+}
+
+func parseTestEvents(content []byte) ([]*TestEvent, error) {
+	decode := func(v interface{}) error {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		return decoder.Decode(v)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		var events []*TestEvent
+		if err := decode(&events); err != nil {
+			return nil, err
+		}
+		return events, nil
+	}
+
+	var event TestEvent
+	if err := decode(&event); err != nil {
+		return nil, err
+	}
+	return []*TestEvent{&event}, nil
+}
+
+// setEventValues sets the fields of an event from their JSON decoded values. The elements of an array are
+// set one after the other, as the setters of array fields append to them.
+func setEventValues(event eval.Event, values map[string]interface{}) error {
+	for field, value := range values {
+		elements, isArray := value.([]interface{})
+		if !isArray {
+			elements = []interface{}{value}
+		}
+
+		for _, element := range elements {
+			if number, ok := element.(json.Number); ok {
+				i, err := number.Int64()
+				if err != nil {
+					return fmt.Errorf("invalid value for field `%s`: %w", field, err)
+				}
+				element = int(i)
+			}
+			if err := event.SetFieldValue(field, element); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TestRuleMatch describes a rule triggered by a replayed event, with the values of the fields of its expression
+type TestRuleMatch struct {
+	RuleID RuleID                     `json:"rule_id"`
+	Fields map[eval.Field]interface{} `json:"fields,omitempty"`
+}
+
+// TestEventResult holds the rules triggered by a replayed event
+type TestEventResult struct {
+	Name    string           `json:"name"`
+	Type    eval.EventType   `json:"type"`
+	Matches []*TestRuleMatch `json:"matches,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// PolicyTestReport is the result of the replay of events by a policy tester
+type PolicyTestReport struct {
+	Events []*TestEventResult `json:"events"`
+}
+
+// MatchedEvents returns the names of the events that triggered a rule
+func (r *PolicyTestReport) MatchedEvents(id RuleID) []string {
+	names := []string{}
+	for _, result := range r.Events {
+		for _, match := range result.Matches {
+			if match.RuleID == id {
+				names = append(names, result.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// PolicyTester replays events against a rule set, without any probe, and reports the rules they trigger
+type PolicyTester struct {
+	ruleSet  *RuleSet
+	newEvent func(eventType eval.EventType) (eval.Event, error)
+
+	matches []*Rule
+}
+
+// NewPolicyTester returns a policy tester for a rule set. newEvent returns an empty event of the given type.
+func NewPolicyTester(rs *RuleSet, newEvent func(eventType eval.EventType) (eval.Event, error)) *PolicyTester {
+	pt := &PolicyTester{
+		ruleSet:  rs,
+		newEvent: newEvent,
+	}
+	rs.AddListener(pt)
+	return pt
+}
+
+// RuleMatch implements the RuleSetListener interface
+func (pt *PolicyTester) RuleMatch(rule *Rule, event eval.Event) {
+	pt.matches = append(pt.matches, rule)
+}
+
+// EventDiscarderFound implements the RuleSetListener interface
+func (pt *PolicyTester) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+// Run replays the events, in order, so that the variables set by the actions of a rule are seen by the next events
+func (pt *PolicyTester) Run(events []*TestEvent) *PolicyTestReport {
+	report := &PolicyTestReport{Events: make([]*TestEventResult, 0, len(events))}
+	for _, testEvent := range events {
+		report.Events = append(report.Events, pt.runEvent(testEvent))
+	}
+	return report
+}
+
+func (pt *PolicyTester) runEvent(testEvent *TestEvent) *TestEventResult {
+	result := &TestEventResult{Name: testEvent.Name, Type: testEvent.Type}
+
+	event, err := pt.newEvent(testEvent.Type)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := setEventValues(event, testEvent.Values); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	pt.matches = pt.matches[:0]
+	pt.ruleSet.Evaluate(event)
+
+	for _, rule := range pt.matches {
+		match := &TestRuleMatch{RuleID: rule.ID}
+		for _, field := range rule.GetFields() {
+			value, err := event.GetFieldValue(field)
+			if err != nil {
+				continue
+			}
+			if match.Fields == nil {
+				match.Fields = make(map[eval.Field]interface{})
+			}
+			match.Fields[field] = value
+		}
+		result.Matches = append(result.Matches, match)
+	}
+	sort.Slice(result.Matches, func(i, j int) bool {
+		return result.Matches[i].RuleID < result.Matches[j].RuleID
+	})
+
+	return result
+}
+
+// RuleExpectations maps rule IDs to the names of the events expected to trigger them. A rule listed
+// without events is expected to be triggered by none of them.
+type RuleExpectations map[RuleID][]string
+
+// LoadRuleExpectations reads rule expectations from YAML
+func LoadRuleExpectations(r io.Reader) (RuleExpectations, error) {
+	var expectations RuleExpectations
+	if err := yaml.NewDecoder(r).Decode(&expectations); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return expectations, nil
+}
+
+// Write writes the rule expectations as YAML
+func (e RuleExpectations) Write(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(e); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// NewRuleExpectations returns the expectations matching the result of a test, for all the rules of a rule set
+func NewRuleExpectations(rs *RuleSet, report *PolicyTestReport) RuleExpectations {
+	expectations := make(RuleExpectations)
+	for _, id := range rs.ListRuleIDs() {
+		expectations[id] = report.MatchedEvents(id)
+	}
+	return expectations
+}
+
+// Check returns an error for each rule that was not triggered by exactly the expected events
+func (e RuleExpectations) Check(rs *RuleSet, report *PolicyTestReport) *multierror.Error {
+	var errs *multierror.Error
+
+	ids := make([]RuleID, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if _, exists := rs.GetRules()[id]; !exists {
+			errs = multierror.Append(errs, fmt.Errorf("rule `%s` is not loaded", id))
+			continue
+		}
+
+		expected := append([]string{}, e[id]...)
+		sort.Strings(expected)
+		matched := report.MatchedEvents(id)
+		sort.Strings(matched)
+
+		if !reflect.DeepEqual(expected, matched) {
+			errs = multierror.Append(errs, fmt.Errorf("rule `%s` was expected to match %v, matched %v", id, expected, matched))
+		}
+	}
+
+	return errs
+}
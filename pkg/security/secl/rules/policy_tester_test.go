@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package rules
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+func newTestPolicyTester(t *testing.T) (*RuleSet, *PolicyTester) {
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "tmp_open",
+			Expression: `open.filename =~ "/tmp/*" && process.uid != 0`,
+		}, {
+			ID:         "root_mkdir",
+			Expression: `mkdir.filename =~ "/etc/*" && process.uid == 0`,
+			Actions: []ActionDefinition{{
+				Set: &SetDefinition{
+					Name:  "etc_mkdir",
+					Value: true,
+				},
+			}},
+		}, {
+			ID:         "open_after_mkdir",
+			Expression: `open.filename == "/etc/passwd" && ${etc_mkdir}`,
+		}},
+	}
+
+	rs, errs := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	if errs.ErrorOrNil() != nil {
+		t.Fatal(errs)
+	}
+
+	return rs, NewPolicyTester(rs, func(eventType eval.EventType) (eval.Event, error) {
+		return &testEvent{kind: eventType}, nil
+	})
+}
+
+func writeTestEvents(t *testing.T) string {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"open.json": `[
+			{"type": "open", "values": {"open.filename": "/tmp/a", "process.uid": 1000}},
+			{"type": "open", "values": {"open.filename": "/tmp/b", "process.uid": 0}},
+			{"name": "passwd", "type": "open", "values": {"open.filename": "/etc/passwd"}}
+		]`,
+		"mkdir.json":   `{"Type": "mkdir", "Values": {"mkdir.filename": "/etc/cron.d", "process.uid": 0}}`,
+		"invalid.json": `{"type": "open", "values": {"open.unknown": "value"}}`,
+		"README.md":    "not an event",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadTestEvents(t *testing.T) {
+	dir := writeTestEvents(t)
+
+	events, err := LoadTestEvents(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, event := range events {
+		names = append(names, event.Name)
+	}
+	if expected := []string{"invalid", "mkdir", "open#0", "open#1", "passwd"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected events %v, got %v", expected, names)
+	}
+
+	events, err = LoadTestEvents(filepath.Join(dir, "mkdir.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != "mkdir" || events[0].Values["mkdir.filename"] != "/etc/cron.d" {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+}
+
+func TestPolicyTester(t *testing.T) {
+	rs, pt := newTestPolicyTester(t)
+
+	events, err := LoadTestEvents(writeTestEvents(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the open of /etc/passwd only matches once the mkdir event set the variable
+	passwd := events[4]
+	report := pt.Run([]*TestEvent{passwd})
+	if matches := report.Events[0].Matches; len(matches) != 0 {
+		t.Errorf("expected no match before the mkdir event, got %+v", matches)
+	}
+
+	report = pt.Run(events)
+	if len(report.Events) != len(events) {
+		t.Fatalf("expected %d results, got %d", len(events), len(report.Events))
+	}
+
+	if report.Events[0].Error == "" {
+		t.Error("expected an error for an unknown field")
+	}
+
+	open := report.Events[2]
+	if len(open.Matches) != 1 || open.Matches[0].RuleID != "tmp_open" {
+		t.Fatalf("expected open#0 to match tmp_open, got %+v", open.Matches)
+	}
+	expectedFields := map[eval.Field]interface{}{"open.filename": "/tmp/a", "process.uid": 1000}
+	if !reflect.DeepEqual(expectedFields, open.Matches[0].Fields) {
+		t.Errorf("expected matched fields %v, got %v", expectedFields, open.Matches[0].Fields)
+	}
+
+	for id, expected := range map[RuleID][]string{
+		"tmp_open":         {"open#0"},
+		"root_mkdir":       {"mkdir"},
+		"open_after_mkdir": {"passwd"},
+	} {
+		if matched := report.MatchedEvents(id); !reflect.DeepEqual(expected, matched) {
+			t.Errorf("expected %s to match %v, got %v", id, expected, matched)
+		}
+	}
+
+	expectations := NewRuleExpectations(rs, report)
+	if errs := expectations.Check(rs, report); errs.ErrorOrNil() != nil {
+		t.Errorf("expected the generated expectations to pass: %s", errs)
+	}
+}
+
+func TestRuleExpectations(t *testing.T) {
+	rs, pt := newTestPolicyTester(t)
+
+	events, err := LoadTestEvents(writeTestEvents(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := pt.Run(events)
+
+	expectations, err := LoadRuleExpectations(strings.NewReader(`
+tmp_open: [open#0, open#1]
+root_mkdir: []
+unknown_rule: []
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := expectations.Check(rs, report)
+	if errs == nil || len(errs.Errors) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+
+	var b bytes.Buffer
+	if err := NewRuleExpectations(rs, report).Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	written, err := LoadRuleExpectations(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := written.Check(rs, report); errs.ErrorOrNil() != nil {
+		t.Errorf("expected the written expectations to pass: %s", errs)
+	}
+}
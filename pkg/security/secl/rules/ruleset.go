@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/cast"
@@ -36,6 +37,7 @@ type MacroDefinition struct {
 	ID         MacroID       `yaml:"id"`
 	Expression string        `yaml:"expression"`
 	Values     []string      `yaml:"values"`
+	ValuesFile string        `yaml:"values_file"`
 	Combine    CombinePolicy `yaml:"combine"`
 }
 
@@ -66,15 +68,16 @@ type RuleID = string
 
 // RuleDefinition holds the definition of a rule
 type RuleDefinition struct {
-	ID                     RuleID             `yaml:"id"`
-	Version                string             `yaml:"version"`
-	Expression             string             `yaml:"expression"`
-	Description            string             `yaml:"description"`
-	Tags                   map[string]string  `yaml:"tags"`
-	AgentVersionConstraint string             `yaml:"agent_version"`
-	Disabled               bool               `yaml:"disabled"`
-	Combine                CombinePolicy      `yaml:"combine"`
-	Actions                []ActionDefinition `yaml:"actions"`
+	ID                     RuleID               `yaml:"id"`
+	Version                string               `yaml:"version"`
+	Expression             string               `yaml:"expression"`
+	Description            string               `yaml:"description"`
+	Tags                   map[string]string    `yaml:"tags"`
+	AgentVersionConstraint string               `yaml:"agent_version"`
+	Disabled               bool                 `yaml:"disabled"`
+	Combine                CombinePolicy        `yaml:"combine"`
+	Actions                []ActionDefinition   `yaml:"actions"`
+	Threshold              *ThresholdDefinition `yaml:"threshold"`
 	Policy                 *Policy
 }
 
@@ -103,28 +106,75 @@ func (rd *RuleDefinition) MergeWith(rd2 *RuleDefinition) error {
 	return nil
 }
 
-// ActionDefinition describes a rule action section
+// ActionDefinition describes a rule action section. Each section holds exactly one action.
 type ActionDefinition struct {
-	Set *SetDefinition `yaml:"set"`
+	Set          *SetDefinition          `yaml:"set"`
+	Kill         *KillDefinition         `yaml:"kill"`
+	Quarantine   *QuarantineDefinition   `yaml:"quarantine"`
+	ActivityDump *ActivityDumpDefinition `yaml:"activity_dump"`
+
+	// DryRun reports the response action without taking it
+	DryRun bool `yaml:"dry_run"`
+	// RateLimit is the maximum number of times per minute the response action is taken, 0 meaning the default
+	RateLimit int `yaml:"rate_limit"`
 }
 
 // Check returns an error if the action in invalid
 func (a *ActionDefinition) Check() error {
-	if a.Set == nil {
-		return errors.New("missing 'set' section in action")
+	var count int
+	for _, defined := range []bool{a.Set != nil, a.Kill != nil, a.Quarantine != nil, a.ActivityDump != nil} {
+		if defined {
+			count++
+		}
+	}
+	switch {
+	case count == 0:
+		return errors.New("missing 'set', 'kill', 'quarantine' or 'activity_dump' section in action")
+	case count > 1:
+		return errors.New("only one of 'set', 'kill', 'quarantine' or 'activity_dump' can be specified per action")
 	}
 
-	if a.Set.Name == "" {
-		return errors.New("action name is empty")
+	if a.RateLimit < 0 {
+		return errors.New("'rate_limit' can't be negative")
 	}
 
-	if (a.Set.Value == nil && a.Set.Field == "") || (a.Set.Value != nil && a.Set.Field != "") {
-		return errors.New("either 'value' or 'field' must be specified")
+	switch {
+	case a.Set != nil:
+		if a.DryRun || a.RateLimit != 0 {
+			return errors.New("'dry_run' and 'rate_limit' are only supported by response actions")
+		}
+
+		if a.Set.Name == "" {
+			return errors.New("action name is empty")
+		}
+
+		if (a.Set.Value == nil && a.Set.Field == "") || (a.Set.Value != nil && a.Set.Field != "") {
+			return errors.New("either 'value' or 'field' must be specified")
+		}
+	case a.Kill != nil:
+		if a.Kill.Signal != "" && !killSignals[a.Kill.Signal] {
+			return fmt.Errorf("unsupported signal '%s'", a.Kill.Signal)
+		}
+	case a.ActivityDump != nil:
+		if a.ActivityDump.Timeout != "" {
+			timeout, err := time.ParseDuration(a.ActivityDump.Timeout)
+			if err != nil {
+				return fmt.Errorf("invalid activity dump timeout: %w", err)
+			}
+			if timeout <= 0 || timeout > MaxActivityDumpActionTimeout {
+				return fmt.Errorf("activity dump timeout must be positive and at most %s", MaxActivityDumpActionTimeout)
+			}
+		}
 	}
 
 	return nil
 }
 
+// IsResponse returns whether the action is a response action, taken by the agent outside of the rule set
+func (a *ActionDefinition) IsResponse() bool {
+	return a.Kill != nil || a.Quarantine != nil || a.ActivityDump != nil
+}
+
 // Scope describes the scope variables
 type Scope string
 
@@ -137,10 +187,67 @@ type SetDefinition struct {
 	Scope  Scope       `yaml:"scope"`
 }
 
+const (
+	// DefaultKillSignal is the signal sent by a kill action that doesn't specify one
+	DefaultKillSignal = "SIGKILL"
+	// DefaultActivityDumpActionTimeout is the duration of the activity dumps triggered by an action that doesn't specify one
+	DefaultActivityDumpActionTimeout = time.Minute
+	// MaxActivityDumpActionTimeout is the maximum duration of the activity dumps triggered by an action
+	MaxActivityDumpActionTimeout = 10 * time.Minute
+)
+
+// killSignals are the signals a kill action can send
+var killSignals = map[string]bool{
+	"SIGKILL": true,
+	"SIGTERM": true,
+	"SIGINT":  true,
+	"SIGQUIT": true,
+	"SIGHUP":  true,
+	"SIGABRT": true,
+	"SIGSTOP": true,
+	"SIGUSR1": true,
+	"SIGUSR2": true,
+}
+
+// KillDefinition describes the 'kill' section of a rule action, which sends a signal to the process of the event
+type KillDefinition struct {
+	Signal string `yaml:"signal"`
+}
+
+// GetSignal returns the signal to send
+func (k *KillDefinition) GetSignal() string {
+	if k.Signal == "" {
+		return DefaultKillSignal
+	}
+	return k.Signal
+}
+
+// QuarantineDefinition describes the 'quarantine' section of a rule action, which reports the container of the
+// event as to be quarantined
+type QuarantineDefinition struct {
+	Reason string `yaml:"reason"`
+}
+
+// ActivityDumpDefinition describes the 'activity_dump' section of a rule action, which dumps the activity of the
+// container of the event
+type ActivityDumpDefinition struct {
+	Timeout string `yaml:"timeout"`
+}
+
+// GetTimeout returns the duration of the activity dump
+func (d *ActivityDumpDefinition) GetTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(d.Timeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return DefaultActivityDumpActionTimeout
+}
+
 // Rule describes a rule of a ruleset
 type Rule struct {
 	*eval.Rule
 	Definition *RuleDefinition
+
+	threshold *thresholdTracker
 }
 
 // RuleSetListener describes the methods implemented by an object used to be
@@ -232,6 +339,8 @@ func (rs *RuleSet) AddMacro(macroDef *MacroDefinition) (*eval.Macro, error) {
 	macro := &Macro{Definition: macroDef}
 
 	switch {
+	case macroDef.ValuesFile != "":
+		return nil, &ErrMacroLoad{Definition: macroDef, Err: errors.New("'values_file' is only supported by the policies of a policy directory")}
 	case macroDef.Expression != "" && len(macroDef.Values) > 0:
 		return nil, &ErrMacroLoad{Definition: macroDef, Err: errors.New("only one of 'expression' and 'values' can be defined")}
 	case macroDef.Expression != "":
@@ -328,6 +437,26 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
 	}
 
+	if ruleDef.Threshold != nil {
+		if err := ruleDef.Threshold.Check(); err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+		}
+
+		for _, field := range ruleDef.Threshold.GroupBy {
+			if _, found := rs.fieldEvaluators[field]; !found {
+				evaluator, err := rs.model.GetEvaluator(field, "")
+				if err != nil {
+					return nil, &ErrRuleLoad{Definition: ruleDef, Err: fmt.Errorf("invalid threshold group_by field: %w", err)}
+				}
+				rs.fieldEvaluators[field] = evaluator
+			}
+		}
+
+		if rule.threshold, err = newThresholdTracker(ruleDef.Threshold); err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+		}
+	}
+
 	// ignore event types not supported
 	if _, exists := rs.opts.EventTypeEnabled["*"]; !exists {
 		if _, exists := rs.opts.EventTypeEnabled[eventType]; !exists {
@@ -535,10 +664,17 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 
 	for _, rule := range bucket.rules {
 		if rule.GetEvaluator().Eval(ctx) {
+			// the event matches, don't look for discarders even if the threshold isn't reached yet
+			result = true
+
+			if rule.threshold != nil && !rule.threshold.hit(rule.threshold.groupKey(ctx, rs.fieldEvaluators)) {
+				rs.logger.Tracef("Rule `%s` matches with event `%s` below its threshold\n", rule.ID, event)
+				continue
+			}
+
 			rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
 
 			rs.NotifyRuleMatch(rule, event)
-			result = true
 
 			if err := rs.runRuleActions(ctx, rule); err != nil {
 				rs.logger.Errorf("Error while executing rule actions: %s", err)
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+const (
+	// MaxThresholdCount is the maximum number of matches a threshold can require
+	MaxThresholdCount = 1000
+	// DefaultThresholdMaxGroups is the number of groups tracked by a threshold that doesn't specify one
+	DefaultThresholdMaxGroups = 1000
+	// MaxThresholdMaxGroups is the maximum number of groups a threshold can track
+	MaxThresholdMaxGroups = 100000
+)
+
+// ThresholdDefinition describes the 'threshold' section of a rule: the rule is only triggered once its expression
+// matched `count` events within `period`, the matches being counted separately for each value of the `group_by`
+// fields. Only the `max_groups` most recently matched groups are tracked.
+type ThresholdDefinition struct {
+	Count     int      `yaml:"count"`
+	Period    string   `yaml:"period"`
+	GroupBy   []string `yaml:"group_by"`
+	MaxGroups int      `yaml:"max_groups"`
+}
+
+// Check returns an error if the threshold is invalid
+func (t *ThresholdDefinition) Check() error {
+	if t.Count < 1 || t.Count > MaxThresholdCount {
+		return fmt.Errorf("threshold count must be between 1 and %d", MaxThresholdCount)
+	}
+
+	period, err := time.ParseDuration(t.Period)
+	if err != nil {
+		return fmt.Errorf("invalid threshold period: %w", err)
+	}
+	if period <= 0 {
+		return errors.New("threshold period must be positive")
+	}
+
+	if t.MaxGroups < 0 || t.MaxGroups > MaxThresholdMaxGroups {
+		return fmt.Errorf("threshold max_groups must be between 0 and %d", MaxThresholdMaxGroups)
+	}
+
+	return nil
+}
+
+// GetPeriod returns the period of the threshold
+func (t *ThresholdDefinition) GetPeriod() time.Duration {
+	period, _ := time.ParseDuration(t.Period)
+	return period
+}
+
+// GetMaxGroups returns the maximum number of groups tracked by the threshold
+func (t *ThresholdDefinition) GetMaxGroups() int {
+	if t.MaxGroups == 0 {
+		return DefaultThresholdMaxGroups
+	}
+	return t.MaxGroups
+}
+
+// thresholdTracker counts the matches of a rule, per group. The memory used is bounded by the maximum number of
+// groups and the count of the threshold.
+type thresholdTracker struct {
+	sync.Mutex
+
+	count   int
+	period  time.Duration
+	groupBy []string
+	// groups holds the timestamps of the matches within the period, per group
+	groups *simplelru.LRU
+	now    func() time.Time
+}
+
+func newThresholdTracker(def *ThresholdDefinition) (*thresholdTracker, error) {
+	groups, err := simplelru.NewLRU(def.GetMaxGroups(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &thresholdTracker{
+		count:   def.Count,
+		period:  def.GetPeriod(),
+		groupBy: def.GroupBy,
+		groups:  groups,
+		now:     time.Now,
+	}, nil
+}
+
+// groupKey returns the key of the group of an event
+func (t *thresholdTracker) groupKey(ctx *eval.Context, evaluators map[string]eval.Evaluator) string {
+	if len(t.groupBy) == 0 {
+		return ""
+	}
+
+	values := make([]string, 0, len(t.groupBy))
+	for _, field := range t.groupBy {
+		if evaluator := evaluators[field]; evaluator != nil {
+			values = append(values, fmt.Sprintf("%v", evaluator.Eval(ctx)))
+		}
+	}
+	return strings.Join(values, "\x00")
+}
+
+// hit records a match of the rule and returns whether the threshold is reached, in which case the count of the
+// group starts over
+func (t *thresholdTracker) hit(key string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	now := t.now()
+
+	var matches []time.Time
+	if value, ok := t.groups.Get(key); ok {
+		matches = value.([]time.Time)
+	}
+
+	// drop the matches that left the period
+	expired := 0
+	for expired < len(matches) && now.Sub(matches[expired]) >= t.period {
+		expired++
+	}
+	matches = append(matches[expired:], now)
+
+	if len(matches) >= t.count {
+		t.groups.Remove(key)
+		return true
+	}
+
+	t.groups.Add(key, matches)
+	return false
+}
@@ -217,6 +217,8 @@ func complianceResult(result string) string {
 		return fmt.Sprintf("[%s]", color.RedString("FAILED"))
 	case "passed":
 		return fmt.Sprintf("[%s]", color.GreenString("PASSED"))
+	case "waived":
+		return fmt.Sprintf("[%s]", color.YellowString("WAIVED"))
 	default:
 		return fmt.Sprintf("[%s]", color.YellowString("UNKNOWN"))
 	}
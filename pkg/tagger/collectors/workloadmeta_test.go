@@ -598,6 +598,7 @@ func TestHandleContainer(t *testing.T) {
 					OrchestratorCardTags: []string{},
 					LowCardTags: append([]string{
 						"docker_image:datadog/agent:latest",
+						"image_id:datadog/agent@sha256:a63d3f66fb2f69d955d4f2ca0b229385537a77872ffc04290acae65aed5317d2",
 						"image_name:datadog/agent",
 						"image_tag:latest",
 						"short_image:agent",
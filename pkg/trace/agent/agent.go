@@ -33,6 +33,15 @@ const (
 	tagHostname = "_dd.hostname"
 )
 
+// Names of the samplers reported along with the sampling decision of each chunk.
+const (
+	samplerPriority   = "priority"
+	samplerErrors     = "errors"
+	samplerRare       = "rare"
+	samplerNoPriority = "no_priority"
+	samplerUserDrop   = "user_drop"
+)
+
 // Agent struct holds all the sub-routines structs and make the data flow between them
 type Agent struct {
 	Receiver              *api.HTTPReceiver
@@ -41,6 +50,7 @@ type Agent struct {
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
 	Replacer              *filters.Replacer
+	ResourceNormalizer    *filters.ResourceNormalizer
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	RareSampler           *sampler.RareSampler
@@ -86,6 +96,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		ClientStatsAggregator: stats.NewClientStatsAggregator(conf, statsChan),
 		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:              filters.NewReplacer(conf.ReplaceTags),
+		ResourceNormalizer:    filters.NewResourceNormalizer(conf.ResourceNormalization),
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:         sampler.NewErrorsSampler(conf),
 		RareSampler:           sampler.NewRareSampler(conf),
@@ -263,6 +274,7 @@ func (a *Agent) Process(p *api.Payload) {
 			}
 		}
 		a.Replacer.Replace(chunk.Spans)
+		a.ResourceNormalizer.Normalize(chunk.Spans)
 
 		{
 			// this section sets up any necessary tags on the root:
@@ -303,7 +315,10 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, pt)
 		}
 
-		numEvents, keep, filteredChunk := a.sample(now, ts, pt)
+		numEvents, keep, filteredChunk, decider := a.sample(now, ts, pt)
+		if inspector := a.Receiver.Inspector; inspector != nil {
+			inspector.Record(now, p.TracerPayload, chunk, root, keep, decider)
+		}
 		if !keep {
 			if numEvents == 0 {
 				// the trace was dropped and no analyzed span were kept
@@ -399,6 +414,7 @@ func (a *Agent) processStats(in pb.ClientStatsPayload, lang, tracerVersion strin
 			}
 			a.obfuscateStatsGroup(&b)
 			a.Replacer.ReplaceStatsGroup(&b)
+			a.ResourceNormalizer.NormalizeStatsGroup(&b)
 			group.Stats[n] = b
 			n++
 		}
@@ -430,8 +446,9 @@ func (a *Agent) ProcessStats(in pb.ClientStatsPayload, lang, tracerVersion strin
 	a.ClientStatsAggregator.In <- a.processStats(in, lang, tracerVersion)
 }
 
-// sample reports the number of events found in pt and whether the chunk should be kept as a trace.
-func (a *Agent) sample(now time.Time, ts *info.TagStats, pt traceutil.ProcessedTrace) (numEvents int64, keep bool, filteredChunk *pb.TraceChunk) {
+// sample reports the number of events found in pt and whether the chunk should be kept as a trace,
+// along with the name of the sampler which took the decision.
+func (a *Agent) sample(now time.Time, ts *info.TagStats, pt traceutil.ProcessedTrace) (numEvents int64, keep bool, filteredChunk *pb.TraceChunk, decider string) {
 	priority, hasPriority := sampler.GetSamplingPriority(pt.TraceChunk)
 
 	if hasPriority {
@@ -441,10 +458,10 @@ func (a *Agent) sample(now time.Time, ts *info.TagStats, pt traceutil.ProcessedT
 	}
 
 	if priority < 0 {
-		return 0, false, nil
+		return 0, false, nil, samplerUserDrop
 	}
 
-	sampled := a.runSamplers(now, pt, hasPriority)
+	sampled, decider := a.runSamplers(now, pt, hasPriority)
 
 	filteredChunk = pt.TraceChunk
 	if !sampled {
@@ -457,12 +474,12 @@ func (a *Agent) sample(now time.Time, ts *info.TagStats, pt traceutil.ProcessedT
 	ts.EventsExtracted.Add(numExtracted)
 	ts.EventsSampled.Add(numEvents)
 
-	return numEvents, sampled, filteredChunk
+	return numEvents, sampled, filteredChunk, decider
 }
 
 // runSamplers runs all the agent's samplers on pt and returns the sampling decision
-// along with the sampling rate.
-func (a *Agent) runSamplers(now time.Time, pt traceutil.ProcessedTrace, hasPriority bool) bool {
+// along with the name of the sampler which took it.
+func (a *Agent) runSamplers(now time.Time, pt traceutil.ProcessedTrace, hasPriority bool) (bool, string) {
 	if hasPriority {
 		return a.samplePriorityTrace(now, pt)
 	}
@@ -472,7 +489,7 @@ func (a *Agent) runSamplers(now time.Time, pt traceutil.ProcessedTrace, hasPrior
 // samplePriorityTrace samples traces with priority set on them. PrioritySampler and
 // ErrorSampler are run in parallel. The RareSampler catches traces with rare top-level
 // or measured spans that are not caught by PrioritySampler and ErrorSampler.
-func (a *Agent) samplePriorityTrace(now time.Time, pt traceutil.ProcessedTrace) bool {
+func (a *Agent) samplePriorityTrace(now time.Time, pt traceutil.ProcessedTrace) (bool, string) {
 	var rare bool
 	if a.conf.RareSamplerDisabled {
 		rare = false
@@ -481,21 +498,24 @@ func (a *Agent) samplePriorityTrace(now time.Time, pt traceutil.ProcessedTrace)
 		rare = a.RareSampler.Sample(now, pt.TraceChunk, pt.TracerEnv)
 	}
 	if a.PrioritySampler.Sample(now, pt.TraceChunk, pt.Root, pt.TracerEnv, pt.ClientDroppedP0sWeight) {
-		return true
+		return true, samplerPriority
 	}
 	if traceContainsError(pt.TraceChunk.Spans) {
-		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)
+		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), samplerErrors
+	}
+	if rare {
+		return true, samplerRare
 	}
-	return rare
+	return false, samplerPriority
 }
 
 // sampleNoPriorityTrace samples traces with no priority set on them. The traces
 // get sampled by either the score sampler or the error sampler if they have an error.
-func (a *Agent) sampleNoPriorityTrace(now time.Time, pt traceutil.ProcessedTrace) bool {
+func (a *Agent) sampleNoPriorityTrace(now time.Time, pt traceutil.ProcessedTrace) (bool, string) {
 	if traceContainsError(pt.TraceChunk.Spans) {
-		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)
+		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), samplerErrors
 	}
-	return a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)
+	return a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), samplerNoPriority
 }
 
 func traceContainsError(trace pb.Trace) bool {
@@ -27,6 +27,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/event"
 	"github.com/DataDog/datadog-agent/pkg/trace/filters"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
@@ -103,6 +104,36 @@ func TestProcess(t *testing.T) {
 		assert.Equal("SELECT name FROM people WHERE age = ? AND extra = ?", span.Meta["sql.query"])
 	})
 
+	t.Run("ResourceNormalizer", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.ResourceNormalization.Enabled = true
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewAgent(ctx, cfg)
+		defer cancel()
+
+		now := time.Now()
+		span := &pb.Span{
+			TraceID:  1,
+			SpanID:   1,
+			Service:  "web",
+			Resource: "GET /users/12345/orders/987",
+			Type:     "web",
+			Meta:     map[string]string{"http.url": "http://localhost/users/12345/orders/987"},
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (500 * time.Millisecond).Nanoseconds(),
+		}
+
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpan(span)),
+			Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+		})
+
+		assert := assert.New(t)
+		assert.Equal("GET /users/?/orders/?", span.Resource)
+		assert.Equal("http://localhost/users/?/orders/?", span.Meta["http.url"])
+	})
+
 	t.Run("Blacklister", func(t *testing.T) {
 		cfg := config.New()
 		cfg.Endpoints[0].APIKey = "test"
@@ -393,6 +424,37 @@ func TestProcess(t *testing.T) {
 		// without missing a trace
 		assert.Equal(t, gotCount, 3)
 	})
+
+	t.Run("Inspector", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.DebugTracesBufferSize = 10
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewAgent(ctx, cfg)
+		defer cancel()
+
+		dropped := testutil.TraceChunkWithSpanAndPriority(&pb.Span{TraceID: 1, SpanID: 1, Service: "web", Resource: "GET /"}, -1)
+		kept := testutil.TraceChunkWithSpanAndPriority(&pb.Span{TraceID: 2, SpanID: 2, Service: "db", Resource: "SELECT 1", Error: 1}, 2)
+		go agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunks([]*pb.TraceChunk{dropped, kept}),
+			Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+		})
+		select {
+		case <-agnt.TraceWriter.In:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out")
+		}
+
+		chunks := agnt.Receiver.Inspector.Find(inspect.Filter{})
+		require.Len(t, chunks, 2)
+		assert.EqualValues(t, 2, chunks[0].TraceID)
+		assert.True(t, chunks[0].Sampled)
+		assert.True(t, chunks[0].Error)
+		assert.Equal(t, samplerPriority, chunks[0].Sampler)
+		assert.EqualValues(t, 1, chunks[1].TraceID)
+		assert.False(t, chunks[1].Sampled)
+		assert.Equal(t, samplerUserDrop, chunks[1].Sampler)
+	})
 }
 
 func spansToChunk(spans ...*pb.Span) *pb.TraceChunk {
@@ -931,7 +993,7 @@ func TestSampling(t *testing.T) {
 			a := configureAgent(tt.agentConfig)
 			for _, tc := range tt.testCases {
 				_, hasPriority := sampler.GetSamplingPriority(tc.trace.TraceChunk)
-				sampled := a.runSamplers(time.Now(), tc.trace, hasPriority)
+				sampled, _ := a.runSamplers(time.Now(), tc.trace, hasPriority)
 				assert.EqualValues(t, tc.wantSampled, sampled)
 			}
 		})
@@ -1544,7 +1606,7 @@ func TestSampleWithPriorityNone(t *testing.T) {
 	defer cancel()
 
 	span := testutil.RandomSpan()
-	numEvents, keep, _ := agnt.sample(time.Now(), info.NewReceiverStats().GetTagStats(info.Tags{}), traceutil.ProcessedTrace{
+	numEvents, keep, _, _ := agnt.sample(time.Now(), info.NewReceiverStats().GetTagStats(info.Tags{}), traceutil.ProcessedTrace{
 		TraceChunk: testutil.TraceChunkWithSpan(span),
 		Root:       span,
 	})
@@ -79,6 +79,21 @@ func FuzzObfuscateSpan(f *testing.F) {
 			Resource: "http://mysite.mydomain/1/2?q=asd",
 			Meta:     map[string]string{"http.url": "http://mysite.mydomain/1/2?q=asd"},
 		},
+		{
+			Type:     "graphql",
+			Resource: "query",
+			Meta:     map[string]string{"graphql.query": `query { user(id: 42) { name } }`, "graphql.variables.id": "42"},
+		},
+		{
+			Type:     "dynamodb",
+			Resource: "DynamoDB.Query",
+			Meta:     map[string]string{"aws.request.params.ExpressionAttributeValues": `{":id": {"S": "1234"}}`},
+		},
+		{
+			Type:     "kafka",
+			Resource: "Produce Topic orders",
+			Meta:     map[string]string{"messaging.message.body": "secret"},
+		},
 	}
 	for _, span := range seedCorpus {
 		span, err := encode(span)
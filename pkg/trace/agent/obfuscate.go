@@ -10,6 +10,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/obfuscate"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/config/features"
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
@@ -21,11 +22,19 @@ const (
 	tagMongoDBQuery     = "mongodb.query"
 	tagElasticBody      = "elasticsearch.body"
 	tagSQLQuery         = "sql.query"
+	tagDBType           = "db.type"
 	tagHTTPURL          = "http.url"
+	tagGraphQLQuery     = "graphql.query"
+
+	// prefixGraphQLVariables is the prefix of the tags holding GraphQL variable values.
+	prefixGraphQLVariables = "graphql.variables."
+	// prefixAWSRequestParams is the prefix of the tags holding AWS SDK request parameters.
+	prefixAWSRequestParams = "aws.request.params."
 )
 
 const (
-	textNonParsable = "Non-parsable SQL query"
+	textNonParsable        = "Non-parsable SQL query"
+	textNonParsableGraphQL = "Non-parsable GraphQL query"
 )
 
 func (a *Agent) obfuscateSpan(span *pb.Span) {
@@ -35,7 +44,7 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 		if span.Resource == "" {
 			return
 		}
-		oq, err := o.ObfuscateSQLString(span.Resource)
+		oq, err := o.ObfuscateSQLStringForDBMS(span.Resource, spanDBMS(span))
 		if err != nil {
 			// we have an error, discard the SQL to avoid polluting user resources.
 			log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
@@ -54,6 +63,9 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 		if len(oq.Metadata.TablesCSV) > 0 {
 			traceutil.SetMeta(span, "sql.tables", oq.Metadata.TablesCSV)
 		}
+		if len(oq.Metadata.Procedures) > 0 {
+			traceutil.SetMeta(span, "sql.procedures", strings.Join(oq.Metadata.Procedures, ","))
+		}
 		if span.Meta != nil && span.Meta[tagSQLQuery] != "" {
 			// "sql.query" tag already set by user, do not change it.
 			return
@@ -97,14 +109,87 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 			return
 		}
 		span.Meta[tagElasticBody] = o.ObfuscateElasticSearchString(v)
+	case "graphql":
+		if a.conf.Obfuscation.GraphQL.Enabled {
+			a.obfuscateGraphQLSpan(span)
+		}
+	case "aws", "dynamodb":
+		if a.conf.Obfuscation.AWS.Enabled {
+			for k, v := range span.Meta {
+				if strings.HasPrefix(k, prefixAWSRequestParams) {
+					span.Meta[k] = o.ObfuscateAWSRequestParam(strings.TrimPrefix(k, prefixAWSRequestParams), v)
+				}
+			}
+		}
+	case "queue", "kafka", "amqp":
+		if a.conf.Obfuscation.Message.Enabled {
+			o.ObfuscateMessageTags(span.Meta)
+		}
+	}
+}
+
+// obfuscateGraphQLSpan obfuscates the "graphql.query" tag and the values of all
+// "graphql.variables.*" tags of the given span.
+func (a *Agent) obfuscateGraphQLSpan(span *pb.Span) {
+	if span.Meta == nil {
+		return
+	}
+	for k, v := range span.Meta {
+		if strings.HasPrefix(k, prefixGraphQLVariables) && v != "" {
+			span.Meta[k] = "?"
+		}
+	}
+	v, ok := span.Meta[tagGraphQLQuery]
+	if !ok || v == "" {
+		return
+	}
+	oq, err := a.obfuscator.ObfuscateGraphQLString(v)
+	if err != nil {
+		// we have an error, discard the query to avoid leaking sensitive data.
+		log.Debugf("Error parsing GraphQL query: %v", err)
+		oq = textNonParsableGraphQL
+	}
+	span.Meta[tagGraphQLQuery] = oq
+}
+
+// dbmsByDBType maps the values of the "db.type" tag, as set by tracers and by the OpenTelemetry
+// "db.system" convention, to the database management systems known by the obfuscator.
+var dbmsByDBType = map[string]string{
+	"mysql":      obfuscate.DBMSMySQL,
+	"mariadb":    obfuscate.DBMSMySQL,
+	"postgres":   obfuscate.DBMSPostgres,
+	"postgresql": obfuscate.DBMSPostgres,
+	"mssql":      obfuscate.DBMSSQLServer,
+	"sqlserver":  obfuscate.DBMSSQLServer,
+	"oracle":     obfuscate.DBMSOracle,
+}
+
+// dbmsForDBType returns the database management system that SQL queries should be obfuscated for,
+// given the value of a "db.type" tag. It returns an empty string if the dialect is unknown or if
+// the "sql_dialects" feature is not enabled, in which case the generic tokenizer is used.
+func dbmsForDBType(dbType string) string {
+	if !features.Has("sql_dialects") {
+		return ""
+	}
+	return dbmsByDBType[strings.ToLower(dbType)]
+}
+
+// spanDBMS returns the database management system that the SQL query of span should be obfuscated
+// for, based on its "db.type" tag.
+func spanDBMS(span *pb.Span) string {
+	if span.Meta == nil {
+		return ""
 	}
+	return dbmsForDBType(span.Meta[tagDBType])
 }
 
 func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 	o := a.obfuscator
 	switch b.Type {
 	case "sql", "cassandra":
-		oq, err := o.ObfuscateSQLString(b.Resource)
+		// use the same dialect as for spans, so that client-computed stats and spans
+		// of the same query end up with the same resource.
+		oq, err := o.ObfuscateSQLStringForDBMS(b.Resource, dbmsForDBType(b.DBType))
 		if err != nil {
 			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
 			b.Resource = textNonParsable
@@ -220,6 +220,89 @@ func TestObfuscateConfig(t *testing.T) {
 		"set key 0 0 0 noreply\r\nvalue",
 		&config.ObfuscationConfig{},
 	))
+
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(id: 42, name: "Jim") { id } }`,
+		`query { user(id: ? name: ?) { id } }`,
+		&config.ObfuscationConfig{GraphQL: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("graphql/invalid", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(name: "Jim) { id } }`,
+		textNonParsableGraphQL,
+		&config.ObfuscationConfig{GraphQL: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("graphql/variables", testConfig(
+		"graphql",
+		"graphql.variables.id",
+		"42",
+		"?",
+		&config.ObfuscationConfig{GraphQL: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query { user(id: 42) { id } }`,
+		`query { user(id: 42) { id } }`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("aws/enabled", testConfig(
+		"aws",
+		"aws.request.params.ExpressionAttributeValues",
+		`{":id": {"S": "1234"}}`,
+		`{":id":{"S":"?"}}`,
+		&config.ObfuscationConfig{AWS: config.AWSObfuscationConfig{Enabled: true}},
+	))
+
+	t.Run("aws/keep", testConfig(
+		"dynamodb",
+		"aws.request.params.TableName",
+		"users",
+		"users",
+		&config.ObfuscationConfig{AWS: config.AWSObfuscationConfig{Enabled: true}},
+	))
+
+	t.Run("aws/disabled", testConfig(
+		"aws",
+		"aws.request.params.MessageBody",
+		"secret",
+		"secret",
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("message/enabled", testConfig(
+		"kafka",
+		"messaging.message.body",
+		"secret",
+		"?",
+		&config.ObfuscationConfig{Message: config.MessageObfuscationConfig{Enabled: true}},
+	))
+
+	t.Run("message/configured", testConfig(
+		"amqp",
+		"amqp.headers.x-user",
+		"jim",
+		"?",
+		&config.ObfuscationConfig{Message: config.MessageObfuscationConfig{
+			Enabled:    true,
+			RedactTags: []string{"amqp.headers.*"},
+		}},
+	))
+
+	t.Run("message/disabled", testConfig(
+		"queue",
+		"messaging.message.body",
+		"secret",
+		"secret",
+		&config.ObfuscationConfig{},
+	))
 }
 
 func SQLSpan(query string) *pb.Span {
@@ -331,3 +414,86 @@ func TestSQLTableNames(t *testing.T) {
 		assert.Empty(t, span.Meta["sql.tables"])
 	})
 }
+
+func TestSQLProcedures(t *testing.T) {
+	t.Run("on", func(t *testing.T) {
+		defer testutil.WithFeatures("sql_procedures")()
+		span := &pb.Span{
+			Resource: "CALL update_user(42, 'Jim')",
+			Type:     "sql",
+		}
+		agnt, stop := agentWithDefaults()
+		defer stop()
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "CALL update_user ( ? )", span.Resource)
+		assert.Equal(t, "update_user", span.Meta["sql.procedures"])
+	})
+
+	t.Run("off", func(t *testing.T) {
+		span := &pb.Span{
+			Resource: "CALL update_user(42, 'Jim')",
+			Type:     "sql",
+		}
+		agnt, stop := agentWithDefaults()
+		defer stop()
+		agnt.obfuscateSpan(span)
+		assert.Empty(t, span.Meta["sql.procedures"])
+	})
+}
+
+func TestSQLDialect(t *testing.T) {
+	const query = "SELECT * FROM [dbo].[users] WHERE [id] = 42"
+
+	t.Run("disabled", func(t *testing.T) {
+		agnt, stop := agentWithDefaults()
+		defer stop()
+		span := &pb.Span{
+			Resource: query,
+			Type:     "sql",
+			Meta:     map[string]string{"db.type": "sqlserver"},
+		}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "SELECT * FROM [ dbo ] . [ users ] WHERE [ id ] = ?", span.Resource)
+	})
+
+	t.Run("span", func(t *testing.T) {
+		defer testutil.WithFeatures("sql_dialects")()
+		agnt, stop := agentWithDefaults()
+		defer stop()
+		for dbType, out := range map[string]string{
+			"sqlserver": "SELECT * FROM [dbo].[users] WHERE [id] = ?",
+			"MSSQL":     "SELECT * FROM [dbo].[users] WHERE [id] = ?",
+			"mysql":     "SELECT * FROM [ dbo ] . [ users ] WHERE [ id ] = ?",
+			"":          "SELECT * FROM [ dbo ] . [ users ] WHERE [ id ] = ?",
+		} {
+			span := &pb.Span{
+				Resource: query,
+				Type:     "sql",
+				Meta:     map[string]string{"db.type": dbType},
+			}
+			agnt.obfuscateSpan(span)
+			assert.Equal(t, out, span.Resource, dbType)
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		defer testutil.WithFeatures("sql_dialects")()
+		agnt, stop := agentWithDefaults()
+		defer stop()
+		for _, q := range []struct{ dbType, query string }{
+			{"sqlserver", query},
+			{"mysql", "SELECT * FROM `db`.`users` WHERE `id` = 42"},
+			{"", query},
+		} {
+			span := &pb.Span{
+				Resource: q.query,
+				Type:     "sql",
+				Meta:     map[string]string{"db.type": q.dbType},
+			}
+			agnt.obfuscateSpan(span)
+			b := &pb.ClientGroupedStats{Type: "sql", DBType: q.dbType, Resource: q.query}
+			agnt.obfuscateStatsGroup(b)
+			assert.Equal(t, span.Resource, b.Resource, q.dbType)
+		}
+	})
+}
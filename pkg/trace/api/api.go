@@ -35,6 +35,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/config/features"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
@@ -70,6 +71,10 @@ type HTTPReceiver struct {
 	Stats       *info.ReceiverStats
 	RateLimiter *rateLimiter
 
+	// Inspector records recently received traces for local inspection on the
+	// debug server. It is nil when disabled.
+	Inspector *inspect.Recorder
+
 	out            chan *Payload
 	conf           *config.AgentConfig
 	dynConf        *sampler.DynamicConfig
@@ -96,9 +101,14 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 	if err != nil {
 		log.Errorf("Could not instantiate AppSec: %v", err)
 	}
+	var inspector *inspect.Recorder
+	if conf.DebugTracesBufferSize > 0 {
+		inspector = inspect.NewRecorder(conf.DebugTracesBufferSize)
+	}
 	return &HTTPReceiver{
 		Stats:       info.NewReceiverStats(),
 		RateLimiter: newRateLimiter(),
+		Inspector:   inspector,
 
 		out:            out,
 		statsProcessor: statsProcessor,
@@ -246,6 +256,15 @@ func (r *HTTPReceiver) attachDebugHandlers(mux *http.ServeMux) {
 		w.Header().Set("Access-Control-Allow-Origin", "http://127.0.0.1:"+r.conf.GUIPort)
 		expvar.Handler().ServeHTTP(w, req)
 	}))
+
+	inspector := http.Handler(r.Inspector)
+	if r.Inspector == nil {
+		inspector = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "trace inspection is disabled, set apm_config.debug_traces.buffer_size to enable it", http.StatusNotFound)
+		})
+	}
+	mux.Handle(inspect.PathPrefix, inspector)
+	mux.Handle(inspect.PathPrefix+"/", inspector)
 }
 
 // listenUnix returns a net.Listener listening on the given "unix" socket path.
@@ -478,6 +478,9 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 	if in.Events().Len() > 0 {
 		setMetaOTLP(span, "events", marshalEvents(in.Events()))
 	}
+	if in.Links().Len() > 0 {
+		span.SpanLinks = convertLinks(in.Links())
+	}
 	in.Attributes().Range(func(k string, v pcommon.Value) bool {
 		switch v.Type() {
 		case pcommon.ValueTypeDouble:
@@ -511,6 +514,10 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 		setMetaOTLP(span, semconv.OtelStatusDescription, msg)
 	}
 	status2Error(in.Status(), in.Events(), span)
+	mapping := &o.conf.OTLPReceiver.AttributesMapping
+	if span.Name == "" {
+		span.Name = mappedAttribute(span, mapping.OperationName)
+	}
 	if span.Name == "" {
 		name := in.Name()
 		if !o.conf.OTLPReceiver.SpanNameAsResourceName {
@@ -526,6 +533,9 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 		}
 		span.Name = name
 	}
+	if span.Service == "" {
+		span.Service = mappedAttribute(span, mapping.Service)
+	}
 	if span.Service == "" {
 		if svc := span.Meta[string(semconv.AttributePeerService)]; svc != "" {
 			span.Service = svc
@@ -535,6 +545,9 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 			span.Service = "OTLPResourceNoServiceName"
 		}
 	}
+	if span.Resource == "" {
+		span.Resource = mappedAttribute(span, mapping.Resource)
+	}
 	if span.Resource == "" {
 		if r := resourceFromTags(span.Meta); r != "" {
 			span.Resource = r
@@ -545,9 +558,57 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 	if span.Type == "" {
 		span.Type = spanKind2Type(in.Kind(), span)
 	}
+	switch in.Kind() {
+	case ptrace.SpanKindClient, ptrace.SpanKindProducer, ptrace.SpanKindConsumer:
+		for tag, keys := range mapping.PeerTags {
+			if v := mappedAttribute(span, keys); v != "" {
+				setMetaOTLP(span, tag, v)
+			}
+		}
+	}
 	return span
 }
 
+// mappedAttribute returns the value of the first of the given attributes which is set on span,
+// either as a tag or as a metric. It returns an empty string if none is set.
+func mappedAttribute(span *pb.Span, keys []string) string {
+	for _, k := range keys {
+		if v := span.Meta[k]; v != "" {
+			return v
+		}
+		if v, ok := span.Metrics[k]; ok {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// convertLinks converts the given OTLP span links into Datadog span links. Their flags are left unset: they were
+// added to the OTLP protocol (v1.1.0) after the version decoded by the pdata module in use (v0.56.0), which drops
+// them, and need a pdata upgrade to be copied from link.Flags().
+func convertLinks(links ptrace.SpanLinkSlice) []*pb.SpanLink {
+	out := make([]*pb.SpanLink, links.Len())
+	for i := 0; i < links.Len(); i++ {
+		l := links.At(i)
+		traceID := l.TraceID().Bytes()
+		link := &pb.SpanLink{
+			TraceID:     traceIDToUint64(traceID),
+			TraceIDHigh: binary.BigEndian.Uint64(traceID[:8]),
+			SpanID:      spanIDToUint64(l.SpanID().Bytes()),
+			Tracestate:  string(l.TraceState()),
+		}
+		if l.Attributes().Len() > 0 {
+			link.Attributes = make(map[string]string, l.Attributes().Len())
+			l.Attributes().Range(func(k string, v pcommon.Value) bool {
+				link.Attributes[k] = v.AsString()
+				return true
+			})
+		}
+		out[i] = link
+	}
+	return out
+}
+
 // resourceFromTags attempts to deduce a more accurate span resource from the given list of tags meta.
 // If this is not possible, it returns an empty string.
 func resourceFromTags(meta map[string]string) string {
@@ -152,6 +152,144 @@ func TestOTLPNameRemapping(t *testing.T) {
 	}
 }
 
+func TestOTLPAttributesMapping(t *testing.T) {
+	cfg := config.New()
+	cfg.OTLPReceiver.AttributesMapping = config.OTLPAttributesMapping{
+		Service:       []string{"app.name", "service.name"},
+		Resource:      []string{"app.route"},
+		OperationName: []string{"app.operation"},
+		PeerTags: map[string][]string{
+			"peer.hostname": {"net.peer.name"},
+			"peer.port":     {"net.peer.port"},
+		},
+	}
+	out := make(chan *Payload, 1)
+	rcv := NewOTLPReceiver(out, cfg)
+	rcv.ReceiveResourceSpans(testutil.NewOTLPTracesRequest([]testutil.OTLPResourceSpan{
+		{
+			LibName:    "libname",
+			LibVersion: "1.2",
+			Attributes: map[string]interface{}{
+				"service.name": "resource-service",
+				"app.route":    "/resource/route",
+			},
+			Spans: []*testutil.OTLPSpan{
+				{
+					SpanID: [8]byte{1},
+					Name:   "mapped",
+					Kind:   ptrace.SpanKindClient,
+					Attributes: map[string]interface{}{
+						"app.name":      "span-service",
+						"app.route":     "/span/route",
+						"app.operation": "span.operation",
+						"net.peer.name": "db.host",
+						"net.peer.port": 5432,
+					},
+				},
+				{
+					SpanID: [8]byte{2},
+					Name:   "resource-mapped",
+					Kind:   ptrace.SpanKindServer,
+					Attributes: map[string]interface{}{
+						"net.peer.name": "client.host",
+					},
+				},
+				{
+					SpanID: [8]byte{3},
+					Name:   "overridden",
+					Kind:   ptrace.SpanKindProducer,
+					Attributes: map[string]interface{}{
+						"app.name":       "span-service",
+						"service.name":   "explicit-service",
+						"resource.name":  "explicit-resource",
+						"operation.name": "explicit.operation",
+					},
+				},
+			},
+		},
+	}).Traces().ResourceSpans().At(0), http.Header{}, "")
+	var spans map[uint64]*pb.Span
+	select {
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timed out")
+	case p := <-out:
+		spans = make(map[uint64]*pb.Span)
+		for _, s := range p.TracerPayload.Chunks[0].Spans {
+			spans[s.SpanID>>56] = s
+		}
+	}
+	assert := assert.New(t)
+
+	// span attributes take precedence over resource attributes
+	assert.Equal("span-service", spans[1].Service)
+	assert.Equal("/span/route", spans[1].Resource)
+	assert.Equal("span.operation", spans[1].Name)
+	assert.Equal("db.host", spans[1].Meta["peer.hostname"])
+	assert.Equal("5432", spans[1].Meta["peer.port"])
+
+	// falls back to resource attributes and default rules
+	assert.Equal("resource-service", spans[2].Service)
+	assert.Equal("/resource/route", spans[2].Resource)
+	assert.Equal("libname.server", spans[2].Name)
+	assert.NotContains(spans[2].Meta, "peer.hostname", "peer tags are only set on outgoing spans")
+
+	// explicit Datadog attributes take precedence over the mapping
+	assert.Equal("explicit-service", spans[3].Service)
+	assert.Equal("explicit-resource", spans[3].Resource)
+	assert.Equal("explicit.operation", spans[3].Name)
+}
+
+func TestOTLPSpanLinks(t *testing.T) {
+	out := make(chan *Payload, 1)
+	rcv := NewOTLPReceiver(out, config.New())
+	rcv.ReceiveResourceSpans(testutil.NewOTLPTracesRequest([]testutil.OTLPResourceSpan{
+		{
+			LibName:    "libname",
+			LibVersion: "1.2",
+			Attributes: map[string]interface{}{},
+			Spans: []*testutil.OTLPSpan{
+				{
+					Name: "consume",
+					Kind: ptrace.SpanKindConsumer,
+					Links: []testutil.OTLPSpanLink{
+						{
+							TraceID:    [16]byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 2},
+							SpanID:     [8]byte{0, 0, 0, 0, 0, 0, 0, 3},
+							TraceState: "dd=s:1",
+							Attributes: map[string]interface{}{"messaging.operation": "publish", "messaging.batch.size": 2},
+							Dropped:    1,
+						},
+						{
+							TraceID: [16]byte{15: 4},
+							SpanID:  [8]byte{7: 5},
+						},
+					},
+				},
+				{Name: "unlinked"},
+			},
+		},
+	}).Traces().ResourceSpans().At(0), http.Header{}, "")
+	select {
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timed out")
+	case p := <-out:
+		spans := p.TracerPayload.Chunks[0].Spans
+		require.Len(t, spans, 2)
+		assert.Equal(t, []*pb.SpanLink{
+			{
+				TraceID:     2,
+				TraceIDHigh: 1,
+				SpanID:      3,
+				Tracestate:  "dd=s:1",
+				Attributes:  map[string]string{"messaging.operation": "publish", "messaging.batch.size": "2"},
+			},
+			{TraceID: 4, SpanID: 5},
+		}, spans[0].SpanLinks)
+		assert.Nil(t, spans[1].SpanLinks)
+		assert.NotContains(t, spans[0].Meta, "links")
+	}
+}
+
 func TestOTLPReceiveResourceSpans(t *testing.T) {
 	cfg := config.New()
 	out := make(chan *Payload, 1)
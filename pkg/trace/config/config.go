@@ -56,6 +56,10 @@ type OTLP struct {
 	// SpanNameAsResourceName uses the OTLP span name as the Datadog resource name.
 	SpanNameAsResourceName bool `mapstructure:"span_name_as_resource_name"`
 
+	// AttributesMapping specifies which OpenTelemetry attributes are used to compute
+	// the Datadog span service, resource, operation name and peer tags.
+	AttributesMapping OTLPAttributesMapping `mapstructure:"attributes_mapping"`
+
 	// MaxRequestBytes specifies the maximum number of bytes that will be read
 	// from an incoming HTTP request.
 	MaxRequestBytes int64 `mapstructure:"-"`
@@ -67,6 +71,25 @@ type OTLP struct {
 	UsePreviewHostnameLogic bool `mapstructure:"-"`
 }
 
+// OTLPAttributesMapping specifies which OpenTelemetry attributes are used to compute Datadog
+// span fields. For each field, the first of the listed attributes which is set on the span or
+// on its resource is used, span attributes taking precedence over resource attributes. When
+// none is set, the field is computed using the default OTLP ingest rules.
+type OTLPAttributesMapping struct {
+	// Service lists the attributes used as the span service, e.g. ["service.name"].
+	Service []string `mapstructure:"service"`
+
+	// Resource lists the attributes used as the span resource, e.g. ["http.route", "rpc.method"].
+	Resource []string `mapstructure:"resource"`
+
+	// OperationName lists the attributes used as the span operation name.
+	OperationName []string `mapstructure:"operation_name"`
+
+	// PeerTags maps Datadog peer tags to the attributes used to set them on client, producer
+	// and consumer spans, e.g. {"peer.hostname": ["net.peer.name", "server.address"]}.
+	PeerTags map[string][]string `mapstructure:"peer_tags"`
+}
+
 // ObfuscationConfig holds the configuration for obfuscating sensitive data
 // for various span types.
 type ObfuscationConfig struct {
@@ -98,6 +121,18 @@ type ObfuscationConfig struct {
 	// for spans of type "memcached".
 	Memcached Enablable `mapstructure:"memcached"`
 
+	// GraphQL holds the configuration for obfuscating the "graphql.query" tag and the
+	// "graphql.variables.*" tags for spans of type "graphql".
+	GraphQL Enablable `mapstructure:"graphql"`
+
+	// AWS holds the configuration for obfuscating the "aws.request.params.*" tags
+	// for spans of type "aws" and "dynamodb".
+	AWS AWSObfuscationConfig `mapstructure:"aws"`
+
+	// Message holds the configuration for redacting message header and body tags
+	// for spans of type "queue", "kafka" and "amqp".
+	Message MessageObfuscationConfig `mapstructure:"message"`
+
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards CreditCardsConfig `mapstructure:"credit_cards"`
 }
@@ -118,11 +153,13 @@ type AppSecConfig struct {
 func (o *ObfuscationConfig) Export() obfuscate.Config {
 	return obfuscate.Config{
 		SQL: obfuscate.SQLConfig{
-			TableNames:       features.Has("table_names"),
-			ReplaceDigits:    features.Has("quantize_sql_tables") || features.Has("replace_sql_digits"),
-			KeepSQLAlias:     features.Has("keep_sql_alias"),
-			DollarQuotedFunc: features.Has("dollar_quoted_func"),
-			Cache:            features.Has("sql_cache"),
+			TableNames:        features.Has("table_names"),
+			CollectProcedures: features.Has("sql_procedures"),
+			DBMSDialect:       features.Has("sql_dialects"),
+			ReplaceDigits:     features.Has("quantize_sql_tables") || features.Has("replace_sql_digits"),
+			KeepSQLAlias:      features.Has("keep_sql_alias"),
+			DollarQuotedFunc:  features.Has("dollar_quoted_func"),
+			Cache:             features.Has("sql_cache"),
 		},
 		ES: obfuscate.JSONConfig{
 			Enabled:            o.ES.Enabled,
@@ -148,6 +185,14 @@ func (o *ObfuscationConfig) Export() obfuscate.Config {
 			RemoveQueryString: o.HTTP.RemoveQueryString,
 			RemovePathDigits:  o.HTTP.RemovePathDigits,
 		},
+		AWS: obfuscate.AWSConfig{
+			Enabled:    o.AWS.Enabled,
+			KeepParams: o.AWS.KeepParams,
+		},
+		Message: obfuscate.MessageConfig{
+			Enabled:    o.Message.Enabled,
+			RedactTags: o.Message.RedactTags,
+		},
 		Logger: new(debugLogger),
 	}
 }
@@ -179,6 +224,26 @@ type HTTPObfuscationConfig struct {
 	RemovePathDigits bool `mapstructure:"remove_paths_with_digits" json:"remove_path_digits"`
 }
 
+// AWSObfuscationConfig holds the configuration settings for AWS SDK request parameter obfuscation.
+type AWSObfuscationConfig struct {
+	// Enabled specifies whether AWS request parameters should be obfuscated.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepParams specifies a set of request parameters for which their values will
+	// not be obfuscated, in addition to the ones known to be safe (e.g. "TableName").
+	KeepParams []string `mapstructure:"keep_params"`
+}
+
+// MessageObfuscationConfig holds the configuration settings for message queue tag obfuscation.
+type MessageObfuscationConfig struct {
+	// Enabled specifies whether message header and body tags should be redacted.
+	Enabled bool `mapstructure:"enabled"`
+
+	// RedactTags specifies the tags to redact. Entries ending in "*" match all tags
+	// having the given prefix. If empty, a default set of tags is used.
+	RedactTags []string `mapstructure:"redact_tags"`
+}
+
 // Enablable can represent any option that has an "enabled" boolean sub-field.
 type Enablable struct {
 	Enabled bool `mapstructure:"enabled"`
@@ -223,6 +288,34 @@ type ReplaceRule struct {
 	Repl string `mapstructure:"repl"`
 }
 
+// ResourceNormalization specifies how the resource names and URLs of HTTP spans are
+// templated before being aggregated into stats.
+type ResourceNormalization struct {
+	// Enabled specifies whether numeric, UUID and hexadecimal path segments are
+	// automatically replaced with "?".
+	Enabled bool `mapstructure:"enabled"`
+
+	// Services restricts the automatic templating to the given services. When empty,
+	// all services are templated.
+	Services []string `mapstructure:"services"`
+
+	// Routes holds user-supplied route patterns which are applied whether or not
+	// Enabled is set.
+	Routes []*RoutePattern `mapstructure:"routes"`
+}
+
+// RoutePattern specifies a route pattern, such as "/users/{id}/orders/*". Segments
+// enclosed in braces, starting with a colon or equal to "*" match any single path
+// segment and are replaced with "?" when the pattern matches.
+type RoutePattern struct {
+	// Service restricts the pattern to the given service. When empty, the pattern
+	// applies to all services.
+	Service string `mapstructure:"service"`
+
+	// Pattern specifies the route pattern.
+	Pattern string `mapstructure:"pattern"`
+}
+
 // WriterConfig specifies configuration for an API writer.
 type WriterConfig struct {
 	// ConnectionLimit specifies the maximum number of concurrent outgoing
@@ -338,6 +431,10 @@ type AgentConfig struct {
 
 	GUIPort string // the port of the Datadog Agent GUI (for control access)
 
+	// DebugTracesBufferSize specifies the number of recently received trace chunks kept in memory
+	// for local inspection on the /debug/traces endpoints. If not set (0), inspection is disabled.
+	DebugTracesBufferSize int
+
 	// Writers
 	SynchronousFlushing     bool // Mode where traces are only submitted when FlushAsync is called, used for Serverless Extension
 	StatsWriter             *WriterConfig
@@ -370,6 +467,10 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// ResourceNormalization specifies how the resource names of HTTP spans are templated
+	// to reduce their cardinality.
+	ResourceNormalization ResourceNormalization
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// tagHTTPURL is the tag holding the URL of HTTP spans.
+const tagHTTPURL = "http.url"
+
+// ResourceNormalizer templates the resource names and URLs of HTTP spans in order to
+// reduce their cardinality, e.g. "GET /users/12345" becomes "GET /users/?". It keeps
+// all spans.
+type ResourceNormalizer struct {
+	enabled  bool
+	services map[string]struct{}
+	routes   []route
+}
+
+// route is a compiled config.RoutePattern.
+type route struct {
+	service  string
+	segments []string
+	params   []bool // params[i] is true when segments[i] matches any segment
+}
+
+// NewResourceNormalizer returns a new ResourceNormalizer using the given configuration.
+func NewResourceNormalizer(conf config.ResourceNormalization) *ResourceNormalizer {
+	n := &ResourceNormalizer{enabled: conf.Enabled}
+	if len(conf.Services) > 0 {
+		n.services = make(map[string]struct{}, len(conf.Services))
+		for _, s := range conf.Services {
+			n.services[s] = struct{}{}
+		}
+	}
+	for _, r := range conf.Routes {
+		if r == nil || r.Pattern == "" {
+			continue
+		}
+		segments := strings.Split(strings.Trim(r.Pattern, "/"), "/")
+		params := make([]bool, len(segments))
+		for i, seg := range segments {
+			params[i] = isRouteParam(seg)
+		}
+		n.routes = append(n.routes, route{service: r.Service, segments: segments, params: params})
+	}
+	return n
+}
+
+// Active reports whether n modifies any span. A nil ResourceNormalizer is inactive.
+func (n *ResourceNormalizer) Active() bool {
+	return n != nil && (n.enabled || len(n.routes) > 0)
+}
+
+// Normalize templates the resource names and http.url tags of the HTTP spans in trace.
+func (n *ResourceNormalizer) Normalize(trace pb.Trace) {
+	if !n.Active() {
+		return
+	}
+	for _, s := range trace {
+		if !isHTTPSpan(s.Type, s.Meta) {
+			continue
+		}
+		s.Resource = n.templateResource(s.Service, s.Resource)
+		if u, ok := s.Meta[tagHTTPURL]; ok {
+			s.Meta[tagHTTPURL] = n.templateURL(s.Service, u)
+		}
+	}
+}
+
+// NormalizeStatsGroup templates the resource name of the given stats bucket group when
+// it aggregates HTTP spans.
+func (n *ResourceNormalizer) NormalizeStatsGroup(b *pb.ClientGroupedStats) {
+	if !n.Active() || !isHTTPSpan(b.Type, nil) {
+		return
+	}
+	b.Resource = n.templateResource(b.Service, b.Resource)
+}
+
+// isHTTPSpan reports whether a span of the given type and tags is an HTTP span.
+func isHTTPSpan(typ string, meta map[string]string) bool {
+	if typ == "http" || typ == "web" {
+		return true
+	}
+	_, ok := meta[tagHTTPURL]
+	return ok
+}
+
+// templateResource templates the path of a resource of the form "/path" or "METHOD /path".
+// Other resources are returned unchanged.
+func (n *ResourceNormalizer) templateResource(service, resource string) string {
+	start := 0
+	if i := strings.IndexByte(resource, ' '); i > 0 {
+		start = i + 1
+	}
+	if start >= len(resource) || resource[start] != '/' {
+		return resource
+	}
+	return resource[:start] + n.templatePath(service, resource[start:])
+}
+
+// templateURL templates the path of the given URL. Its scheme, host, query and
+// fragment are kept as is.
+func (n *ResourceNormalizer) templateURL(service, url string) string {
+	start := 0
+	if i := strings.Index(url, "://"); i >= 0 {
+		j := strings.IndexByte(url[i+3:], '/')
+		if j < 0 {
+			return url
+		}
+		start = i + 3 + j
+	}
+	if start >= len(url) || url[start] != '/' {
+		return url
+	}
+	return url[:start] + n.templatePath(service, url[start:])
+}
+
+// templatePath templates the given path, which starts with a slash and may end with
+// a query string or fragment. The first route pattern of service matching the path is
+// used. When none matches, numeric, UUID and hexadecimal segments are replaced with
+// "?" if the automatic templating is enabled for service.
+func (n *ResourceNormalizer) templatePath(service, path string) string {
+	suffix := ""
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path, suffix = path[:i], path[i:]
+	}
+	segments := strings.Split(path[1:], "/")
+	if r := n.matchRoute(service, segments); r != nil {
+		for i := range segments {
+			if r.params[i] {
+				segments[i] = "?"
+			}
+		}
+		return "/" + strings.Join(segments, "/") + suffix
+	}
+	if !n.enabled {
+		return path + suffix
+	}
+	if _, ok := n.services[service]; n.services != nil && !ok {
+		return path + suffix
+	}
+	for i, seg := range segments {
+		if isPathParam(seg) {
+			segments[i] = "?"
+		}
+	}
+	return "/" + strings.Join(segments, "/") + suffix
+}
+
+// matchRoute returns the first route of service matching the given path segments,
+// or nil if there is none.
+func (n *ResourceNormalizer) matchRoute(service string, segments []string) *route {
+outer:
+	for i := range n.routes {
+		r := &n.routes[i]
+		if (r.service != "" && r.service != service) || len(r.segments) != len(segments) {
+			continue
+		}
+		for j, seg := range segments {
+			if r.params[j] {
+				if seg == "" {
+					continue outer
+				}
+			} else if seg != r.segments[j] {
+				continue outer
+			}
+		}
+		return r
+	}
+	return nil
+}
+
+// isRouteParam reports whether the given route pattern segment matches any segment.
+func isRouteParam(seg string) bool {
+	return seg == "*" ||
+		(len(seg) > 1 && seg[0] == ':') ||
+		(len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}')
+}
+
+// isPathParam reports whether the given path segment looks like an identifier: a
+// number, a UUID or a hexadecimal string of at least 8 characters holding at least
+// one digit.
+func isPathParam(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	if isUUID(seg) {
+		return true
+	}
+	var digits, letters int
+	for i := 0; i < len(seg); i++ {
+		switch c := seg[i]; {
+		case c >= '0' && c <= '9':
+			digits++
+		case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			letters++
+		default:
+			return false
+		}
+	}
+	return letters == 0 || (digits > 0 && len(seg) >= 8)
+}
+
+// isUUID reports whether s is a UUID such as "123e4567-e89b-12d3-a456-426614174000".
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') && !(c >= 'A' && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
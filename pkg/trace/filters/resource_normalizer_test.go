@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func TestResourceNormalizer(t *testing.T) {
+	t.Run("auto", func(t *testing.T) {
+		n := NewResourceNormalizer(config.ResourceNormalization{Enabled: true})
+		for in, want := range map[string]string{
+			"GET /users/12345/orders/987":                     "GET /users/?/orders/?",
+			"GET /users/123e4567-e89b-12d3-a456-426614174000": "GET /users/?",
+			"GET /objects/507f1f77bcf86cd799439011/raw":       "GET /objects/?/raw",
+			"POST /v1/users/john":                             "POST /v1/users/john",
+			"GET /static/deadbeef":                            "GET /static/deadbeef",
+			"GET /static/a1b2":                                "GET /static/a1b2",
+			"/users/42?expand=true":                           "/users/??expand=true",
+			"GET /":                                           "GET /",
+			"GET":                                             "GET",
+			"UsersController#show":                            "UsersController#show",
+			"GET /users/42/":                                  "GET /users/?/",
+		} {
+			assert.Equal(t, want, n.templateResource("web", in), in)
+		}
+		for in, want := range map[string]string{
+			"http://example.com/users/42?page=3": "http://example.com/users/??page=3",
+			"https://example.com:8080/a/1#frag":  "https://example.com:8080/a/?#frag",
+			"https://example.com":                "https://example.com",
+			"/users/42":                          "/users/?",
+			"example.com/users/42":               "example.com/users/42",
+		} {
+			assert.Equal(t, want, n.templateURL("web", in), in)
+		}
+	})
+
+	t.Run("services", func(t *testing.T) {
+		n := NewResourceNormalizer(config.ResourceNormalization{Enabled: true, Services: []string{"web"}})
+		assert.Equal(t, "GET /users/?", n.templateResource("web", "GET /users/42"))
+		assert.Equal(t, "GET /users/42", n.templateResource("api", "GET /users/42"))
+	})
+
+	t.Run("routes", func(t *testing.T) {
+		n := NewResourceNormalizer(config.ResourceNormalization{
+			Routes: []*config.RoutePattern{
+				{Service: "web", Pattern: "/users/{name}/orders/:id"},
+				{Pattern: "/files/*"},
+				{Pattern: ""},
+			},
+		})
+		assert.True(t, n.Active())
+		assert.Equal(t, "GET /users/?/orders/?", n.templateResource("web", "GET /users/john/orders/abc"))
+		assert.Equal(t, "GET /users/john/orders/abc", n.templateResource("api", "GET /users/john/orders/abc"))
+		assert.Equal(t, "GET /users/john/orders", n.templateResource("web", "GET /users/john/orders"))
+		assert.Equal(t, "GET /files/?", n.templateResource("api", "GET /files/report.pdf"))
+		assert.Equal(t, "GET /files/a/b", n.templateResource("api", "GET /files/a/b"), "automatic templating is disabled")
+	})
+
+	t.Run("spans", func(t *testing.T) {
+		n := NewResourceNormalizer(config.ResourceNormalization{Enabled: true})
+		trace := pb.Trace{
+			{Service: "web", Type: "web", Resource: "GET /users/42"},
+			{Service: "web", Type: "http", Resource: "GET /users/42", Meta: map[string]string{"http.url": "http://api/users/42"}},
+			{Service: "web", Resource: "GET /users/42", Meta: map[string]string{"http.url": "/users/42"}},
+			{Service: "db", Type: "sql", Resource: "SELECT * FROM users WHERE id = 42"},
+		}
+		n.Normalize(trace)
+		assert.Equal(t, "GET /users/?", trace[0].Resource)
+		assert.Equal(t, "GET /users/?", trace[1].Resource)
+		assert.Equal(t, "http://api/users/?", trace[1].Meta["http.url"])
+		assert.Equal(t, "GET /users/?", trace[2].Resource)
+		assert.Equal(t, "/users/?", trace[2].Meta["http.url"])
+		assert.Equal(t, "SELECT * FROM users WHERE id = 42", trace[3].Resource)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		n := NewResourceNormalizer(config.ResourceNormalization{Enabled: true})
+		b := &pb.ClientGroupedStats{Service: "web", Type: "web", Resource: "GET /users/42"}
+		n.NormalizeStatsGroup(b)
+		assert.Equal(t, "GET /users/?", b.Resource)
+		b = &pb.ClientGroupedStats{Service: "db", Type: "sql", Resource: "/users/42"}
+		n.NormalizeStatsGroup(b)
+		assert.Equal(t, "/users/42", b.Resource)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		n := NewResourceNormalizer(config.ResourceNormalization{})
+		assert.False(t, n.Active())
+		trace := pb.Trace{{Service: "web", Type: "web", Resource: "GET /users/42"}}
+		n.Normalize(trace)
+		assert.Equal(t, "GET /users/42", trace[0].Resource)
+	})
+}
@@ -59,6 +59,9 @@ const (
     {{ range $i, $e := .Status.Config.Endpoints}}
     {{ $e.Host }}
     {{end}}
+  {{ with .Status.Config.ResourceNormalization }}{{ if or .Enabled .Routes }}
+  Resource normalization: {{if .Enabled}}enabled{{if .Services}} for {{join .Services ", "}}{{else}} for all services{{end}}{{else}}disabled{{end}}, {{len .Routes}} route pattern(s)
+  {{end}}{{end}}
 
   --- Receiver stats (1 min) ---
 
@@ -216,6 +219,7 @@ func InitInfo(conf *config.AgentConfig) error {
 		"percent": func(v float64) string {
 			return fmt.Sprintf("%02.1f", v*100)
 		},
+		"join": strings.Join,
 	}
 
 	once.Do(func() {
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PathPrefix is the path under which the recorder is served on the debug server.
+const PathPrefix = "/debug/traces"
+
+// Trace is the response of the trace endpoint. It holds all the recorded chunks of
+// a trace, most recent first.
+type Trace struct {
+	TraceID uint64      `json:"trace_id"`
+	Chunks  []ChunkTree `json:"chunks"`
+}
+
+// ChunkTree is a recorded chunk with its spans arranged as trees.
+type ChunkTree struct {
+	Chunk
+	Tree []*Node `json:"tree"`
+}
+
+// ServeHTTP implements http.Handler. It serves the following endpoints:
+//
+//	GET /debug/traces?service=<service>&resource=<resource>&error=true&limit=<n>
+//	GET /debug/traces/<trace_id>
+//
+// The first lists the recorded chunks matching the given filters, all of which are
+// optional. The second returns all the recorded chunks of the given trace along
+// with their span trees.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(req.URL.Path, PathPrefix), "/")
+	if id == "" {
+		r.serveList(w, req)
+		return
+	}
+	traceID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid trace ID", http.StatusBadRequest)
+		return
+	}
+	r.serveTrace(w, traceID)
+}
+
+func (r *Recorder) serveList(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	f := Filter{
+		Service:  q.Get("service"),
+		Resource: q.Get("resource"),
+	}
+	if v := q.Get("error"); v != "" {
+		errOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "error must be a boolean", http.StatusBadRequest)
+			return
+		}
+		f.Error = errOnly
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		f.Limit = n
+	}
+	chunks := r.Find(f)
+	if chunks == nil {
+		chunks = []*Chunk{}
+	}
+	writeJSON(w, chunks)
+}
+
+func (r *Recorder) serveTrace(w http.ResponseWriter, traceID uint64) {
+	chunks := r.Trace(traceID)
+	if len(chunks) == 0 {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return
+	}
+	t := Trace{TraceID: traceID, Chunks: make([]ChunkTree, len(chunks))}
+	for i, c := range chunks {
+		t.Chunks[i].Chunk = *c
+		t.Chunks[i].Chunk.Spans = nil
+		t.Chunks[i].Tree = Tree(c.Spans)
+	}
+	writeJSON(w, t)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRecorder(10)
+	record(r, 1, "web", "GET /users", false, true)
+	record(r, 2, "db", "SELECT * FROM users", true, false)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("list", func(t *testing.T) {
+		for path, want := range map[string][]uint64{
+			"/debug/traces":                  {2, 1},
+			"/debug/traces/":                 {2, 1},
+			"/debug/traces?service=web":      {1},
+			"/debug/traces?resource=SELECT":  {2},
+			"/debug/traces?error=true":       {2},
+			"/debug/traces?limit=1":          {2},
+			"/debug/traces?service=whatever": nil,
+		} {
+			rec := get(path)
+			require.Equal(t, http.StatusOK, rec.Code, path)
+			var chunks []*Chunk
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&chunks))
+			assert.Equal(t, want, traceIDs(chunks), path)
+		}
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		rec := get("/debug/traces/2")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var tr Trace
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&tr))
+		assert.EqualValues(t, 2, tr.TraceID)
+		require.Len(t, tr.Chunks, 1)
+		c := tr.Chunks[0]
+		assert.False(t, c.Sampled)
+		assert.Equal(t, "priority", c.Sampler)
+		assert.Nil(t, c.Spans)
+		require.Len(t, c.Tree, 1)
+		assert.EqualValues(t, 1, c.Tree[0].Span.SpanID)
+		require.Len(t, c.Tree[0].Children, 1)
+		assert.EqualValues(t, 2, c.Tree[0].Children[0].Span.SpanID)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/debug/traces/42").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/debug/traces/abc").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/debug/traces?error=maybe").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/debug/traces?limit=-1").Code)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/traces", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
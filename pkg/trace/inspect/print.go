@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// PrintTraces queries the agent running with the given configuration for the
+// recorded chunks matching f and writes them to w as a table.
+func PrintTraces(w io.Writer, conf *config.AgentConfig, f Filter) error {
+	q := url.Values{}
+	if f.Service != "" {
+		q.Set("service", f.Service)
+	}
+	if f.Resource != "" {
+		q.Set("resource", f.Resource)
+	}
+	if f.Error {
+		q.Set("error", "true")
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	var chunks []*Chunk
+	if err := get(conf, PathPrefix+"?"+q.Encode(), &chunks); err != nil {
+		return err
+	}
+	return RenderList(w, chunks)
+}
+
+// PrintTrace queries the agent running with the given configuration for the trace
+// with the given ID and writes its span trees to w.
+func PrintTrace(w io.Writer, conf *config.AgentConfig, traceID uint64) error {
+	var t Trace
+	if err := get(conf, fmt.Sprintf("%s/%d", PathPrefix, traceID), &t); err != nil {
+		return err
+	}
+	return RenderTrace(w, &t)
+}
+
+// get queries the given path on the debug server of the running agent and decodes
+// the JSON response into v.
+func get(conf *config.AgentConfig, path string, v interface{}) error {
+	client := http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://%s:%d%s", conf.ReceiverHost, conf.ReceiverPort, path))
+	if err != nil {
+		return fmt.Errorf("could not reach the trace-agent on port %d: %v", conf.ReceiverPort, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// RenderList writes the given chunks to w as a table.
+func RenderList(w io.Writer, chunks []*Chunk) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRACE ID\tSERVICE\tRESOURCE\tSPANS\tDURATION\tERROR\tDECISION\tRECEIVED")
+	for _, c := range chunks {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t%t\t%s\t%s\n",
+			c.TraceID,
+			c.Service,
+			truncate(c.Resource, 60),
+			c.SpanCount,
+			time.Duration(c.Duration),
+			c.Error,
+			decision(c),
+			c.Received.Format(time.RFC3339),
+		)
+	}
+	return tw.Flush()
+}
+
+// RenderTrace writes the span trees of all the chunks of t to w.
+func RenderTrace(w io.Writer, t *Trace) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Trace %d\n", t.TraceID)
+	for i, c := range t.Chunks {
+		fmt.Fprintf(&sb, "\nChunk %d/%d: %s (priority %d), env:%q host:%q received %s\n",
+			i+1, len(t.Chunks), decision(&c.Chunk), c.Priority, c.Env, c.Hostname, c.Received.Format(time.RFC3339))
+		for _, n := range c.Tree {
+			renderNode(&sb, n, "", "")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// renderNode writes n and its children to sb. prefix is written before n and
+// childPrefix before each of its children.
+func renderNode(sb *strings.Builder, n *Node, prefix, childPrefix string) {
+	s := n.Span
+	sb.WriteString(prefix)
+	fmt.Fprintf(sb, "%s %s [%s] %s", s.Name, truncate(s.Resource, 80), s.Service, time.Duration(s.Duration))
+	if s.Error != 0 {
+		sb.WriteString(" (error)")
+	}
+	sb.WriteByte('\n')
+	for i, child := range n.Children {
+		if i == len(n.Children)-1 {
+			renderNode(sb, child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			renderNode(sb, child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
+
+// decision returns a human readable description of the sampling decision taken on c.
+func decision(c *Chunk) string {
+	if c.Sampled {
+		return "kept by " + c.Sampler
+	}
+	return "dropped by " + c.Sampler
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
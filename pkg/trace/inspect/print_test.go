@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func TestRenderTrace(t *testing.T) {
+	spans := []*pb.Span{
+		{SpanID: 1, Name: "http.request", Service: "web", Resource: "GET /users", Duration: int64(10 * time.Millisecond)},
+		{SpanID: 2, ParentID: 1, Start: 1, Name: "db.query", Service: "db", Resource: "SELECT ?", Duration: int64(2 * time.Millisecond), Error: 1},
+		{SpanID: 3, ParentID: 2, Start: 2, Name: "db.fetch", Service: "db", Resource: "fetch", Duration: int64(time.Millisecond)},
+		{SpanID: 4, ParentID: 1, Start: 3, Name: "template.render", Service: "web", Resource: "users.html", Duration: int64(3 * time.Millisecond)},
+	}
+	tr := &Trace{
+		TraceID: 42,
+		Chunks: []ChunkTree{{
+			Chunk: Chunk{Env: "prod", Hostname: "host", Priority: 1, Sampled: true, Sampler: "priority", Received: time.Unix(0, 0).UTC()},
+			Tree:  Tree(spans),
+		}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, RenderTrace(&buf, tr))
+	assert.Equal(t, `Trace 42
+
+Chunk 1/1: kept by priority (priority 1), env:"prod" host:"host" received 1970-01-01T00:00:00Z
+http.request GET /users [web] 10ms
+├── db.query SELECT ? [db] 2ms (error)
+│   └── db.fetch fetch [db] 1ms
+└── template.render users.html [web] 3ms
+`, buf.String())
+}
+
+func TestPrintTraces(t *testing.T) {
+	r := NewRecorder(10)
+	record(r, 1, "web", "GET /users", false, true)
+	record(r, 2, "db", "SELECT * FROM users", true, false)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	conf := &config.AgentConfig{ReceiverHost: u.Hostname(), ReceiverPort: port}
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintTraces(&buf, conf, Filter{Error: true}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "TRACE ID"))
+	assert.Contains(t, lines[1], "SELECT * FROM users")
+	assert.Contains(t, lines[1], "dropped by priority")
+
+	buf.Reset()
+	require.NoError(t, PrintTrace(&buf, conf, 1))
+	assert.Contains(t, buf.String(), "Trace 1\n")
+	assert.Contains(t, buf.String(), "kept by priority")
+
+	assert.Error(t, PrintTrace(&buf, conf, 3))
+}
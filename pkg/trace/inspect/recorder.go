@@ -0,0 +1,197 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package inspect keeps a bounded history of the trace chunks received by the agent,
+// along with the sampling decisions taken on them, so that they can be inspected
+// locally through the debug server without going to the backend.
+package inspect
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// Chunk holds a trace chunk received by the agent and the sampling decision taken on it.
+type Chunk struct {
+	TraceID       uint64    `json:"trace_id"`
+	Received      time.Time `json:"received"`
+	Service       string    `json:"service"`
+	Name          string    `json:"name"`
+	Resource      string    `json:"resource"`
+	Env           string    `json:"env"`
+	Hostname      string    `json:"hostname"`
+	ContainerID   string    `json:"container_id,omitempty"`
+	LanguageName  string    `json:"language_name,omitempty"`
+	TracerVersion string    `json:"tracer_version,omitempty"`
+	Start         int64     `json:"start"`
+	Duration      int64     `json:"duration"`
+	Error         bool      `json:"error"`
+	SpanCount     int       `json:"span_count"`
+	Priority      int32     `json:"priority"`
+
+	// Sampled reports whether the chunk was kept. Sampler is the name of the
+	// sampler which took the decision.
+	Sampled bool   `json:"sampled"`
+	Sampler string `json:"sampler"`
+
+	// Spans holds a copy of all the spans of the chunk. It is omitted when listing.
+	Spans []*pb.Span `json:"spans,omitempty"`
+}
+
+// Filter specifies which chunks should be returned by (*Recorder).Find.
+type Filter struct {
+	// Service, if set, only matches chunks with this root service.
+	Service string
+	// Resource, if set, only matches chunks with a root resource containing it.
+	Resource string
+	// Error, if set, only matches chunks with at least one span in error.
+	Error bool
+	// Limit is the maximum number of chunks to return. Zero means no limit.
+	Limit int
+}
+
+func (f *Filter) matches(c *Chunk) bool {
+	if f.Service != "" && c.Service != f.Service {
+		return false
+	}
+	if f.Resource != "" && !strings.Contains(c.Resource, f.Resource) {
+		return false
+	}
+	if f.Error && !c.Error {
+		return false
+	}
+	return true
+}
+
+// Recorder is a fixed size ring buffer of the most recent chunks received by the agent.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.RWMutex
+	chunks []*Chunk // ring buffer of recorded chunks
+	next   int      // index in chunks where the next chunk will be written
+	full   bool     // true once the buffer has wrapped around
+}
+
+// NewRecorder returns a Recorder which keeps the last size chunks.
+func NewRecorder(size int) *Recorder {
+	if size < 1 {
+		size = 1
+	}
+	return &Recorder{chunks: make([]*Chunk, size)}
+}
+
+// Record adds the given chunk coming from the tracer payload tp to the recorder. root is the
+// root span of the chunk; sampled and sampler describe the sampling decision. The spans are
+// copied so that the chunk can safely be modified further down the pipeline.
+func (r *Recorder) Record(now time.Time, tp *pb.TracerPayload, chunk *pb.TraceChunk, root *pb.Span, sampled bool, sampler string) {
+	c := &Chunk{
+		TraceID:       root.TraceID,
+		Received:      now,
+		Service:       root.Service,
+		Name:          root.Name,
+		Resource:      root.Resource,
+		Env:           tp.Env,
+		Hostname:      tp.Hostname,
+		ContainerID:   tp.ContainerID,
+		LanguageName:  tp.LanguageName,
+		TracerVersion: tp.TracerVersion,
+		Start:         root.Start,
+		Duration:      root.Duration,
+		SpanCount:     len(chunk.Spans),
+		Priority:      chunk.Priority,
+		Sampled:       sampled,
+		Sampler:       sampler,
+		Spans:         make([]*pb.Span, len(chunk.Spans)),
+	}
+	for i, s := range chunk.Spans {
+		c.Spans[i] = copySpan(s)
+		if s.Error != 0 {
+			c.Error = true
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chunks[r.next] = c
+	r.next++
+	if r.next == len(r.chunks) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// each calls fn on all recorded chunks, from the most recent to the oldest, until it returns false.
+// It must be called with the read lock held.
+func (r *Recorder) each(fn func(c *Chunk) bool) {
+	n := r.next
+	if r.full {
+		n = len(r.chunks)
+	}
+	for i := 1; i <= n; i++ {
+		c := r.chunks[(r.next-i+len(r.chunks))%len(r.chunks)]
+		if !fn(c) {
+			return
+		}
+	}
+}
+
+// Find returns the recorded chunks matching f, most recent first. The returned
+// chunks do not include spans.
+func (r *Recorder) Find(f Filter) []*Chunk {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []*Chunk
+	r.each(func(c *Chunk) bool {
+		if !f.matches(c) {
+			return true
+		}
+		summary := *c
+		summary.Spans = nil
+		out = append(out, &summary)
+		return f.Limit <= 0 || len(out) < f.Limit
+	})
+	return out
+}
+
+// Trace returns all the recorded chunks belonging to the trace with the given ID, most
+// recent first. Chunks are returned along with their spans.
+func (r *Recorder) Trace(traceID uint64) []*Chunk {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []*Chunk
+	r.each(func(c *Chunk) bool {
+		if c.TraceID == traceID {
+			out = append(out, c)
+		}
+		return true
+	})
+	return out
+}
+
+// copySpan returns a copy of s which does not share its maps with the original.
+func copySpan(s *pb.Span) *pb.Span {
+	cp := *s
+	if s.Meta != nil {
+		cp.Meta = make(map[string]string, len(s.Meta))
+		for k, v := range s.Meta {
+			cp.Meta[k] = v
+		}
+	}
+	if s.Metrics != nil {
+		cp.Metrics = make(map[string]float64, len(s.Metrics))
+		for k, v := range s.Metrics {
+			cp.Metrics[k] = v
+		}
+	}
+	if s.MetaStruct != nil {
+		cp.MetaStruct = make(map[string][]byte, len(s.MetaStruct))
+		for k, v := range s.MetaStruct {
+			cp.MetaStruct[k] = v
+		}
+	}
+	return &cp
+}
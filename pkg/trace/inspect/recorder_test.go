@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func record(r *Recorder, traceID uint64, service, resource string, isErr bool, sampled bool) {
+	root := &pb.Span{TraceID: traceID, SpanID: 1, Service: service, Resource: resource, Meta: map[string]string{"k": "v"}}
+	if isErr {
+		root.Error = 1
+	}
+	child := &pb.Span{TraceID: traceID, SpanID: 2, ParentID: 1, Service: service}
+	chunk := &pb.TraceChunk{Priority: 1, Spans: []*pb.Span{root, child}}
+	tp := &pb.TracerPayload{Env: "prod", Hostname: "host", Chunks: []*pb.TraceChunk{chunk}}
+	r.Record(time.Now(), tp, chunk, root, sampled, "priority")
+}
+
+func traceIDs(chunks []*Chunk) []uint64 {
+	var ids []uint64
+	for _, c := range chunks {
+		ids = append(ids, c.TraceID)
+	}
+	return ids
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("ring", func(t *testing.T) {
+		r := NewRecorder(3)
+		assert.Empty(t, r.Find(Filter{}))
+		record(r, 1, "web", "GET /", false, true)
+		record(r, 2, "web", "GET /", false, true)
+		assert.Equal(t, []uint64{2, 1}, traceIDs(r.Find(Filter{})))
+		for i := uint64(3); i <= 5; i++ {
+			record(r, i, "web", "GET /", false, true)
+		}
+		assert.Equal(t, []uint64{5, 4, 3}, traceIDs(r.Find(Filter{})))
+		assert.Empty(t, r.Trace(1))
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		r := NewRecorder(10)
+		record(r, 1, "web", "GET /users", false, true)
+		record(r, 2, "db", "SELECT * FROM users", true, false)
+		record(r, 3, "web", "POST /users", true, true)
+		record(r, 4, "web", "GET /orders", false, false)
+
+		assert.Equal(t, []uint64{4, 3, 1}, traceIDs(r.Find(Filter{Service: "web"})))
+		assert.Equal(t, []uint64{3, 2, 1}, traceIDs(r.Find(Filter{Resource: "users"})))
+		assert.Equal(t, []uint64{3, 2}, traceIDs(r.Find(Filter{Error: true})))
+		assert.Equal(t, []uint64{3}, traceIDs(r.Find(Filter{Service: "web", Error: true})))
+		assert.Equal(t, []uint64{4, 3}, traceIDs(r.Find(Filter{Limit: 2})))
+		assert.Empty(t, r.Find(Filter{Service: "unknown"}))
+		for _, c := range r.Find(Filter{}) {
+			assert.Nil(t, c.Spans)
+			assert.Equal(t, 2, c.SpanCount)
+		}
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		r := NewRecorder(10)
+		record(r, 1, "web", "GET /", false, true)
+		record(r, 2, "db", "SELECT", false, false)
+		record(r, 1, "worker", "job", false, true)
+
+		chunks := r.Trace(1)
+		assert.Len(t, chunks, 2)
+		assert.Equal(t, "worker", chunks[0].Service)
+		assert.Equal(t, "web", chunks[1].Service)
+		assert.Len(t, chunks[0].Spans, 2)
+		assert.Equal(t, "prod", chunks[0].Env)
+		assert.Equal(t, "host", chunks[0].Hostname)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		r := NewRecorder(1)
+		root := &pb.Span{TraceID: 1, SpanID: 1, Resource: "GET /", Meta: map[string]string{"k": "v"}}
+		chunk := &pb.TraceChunk{Spans: []*pb.Span{root}}
+		r.Record(time.Now(), &pb.TracerPayload{}, chunk, root, true, "priority")
+		root.Resource = "changed"
+		root.Meta["k"] = "changed"
+		span := r.Trace(1)[0].Spans[0]
+		assert.Equal(t, "GET /", span.Resource)
+		assert.Equal(t, "v", span.Meta["k"])
+	})
+}
+
+func TestTree(t *testing.T) {
+	spans := []*pb.Span{
+		{SpanID: 3, ParentID: 1, Start: 20},
+		{SpanID: 1, ParentID: 0, Start: 0},
+		{SpanID: 2, ParentID: 1, Start: 10},
+		{SpanID: 4, ParentID: 2, Start: 15},
+		{SpanID: 5, ParentID: 42, Start: 5}, // orphan
+	}
+	roots := Tree(spans)
+	assert.Len(t, roots, 2)
+	assert.EqualValues(t, 1, roots[0].Span.SpanID)
+	assert.EqualValues(t, 5, roots[1].Span.SpanID)
+	assert.Len(t, roots[0].Children, 2)
+	assert.EqualValues(t, 2, roots[0].Children[0].Span.SpanID)
+	assert.EqualValues(t, 3, roots[0].Children[1].Span.SpanID)
+	assert.EqualValues(t, 4, roots[0].Children[0].Children[0].Span.SpanID)
+}
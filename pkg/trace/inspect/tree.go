@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// Node is a span along with its children, ordered by start time.
+type Node struct {
+	Span     *pb.Span `json:"span"`
+	Children []*Node  `json:"children,omitempty"`
+}
+
+// Tree arranges the given spans into trees using their parent IDs. Spans whose parent
+// is not part of spans are returned as roots. Roots and children are sorted by start time.
+func Tree(spans []*pb.Span) []*Node {
+	nodes := make(map[uint64]*Node, len(spans))
+	for _, s := range spans {
+		nodes[s.SpanID] = &Node{Span: s}
+	}
+	var roots []*Node
+	for _, s := range spans {
+		n := nodes[s.SpanID]
+		if parent, ok := nodes[s.ParentID]; ok && s.ParentID != s.SpanID {
+			parent.Children = append(parent.Children, n)
+			continue
+		}
+		roots = append(roots, n)
+	}
+	sortNodes(roots)
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+	return roots
+}
+
+func sortNodes(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Span.Start < nodes[j].Span.Start
+	})
+}
@@ -16,9 +16,14 @@ import (
 // MarshalMsg implements msgp.Marshaler
 func (z *Span) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 13
+	// map header, size 13, or 14 when span_links is set
+	if len(z.SpanLinks) > 0 {
+		o = append(o, 0x8e)
+	} else {
+		o = append(o, 0x8d)
+	}
 	// string "service"
-	o = append(o, 0x8d, 0xa7, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65)
+	o = append(o, 0xa7, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65)
 	o = msgp.AppendString(o, z.Service)
 	// string "name"
 	o = append(o, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
@@ -69,6 +74,22 @@ func (z *Span) MarshalMsg(b []byte) (o []byte, err error) {
 		o = msgp.AppendString(o, za0005)
 		o = msgp.AppendBytes(o, za0006)
 	}
+	if len(z.SpanLinks) > 0 {
+		// string "span_links"
+		o = append(o, 0xaa, 0x73, 0x70, 0x61, 0x6e, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73)
+		o = msgp.AppendArrayHeader(o, uint32(len(z.SpanLinks)))
+		for _, za0007 := range z.SpanLinks {
+			if za0007 == nil {
+				o = msgp.AppendNil(o)
+				continue
+			}
+			o, err = za0007.MarshalMsg(o)
+			if err != nil {
+				err = msgp.WrapError(err, "SpanLinks")
+				return
+			}
+		}
+	}
 	return
 }
 
@@ -260,6 +281,41 @@ func (z *Span) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				}
 				z.MetaStruct[za0005] = za0006
 			}
+		case "span_links":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				z.SpanLinks = nil
+				break
+			}
+			var zb0005 uint32
+			zb0005, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "SpanLinks")
+				return
+			}
+			if cap(z.SpanLinks) >= int(zb0005) {
+				z.SpanLinks = z.SpanLinks[:zb0005]
+			} else {
+				z.SpanLinks = make([]*SpanLink, zb0005)
+			}
+			for za0007 := range z.SpanLinks {
+				if msgp.IsNil(bts) {
+					bts, err = msgp.ReadNilBytes(bts)
+					if err != nil {
+						return
+					}
+					z.SpanLinks[za0007] = nil
+					continue
+				}
+				if z.SpanLinks[za0007] == nil {
+					z.SpanLinks[za0007] = new(SpanLink)
+				}
+				bts, err = z.SpanLinks[za0007].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "SpanLinks", za0007)
+					return
+				}
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -295,5 +351,176 @@ func (z *Span) Msgsize() (s int) {
 			s += msgp.StringPrefixSize + len(za0005) + msgp.BytesPrefixSize + len(za0006)
 		}
 	}
+	if len(z.SpanLinks) > 0 {
+		s += 11 + msgp.ArrayHeaderSize
+		for _, za0007 := range z.SpanLinks {
+			if za0007 == nil {
+				s += msgp.NilSize
+			} else {
+				s += za0007.Msgsize()
+			}
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *SpanLink) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// omit empty optional fields
+	sz := uint32(6)
+	if z.TraceIDHigh == 0 {
+		sz--
+	}
+	if len(z.Attributes) == 0 {
+		sz--
+	}
+	if z.Tracestate == "" {
+		sz--
+	}
+	if z.Flags == 0 {
+		sz--
+	}
+	o = msgp.AppendMapHeader(o, sz)
+	// string "trace_id"
+	o = append(o, 0xa8, 0x74, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64)
+	o = msgp.AppendUint64(o, z.TraceID)
+	if z.TraceIDHigh != 0 {
+		// string "trace_id_high"
+		o = append(o, 0xad, 0x74, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x5f, 0x68, 0x69, 0x67, 0x68)
+		o = msgp.AppendUint64(o, z.TraceIDHigh)
+	}
+	// string "span_id"
+	o = append(o, 0xa7, 0x73, 0x70, 0x61, 0x6e, 0x5f, 0x69, 0x64)
+	o = msgp.AppendUint64(o, z.SpanID)
+	if len(z.Attributes) > 0 {
+		// string "attributes"
+		o = append(o, 0xaa, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73)
+		o = msgp.AppendMapHeader(o, uint32(len(z.Attributes)))
+		for za0001, za0002 := range z.Attributes {
+			o = msgp.AppendString(o, za0001)
+			o = msgp.AppendString(o, za0002)
+		}
+	}
+	if z.Tracestate != "" {
+		// string "tracestate"
+		o = append(o, 0xaa, 0x74, 0x72, 0x61, 0x63, 0x65, 0x73, 0x74, 0x61, 0x74, 0x65)
+		o = msgp.AppendString(o, z.Tracestate)
+	}
+	if z.Flags != 0 {
+		// string "flags"
+		o = append(o, 0xa5, 0x66, 0x6c, 0x61, 0x67, 0x73)
+		o = msgp.AppendUint32(o, z.Flags)
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *SpanLink) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "trace_id":
+			z.TraceID, bts, err = parseUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TraceID")
+				return
+			}
+		case "trace_id_high":
+			z.TraceIDHigh, bts, err = parseUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TraceIDHigh")
+				return
+			}
+		case "span_id":
+			z.SpanID, bts, err = parseUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "SpanID")
+				return
+			}
+		case "attributes":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				z.Attributes = nil
+				break
+			}
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Attributes")
+				return
+			}
+			if z.Attributes == nil && zb0002 > 0 {
+				z.Attributes = make(map[string]string, zb0002)
+			} else if len(z.Attributes) > 0 {
+				for key := range z.Attributes {
+					delete(z.Attributes, key)
+				}
+			}
+			for zb0002 > 0 {
+				var za0001 string
+				var za0002 string
+				zb0002--
+				za0001, bts, err = parseStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Attributes")
+					return
+				}
+				za0002, bts, err = parseStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Attributes", za0001)
+					return
+				}
+				z.Attributes[za0001] = za0002
+			}
+		case "tracestate":
+			z.Tracestate, bts, err = parseStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Tracestate")
+				return
+			}
+		case "flags":
+			var flags uint64
+			flags, bts, err = parseUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Flags")
+				return
+			}
+			z.Flags = uint32(flags)
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *SpanLink) Msgsize() (s int) {
+	s = 1 + 9 + msgp.Uint64Size + 14 + msgp.Uint64Size + 8 + msgp.Uint64Size + 11 + msgp.MapHeaderSize
+	if z.Attributes != nil {
+		for za0001, za0002 := range z.Attributes {
+			_ = za0002
+			s += msgp.StringPrefixSize + len(za0001) + msgp.StringPrefixSize + len(za0002)
+		}
+	}
+	s += 11 + msgp.StringPrefixSize + len(z.Tracestate) + 6 + msgp.Uint32Size
 	return
 }
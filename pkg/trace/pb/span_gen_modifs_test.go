@@ -410,3 +410,40 @@ func TestDeserialization(t *testing.T) {
 		})
 	})
 }
+
+func TestSpanLinks(t *testing.T) {
+	span := &Span{
+		Service: "consumer",
+		SpanID:  3,
+		SpanLinks: []*SpanLink{
+			{TraceID: 1, TraceIDHigh: 2, SpanID: 42, Attributes: map[string]string{"link.kind": "producer"}, Tracestate: "dd=s:1", Flags: 1},
+			{TraceID: 7, SpanID: 8},
+		},
+	}
+
+	t.Run("msgp", func(t *testing.T) {
+		bts, err := span.MarshalMsg(nil)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(bts), span.Msgsize())
+		got, err := decodeBytes(bts)
+		assert.NoError(t, err)
+		assert.Equal(t, span.SpanLinks, got.SpanLinks)
+	})
+
+	t.Run("msgp-empty", func(t *testing.T) {
+		bts, err := (&Span{Service: "consumer"}).MarshalMsg(nil)
+		assert.NoError(t, err)
+		got, err := decodeBytes(bts)
+		assert.NoError(t, err)
+		assert.Nil(t, got.SpanLinks)
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		bts, err := span.Marshal()
+		assert.NoError(t, err)
+		assert.Len(t, bts, span.Size())
+		var got Span
+		assert.NoError(t, got.Unmarshal(bts))
+		assert.Equal(t, span.SpanLinks, got.SpanLinks)
+	})
+}
@@ -29,6 +29,15 @@ type OTLPSpanEvent struct {
 	Dropped    uint32                 `json:"dropped_attributes_count"`
 }
 
+// OTLPSpanLink defines an OTLP test span link.
+type OTLPSpanLink struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceState string
+	Attributes map[string]interface{}
+	Dropped    uint32
+}
+
 // OTLPSpan defines an OTLP test span.
 type OTLPSpan struct {
 	TraceID    [16]byte
@@ -40,6 +49,7 @@ type OTLPSpan struct {
 	Start, End uint64
 	Attributes map[string]interface{}
 	Events     []OTLPSpanEvent
+	Links      []OTLPSpanLink
 	StatusMsg  string
 	StatusCode ptrace.StatusCode
 }
@@ -87,6 +97,15 @@ func setOTLPSpan(span ptrace.Span, s *OTLPSpan) {
 		insertAttributes(ev.Attributes(), e.Attributes)
 		ev.SetDroppedAttributesCount(e.Dropped)
 	}
+	links := span.Links()
+	for _, l := range s.Links {
+		link := links.AppendEmpty()
+		link.SetTraceID(pcommon.NewTraceID(l.TraceID))
+		link.SetSpanID(pcommon.NewSpanID(l.SpanID))
+		link.SetTraceState(ptrace.TraceState(l.TraceState))
+		insertAttributes(link.Attributes(), l.Attributes)
+		link.SetDroppedAttributesCount(l.Dropped)
+	}
 	span.Status().SetCode(s.StatusCode)
 	span.Status().SetMessage(s.StatusMsg)
 }
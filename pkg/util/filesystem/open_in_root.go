@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+)
+
+// secureJoinOpen opens a file for reading after resolving each component of its path, including the targets of
+// symlinks, inside a root directory with securejoin. The path is then opened without following a final symlink, so
+// that a file can't be swapped for one outside of the root directory after its path was resolved.
+func secureJoinOpen(rootPath, path string) (*os.File, error) {
+	resolved, err := securejoin.SecureJoin(rootPath, path)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(resolved, os.O_RDONLY|noFollowFlag, 0)
+}
+
+// relativeToRoot returns a path relative to a root directory, which can't reference its parents
+func relativeToRoot(path string) string {
+	return strings.TrimPrefix(filepath.Clean("/"+path), "/")
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+const noFollowFlag = unix.O_NOFOLLOW
+
+// OpenInRoot opens a file, or a directory, for reading as if root was the root directory of the filesystem: absolute
+// symlinks and `..` components are resolved inside root and can't escape it. This is meant to read the files of a
+// container from /proc/<pid>/root, which are controlled by the container. It uses openat2 and RESOLVE_IN_ROOT when
+// available (kernel 5.6+), and resolves each component of the path with securejoin otherwise.
+func OpenInRoot(root *os.File, path string) (*os.File, error) {
+	fd, err := unix.Openat2(int(root.Fd()), relativeToRoot(path), &unix.OpenHow{
+		Flags:   unix.O_RDONLY | unix.O_CLOEXEC,
+		Resolve: unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_MAGICLINKS,
+	})
+	if errors.Is(err, unix.ENOSYS) {
+		// the root directory is reached through its file descriptor, as its path may not exist anymore
+		return secureJoinOpen("/proc/self/fd/"+strconv.Itoa(int(root.Fd())), path)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "openat2", Path: path, Err: err}
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package filesystem
+
+import (
+	"os"
+)
+
+const noFollowFlag = 0
+
+// OpenInRoot opens a file, or a directory, for reading as if root was the root directory of the filesystem: absolute
+// symlinks and `..` components are resolved inside root with securejoin and can't escape it.
+func OpenInRoot(root *os.File, path string) (*os.File, error) {
+	return secureJoinOpen(root.Name(), relativeToRoot(path))
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+// +build !windows
+
+package filesystem
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenInRoot(t *testing.T) {
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("outside"), 0644))
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, outside), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, outside, "secret"), []byte("inside"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "os-release"), []byte("ID=debian"), 0644))
+	// absolute symlinks, in the middle or at the end of a path, and parent references are resolved inside root
+	require.NoError(t, os.Symlink("/", filepath.Join(root, "etc", "host")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "etc", "secret")))
+	require.NoError(t, os.Symlink("../../../../../etc/os-release", filepath.Join(root, "etc", "release")))
+
+	rootDir, err := os.Open(root)
+	require.NoError(t, err)
+	defer rootDir.Close()
+
+	for path, content := range map[string]string{
+		"/etc/os-release": "ID=debian",
+		"/etc/release":    "ID=debian",
+		"/etc/secret":     "inside",
+		filepath.Join("/etc/host", outside, "secret"): "inside",
+		filepath.Join("../../..", outside, "secret"):  "inside",
+	} {
+		for name, open := range map[string]func() (*os.File, error){
+			"OpenInRoot":     func() (*os.File, error) { return OpenInRoot(rootDir, path) },
+			"secureJoinOpen": func() (*os.File, error) { return secureJoinOpen(root, path) },
+		} {
+			f, err := open()
+			require.NoError(t, err, "%s %s", name, path)
+			data, err := io.ReadAll(f)
+			f.Close()
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data), "%s %s", name, path)
+		}
+	}
+}
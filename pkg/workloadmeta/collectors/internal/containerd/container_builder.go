@@ -46,6 +46,14 @@ func buildWorkloadMetaContainer(container containerd.Container, containerdClient
 		log.Debugf("cannot split image name %q: %s", info.Image, err)
 	}
 
+	// the image ID is the digest of the image the container was created from,
+	// which doesn't change when its name is moved to another image
+	if img, err := containerdClient.Image(container); err != nil {
+		log.Debugf("cannot get the image of container %q: %s", container.ID(), err)
+	} else {
+		image.ID = img.Target().Digest.String()
+	}
+
 	status, err := containerdClient.Status(container)
 	if err != nil {
 		if !errdefs.IsNotFound(err) {
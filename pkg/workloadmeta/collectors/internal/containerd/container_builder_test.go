@@ -16,6 +16,7 @@ import (
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/containers"
 	"github.com/containerd/containerd/oci"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
 
@@ -34,19 +35,25 @@ func (m *mockedContainer) ID() string {
 
 type mockedImage struct {
 	containerd.Image
-	mockName func() string
+	mockName   func() string
+	mockTarget func() ocispec.Descriptor
 }
 
 func (m *mockedImage) Name() string {
 	return m.mockName()
 }
 
+func (m *mockedImage) Target() ocispec.Descriptor {
+	return m.mockTarget()
+}
+
 func TestBuildWorkloadMetaContainer(t *testing.T) {
 	containerID := "10"
 	labels := map[string]string{
 		"some_label": "some_val",
 	}
 	imgName := "datadog/agent:7"
+	const imgDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 	envVarStrs := []string{
 		"test_env=test_val",
 	}
@@ -79,6 +86,13 @@ func TestBuildWorkloadMetaContainer(t *testing.T) {
 				Image:     imgName,
 			}, nil
 		},
+		MockImage: func(ctn containerd.Container) (containerd.Image, error) {
+			return &mockedImage{
+				mockTarget: func() ocispec.Descriptor {
+					return ocispec.Descriptor{Digest: imgDigest}
+				},
+			}, nil
+		},
 		MockSpec: func(ctn containerd.Container) (*oci.Spec, error) {
 			return &oci.Spec{Hostname: hostName, Process: &specs.Process{Env: envVarStrs}}, nil
 		},
@@ -103,6 +117,7 @@ func TestBuildWorkloadMetaContainer(t *testing.T) {
 			Labels: labels,
 		},
 		Image: workloadmeta.ContainerImage{
+			ID:        imgDigest,
 			RawName:   "datadog/agent:7",
 			Name:      "datadog/agent",
 			ShortName: "agent",
@@ -20,6 +20,7 @@ import (
 	"github.com/containerd/containerd/oci"
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/DataDog/datadog-agent/pkg/util/containerd/fake"
@@ -295,6 +296,9 @@ func containerdClient(container containerd.Container) fake.MockedContainerdClien
 				mockName: func() string {
 					return imgName
 				},
+				mockTarget: func() ocispec.Descriptor {
+					return ocispec.Descriptor{}
+				},
 			}, nil
 		},
 		MockEnvVars: func(ctn containerd.Container) (map[string]string, error) {
@@ -272,6 +272,8 @@ func (c *collector) buildCollectorEvent(ctx context.Context, ev *docker.Containe
 func extractImage(ctx context.Context, container types.ContainerJSON, resolve resolveHook) workloadmeta.ContainerImage {
 	imageSpec := container.Config.Image
 	image := workloadmeta.ContainerImage{
+		// the ID of the image the container was created from, which doesn't change when its tag is moved
+		ID:      container.Image,
 		RawName: imageSpec,
 		Name:    imageSpec,
 	}